@@ -0,0 +1,24 @@
+package entity
+
+import "time"
+
+// AuditLog records an action taken by a user, or by the app when ActorID is 0.
+// Before and After hold the state of the target as json before and after the
+// action. With hash chaining Hash covers the record and PrevHash, the Hash of
+// the chained record before it, so a changed or removed record breaks the
+// chain.
+type AuditLog struct {
+	ID         int    `gorm:"primarykey"`
+	ActorID    int    `gorm:"index"`
+	Action     string `gorm:"size:64;index"`
+	TargetType string `gorm:"size:64"`
+	TargetID   int
+	IP         string `gorm:"size:64"`
+	UserAgent  string `gorm:"size:255"`
+	RequestID  string `gorm:"size:64;index"`
+	Before     string `gorm:"type:text"`
+	After      string `gorm:"type:text"`
+	PrevHash   string `gorm:"size:64"`
+	Hash       string `gorm:"size:64"`
+	CreatedAt  time.Time
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// ChannelIdentity links the user of a messaging channel, identified by
+// ExternalID on that Channel, to the user whose questions they ask.
+// ConversationID is the conversation they are continuing, 0 until the first
+// question or after they started a new one.
+type ChannelIdentity struct {
+	ID             int    `gorm:"primarykey"`
+	Channel        string `gorm:"size:32;uniqueIndex:idx_channel_identity,priority:1"`
+	ExternalID     string `gorm:"size:128;uniqueIndex:idx_channel_identity,priority:2"`
+	UserID         int    `gorm:"index"`
+	Username       string
+	ConversationID int
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
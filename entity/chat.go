@@ -6,12 +6,26 @@ import (
 	"gorm.io/gorm"
 )
 
+// Chat is a message of a conversation. Message is stored encrypted under the
+// master key KeyID, or as it is when KeyID is empty. An anonymized message was
+// emptied by a retention policy.
 type Chat struct {
-	ID        int `gorm:"primarykey"`
-	UserID    int
-	Name      string
-	Message   string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID             int `gorm:"primarykey"`
+	UserID         int
+	ConversationID int `gorm:"index"`
+	PersonaID      int
+	PromptVersion  int
+	Model          string
+	TemplateID     int
+	Name           string
+	Message        string
+	KeyID          string `gorm:"size:64;not null;default:'';index"`
+	ExternalID     string `gorm:"index"`
+	Starred        bool
+	Anonymized     bool `gorm:"not null;default:false;index"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+	ToolCalls      []ToolCall
+	Attachments    []ChatAttachment
 }
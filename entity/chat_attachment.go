@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// ChatAttachment is a file sent with a chat message. The file itself is kept
+// in the blob storage under StorageKey. Audio attachments also keep the text
+// spoken in them with its language and duration.
+type ChatAttachment struct {
+	ID          int `gorm:"primarykey"`
+	ChatID      int `gorm:"index"`
+	UserID      int `gorm:"index"`
+	Kind        string
+	Name        string
+	ContentType string
+	Size        int
+	StorageKey  string
+	Transcript  string `gorm:"type:text"`
+	Language    string
+	Duration    float64
+	CreatedAt   time.Time
+}
@@ -0,0 +1,22 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChatModel is an entry of the admin maintained allowlist of models users
+// may request, together with the limits that apply to that model.
+type ChatModel struct {
+	ID                 int    `gorm:"primarykey"`
+	Name               string `gorm:"uniqueIndex;size:100"`
+	MaxTokens          int
+	MaxTemperature     float32
+	SupportsJsonFormat bool
+	SupportsVision     bool
+	Enabled            bool
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	DeletedAt          gorm.DeletedAt `gorm:"index"`
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Collection is a knowledge base of documents owned by a user, or by a team
+// when TeamID is set.
+type Collection struct {
+	ID             int `gorm:"primarykey"`
+	UserID         int `gorm:"index"`
+	TeamID         int `gorm:"index"`
+	Name           string
+	Description    string
+	EmbeddingModel string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+// ConversationCollection attaches a collection to a conversation so its
+// documents are searched for every question asked in it.
+type ConversationCollection struct {
+	ID             int `gorm:"primarykey"`
+	ConversationID int `gorm:"index"`
+	CollectionID   int `gorm:"index"`
+}
+
+// EmbeddingJob re-embeds the chunks of a collection with the current
+// embedding model.
+type EmbeddingJob struct {
+	ID             int `gorm:"primarykey"`
+	CollectionID   int `gorm:"index"`
+	EmbeddingModel string
+	Status         string
+	Total          int
+	Processed      int
+	Error          string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Conversation groups the messages of a user with a persona. Messages up to
+// ContextStartID are kept in the history but left out of the context the
+// model answers from.
+type Conversation struct {
+	ID             int `gorm:"primarykey"`
+	UserID         int `gorm:"index"`
+	PersonaID      int
+	Title          string
+	Pinned         bool
+	ContextStartID int
+	Source         string
+	ExternalID     string `gorm:"index"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+// ConversationTag is a label the user put on a conversation
+type ConversationTag struct {
+	ID             int    `gorm:"primarykey"`
+	UserID         int    `gorm:"index"`
+	ConversationID int    `gorm:"index"`
+	Name           string `gorm:"size:50;index"`
+}
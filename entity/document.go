@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Document is a file a user uploaded for the bot to answer from. Documents
+// without a CollectionID are the user's own uploads.
+type Document struct {
+	ID           int `gorm:"primarykey"`
+	UserID       int `gorm:"index"`
+	CollectionID int `gorm:"index"`
+	Name         string
+	ContentType  string
+	Size         int
+	ChunkCount   int
+	Version      int
+	ContentHash  string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+}
+
+// DocumentVersion records every version of a document uploaded to a collection.
+type DocumentVersion struct {
+	ID          int `gorm:"primarykey"`
+	DocumentID  int `gorm:"index"`
+	Version     int
+	UserID      int
+	ContentHash string
+	Size        int
+	ChunkCount  int
+	CreatedAt   time.Time
+}
+
+// DocumentChunk is an embedded piece of a document's text. The ContentHash
+// lets a new version of the document keep the embeddings of unchanged chunks.
+type DocumentChunk struct {
+	ID             int `gorm:"primarykey"`
+	DocumentID     int `gorm:"index"`
+	UserID         int `gorm:"index"`
+	CollectionID   int `gorm:"index"`
+	ChunkIndex     int
+	Content        string `gorm:"type:text"`
+	ContentHash    string
+	EmbeddingModel string
+	Embedding      Vector `gorm:"type:longblob"`
+	CreatedAt      time.Time
+	Document       Document
+}
+
+// Vector is an embedding stored as little-endian float32 values.
+type Vector []float32
+
+// Value encodes the vector for the database
+func (v Vector) Value() (driver.Value, error) {
+	data := make([]byte, 4*len(v))
+	for i := 0; i < len(v); i++ {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v[i]))
+	}
+	return data, nil
+}
+
+// Scan decodes the vector from the database
+func (v *Vector) Scan(value any) error {
+	data, ok := value.([]byte)
+	if !ok {
+		return errors.New("vector must be scanned from bytes")
+	}
+	if len(data)%4 != 0 {
+		return errors.New("vector data has an invalid length")
+	}
+
+	vector := make(Vector, len(data)/4)
+	for i := 0; i < len(vector); i++ {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	*v = vector
+	return nil
+}
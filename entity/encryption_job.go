@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// EncryptionJob encrypts the messages that are not encrypted under the master
+// key KeyID again, after a key rotation or when encryption is turned on.
+type EncryptionJob struct {
+	ID        int `gorm:"primarykey"`
+	KeyID     string
+	Status    string
+	Total     int
+	Processed int
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
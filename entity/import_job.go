@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// ImportJob imports the conversations of an export file from another chat
+// service. Total and Processed count conversations, Imported and Skipped
+// count messages.
+type ImportJob struct {
+	ID        int `gorm:"primarykey"`
+	UserID    int `gorm:"index"`
+	Format    string
+	FileName  string
+	Status    string
+	Total     int
+	Processed int
+	Imported  int
+	Skipped   int
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
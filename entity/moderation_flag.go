@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// ModerationFlag is a question or answer flagged by moderation, queued for an
+// admin to review. ChatID is zero when the content was blocked and never stored.
+type ModerationFlag struct {
+	ID             int `gorm:"primarykey"`
+	UserID         int `gorm:"index"`
+	ConversationID int `gorm:"index"`
+	ChatID         int
+	Direction      string `gorm:"size:10"`
+	Content        string `gorm:"type:text"`
+	Providers      string `gorm:"size:100"`
+	Categories     string `gorm:"size:255"`
+	Action         string `gorm:"size:10"`
+	Status         string `gorm:"size:20;index"`
+	ReviewerID     int
+	ReviewNote     string `gorm:"size:500"`
+	ReviewedAt     *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Persona struct {
+	ID            int `gorm:"primarykey"`
+	Name          string
+	Description   string
+	SystemPrompt  string
+	PromptVersion int
+	Model         string
+	Temperature   float32
+	MaxTokens     int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+	AllowedUsers  []PersonaUser
+}
+
+// PersonaUser restricts a persona to the listed users. A persona without
+// any PersonaUser rows is available to everyone.
+type PersonaUser struct {
+	ID        int `gorm:"primarykey"`
+	PersonaID int `gorm:"index"`
+	UserID    int `gorm:"index"`
+}
+
+// PersonaPrompt keeps every system prompt a persona has used, so the
+// PromptVersion stored on a chat message can be traced back to its text.
+type PersonaPrompt struct {
+	ID           int `gorm:"primarykey"`
+	PersonaID    int `gorm:"index"`
+	Version      int
+	SystemPrompt string
+	CreatedAt    time.Time
+}
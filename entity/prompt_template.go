@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PromptTemplate struct {
+	ID          int `gorm:"primarykey"`
+	UserID      int `gorm:"index"`
+	Name        string
+	Description string
+	Content     string
+	Visibility  string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	DeletedAt   gorm.DeletedAt `gorm:"index"`
+	Variables   []PromptTemplateVariable
+}
+
+// PromptTemplateVariable describes a {{variable}} placeholder of a template.
+// An optional variable falls back to its DefaultValue when no value is given.
+type PromptTemplateVariable struct {
+	ID               int `gorm:"primarykey"`
+	PromptTemplateID int `gorm:"index"`
+	Name             string
+	Required         bool
+	DefaultValue     string
+}
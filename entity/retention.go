@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// RetentionPolicy keeps the messages of users for Days days, after which they
+// are deleted or anonymized by Action. A policy of a user overrides the one of
+// their role, which overrides the global one without a role or user.
+type RetentionPolicy struct {
+	ID        int    `gorm:"primarykey"`
+	Role      string `gorm:"size:32;index"`
+	UserID    int    `gorm:"index"`
+	Days      int
+	Action    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// PurgeReport records a run of the retention purge. A dry run only counts the
+// messages and deleted users that would be purged.
+type PurgeReport struct {
+	ID              int `gorm:"primarykey"`
+	DryRun          bool
+	Status          string
+	Users           int
+	ChatsDeleted    int
+	ChatsAnonymized int
+	UsersDeleted    int
+	UsersAnonymized int
+	Error           string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// ShareLink publishes a read-only snapshot of a conversation at an
+// unguessable token. The snapshot holds the messages up to LastChatID, so
+// messages written after the link was created are not shown.
+type ShareLink struct {
+	ID             int    `gorm:"primarykey"`
+	UserID         int    `gorm:"index"`
+	ConversationID int    `gorm:"index"`
+	Token          string `gorm:"size:64;uniqueIndex"`
+	LastChatID     int
+	PasswordHash   string
+	ExpiresAt      *time.Time
+	RevokedAt      *time.Time
+	Views          int
+	LastViewedAt   *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
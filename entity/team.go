@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Team groups users that share knowledge base collections.
+type Team struct {
+	ID        int `gorm:"primarykey"`
+	Name      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	Members   []TeamMember
+}
+
+type TeamMember struct {
+	ID     int `gorm:"primarykey"`
+	TeamID int `gorm:"index"`
+	UserID int `gorm:"index"`
+}
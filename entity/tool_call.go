@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// ToolCall records a tool the model called while writing a bot message.
+type ToolCall struct {
+	ID         int `gorm:"primarykey"`
+	ChatID     int `gorm:"index"`
+	ToolCallID string
+	Name       string
+	Arguments  string
+	Result     string
+	CreatedAt  time.Time
+}
@@ -11,6 +11,7 @@ type User struct {
 	Email     string
 	Password  string
 	Name      string
+	Role      string `gorm:"default:user"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
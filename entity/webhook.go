@@ -0,0 +1,48 @@
+package entity
+
+import "time"
+
+// WebhookSubscription posts the events of its user to URL, signed with
+// Secret. A subscription an admin made for AllUsers gets the events of every
+// user. Events is a comma separated list.
+type WebhookSubscription struct {
+	ID        int    `gorm:"primarykey"`
+	UserID    int    `gorm:"index"`
+	URL       string `gorm:"size:2048"`
+	Events    string `gorm:"size:255"`
+	Secret    string `gorm:"size:64"`
+	AllUsers  bool
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery is an event waiting to be delivered to a subscription, or
+// that was delivered or given up on. UserID is the user the event is about,
+// and Payload is the signed json body, stored encrypted like the messages.
+type WebhookDelivery struct {
+	ID             int    `gorm:"primarykey"`
+	SubscriptionID int    `gorm:"index"`
+	UserID         int    `gorm:"index"`
+	EventID        string `gorm:"size:64"`
+	Event          string `gorm:"size:64"`
+	Payload        string `gorm:"type:mediumtext"`
+	Status         string `gorm:"size:16;index:idx_webhook_delivery_due,priority:1"`
+	Attempts       int
+	NextAttemptAt  time.Time `gorm:"index:idx_webhook_delivery_due,priority:2"`
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	AttemptLog     []WebhookAttempt `gorm:"foreignKey:DeliveryID"`
+}
+
+// WebhookAttempt is an attempt at delivering an event. StatusCode is 0 when
+// no response came, and Duration is in milliseconds.
+type WebhookAttempt struct {
+	ID         int `gorm:"primarykey"`
+	DeliveryID int `gorm:"index"`
+	StatusCode int
+	Error      string `gorm:"type:text"`
+	Duration   int64
+	CreatedAt  time.Time
+}
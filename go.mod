@@ -9,6 +9,8 @@ require (
 	github.com/golang-jwt/jwt v3.2.2+incompatible
 	github.com/google/uuid v1.5.0
 	github.com/joho/godotenv v1.5.1
+	github.com/jung-kurt/gofpdf v1.16.2
+	github.com/ledongthuc/pdf v0.0.0-20240201131950-da5b75280b06
 	github.com/sashabaranov/go-openai v1.18.1
 	github.com/spf13/cast v1.6.0
 	github.com/stretchr/testify v1.8.4
@@ -1,19 +1,30 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 
 	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/http/channel"
 	"github.com/fadilahonespot/chatbot/repository/http/chatgbt"
+	"github.com/fadilahonespot/chatbot/repository/http/embedding"
+	"github.com/fadilahonespot/chatbot/repository/http/moderation"
+	"github.com/fadilahonespot/chatbot/repository/http/speech"
+	"github.com/fadilahonespot/chatbot/repository/http/webhook"
 	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/repository/storage"
+	"github.com/fadilahonespot/chatbot/repository/vectorstore"
 	"github.com/fadilahonespot/chatbot/server/handler"
 	"github.com/fadilahonespot/chatbot/server/router"
 	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/tool"
 	"github.com/fadilahonespot/chatbot/utils/database"
+	"github.com/fadilahonespot/chatbot/utils/envelope"
 	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/redact"
 	"github.com/joho/godotenv"
 )
 
@@ -28,33 +39,164 @@ func main() {
 	// Setup Database
 	db := database.InitDB()
 
+	// Setup Encryption
+	encryptor, err := envelope.NewEncryptorFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Setup Mysql
 	userRepo := mysql.NewUserRepository(db)
-	chatRepo := mysql.NewChatRepository(db)
+	chatRepo := mysql.NewChatRepository(db, encryptor)
+	conversationRepo := mysql.NewConversationRepository(db)
+	personaRepo := mysql.NewPersonaRepository(db)
+	chatModelRepo := mysql.NewChatModelRepository(db)
+	promptTemplateRepo := mysql.NewPromptTemplateRepository(db)
+	documentRepo := mysql.NewDocumentRepository(db)
+	teamRepo := mysql.NewTeamRepository(db)
+	collectionRepo := mysql.NewCollectionRepository(db)
+	importRepo := mysql.NewImportRepository(db)
+	shareRepo := mysql.NewShareRepository(db)
+	auditRepo := mysql.NewAuditRepository(db)
+	moderationRepo := mysql.NewModerationRepository(db, encryptor)
+	encryptionRepo := mysql.NewEncryptionRepository(db)
+	retentionRepo := mysql.NewRetentionRepository(db)
+	webhookRepo := mysql.NewWebhookRepository(db, encryptor)
+	channelRepo := mysql.NewChannelRepository(db)
+
+	// Setup Vector Store
+	vectorStore := vectorstore.NewDatabaseStore(db)
 
 	// Setup Wrapper
-	openAiWrapper := chatgbt.NewWrapper()
-	cacheWrapper := cached.NewWrapper()
+	redactor, err := redact.NewRedactorFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cacheWrapper := cached.NewEncryptedWrapper(cached.NewWrapper(), encryptor)
+	locker := cached.NewLocker()
+	queue := cached.NewQueue()
+	webhookSender := webhook.NewSender()
+	channels := []channel.Channel{
+		channel.NewTelegram(),
+		channel.NewWhatsApp(),
+		channel.NewSlack(),
+	}
+	embeddingProvider := embedding.NewRedactingProvider(embedding.NewOpenAIProvider(), redactor)
+	cachingWrapper, err := chatgbt.NewCachingWrapperFromEnv(chatgbt.NewWrapper(), cacheWrapper, embeddingProvider)
+	if err != nil {
+		log.Fatal(err)
+	}
+	openAiWrapper := chatgbt.NewRedactingWrapper(cachingWrapper, redactor)
+	speechProvider := speech.NewRedactingProvider(speech.NewOpenAIProvider(), redactor)
+	moderator, err := moderation.NewModerator(redactor)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Setup Storage
+	blobStorage := storage.NewLocalStorage()
+
+	// Setup Tools
+	toolRegistry := tool.NewRegistry(
+		tool.NewDateTimeTool(),
+		tool.NewCalculatorTool(),
+		tool.NewChatHistoryTool(chatRepo),
+	)
 
 	// Setup Usecase
-	userUsecase := usecase.NewUserUsecase(userRepo)
-	chatUsecase := usecase.NewChatUsecase(userRepo, chatRepo, openAiWrapper, cacheWrapper)
+	webhookUsecase := usecase.NewWebhookUsecase(userRepo, webhookRepo, auditRepo, webhookSender)
+	userUsecase := usecase.NewUserUsecase(userRepo, auditRepo, webhookUsecase)
+	chatUsecase := usecase.NewChatUsecase(userRepo, chatRepo, conversationRepo, personaRepo, chatModelRepo, promptTemplateRepo, collectionRepo, teamRepo, moderationRepo, toolRegistry, embeddingProvider, vectorStore, blobStorage, speechProvider, openAiWrapper, cacheWrapper, locker, moderator, webhookUsecase)
+	chatJobUsecase := usecase.NewChatJobUsecase(chatUsecase, cacheWrapper, queue, webhookSender)
+	channelUsecase := usecase.NewChannelUsecase(chatUsecase, channelRepo, cacheWrapper, channels)
+	personaUsecase := usecase.NewPersonaUsecase(personaRepo)
+	chatModelUsecase := usecase.NewChatModelUsecase(chatModelRepo)
+	promptTemplateUsecase := usecase.NewPromptTemplateUsecase(promptTemplateRepo)
+	documentUsecase := usecase.NewDocumentUsecase(documentRepo, embeddingProvider, vectorStore)
+	teamUsecase := usecase.NewTeamUsecase(teamRepo)
+	collectionUsecase := usecase.NewCollectionUsecase(collectionRepo, documentRepo, teamRepo, embeddingProvider, vectorStore)
+	conversationUsecase := usecase.NewConversationUsecase(conversationRepo, chatRepo, auditRepo, cacheWrapper)
+	exportUsecase := usecase.NewExportUsecase(userRepo, chatRepo, conversationRepo)
+	importUsecase := usecase.NewImportUsecase(userRepo, chatRepo, conversationRepo, importRepo, cacheWrapper)
+	shareUsecase := usecase.NewShareUsecase(userRepo, chatRepo, conversationRepo, shareRepo, auditRepo)
+	moderationUsecase := usecase.NewModerationUsecase(moderationRepo, auditRepo)
+	encryptionUsecase := usecase.NewEncryptionUsecase(chatRepo, encryptionRepo, auditRepo, encryptor)
+	auditUsecase := usecase.NewAuditUsecase(auditRepo)
+	retentionUsecase := usecase.NewRetentionUsecase(userRepo, chatRepo, retentionRepo, webhookRepo, moderationRepo, auditRepo, blobStorage, cacheWrapper)
+
+	// Promote a registered user to admin, as `chatbot promote-admin <email>`,
+	// instead of serving
+	if len(os.Args) == 3 && os.Args[1] == "promote-admin" {
+		err = userUsecase.PromoteAdmin(context.Background(), os.Args[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("%v is now an admin", os.Args[2])
+		return
+	}
+
+	// Re-embed the collections embedded with a previous embedding model
+	collectionUsecase.ReembedOutdatedCollections(context.Background())
+
+	// Encrypt again the messages not encrypted under the current master key
+	encryptionUsecase.ReencryptOutdatedChats(context.Background())
+
+	// Purge the messages past their retention on schedule
+	retentionUsecase.SchedulePurge(context.Background())
+
+	// Answer the questions asked in the background
+	chatJobUsecase.StartWorkers(context.Background())
+
+	// Deliver the events queued for the webhooks
+	webhookUsecase.StartDeliveries(context.Background())
 
 	// Setup Handler
 	userHandler := handler.NewUserHandler(userUsecase)
-	chatHandler := handler.NewChatHandler(chatUsecase)
+	chatHandler := handler.NewChatHandler(chatUsecase, chatJobUsecase)
+	personaHandler := handler.NewPersonaHandler(personaUsecase)
+	chatModelHandler := handler.NewChatModelHandler(chatModelUsecase)
+	promptTemplateHandler := handler.NewPromptTemplateHandler(promptTemplateUsecase)
+	documentHandler := handler.NewDocumentHandler(documentUsecase)
+	teamHandler := handler.NewTeamHandler(teamUsecase)
+	collectionHandler := handler.NewCollectionHandler(collectionUsecase)
+	conversationHandler := handler.NewConversationHandler(conversationUsecase)
+	exportHandler := handler.NewExportHandler(exportUsecase)
+	importHandler := handler.NewImportHandler(importUsecase)
+	shareHandler := handler.NewShareHandler(shareUsecase)
+	moderationHandler := handler.NewModerationHandler(moderationUsecase)
+	encryptionHandler := handler.NewEncryptionHandler(encryptionUsecase)
+	retentionHandler := handler.NewRetentionHandler(retentionUsecase)
+	auditHandler := handler.NewAuditHandler(auditUsecase)
+	webhookHandler := handler.NewWebhookHandler(webhookUsecase)
+	channelHandler := handler.NewChannelHandler(channelUsecase)
 
 	// Setup Router
 	route := router.NewRouter().
 		SetUserHandler(userHandler).
 		SetChatHandler(chatHandler).
+		SetPersonaHandler(personaHandler).
+		SetChatModelHandler(chatModelHandler).
+		SetPromptTemplateHandler(promptTemplateHandler).
+		SetDocumentHandler(documentHandler).
+		SetTeamHandler(teamHandler).
+		SetCollectionHandler(collectionHandler).
+		SetConversationHandler(conversationHandler).
+		SetExportHandler(exportHandler).
+		SetImportHandler(importHandler).
+		SetShareHandler(shareHandler).
+		SetModerationHandler(moderationHandler).
+		SetEncryptionHandler(encryptionHandler).
+		SetRetentionHandler(retentionHandler).
+		SetAuditHandler(auditHandler).
+		SetWebhookHandler(webhookHandler).
+		SetChannelHandler(channelHandler).
 		Validate()
 
 	route.SetupRouter()
 
 	port := os.Getenv("APP_PORT")
 	fmt.Printf("Server running on :%v...\n", port)
-	err := http.ListenAndServe(fmt.Sprintf(":%v", port), nil)
+	err = http.ListenAndServe(fmt.Sprintf(":%v", port), nil)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,116 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	mysql "github.com/fadilahonespot/chatbot/repository/mysql"
+)
+
+// AuditRepository is an autogenerated mock type for the AuditRepository type
+type AuditRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, req
+func (_m *AuditRepository) Create(ctx context.Context, req *entity.AuditLog) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.AuditLog) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Find provides a mock function with given fields: ctx, filter
+func (_m *AuditRepository) Find(ctx context.Context, filter mysql.AuditFilter) ([]entity.AuditLog, int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Find")
+	}
+
+	var r0 []entity.AuditLog
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.AuditFilter) ([]entity.AuditLog, int64, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.AuditFilter) []entity.AuditLog); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, mysql.AuditFilter) int64); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, mysql.AuditFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// FindAfter provides a mock function with given fields: ctx, afterId, limit
+func (_m *AuditRepository) FindAfter(ctx context.Context, afterId int, limit int) ([]entity.AuditLog, error) {
+	ret := _m.Called(ctx, afterId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAfter")
+	}
+
+	var r0 []entity.AuditLog
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]entity.AuditLog, error)); ok {
+		return rf(ctx, afterId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []entity.AuditLog); ok {
+		r0 = rf(ctx, afterId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.AuditLog)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, afterId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAuditRepository creates a new instance of AuditRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuditRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuditRepository {
+	mock := &AuditRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,85 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AuditUsecase is an autogenerated mock type for the AuditUsecase type
+type AuditUsecase struct {
+	mock.Mock
+}
+
+// GetAuditLogs provides a mock function with given fields: ctx, filter
+func (_m *AuditUsecase) GetAuditLogs(ctx context.Context, filter dto.AuditFilter) (dto.AuditLogListResponse, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAuditLogs")
+	}
+
+	var r0 dto.AuditLogListResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.AuditFilter) (dto.AuditLogListResponse, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.AuditFilter) dto.AuditLogListResponse); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		r0 = ret.Get(0).(dto.AuditLogListResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.AuditFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// VerifyChain provides a mock function with given fields: ctx
+func (_m *AuditUsecase) VerifyChain(ctx context.Context) (dto.AuditVerifyResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyChain")
+	}
+
+	var r0 dto.AuditVerifyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (dto.AuditVerifyResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) dto.AuditVerifyResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(dto.AuditVerifyResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAuditUsecase creates a new instance of AuditUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuditUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AuditUsecase {
+	mock := &AuditUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -78,6 +78,34 @@ func (_m *CacheWrapper) Set(ctx context.Context, key string, value string, durat
 	return r0
 }
 
+// SetNX provides a mock function with given fields: ctx, key, value, duration
+func (_m *CacheWrapper) SetNX(ctx context.Context, key string, value string, duration time.Duration) (bool, error) {
+	ret := _m.Called(ctx, key, value, duration)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetNX")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) (bool, error)); ok {
+		return rf(ctx, key, value, duration)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Duration) bool); ok {
+		r0 = rf(ctx, key, value, duration)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Duration) error); ok {
+		r1 = rf(ctx, key, value, duration)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewCacheWrapper creates a new instance of CacheWrapper. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewCacheWrapper(t interface {
@@ -0,0 +1,142 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	channel "github.com/fadilahonespot/chatbot/repository/http/channel"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Channel is an autogenerated mock type for the Channel type
+type Channel struct {
+	mock.Mock
+}
+
+// Limits provides a mock function with given fields:
+func (_m *Channel) Limits() channel.Limits {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Limits")
+	}
+
+	var r0 channel.Limits
+	if rf, ok := ret.Get(0).(func() channel.Limits); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(channel.Limits)
+	}
+
+	return r0
+}
+
+// Name provides a mock function with given fields:
+func (_m *Channel) Name() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Name")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// Parse provides a mock function with given fields: req
+func (_m *Channel) Parse(req channel.Request) ([]channel.Message, error) {
+	ret := _m.Called(req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Parse")
+	}
+
+	var r0 []channel.Message
+	var r1 error
+	if rf, ok := ret.Get(0).(func(channel.Request) ([]channel.Message, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(channel.Request) []channel.Message); ok {
+		r0 = rf(req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]channel.Message)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(channel.Request) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Send provides a mock function with given fields: ctx, to, text
+func (_m *Channel) Send(ctx context.Context, to string, text string) error {
+	ret := _m.Called(ctx, to, text)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Send")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, to, text)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Verify provides a mock function with given fields: req
+func (_m *Channel) Verify(req channel.Request) (string, error) {
+	ret := _m.Called(req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Verify")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(channel.Request) (string, error)); ok {
+		return rf(req)
+	}
+	if rf, ok := ret.Get(0).(func(channel.Request) string); ok {
+		r0 = rf(req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(channel.Request) error); ok {
+		r1 = rf(req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewChannel creates a new instance of Channel. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewChannel(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Channel {
+	mock := &Channel{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,143 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ChannelRepository is an autogenerated mock type for the ChannelRepository type
+type ChannelRepository struct {
+	mock.Mock
+}
+
+// DeleteIdentity provides a mock function with given fields: ctx, id
+func (_m *ChannelRepository) DeleteIdentity(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetIdentitiesByUserId provides a mock function with given fields: ctx, userId
+func (_m *ChannelRepository) GetIdentitiesByUserId(ctx context.Context, userId int) ([]entity.ChannelIdentity, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdentitiesByUserId")
+	}
+
+	var r0 []entity.ChannelIdentity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]entity.ChannelIdentity, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.ChannelIdentity); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.ChannelIdentity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIdentity provides a mock function with given fields: ctx, channel, externalId
+func (_m *ChannelRepository) GetIdentity(ctx context.Context, channel string, externalId string) (*entity.ChannelIdentity, error) {
+	ret := _m.Called(ctx, channel, externalId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdentity")
+	}
+
+	var r0 *entity.ChannelIdentity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.ChannelIdentity, error)); ok {
+		return rf(ctx, channel, externalId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.ChannelIdentity); ok {
+		r0 = rf(ctx, channel, externalId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ChannelIdentity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, channel, externalId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveIdentity provides a mock function with given fields: ctx, req
+func (_m *ChannelRepository) SaveIdentity(ctx context.Context, req *entity.ChannelIdentity) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveIdentity")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.ChannelIdentity) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateConversation provides a mock function with given fields: ctx, id, conversationId
+func (_m *ChannelRepository) UpdateConversation(ctx context.Context, id int, conversationId int) error {
+	ret := _m.Called(ctx, id, conversationId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateConversation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, id, conversationId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewChannelRepository creates a new instance of ChannelRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewChannelRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ChannelRepository {
+	mock := &ChannelRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
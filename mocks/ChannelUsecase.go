@@ -0,0 +1,136 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	channel "github.com/fadilahonespot/chatbot/repository/http/channel"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ChannelUsecase is an autogenerated mock type for the ChannelUsecase type
+type ChannelUsecase struct {
+	mock.Mock
+}
+
+// CreateLink provides a mock function with given fields: ctx, userId
+func (_m *ChannelUsecase) CreateLink(ctx context.Context, userId int) (dto.ChannelLinkResponse, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateLink")
+	}
+
+	var r0 dto.ChannelLinkResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (dto.ChannelLinkResponse, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) dto.ChannelLinkResponse); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		r0 = ret.Get(0).(dto.ChannelLinkResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetIdentities provides a mock function with given fields: ctx, userId
+func (_m *ChannelUsecase) GetIdentities(ctx context.Context, userId int) ([]dto.ChannelIdentityResponse, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetIdentities")
+	}
+
+	var r0 []dto.ChannelIdentityResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]dto.ChannelIdentityResponse, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []dto.ChannelIdentityResponse); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.ChannelIdentityResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// HandleWebhook provides a mock function with given fields: ctx, name, req
+func (_m *ChannelUsecase) HandleWebhook(ctx context.Context, name string, req channel.Request) (string, error) {
+	ret := _m.Called(ctx, name, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleWebhook")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, channel.Request) (string, error)); ok {
+		return rf(ctx, name, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, channel.Request) string); ok {
+		r0 = rf(ctx, name, req)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, channel.Request) error); ok {
+		r1 = rf(ctx, name, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Unlink provides a mock function with given fields: ctx, userId, id
+func (_m *ChannelUsecase) Unlink(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Unlink")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewChannelUsecase creates a new instance of ChannelUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewChannelUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ChannelUsecase {
+	mock := &ChannelUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
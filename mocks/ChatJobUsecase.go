@@ -0,0 +1,90 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ChatJobUsecase is an autogenerated mock type for the ChatJobUsecase type
+type ChatJobUsecase struct {
+	mock.Mock
+}
+
+// EnqueueChat provides a mock function with given fields: ctx, userId, req
+func (_m *ChatJobUsecase) EnqueueChat(ctx context.Context, userId int, req dto.ChatQuestionRequest) (dto.ChatJobResponse, error) {
+	ret := _m.Called(ctx, userId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnqueueChat")
+	}
+
+	var r0 dto.ChatJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ChatQuestionRequest) (dto.ChatJobResponse, error)); ok {
+		return rf(ctx, userId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ChatQuestionRequest) dto.ChatJobResponse); ok {
+		r0 = rf(ctx, userId, req)
+	} else {
+		r0 = ret.Get(0).(dto.ChatJobResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.ChatQuestionRequest) error); ok {
+		r1 = rf(ctx, userId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetChatJob provides a mock function with given fields: ctx, userId, id
+func (_m *ChatJobUsecase) GetChatJob(ctx context.Context, userId int, id string) (dto.ChatJobResponse, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatJob")
+	}
+
+	var r0 dto.ChatJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) (dto.ChatJobResponse, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) dto.ChatJobResponse); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(dto.ChatJobResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StartWorkers provides a mock function with given fields: ctx
+func (_m *ChatJobUsecase) StartWorkers(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// NewChatJobUsecase creates a new instance of ChatJobUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewChatJobUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ChatJobUsecase {
+	mock := &ChatJobUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,163 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ChatModelUsecase is an autogenerated mock type for the ChatModelUsecase type
+type ChatModelUsecase struct {
+	mock.Mock
+}
+
+// CreateChatModel provides a mock function with given fields: ctx, req
+func (_m *ChatModelUsecase) CreateChatModel(ctx context.Context, req dto.ChatModelRequest) (dto.ChatModelResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateChatModel")
+	}
+
+	var r0 dto.ChatModelResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.ChatModelRequest) (dto.ChatModelResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.ChatModelRequest) dto.ChatModelResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(dto.ChatModelResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.ChatModelRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteChatModel provides a mock function with given fields: ctx, id
+func (_m *ChatModelUsecase) DeleteChatModel(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteChatModel")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetChatModels provides a mock function with given fields: ctx
+func (_m *ChatModelUsecase) GetChatModels(ctx context.Context) ([]dto.ChatModelResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatModels")
+	}
+
+	var r0 []dto.ChatModelResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]dto.ChatModelResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []dto.ChatModelResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.ChatModelResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEnabledChatModels provides a mock function with given fields: ctx
+func (_m *ChatModelUsecase) GetEnabledChatModels(ctx context.Context) ([]dto.ChatModelResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEnabledChatModels")
+	}
+
+	var r0 []dto.ChatModelResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]dto.ChatModelResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []dto.ChatModelResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.ChatModelResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateChatModel provides a mock function with given fields: ctx, id, req
+func (_m *ChatModelUsecase) UpdateChatModel(ctx context.Context, id int, req dto.ChatModelRequest) (dto.ChatModelResponse, error) {
+	ret := _m.Called(ctx, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateChatModel")
+	}
+
+	var r0 dto.ChatModelResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ChatModelRequest) (dto.ChatModelResponse, error)); ok {
+		return rf(ctx, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ChatModelRequest) dto.ChatModelResponse); ok {
+		r0 = rf(ctx, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.ChatModelResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.ChatModelRequest) error); ok {
+		r1 = rf(ctx, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewChatModelUsecase creates a new instance of ChatModelUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewChatModelUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ChatModelUsecase {
+	mock := &ChatModelUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
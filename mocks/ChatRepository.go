@@ -9,6 +9,10 @@ import (
 	gorm "gorm.io/gorm"
 
 	mock "github.com/stretchr/testify/mock"
+
+	mysql "github.com/fadilahonespot/chatbot/repository/mysql"
+
+	time "time"
 )
 
 // ChatRepository is an autogenerated mock type for the ChatRepository type
@@ -16,6 +20,24 @@ type ChatRepository struct {
 	mock.Mock
 }
 
+// AnonymizeChats provides a mock function with given fields: ctx, ids
+func (_m *ChatRepository) AnonymizeChats(ctx context.Context, ids []int) error {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AnonymizeChats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) error); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BeginsTrans provides a mock function with given fields:
 func (_m *ChatRepository) BeginsTrans() *gorm.DB {
 	ret := _m.Called()
@@ -54,6 +76,62 @@ func (_m *ChatRepository) Commit(tx *gorm.DB) error {
 	return r0
 }
 
+// CountExpired provides a mock function with given fields: ctx, userId, before
+func (_m *ChatRepository) CountExpired(ctx context.Context, userId int, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, userId, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountExpired")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) (int64, error)); ok {
+		return rf(ctx, userId, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) int64); ok {
+		r0 = rf(ctx, userId, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Time) error); ok {
+		r1 = rf(ctx, userId, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CountOutdatedKey provides a mock function with given fields: ctx
+func (_m *ChatRepository) CountOutdatedKey(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountOutdatedKey")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Create provides a mock function with given fields: ctx, tx, req
 func (_m *ChatRepository) Create(ctx context.Context, tx *gorm.DB, req *entity.Chat) error {
 	ret := _m.Called(ctx, tx, req)
@@ -72,6 +150,258 @@ func (_m *ChatRepository) Create(ctx context.Context, tx *gorm.DB, req *entity.C
 	return r0
 }
 
+// CreateAttachment provides a mock function with given fields: ctx, tx, req
+func (_m *ChatRepository) CreateAttachment(ctx context.Context, tx *gorm.DB, req *entity.ChatAttachment) error {
+	ret := _m.Called(ctx, tx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAttachment")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *entity.ChatAttachment) error); ok {
+		r0 = rf(ctx, tx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateToolCall provides a mock function with given fields: ctx, tx, req
+func (_m *ChatRepository) CreateToolCall(ctx context.Context, tx *gorm.DB, req *entity.ToolCall) error {
+	ret := _m.Called(ctx, tx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateToolCall")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *gorm.DB, *entity.ToolCall) error); ok {
+		r0 = rf(ctx, tx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *ChatRepository) Delete(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteByConversationId provides a mock function with given fields: ctx, conversationId
+func (_m *ChatRepository) DeleteByConversationId(ctx context.Context, conversationId int) error {
+	ret := _m.Called(ctx, conversationId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByConversationId")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, conversationId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Find provides a mock function with given fields: ctx, filter
+func (_m *ChatRepository) Find(ctx context.Context, filter mysql.ChatFilter) ([]entity.Chat, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Find")
+	}
+
+	var r0 []entity.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.ChatFilter) ([]entity.Chat, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.ChatFilter) []entity.Chat); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Chat)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, mysql.ChatFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindExpired provides a mock function with given fields: ctx, userId, before, limit
+func (_m *ChatRepository) FindExpired(ctx context.Context, userId int, before time.Time, limit int) ([]entity.Chat, error) {
+	ret := _m.Called(ctx, userId, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindExpired")
+	}
+
+	var r0 []entity.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time, int) ([]entity.Chat, error)); ok {
+		return rf(ctx, userId, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time, int) []entity.Chat); ok {
+		r0 = rf(ctx, userId, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Chat)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Time, int) error); ok {
+		r1 = rf(ctx, userId, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindOutdatedKey provides a mock function with given fields: ctx, afterId, limit
+func (_m *ChatRepository) FindOutdatedKey(ctx context.Context, afterId int, limit int) ([]entity.Chat, error) {
+	ret := _m.Called(ctx, afterId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindOutdatedKey")
+	}
+
+	var r0 []entity.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]entity.Chat, error)); ok {
+		return rf(ctx, afterId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []entity.Chat); ok {
+		r0 = rf(ctx, afterId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Chat)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, afterId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAttachmentById provides a mock function with given fields: ctx, id
+func (_m *ChatRepository) GetAttachmentById(ctx context.Context, id int) (*entity.ChatAttachment, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAttachmentById")
+	}
+
+	var r0 *entity.ChatAttachment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.ChatAttachment, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.ChatAttachment); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ChatAttachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByConversationId provides a mock function with given fields: ctx, conversationId
+func (_m *ChatRepository) GetByConversationId(ctx context.Context, conversationId int) ([]entity.Chat, error) {
+	ret := _m.Called(ctx, conversationId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByConversationId")
+	}
+
+	var r0 []entity.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]entity.Chat, error)); ok {
+		return rf(ctx, conversationId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.Chat); ok {
+		r0 = rf(ctx, conversationId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Chat)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, conversationId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: ctx, id
+func (_m *ChatRepository) GetById(ctx context.Context, id int) (*entity.Chat, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetById")
+	}
+
+	var r0 *entity.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.Chat, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.Chat); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Chat)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetByUserId provides a mock function with given fields: ctx, userId
 func (_m *ChatRepository) GetByUserId(ctx context.Context, userId int) ([]entity.Chat, error) {
 	ret := _m.Called(ctx, userId)
@@ -102,9 +432,39 @@ func (_m *ChatRepository) GetByUserId(ctx context.Context, userId int) ([]entity
 	return r0, r1
 }
 
-// GetHistoryChatByUserId provides a mock function with given fields: ctx, userId
-func (_m *ChatRepository) GetHistoryChatByUserId(ctx context.Context, userId int) ([]entity.Chat, error) {
-	ret := _m.Called(ctx, userId)
+// GetExternalIds provides a mock function with given fields: ctx, conversationId
+func (_m *ChatRepository) GetExternalIds(ctx context.Context, conversationId int) ([]string, error) {
+	ret := _m.Called(ctx, conversationId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetExternalIds")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]string, error)); ok {
+		return rf(ctx, conversationId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []string); ok {
+		r0 = rf(ctx, conversationId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, conversationId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetHistoryChatByUserId provides a mock function with given fields: ctx, userId, filter
+func (_m *ChatRepository) GetHistoryChatByUserId(ctx context.Context, userId int, filter mysql.HistoryFilter) ([]entity.Chat, error) {
+	ret := _m.Called(ctx, userId, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetHistoryChatByUserId")
@@ -112,19 +472,19 @@ func (_m *ChatRepository) GetHistoryChatByUserId(ctx context.Context, userId int
 
 	var r0 []entity.Chat
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) ([]entity.Chat, error)); ok {
-		return rf(ctx, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, mysql.HistoryFilter) ([]entity.Chat, error)); ok {
+		return rf(ctx, userId, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.Chat); ok {
-		r0 = rf(ctx, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, mysql.HistoryFilter) []entity.Chat); ok {
+		r0 = rf(ctx, userId, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]entity.Chat)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(ctx, userId)
+	if rf, ok := ret.Get(1).(func(context.Context, int, mysql.HistoryFilter) error); ok {
+		r1 = rf(ctx, userId, filter)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -132,6 +492,42 @@ func (_m *ChatRepository) GetHistoryChatByUserId(ctx context.Context, userId int
 	return r0, r1
 }
 
+// PurgeChats provides a mock function with given fields: ctx, ids
+func (_m *ChatRepository) PurgeChats(ctx context.Context, ids []int) error {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeChats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) error); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reencrypt provides a mock function with given fields: ctx, chat
+func (_m *ChatRepository) Reencrypt(ctx context.Context, chat *entity.Chat) error {
+	ret := _m.Called(ctx, chat)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reencrypt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Chat) error); ok {
+		r0 = rf(ctx, chat)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Rollback provides a mock function with given fields: tx
 func (_m *ChatRepository) Rollback(tx *gorm.DB) error {
 	ret := _m.Called(tx)
@@ -150,6 +546,54 @@ func (_m *ChatRepository) Rollback(tx *gorm.DB) error {
 	return r0
 }
 
+// SearchByUserId provides a mock function with given fields: ctx, userId, query, limit
+func (_m *ChatRepository) SearchByUserId(ctx context.Context, userId int, query string, limit int) ([]entity.Chat, error) {
+	ret := _m.Called(ctx, userId, query, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SearchByUserId")
+	}
+
+	var r0 []entity.Chat
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int) ([]entity.Chat, error)); ok {
+		return rf(ctx, userId, query, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int) []entity.Chat); ok {
+		r0 = rf(ctx, userId, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Chat)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, int) error); ok {
+		r1 = rf(ctx, userId, query, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetStarred provides a mock function with given fields: ctx, id, starred
+func (_m *ChatRepository) SetStarred(ctx context.Context, id int, starred bool) error {
+	ret := _m.Called(ctx, id, starred)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetStarred")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) error); ok {
+		r0 = rf(ctx, id, starred)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewChatRepository creates a new instance of ChatRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewChatRepository(t interface {
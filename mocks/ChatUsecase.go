@@ -42,9 +42,83 @@ func (_m *ChatUsecase) ChatQuestion(ctx context.Context, userId int, req dto.Cha
 	return r0, r1
 }
 
-// GetHistoryChat provides a mock function with given fields: ctx, userId
-func (_m *ChatUsecase) GetHistoryChat(ctx context.Context, userId int) ([]dto.ChatHistoryResponse, error) {
-	ret := _m.Called(ctx, userId)
+// GetChatAudio provides a mock function with given fields: ctx, userId, id
+func (_m *ChatUsecase) GetChatAudio(ctx context.Context, userId int, id int) ([]byte, string, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatAudio")
+	}
+
+	var r0 []byte
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]byte, string, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []byte); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) string); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = rf(ctx, userId, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetChatImage provides a mock function with given fields: ctx, userId, id
+func (_m *ChatUsecase) GetChatImage(ctx context.Context, userId int, id int) ([]byte, string, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetChatImage")
+	}
+
+	var r0 []byte
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]byte, string, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []byte); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) string); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int) error); ok {
+		r2 = rf(ctx, userId, id)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetHistoryChat provides a mock function with given fields: ctx, userId, filter
+func (_m *ChatUsecase) GetHistoryChat(ctx context.Context, userId int, filter dto.ChatHistoryFilter) ([]dto.ChatHistoryResponse, error) {
+	ret := _m.Called(ctx, userId, filter)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetHistoryChat")
@@ -52,19 +126,65 @@ func (_m *ChatUsecase) GetHistoryChat(ctx context.Context, userId int) ([]dto.Ch
 
 	var r0 []dto.ChatHistoryResponse
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) ([]dto.ChatHistoryResponse, error)); ok {
-		return rf(ctx, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ChatHistoryFilter) ([]dto.ChatHistoryResponse, error)); ok {
+		return rf(ctx, userId, filter)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) []dto.ChatHistoryResponse); ok {
-		r0 = rf(ctx, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ChatHistoryFilter) []dto.ChatHistoryResponse); ok {
+		r0 = rf(ctx, userId, filter)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]dto.ChatHistoryResponse)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(ctx, userId)
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.ChatHistoryFilter) error); ok {
+		r1 = rf(ctx, userId, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StarChat provides a mock function with given fields: ctx, userId, id, req
+func (_m *ChatUsecase) StarChat(ctx context.Context, userId int, id int, req dto.ChatStarRequest) error {
+	ret := _m.Called(ctx, userId, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StarChat")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.ChatStarRequest) error); ok {
+		r0 = rf(ctx, userId, id, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VoiceQuestion provides a mock function with given fields: ctx, userId, req
+func (_m *ChatUsecase) VoiceQuestion(ctx context.Context, userId int, req dto.VoiceQuestionRequest) (dto.VoiceQuestionResponse, error) {
+	ret := _m.Called(ctx, userId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VoiceQuestion")
+	}
+
+	var r0 dto.VoiceQuestionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.VoiceQuestionRequest) (dto.VoiceQuestionResponse, error)); ok {
+		return rf(ctx, userId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.VoiceQuestionRequest) dto.VoiceQuestionResponse); ok {
+		r0 = rf(ctx, userId, req)
+	} else {
+		r0 = ret.Get(0).(dto.VoiceQuestionResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.VoiceQuestionRequest) error); ok {
+		r1 = rf(ctx, userId, req)
 	} else {
 		r1 = ret.Error(1)
 	}
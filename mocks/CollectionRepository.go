@@ -0,0 +1,239 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CollectionRepository is an autogenerated mock type for the CollectionRepository type
+type CollectionRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, req
+func (_m *CollectionRepository) Create(ctx context.Context, req *entity.Collection) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Collection) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateEmbeddingJob provides a mock function with given fields: ctx, req
+func (_m *CollectionRepository) CreateEmbeddingJob(ctx context.Context, req *entity.EmbeddingJob) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateEmbeddingJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.EmbeddingJob) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *CollectionRepository) Delete(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAccessible provides a mock function with given fields: ctx, userId, teamIds
+func (_m *CollectionRepository) GetAccessible(ctx context.Context, userId int, teamIds []int) ([]entity.Collection, error) {
+	ret := _m.Called(ctx, userId, teamIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccessible")
+	}
+
+	var r0 []entity.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []int) ([]entity.Collection, error)); ok {
+		return rf(ctx, userId, teamIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, []int) []entity.Collection); ok {
+		r0 = rf(ctx, userId, teamIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Collection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, []int) error); ok {
+		r1 = rf(ctx, userId, teamIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByEmbeddingModelNot provides a mock function with given fields: ctx, model
+func (_m *CollectionRepository) GetByEmbeddingModelNot(ctx context.Context, model string) ([]entity.Collection, error) {
+	ret := _m.Called(ctx, model)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByEmbeddingModelNot")
+	}
+
+	var r0 []entity.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]entity.Collection, error)); ok {
+		return rf(ctx, model)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []entity.Collection); ok {
+		r0 = rf(ctx, model)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Collection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, model)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: ctx, id
+func (_m *CollectionRepository) GetById(ctx context.Context, id int) (*entity.Collection, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetById")
+	}
+
+	var r0 *entity.Collection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.Collection, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.Collection); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Collection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEmbeddingJobById provides a mock function with given fields: ctx, id
+func (_m *CollectionRepository) GetEmbeddingJobById(ctx context.Context, id int) (*entity.EmbeddingJob, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEmbeddingJobById")
+	}
+
+	var r0 *entity.EmbeddingJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.EmbeddingJob, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.EmbeddingJob); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.EmbeddingJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, req
+func (_m *CollectionRepository) Update(ctx context.Context, req *entity.Collection) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Collection) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateEmbeddingJob provides a mock function with given fields: ctx, req
+func (_m *CollectionRepository) UpdateEmbeddingJob(ctx context.Context, req *entity.EmbeddingJob) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateEmbeddingJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.EmbeddingJob) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewCollectionRepository creates a new instance of CollectionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCollectionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CollectionRepository {
+	mock := &CollectionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,311 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// CollectionUsecase is an autogenerated mock type for the CollectionUsecase type
+type CollectionUsecase struct {
+	mock.Mock
+}
+
+// AddDocument provides a mock function with given fields: ctx, userId, collectionId, req
+func (_m *CollectionUsecase) AddDocument(ctx context.Context, userId int, collectionId int, req dto.DocumentUploadRequest) (dto.CollectionDocumentResponse, error) {
+	ret := _m.Called(ctx, userId, collectionId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddDocument")
+	}
+
+	var r0 dto.CollectionDocumentResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.DocumentUploadRequest) (dto.CollectionDocumentResponse, error)); ok {
+		return rf(ctx, userId, collectionId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.DocumentUploadRequest) dto.CollectionDocumentResponse); ok {
+		r0 = rf(ctx, userId, collectionId, req)
+	} else {
+		r0 = ret.Get(0).(dto.CollectionDocumentResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.DocumentUploadRequest) error); ok {
+		r1 = rf(ctx, userId, collectionId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateCollection provides a mock function with given fields: ctx, userId, req
+func (_m *CollectionUsecase) CreateCollection(ctx context.Context, userId int, req dto.CollectionRequest) (dto.CollectionResponse, error) {
+	ret := _m.Called(ctx, userId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateCollection")
+	}
+
+	var r0 dto.CollectionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.CollectionRequest) (dto.CollectionResponse, error)); ok {
+		return rf(ctx, userId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.CollectionRequest) dto.CollectionResponse); ok {
+		r0 = rf(ctx, userId, req)
+	} else {
+		r0 = ret.Get(0).(dto.CollectionResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.CollectionRequest) error); ok {
+		r1 = rf(ctx, userId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCollection provides a mock function with given fields: ctx, userId, id
+func (_m *CollectionUsecase) DeleteCollection(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteCollection")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDocument provides a mock function with given fields: ctx, userId, documentId
+func (_m *CollectionUsecase) DeleteDocument(ctx context.Context, userId int, documentId int) error {
+	ret := _m.Called(ctx, userId, documentId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDocument")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, documentId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetCollectionDocuments provides a mock function with given fields: ctx, userId, collectionId
+func (_m *CollectionUsecase) GetCollectionDocuments(ctx context.Context, userId int, collectionId int) ([]dto.CollectionDocumentResponse, error) {
+	ret := _m.Called(ctx, userId, collectionId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollectionDocuments")
+	}
+
+	var r0 []dto.CollectionDocumentResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]dto.CollectionDocumentResponse, error)); ok {
+		return rf(ctx, userId, collectionId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []dto.CollectionDocumentResponse); ok {
+		r0 = rf(ctx, userId, collectionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.CollectionDocumentResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, collectionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCollections provides a mock function with given fields: ctx, userId
+func (_m *CollectionUsecase) GetCollections(ctx context.Context, userId int) ([]dto.CollectionResponse, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollections")
+	}
+
+	var r0 []dto.CollectionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]dto.CollectionResponse, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []dto.CollectionResponse); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.CollectionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetEmbeddingJob provides a mock function with given fields: ctx, userId, id
+func (_m *CollectionUsecase) GetEmbeddingJob(ctx context.Context, userId int, id int) (dto.EmbeddingJobResponse, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEmbeddingJob")
+	}
+
+	var r0 dto.EmbeddingJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (dto.EmbeddingJobResponse, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) dto.EmbeddingJobResponse); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(dto.EmbeddingJobResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReembedCollection provides a mock function with given fields: ctx, userId, collectionId
+func (_m *CollectionUsecase) ReembedCollection(ctx context.Context, userId int, collectionId int) (dto.EmbeddingJobResponse, error) {
+	ret := _m.Called(ctx, userId, collectionId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReembedCollection")
+	}
+
+	var r0 dto.EmbeddingJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (dto.EmbeddingJobResponse, error)); ok {
+		return rf(ctx, userId, collectionId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) dto.EmbeddingJobResponse); ok {
+		r0 = rf(ctx, userId, collectionId)
+	} else {
+		r0 = ret.Get(0).(dto.EmbeddingJobResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, collectionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReembedOutdatedCollections provides a mock function with given fields: ctx
+func (_m *CollectionUsecase) ReembedOutdatedCollections(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReembedOutdatedCollections")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateCollection provides a mock function with given fields: ctx, userId, id, req
+func (_m *CollectionUsecase) UpdateCollection(ctx context.Context, userId int, id int, req dto.CollectionRequest) (dto.CollectionResponse, error) {
+	ret := _m.Called(ctx, userId, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateCollection")
+	}
+
+	var r0 dto.CollectionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.CollectionRequest) (dto.CollectionResponse, error)); ok {
+		return rf(ctx, userId, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.CollectionRequest) dto.CollectionResponse); ok {
+		r0 = rf(ctx, userId, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.CollectionResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.CollectionRequest) error); ok {
+		r1 = rf(ctx, userId, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDocument provides a mock function with given fields: ctx, userId, documentId, req
+func (_m *CollectionUsecase) UpdateDocument(ctx context.Context, userId int, documentId int, req dto.DocumentUploadRequest) (dto.CollectionDocumentResponse, error) {
+	ret := _m.Called(ctx, userId, documentId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDocument")
+	}
+
+	var r0 dto.CollectionDocumentResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.DocumentUploadRequest) (dto.CollectionDocumentResponse, error)); ok {
+		return rf(ctx, userId, documentId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.DocumentUploadRequest) dto.CollectionDocumentResponse); ok {
+		r0 = rf(ctx, userId, documentId, req)
+	} else {
+		r0 = ret.Get(0).(dto.CollectionDocumentResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.DocumentUploadRequest) error); ok {
+		r1 = rf(ctx, userId, documentId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewCollectionUsecase creates a new instance of CollectionUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCollectionUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *CollectionUsecase {
+	mock := &CollectionUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
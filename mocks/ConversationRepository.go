@@ -0,0 +1,367 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	mysql "github.com/fadilahonespot/chatbot/repository/mysql"
+)
+
+// ConversationRepository is an autogenerated mock type for the ConversationRepository type
+type ConversationRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, req
+func (_m *ConversationRepository) Create(ctx context.Context, req *entity.Conversation) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Conversation) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Find provides a mock function with given fields: ctx, filter
+func (_m *ConversationRepository) Find(ctx context.Context, filter mysql.ConversationFilter) ([]entity.Conversation, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Find")
+	}
+
+	var r0 []entity.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.ConversationFilter) ([]entity.Conversation, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.ConversationFilter) []entity.Conversation); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Conversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, mysql.ConversationFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByExternalId provides a mock function with given fields: ctx, userId, source, externalId
+func (_m *ConversationRepository) GetByExternalId(ctx context.Context, userId int, source string, externalId string) (*entity.Conversation, error) {
+	ret := _m.Called(ctx, userId, source, externalId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByExternalId")
+	}
+
+	var r0 *entity.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) (*entity.Conversation, error)); ok {
+		return rf(ctx, userId, source, externalId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) *entity.Conversation); ok {
+		r0 = rf(ctx, userId, source, externalId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Conversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, string) error); ok {
+		r1 = rf(ctx, userId, source, externalId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: ctx, id
+func (_m *ConversationRepository) GetById(ctx context.Context, id int) (*entity.Conversation, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetById")
+	}
+
+	var r0 *entity.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.Conversation, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.Conversation); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Conversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCollectionIds provides a mock function with given fields: ctx, conversationId
+func (_m *ConversationRepository) GetCollectionIds(ctx context.Context, conversationId int) ([]int, error) {
+	ret := _m.Called(ctx, conversationId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCollectionIds")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]int, error)); ok {
+		return rf(ctx, conversationId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []int); ok {
+		r0 = rf(ctx, conversationId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, conversationId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetLatestByUserId provides a mock function with given fields: ctx, userId
+func (_m *ConversationRepository) GetLatestByUserId(ctx context.Context, userId int) (*entity.Conversation, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLatestByUserId")
+	}
+
+	var r0 *entity.Conversation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.Conversation, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.Conversation); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Conversation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTagNames provides a mock function with given fields: ctx, userId
+func (_m *ConversationRepository) GetTagNames(ctx context.Context, userId int) ([]string, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTagNames")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]string, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []string); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTags provides a mock function with given fields: ctx, conversationIds
+func (_m *ConversationRepository) GetTags(ctx context.Context, conversationIds []int) ([]entity.ConversationTag, error) {
+	ret := _m.Called(ctx, conversationIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTags")
+	}
+
+	var r0 []entity.ConversationTag
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) ([]entity.ConversationTag, error)); ok {
+		return rf(ctx, conversationIds)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) []entity.ConversationTag); ok {
+		r0 = rf(ctx, conversationIds)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.ConversationTag)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, conversationIds)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetCollectionIds provides a mock function with given fields: ctx, conversationId, collectionIds
+func (_m *ConversationRepository) SetCollectionIds(ctx context.Context, conversationId int, collectionIds []int) error {
+	ret := _m.Called(ctx, conversationId, collectionIds)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetCollectionIds")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, []int) error); ok {
+		r0 = rf(ctx, conversationId, collectionIds)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetContextStart provides a mock function with given fields: ctx, id, chatId
+func (_m *ConversationRepository) SetContextStart(ctx context.Context, id int, chatId int) error {
+	ret := _m.Called(ctx, id, chatId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetContextStart")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, id, chatId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetGeneratedTitle provides a mock function with given fields: ctx, id, title
+func (_m *ConversationRepository) SetGeneratedTitle(ctx context.Context, id int, title string) error {
+	ret := _m.Called(ctx, id, title)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetGeneratedTitle")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, id, title)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetPinned provides a mock function with given fields: ctx, id, pinned
+func (_m *ConversationRepository) SetPinned(ctx context.Context, id int, pinned bool) error {
+	ret := _m.Called(ctx, id, pinned)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetPinned")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) error); ok {
+		r0 = rf(ctx, id, pinned)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetTags provides a mock function with given fields: ctx, userId, conversationId, tags
+func (_m *ConversationRepository) SetTags(ctx context.Context, userId int, conversationId int, tags []string) error {
+	ret := _m.Called(ctx, userId, conversationId, tags)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetTags")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, []string) error); ok {
+		r0 = rf(ctx, userId, conversationId, tags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateTitle provides a mock function with given fields: ctx, id, title
+func (_m *ConversationRepository) UpdateTitle(ctx context.Context, id int, title string) error {
+	ret := _m.Called(ctx, id, title)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTitle")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, id, title)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewConversationRepository creates a new instance of ConversationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewConversationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ConversationRepository {
+	mock := &ConversationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,237 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ConversationUsecase is an autogenerated mock type for the ConversationUsecase type
+type ConversationUsecase struct {
+	mock.Mock
+}
+
+// ClearConversation provides a mock function with given fields: ctx, userId, id
+func (_m *ConversationUsecase) ClearConversation(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClearConversation")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteChat provides a mock function with given fields: ctx, userId, id
+func (_m *ConversationUsecase) DeleteChat(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteChat")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetConversations provides a mock function with given fields: ctx, userId, filter
+func (_m *ConversationUsecase) GetConversations(ctx context.Context, userId int, filter dto.ConversationFilter) ([]dto.ConversationResponse, error) {
+	ret := _m.Called(ctx, userId, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetConversations")
+	}
+
+	var r0 []dto.ConversationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ConversationFilter) ([]dto.ConversationResponse, error)); ok {
+		return rf(ctx, userId, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ConversationFilter) []dto.ConversationResponse); ok {
+		r0 = rf(ctx, userId, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.ConversationResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.ConversationFilter) error); ok {
+		r1 = rf(ctx, userId, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTags provides a mock function with given fields: ctx, userId
+func (_m *ConversationUsecase) GetTags(ctx context.Context, userId int) ([]string, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTags")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]string, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []string); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PinConversation provides a mock function with given fields: ctx, userId, id, req
+func (_m *ConversationUsecase) PinConversation(ctx context.Context, userId int, id int, req dto.ConversationPinRequest) (dto.ConversationResponse, error) {
+	ret := _m.Called(ctx, userId, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PinConversation")
+	}
+
+	var r0 dto.ConversationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.ConversationPinRequest) (dto.ConversationResponse, error)); ok {
+		return rf(ctx, userId, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.ConversationPinRequest) dto.ConversationResponse); ok {
+		r0 = rf(ctx, userId, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.ConversationResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.ConversationPinRequest) error); ok {
+		r1 = rf(ctx, userId, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResetContext provides a mock function with given fields: ctx, userId, id
+func (_m *ConversationUsecase) ResetContext(ctx context.Context, userId int, id int) (dto.ConversationResponse, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetContext")
+	}
+
+	var r0 dto.ConversationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (dto.ConversationResponse, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) dto.ConversationResponse); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(dto.ConversationResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetConversationTags provides a mock function with given fields: ctx, userId, id, req
+func (_m *ConversationUsecase) SetConversationTags(ctx context.Context, userId int, id int, req dto.ConversationTagsRequest) (dto.ConversationResponse, error) {
+	ret := _m.Called(ctx, userId, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetConversationTags")
+	}
+
+	var r0 dto.ConversationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.ConversationTagsRequest) (dto.ConversationResponse, error)); ok {
+		return rf(ctx, userId, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.ConversationTagsRequest) dto.ConversationResponse); ok {
+		r0 = rf(ctx, userId, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.ConversationResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.ConversationTagsRequest) error); ok {
+		r1 = rf(ctx, userId, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateConversationTitle provides a mock function with given fields: ctx, userId, id, req
+func (_m *ConversationUsecase) UpdateConversationTitle(ctx context.Context, userId int, id int, req dto.ConversationTitleRequest) (dto.ConversationResponse, error) {
+	ret := _m.Called(ctx, userId, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateConversationTitle")
+	}
+
+	var r0 dto.ConversationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.ConversationTitleRequest) (dto.ConversationResponse, error)); ok {
+		return rf(ctx, userId, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.ConversationTitleRequest) dto.ConversationResponse); ok {
+		r0 = rf(ctx, userId, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.ConversationResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.ConversationTitleRequest) error); ok {
+		r1 = rf(ctx, userId, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewConversationUsecase creates a new instance of ConversationUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewConversationUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ConversationUsecase {
+	mock := &ConversationUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
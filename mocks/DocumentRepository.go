@@ -0,0 +1,191 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DocumentRepository is an autogenerated mock type for the DocumentRepository type
+type DocumentRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, req
+func (_m *DocumentRepository) Create(ctx context.Context, req *entity.Document) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Document) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateVersion provides a mock function with given fields: ctx, req
+func (_m *DocumentRepository) CreateVersion(ctx context.Context, req *entity.DocumentVersion) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateVersion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.DocumentVersion) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *DocumentRepository) Delete(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByCollectionId provides a mock function with given fields: ctx, collectionId
+func (_m *DocumentRepository) GetByCollectionId(ctx context.Context, collectionId int) ([]entity.Document, error) {
+	ret := _m.Called(ctx, collectionId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByCollectionId")
+	}
+
+	var r0 []entity.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]entity.Document, error)); ok {
+		return rf(ctx, collectionId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.Document); ok {
+		r0 = rf(ctx, collectionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, collectionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: ctx, id
+func (_m *DocumentRepository) GetById(ctx context.Context, id int) (*entity.Document, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetById")
+	}
+
+	var r0 *entity.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.Document, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.Document); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByUserId provides a mock function with given fields: ctx, userId
+func (_m *DocumentRepository) GetByUserId(ctx context.Context, userId int) ([]entity.Document, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByUserId")
+	}
+
+	var r0 []entity.Document
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]entity.Document, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.Document); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Document)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, req
+func (_m *DocumentRepository) Update(ctx context.Context, req *entity.Document) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Document) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewDocumentRepository creates a new instance of DocumentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDocumentRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DocumentRepository {
+	mock := &DocumentRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
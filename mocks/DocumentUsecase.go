@@ -0,0 +1,105 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// DocumentUsecase is an autogenerated mock type for the DocumentUsecase type
+type DocumentUsecase struct {
+	mock.Mock
+}
+
+// DeleteDocument provides a mock function with given fields: ctx, userId, id
+func (_m *DocumentUsecase) DeleteDocument(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteDocument")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDocuments provides a mock function with given fields: ctx, userId
+func (_m *DocumentUsecase) GetDocuments(ctx context.Context, userId int) ([]dto.DocumentResponse, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDocuments")
+	}
+
+	var r0 []dto.DocumentResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]dto.DocumentResponse, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []dto.DocumentResponse); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.DocumentResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UploadDocument provides a mock function with given fields: ctx, userId, req
+func (_m *DocumentUsecase) UploadDocument(ctx context.Context, userId int, req dto.DocumentUploadRequest) (dto.DocumentResponse, error) {
+	ret := _m.Called(ctx, userId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UploadDocument")
+	}
+
+	var r0 dto.DocumentResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.DocumentUploadRequest) (dto.DocumentResponse, error)); ok {
+		return rf(ctx, userId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.DocumentUploadRequest) dto.DocumentResponse); ok {
+		r0 = rf(ctx, userId, req)
+	} else {
+		r0 = ret.Get(0).(dto.DocumentResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.DocumentUploadRequest) error); ok {
+		r1 = rf(ctx, userId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewDocumentUsecase creates a new instance of DocumentUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDocumentUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DocumentUsecase {
+	mock := &DocumentUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
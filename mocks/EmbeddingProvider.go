@@ -0,0 +1,76 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EmbeddingProvider is an autogenerated mock type for the EmbeddingProvider type
+type EmbeddingProvider struct {
+	mock.Mock
+}
+
+// Embed provides a mock function with given fields: ctx, inputs
+func (_m *EmbeddingProvider) Embed(ctx context.Context, inputs []string) ([][]float32, error) {
+	ret := _m.Called(ctx, inputs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Embed")
+	}
+
+	var r0 [][]float32
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([][]float32, error)); ok {
+		return rf(ctx, inputs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) [][]float32); ok {
+		r0 = rf(ctx, inputs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]float32)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, inputs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Model provides a mock function with given fields:
+func (_m *EmbeddingProvider) Model() string {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Model")
+	}
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// NewEmbeddingProvider creates a new instance of EmbeddingProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEmbeddingProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EmbeddingProvider {
+	mock := &EmbeddingProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,95 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EncryptionRepository is an autogenerated mock type for the EncryptionRepository type
+type EncryptionRepository struct {
+	mock.Mock
+}
+
+// CreateJob provides a mock function with given fields: ctx, req
+func (_m *EncryptionRepository) CreateJob(ctx context.Context, req *entity.EncryptionJob) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.EncryptionJob) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetJobById provides a mock function with given fields: ctx, id
+func (_m *EncryptionRepository) GetJobById(ctx context.Context, id int) (*entity.EncryptionJob, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetJobById")
+	}
+
+	var r0 *entity.EncryptionJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.EncryptionJob, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.EncryptionJob); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.EncryptionJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateJob provides a mock function with given fields: ctx, req
+func (_m *EncryptionRepository) UpdateJob(ctx context.Context, req *entity.EncryptionJob) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.EncryptionJob) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewEncryptionRepository creates a new instance of EncryptionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEncryptionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EncryptionRepository {
+	mock := &EncryptionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
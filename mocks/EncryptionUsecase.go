@@ -0,0 +1,103 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// EncryptionUsecase is an autogenerated mock type for the EncryptionUsecase type
+type EncryptionUsecase struct {
+	mock.Mock
+}
+
+// GetEncryptionJob provides a mock function with given fields: ctx, id
+func (_m *EncryptionUsecase) GetEncryptionJob(ctx context.Context, id int) (dto.EncryptionJobResponse, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEncryptionJob")
+	}
+
+	var r0 dto.EncryptionJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (dto.EncryptionJobResponse, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) dto.EncryptionJobResponse); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(dto.EncryptionJobResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReencryptOutdatedChats provides a mock function with given fields: ctx
+func (_m *EncryptionUsecase) ReencryptOutdatedChats(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReencryptOutdatedChats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// StartReencryption provides a mock function with given fields: ctx, adminId, rotate
+func (_m *EncryptionUsecase) StartReencryption(ctx context.Context, adminId int, rotate bool) (dto.EncryptionJobResponse, error) {
+	ret := _m.Called(ctx, adminId, rotate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartReencryption")
+	}
+
+	var r0 dto.EncryptionJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) (dto.EncryptionJobResponse, error)); ok {
+		return rf(ctx, adminId, rotate)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) dto.EncryptionJobResponse); ok {
+		r0 = rf(ctx, adminId, rotate)
+	} else {
+		r0 = ret.Get(0).(dto.EncryptionJobResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, bool) error); ok {
+		r1 = rf(ctx, adminId, rotate)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewEncryptionUsecase creates a new instance of EncryptionUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewEncryptionUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *EncryptionUsecase {
+	mock := &EncryptionUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,57 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ExportUsecase is an autogenerated mock type for the ExportUsecase type
+type ExportUsecase struct {
+	mock.Mock
+}
+
+// ExportChats provides a mock function with given fields: ctx, userId, req
+func (_m *ExportUsecase) ExportChats(ctx context.Context, userId int, req dto.ExportRequest) (dto.ExportResponse, error) {
+	ret := _m.Called(ctx, userId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExportChats")
+	}
+
+	var r0 dto.ExportResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ExportRequest) (dto.ExportResponse, error)); ok {
+		return rf(ctx, userId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ExportRequest) dto.ExportResponse); ok {
+		r0 = rf(ctx, userId, req)
+	} else {
+		r0 = ret.Get(0).(dto.ExportResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.ExportRequest) error); ok {
+		r1 = rf(ctx, userId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewExportUsecase creates a new instance of ExportUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewExportUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ExportUsecase {
+	mock := &ExportUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
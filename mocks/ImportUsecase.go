@@ -0,0 +1,85 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ImportUsecase is an autogenerated mock type for the ImportUsecase type
+type ImportUsecase struct {
+	mock.Mock
+}
+
+// GetImportJob provides a mock function with given fields: ctx, userId, id
+func (_m *ImportUsecase) GetImportJob(ctx context.Context, userId int, id int) (dto.ImportJobResponse, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetImportJob")
+	}
+
+	var r0 dto.ImportJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (dto.ImportJobResponse, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) dto.ImportJobResponse); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(dto.ImportJobResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImportChats provides a mock function with given fields: ctx, userId, req
+func (_m *ImportUsecase) ImportChats(ctx context.Context, userId int, req dto.ImportRequest) (dto.ImportJobResponse, error) {
+	ret := _m.Called(ctx, userId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ImportChats")
+	}
+
+	var r0 dto.ImportJobResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ImportRequest) (dto.ImportJobResponse, error)); ok {
+		return rf(ctx, userId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ImportRequest) dto.ImportJobResponse); ok {
+		r0 = rf(ctx, userId, req)
+	} else {
+		r0 = ret.Get(0).(dto.ImportJobResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.ImportRequest) error); ok {
+		r1 = rf(ctx, userId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewImportUsecase creates a new instance of ImportUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewImportUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ImportUsecase {
+	mock := &ImportUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
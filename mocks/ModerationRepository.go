@@ -0,0 +1,154 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	mysql "github.com/fadilahonespot/chatbot/repository/mysql"
+
+	time "time"
+)
+
+// ModerationRepository is an autogenerated mock type for the ModerationRepository type
+type ModerationRepository struct {
+	mock.Mock
+}
+
+// CreateFlag provides a mock function with given fields: ctx, req
+func (_m *ModerationRepository) CreateFlag(ctx context.Context, req *entity.ModerationFlag) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateFlag")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.ModerationFlag) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindFlags provides a mock function with given fields: ctx, filter
+func (_m *ModerationRepository) FindFlags(ctx context.Context, filter mysql.ModerationFilter) ([]entity.ModerationFlag, int64, error) {
+	ret := _m.Called(ctx, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindFlags")
+	}
+
+	var r0 []entity.ModerationFlag
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.ModerationFilter) ([]entity.ModerationFlag, int64, error)); ok {
+		return rf(ctx, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, mysql.ModerationFilter) []entity.ModerationFlag); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.ModerationFlag)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, mysql.ModerationFilter) int64); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, mysql.ModerationFilter) error); ok {
+		r2 = rf(ctx, filter)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetFlagById provides a mock function with given fields: ctx, id
+func (_m *ModerationRepository) GetFlagById(ctx context.Context, id int) (*entity.ModerationFlag, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFlagById")
+	}
+
+	var r0 *entity.ModerationFlag
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.ModerationFlag, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.ModerationFlag); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.ModerationFlag)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RemoveContent provides a mock function with given fields: ctx, userId, before
+func (_m *ModerationRepository) RemoveContent(ctx context.Context, userId int, before time.Time) error {
+	ret := _m.Called(ctx, userId, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RemoveContent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) error); ok {
+		r0 = rf(ctx, userId, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateFlag provides a mock function with given fields: ctx, req
+func (_m *ModerationRepository) UpdateFlag(ctx context.Context, req *entity.ModerationFlag) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFlag")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.ModerationFlag) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewModerationRepository creates a new instance of ModerationRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewModerationRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ModerationRepository {
+	mock := &ModerationRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
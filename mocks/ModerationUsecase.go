@@ -0,0 +1,85 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ModerationUsecase is an autogenerated mock type for the ModerationUsecase type
+type ModerationUsecase struct {
+	mock.Mock
+}
+
+// GetFlags provides a mock function with given fields: ctx, adminId, filter
+func (_m *ModerationUsecase) GetFlags(ctx context.Context, adminId int, filter dto.ModerationFilter) (dto.ModerationFlagListResponse, error) {
+	ret := _m.Called(ctx, adminId, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFlags")
+	}
+
+	var r0 dto.ModerationFlagListResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ModerationFilter) (dto.ModerationFlagListResponse, error)); ok {
+		return rf(ctx, adminId, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ModerationFilter) dto.ModerationFlagListResponse); ok {
+		r0 = rf(ctx, adminId, filter)
+	} else {
+		r0 = ret.Get(0).(dto.ModerationFlagListResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.ModerationFilter) error); ok {
+		r1 = rf(ctx, adminId, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReviewFlag provides a mock function with given fields: ctx, reviewerId, id, req
+func (_m *ModerationUsecase) ReviewFlag(ctx context.Context, reviewerId int, id int, req dto.ModerationReviewRequest) (dto.ModerationFlagResponse, error) {
+	ret := _m.Called(ctx, reviewerId, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReviewFlag")
+	}
+
+	var r0 dto.ModerationFlagResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.ModerationReviewRequest) (dto.ModerationFlagResponse, error)); ok {
+		return rf(ctx, reviewerId, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.ModerationReviewRequest) dto.ModerationFlagResponse); ok {
+		r0 = rf(ctx, reviewerId, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.ModerationFlagResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.ModerationReviewRequest) error); ok {
+		r1 = rf(ctx, reviewerId, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewModerationUsecase creates a new instance of ModerationUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewModerationUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ModerationUsecase {
+	mock := &ModerationUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,57 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	moderation "github.com/fadilahonespot/chatbot/repository/http/moderation"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Moderator is an autogenerated mock type for the Moderator type
+type Moderator struct {
+	mock.Mock
+}
+
+// Moderate provides a mock function with given fields: ctx, text
+func (_m *Moderator) Moderate(ctx context.Context, text string) (moderation.Result, error) {
+	ret := _m.Called(ctx, text)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Moderate")
+	}
+
+	var r0 moderation.Result
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (moderation.Result, error)); ok {
+		return rf(ctx, text)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) moderation.Result); ok {
+		r0 = rf(ctx, text)
+	} else {
+		r0 = ret.Get(0).(moderation.Result)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, text)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewModerator creates a new instance of Moderator. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewModerator(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Moderator {
+	mock := &Moderator{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,173 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PersonaRepository is an autogenerated mock type for the PersonaRepository type
+type PersonaRepository struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, req
+func (_m *PersonaRepository) Create(ctx context.Context, req *entity.Persona) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Persona) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *PersonaRepository) Delete(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx
+func (_m *PersonaRepository) GetAll(ctx context.Context) ([]entity.Persona, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []entity.Persona
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]entity.Persona, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.Persona); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Persona)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: ctx, id
+func (_m *PersonaRepository) GetById(ctx context.Context, id int) (*entity.Persona, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetById")
+	}
+
+	var r0 *entity.Persona
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.Persona, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.Persona); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Persona)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPrompt provides a mock function with given fields: ctx, personaId, version
+func (_m *PersonaRepository) GetPrompt(ctx context.Context, personaId int, version int) (*entity.PersonaPrompt, error) {
+	ret := _m.Called(ctx, personaId, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPrompt")
+	}
+
+	var r0 *entity.PersonaPrompt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (*entity.PersonaPrompt, error)); ok {
+		return rf(ctx, personaId, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) *entity.PersonaPrompt); ok {
+		r0 = rf(ctx, personaId, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.PersonaPrompt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, personaId, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, req
+func (_m *PersonaRepository) Update(ctx context.Context, req *entity.Persona) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.Persona) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewPersonaRepository creates a new instance of PersonaRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPersonaRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PersonaRepository {
+	mock := &PersonaRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,163 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PersonaUsecase is an autogenerated mock type for the PersonaUsecase type
+type PersonaUsecase struct {
+	mock.Mock
+}
+
+// CreatePersona provides a mock function with given fields: ctx, req
+func (_m *PersonaUsecase) CreatePersona(ctx context.Context, req dto.PersonaRequest) (dto.PersonaResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePersona")
+	}
+
+	var r0 dto.PersonaResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.PersonaRequest) (dto.PersonaResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.PersonaRequest) dto.PersonaResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(dto.PersonaResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.PersonaRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePersona provides a mock function with given fields: ctx, id
+func (_m *PersonaUsecase) DeletePersona(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePersona")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAvailablePersonas provides a mock function with given fields: ctx, userId
+func (_m *PersonaUsecase) GetAvailablePersonas(ctx context.Context, userId int) ([]dto.PersonaResponse, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAvailablePersonas")
+	}
+
+	var r0 []dto.PersonaResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]dto.PersonaResponse, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []dto.PersonaResponse); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.PersonaResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPersonas provides a mock function with given fields: ctx
+func (_m *PersonaUsecase) GetPersonas(ctx context.Context) ([]dto.PersonaResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPersonas")
+	}
+
+	var r0 []dto.PersonaResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]dto.PersonaResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []dto.PersonaResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.PersonaResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePersona provides a mock function with given fields: ctx, id, req
+func (_m *PersonaUsecase) UpdatePersona(ctx context.Context, id int, req dto.PersonaRequest) (dto.PersonaResponse, error) {
+	ret := _m.Called(ctx, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePersona")
+	}
+
+	var r0 dto.PersonaResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.PersonaRequest) (dto.PersonaResponse, error)); ok {
+		return rf(ctx, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.PersonaRequest) dto.PersonaResponse); ok {
+		r0 = rf(ctx, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.PersonaResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.PersonaRequest) error); ok {
+		r1 = rf(ctx, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPersonaUsecase creates a new instance of PersonaUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPersonaUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PersonaUsecase {
+	mock := &PersonaUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,161 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// PromptTemplateUsecase is an autogenerated mock type for the PromptTemplateUsecase type
+type PromptTemplateUsecase struct {
+	mock.Mock
+}
+
+// CreatePromptTemplate provides a mock function with given fields: ctx, userId, req
+func (_m *PromptTemplateUsecase) CreatePromptTemplate(ctx context.Context, userId int, req dto.PromptTemplateRequest) (dto.PromptTemplateResponse, error) {
+	ret := _m.Called(ctx, userId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePromptTemplate")
+	}
+
+	var r0 dto.PromptTemplateResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.PromptTemplateRequest) (dto.PromptTemplateResponse, error)); ok {
+		return rf(ctx, userId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.PromptTemplateRequest) dto.PromptTemplateResponse); ok {
+		r0 = rf(ctx, userId, req)
+	} else {
+		r0 = ret.Get(0).(dto.PromptTemplateResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.PromptTemplateRequest) error); ok {
+		r1 = rf(ctx, userId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePromptTemplate provides a mock function with given fields: ctx, userId, id
+func (_m *PromptTemplateUsecase) DeletePromptTemplate(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePromptTemplate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPromptTemplate provides a mock function with given fields: ctx, userId, id
+func (_m *PromptTemplateUsecase) GetPromptTemplate(ctx context.Context, userId int, id int) (dto.PromptTemplateResponse, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPromptTemplate")
+	}
+
+	var r0 dto.PromptTemplateResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (dto.PromptTemplateResponse, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) dto.PromptTemplateResponse); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(dto.PromptTemplateResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPromptTemplates provides a mock function with given fields: ctx, userId
+func (_m *PromptTemplateUsecase) GetPromptTemplates(ctx context.Context, userId int) ([]dto.PromptTemplateResponse, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPromptTemplates")
+	}
+
+	var r0 []dto.PromptTemplateResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]dto.PromptTemplateResponse, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []dto.PromptTemplateResponse); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.PromptTemplateResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePromptTemplate provides a mock function with given fields: ctx, userId, id, req
+func (_m *PromptTemplateUsecase) UpdatePromptTemplate(ctx context.Context, userId int, id int, req dto.PromptTemplateRequest) (dto.PromptTemplateResponse, error) {
+	ret := _m.Called(ctx, userId, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePromptTemplate")
+	}
+
+	var r0 dto.PromptTemplateResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.PromptTemplateRequest) (dto.PromptTemplateResponse, error)); ok {
+		return rf(ctx, userId, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.PromptTemplateRequest) dto.PromptTemplateResponse); ok {
+		r0 = rf(ctx, userId, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.PromptTemplateResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.PromptTemplateRequest) error); ok {
+		r1 = rf(ctx, userId, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewPromptTemplateUsecase creates a new instance of PromptTemplateUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPromptTemplateUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PromptTemplateUsecase {
+	mock := &PromptTemplateUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,209 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RetentionRepository is an autogenerated mock type for the RetentionRepository type
+type RetentionRepository struct {
+	mock.Mock
+}
+
+// CreatePolicy provides a mock function with given fields: ctx, req
+func (_m *RetentionRepository) CreatePolicy(ctx context.Context, req *entity.RetentionPolicy) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.RetentionPolicy) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateReport provides a mock function with given fields: ctx, req
+func (_m *RetentionRepository) CreateReport(ctx context.Context, req *entity.PurgeReport) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateReport")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.PurgeReport) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePolicy provides a mock function with given fields: ctx, id
+func (_m *RetentionRepository) DeletePolicy(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPolicies provides a mock function with given fields: ctx
+func (_m *RetentionRepository) GetPolicies(ctx context.Context) ([]entity.RetentionPolicy, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPolicies")
+	}
+
+	var r0 []entity.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]entity.RetentionPolicy, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []entity.RetentionPolicy); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPolicyById provides a mock function with given fields: ctx, id
+func (_m *RetentionRepository) GetPolicyById(ctx context.Context, id int) (*entity.RetentionPolicy, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPolicyById")
+	}
+
+	var r0 *entity.RetentionPolicy
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.RetentionPolicy, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.RetentionPolicy); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.RetentionPolicy)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReportById provides a mock function with given fields: ctx, id
+func (_m *RetentionRepository) GetReportById(ctx context.Context, id int) (*entity.PurgeReport, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetReportById")
+	}
+
+	var r0 *entity.PurgeReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.PurgeReport, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.PurgeReport); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.PurgeReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePolicy provides a mock function with given fields: ctx, req
+func (_m *RetentionRepository) UpdatePolicy(ctx context.Context, req *entity.RetentionPolicy) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.RetentionPolicy) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateReport provides a mock function with given fields: ctx, req
+func (_m *RetentionRepository) UpdateReport(ctx context.Context, req *entity.PurgeReport) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateReport")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.PurgeReport) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRetentionRepository creates a new instance of RetentionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRetentionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RetentionRepository {
+	mock := &RetentionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,194 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RetentionUsecase is an autogenerated mock type for the RetentionUsecase type
+type RetentionUsecase struct {
+	mock.Mock
+}
+
+// CreatePolicy provides a mock function with given fields: ctx, adminId, req
+func (_m *RetentionUsecase) CreatePolicy(ctx context.Context, adminId int, req dto.RetentionPolicyRequest) (dto.RetentionPolicyResponse, error) {
+	ret := _m.Called(ctx, adminId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreatePolicy")
+	}
+
+	var r0 dto.RetentionPolicyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.RetentionPolicyRequest) (dto.RetentionPolicyResponse, error)); ok {
+		return rf(ctx, adminId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.RetentionPolicyRequest) dto.RetentionPolicyResponse); ok {
+		r0 = rf(ctx, adminId, req)
+	} else {
+		r0 = ret.Get(0).(dto.RetentionPolicyResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.RetentionPolicyRequest) error); ok {
+		r1 = rf(ctx, adminId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePolicy provides a mock function with given fields: ctx, adminId, id
+func (_m *RetentionUsecase) DeletePolicy(ctx context.Context, adminId int, id int) error {
+	ret := _m.Called(ctx, adminId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePolicy")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, adminId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetPolicies provides a mock function with given fields: ctx
+func (_m *RetentionUsecase) GetPolicies(ctx context.Context) ([]dto.RetentionPolicyResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPolicies")
+	}
+
+	var r0 []dto.RetentionPolicyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]dto.RetentionPolicyResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []dto.RetentionPolicyResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.RetentionPolicyResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPurgeReport provides a mock function with given fields: ctx, id
+func (_m *RetentionUsecase) GetPurgeReport(ctx context.Context, id int) (dto.PurgeReportResponse, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPurgeReport")
+	}
+
+	var r0 dto.PurgeReportResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (dto.PurgeReportResponse, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) dto.PurgeReportResponse); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(dto.PurgeReportResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SchedulePurge provides a mock function with given fields: ctx
+func (_m *RetentionUsecase) SchedulePurge(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// StartPurge provides a mock function with given fields: ctx, adminId, dryRun
+func (_m *RetentionUsecase) StartPurge(ctx context.Context, adminId int, dryRun bool) (dto.PurgeReportResponse, error) {
+	ret := _m.Called(ctx, adminId, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartPurge")
+	}
+
+	var r0 dto.PurgeReportResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) (dto.PurgeReportResponse, error)); ok {
+		return rf(ctx, adminId, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, bool) dto.PurgeReportResponse); ok {
+		r0 = rf(ctx, adminId, dryRun)
+	} else {
+		r0 = ret.Get(0).(dto.PurgeReportResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, bool) error); ok {
+		r1 = rf(ctx, adminId, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePolicy provides a mock function with given fields: ctx, adminId, id, req
+func (_m *RetentionUsecase) UpdatePolicy(ctx context.Context, adminId int, id int, req dto.RetentionPolicyRequest) (dto.RetentionPolicyResponse, error) {
+	ret := _m.Called(ctx, adminId, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePolicy")
+	}
+
+	var r0 dto.RetentionPolicyResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.RetentionPolicyRequest) (dto.RetentionPolicyResponse, error)); ok {
+		return rf(ctx, adminId, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.RetentionPolicyRequest) dto.RetentionPolicyResponse); ok {
+		r0 = rf(ctx, adminId, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.RetentionPolicyResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.RetentionPolicyRequest) error); ok {
+		r1 = rf(ctx, adminId, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRetentionUsecase creates a new instance of RetentionUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRetentionUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RetentionUsecase {
+	mock := &RetentionUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,133 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ShareUsecase is an autogenerated mock type for the ShareUsecase type
+type ShareUsecase struct {
+	mock.Mock
+}
+
+// CreateShare provides a mock function with given fields: ctx, userId, req
+func (_m *ShareUsecase) CreateShare(ctx context.Context, userId int, req dto.ShareRequest) (dto.ShareResponse, error) {
+	ret := _m.Called(ctx, userId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateShare")
+	}
+
+	var r0 dto.ShareResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ShareRequest) (dto.ShareResponse, error)); ok {
+		return rf(ctx, userId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.ShareRequest) dto.ShareResponse); ok {
+		r0 = rf(ctx, userId, req)
+	} else {
+		r0 = ret.Get(0).(dto.ShareResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.ShareRequest) error); ok {
+		r1 = rf(ctx, userId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetShares provides a mock function with given fields: ctx, userId
+func (_m *ShareUsecase) GetShares(ctx context.Context, userId int) ([]dto.ShareResponse, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetShares")
+	}
+
+	var r0 []dto.ShareResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]dto.ShareResponse, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []dto.ShareResponse); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.ShareResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeShare provides a mock function with given fields: ctx, userId, id
+func (_m *ShareUsecase) RevokeShare(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeShare")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ViewShare provides a mock function with given fields: ctx, token, password
+func (_m *ShareUsecase) ViewShare(ctx context.Context, token string, password string) (dto.SharedConversationResponse, error) {
+	ret := _m.Called(ctx, token, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ViewShare")
+	}
+
+	var r0 dto.SharedConversationResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (dto.SharedConversationResponse, error)); ok {
+		return rf(ctx, token, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) dto.SharedConversationResponse); ok {
+		r0 = rf(ctx, token, password)
+	} else {
+		r0 = ret.Get(0).(dto.SharedConversationResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, token, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewShareUsecase creates a new instance of ShareUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewShareUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ShareUsecase {
+	mock := &ShareUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
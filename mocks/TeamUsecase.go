@@ -0,0 +1,163 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TeamUsecase is an autogenerated mock type for the TeamUsecase type
+type TeamUsecase struct {
+	mock.Mock
+}
+
+// CreateTeam provides a mock function with given fields: ctx, req
+func (_m *TeamUsecase) CreateTeam(ctx context.Context, req dto.TeamRequest) (dto.TeamResponse, error) {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateTeam")
+	}
+
+	var r0 dto.TeamResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, dto.TeamRequest) (dto.TeamResponse, error)); ok {
+		return rf(ctx, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, dto.TeamRequest) dto.TeamResponse); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(dto.TeamResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, dto.TeamRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteTeam provides a mock function with given fields: ctx, id
+func (_m *TeamUsecase) DeleteTeam(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteTeam")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetTeams provides a mock function with given fields: ctx
+func (_m *TeamUsecase) GetTeams(ctx context.Context) ([]dto.TeamResponse, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTeams")
+	}
+
+	var r0 []dto.TeamResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]dto.TeamResponse, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []dto.TeamResponse); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.TeamResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserTeams provides a mock function with given fields: ctx, userId
+func (_m *TeamUsecase) GetUserTeams(ctx context.Context, userId int) ([]dto.TeamResponse, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserTeams")
+	}
+
+	var r0 []dto.TeamResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]dto.TeamResponse, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []dto.TeamResponse); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.TeamResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateTeam provides a mock function with given fields: ctx, id, req
+func (_m *TeamUsecase) UpdateTeam(ctx context.Context, id int, req dto.TeamRequest) (dto.TeamResponse, error) {
+	ret := _m.Called(ctx, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateTeam")
+	}
+
+	var r0 dto.TeamResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.TeamRequest) (dto.TeamResponse, error)); ok {
+		return rf(ctx, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.TeamRequest) dto.TeamResponse); ok {
+		r0 = rf(ctx, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.TeamResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.TeamRequest) error); ok {
+		r1 = rf(ctx, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTeamUsecase creates a new instance of TeamUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTeamUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TeamUsecase {
+	mock := &TeamUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -14,6 +14,24 @@ type UserRepository struct {
 	mock.Mock
 }
 
+// Anonymize provides a mock function with given fields: ctx, id
+func (_m *UserRepository) Anonymize(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Anonymize")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: ctx, req
 func (_m *UserRepository) Create(ctx context.Context, req *entity.User) error {
 	ret := _m.Called(ctx, req)
@@ -32,6 +50,36 @@ func (_m *UserRepository) Create(ctx context.Context, req *entity.User) error {
 	return r0
 }
 
+// FindUnscoped provides a mock function with given fields: ctx, afterId, limit
+func (_m *UserRepository) FindUnscoped(ctx context.Context, afterId int, limit int) ([]entity.User, error) {
+	ret := _m.Called(ctx, afterId, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindUnscoped")
+	}
+
+	var r0 []entity.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) ([]entity.User, error)); ok {
+		return rf(ctx, afterId, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []entity.User); ok {
+		r0 = rf(ctx, afterId, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, afterId, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserByEmail provides a mock function with given fields: ctx, email
 func (_m *UserRepository) GetUserByEmail(ctx context.Context, email string) (*entity.User, error) {
 	ret := _m.Called(ctx, email)
@@ -92,6 +140,42 @@ func (_m *UserRepository) GetUserById(ctx context.Context, id int) (*entity.User
 	return r0, r1
 }
 
+// Purge provides a mock function with given fields: ctx, id
+func (_m *UserRepository) Purge(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Purge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateRole provides a mock function with given fields: ctx, id, role
+func (_m *UserRepository) UpdateRole(ctx context.Context, id int, role string) error {
+	ret := _m.Called(ctx, id, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+		r0 = rf(ctx, id, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewUserRepository creates a new instance of UserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUserRepository(t interface {
@@ -42,6 +42,24 @@ func (_m *UserUsecase) Login(ctx context.Context, req dto.LoginRequest) (dto.Log
 	return r0, r1
 }
 
+// PromoteAdmin provides a mock function with given fields: ctx, email
+func (_m *UserUsecase) PromoteAdmin(ctx context.Context, email string) error {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PromoteAdmin")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Register provides a mock function with given fields: ctx, req
 func (_m *UserUsecase) Register(ctx context.Context, req dto.RegisterRequest) error {
 	ret := _m.Called(ctx, req)
@@ -0,0 +1,175 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	vectorstore "github.com/fadilahonespot/chatbot/repository/vectorstore"
+)
+
+// VectorStore is an autogenerated mock type for the VectorStore type
+type VectorStore struct {
+	mock.Mock
+}
+
+// DeleteByDocumentId provides a mock function with given fields: ctx, documentId
+func (_m *VectorStore) DeleteByDocumentId(ctx context.Context, documentId int) error {
+	ret := _m.Called(ctx, documentId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByDocumentId")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, documentId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteByIds provides a mock function with given fields: ctx, ids
+func (_m *VectorStore) DeleteByIds(ctx context.Context, ids []int) error {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByIds")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) error); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetByCollectionId provides a mock function with given fields: ctx, collectionId
+func (_m *VectorStore) GetByCollectionId(ctx context.Context, collectionId int) ([]entity.DocumentChunk, error) {
+	ret := _m.Called(ctx, collectionId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByCollectionId")
+	}
+
+	var r0 []entity.DocumentChunk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]entity.DocumentChunk, error)); ok {
+		return rf(ctx, collectionId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.DocumentChunk); ok {
+		r0 = rf(ctx, collectionId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.DocumentChunk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, collectionId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetByDocumentId provides a mock function with given fields: ctx, documentId
+func (_m *VectorStore) GetByDocumentId(ctx context.Context, documentId int) ([]entity.DocumentChunk, error) {
+	ret := _m.Called(ctx, documentId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByDocumentId")
+	}
+
+	var r0 []entity.DocumentChunk
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]entity.DocumentChunk, error)); ok {
+		return rf(ctx, documentId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.DocumentChunk); ok {
+		r0 = rf(ctx, documentId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.DocumentChunk)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, documentId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Search provides a mock function with given fields: ctx, filter, embedding, topK
+func (_m *VectorStore) Search(ctx context.Context, filter vectorstore.SearchFilter, embedding []float32, topK int) ([]vectorstore.SearchResult, error) {
+	ret := _m.Called(ctx, filter, embedding, topK)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Search")
+	}
+
+	var r0 []vectorstore.SearchResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, vectorstore.SearchFilter, []float32, int) ([]vectorstore.SearchResult, error)); ok {
+		return rf(ctx, filter, embedding, topK)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, vectorstore.SearchFilter, []float32, int) []vectorstore.SearchResult); ok {
+		r0 = rf(ctx, filter, embedding, topK)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]vectorstore.SearchResult)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, vectorstore.SearchFilter, []float32, int) error); ok {
+		r1 = rf(ctx, filter, embedding, topK)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Upsert provides a mock function with given fields: ctx, chunks
+func (_m *VectorStore) Upsert(ctx context.Context, chunks []entity.DocumentChunk) error {
+	ret := _m.Called(ctx, chunks)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.DocumentChunk) error); ok {
+		r0 = rf(ctx, chunks)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewVectorStore creates a new instance of VectorStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewVectorStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *VectorStore {
+	mock := &VectorStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
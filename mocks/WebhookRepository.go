@@ -0,0 +1,372 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	entity "github.com/fadilahonespot/chatbot/entity"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// WebhookRepository is an autogenerated mock type for the WebhookRepository type
+type WebhookRepository struct {
+	mock.Mock
+}
+
+// ClaimDeliveries provides a mock function with given fields: ctx, lease, limit
+func (_m *WebhookRepository) ClaimDeliveries(ctx context.Context, lease time.Duration, limit int) ([]entity.WebhookDelivery, error) {
+	ret := _m.Called(ctx, lease, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDeliveries")
+	}
+
+	var r0 []entity.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration, int) ([]entity.WebhookDelivery, error)); ok {
+		return rf(ctx, lease, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Duration, int) []entity.WebhookDelivery); ok {
+		r0 = rf(ctx, lease, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Duration, int) error); ok {
+		r1 = rf(ctx, lease, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateAttempt provides a mock function with given fields: ctx, req
+func (_m *WebhookRepository) CreateAttempt(ctx context.Context, req *entity.WebhookAttempt) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateAttempt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.WebhookAttempt) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateDeliveries provides a mock function with given fields: ctx, req
+func (_m *WebhookRepository) CreateDeliveries(ctx context.Context, req []entity.WebhookDelivery) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateDeliveries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []entity.WebhookDelivery) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateSubscription provides a mock function with given fields: ctx, req
+func (_m *WebhookRepository) CreateSubscription(ctx context.Context, req *entity.WebhookSubscription) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.WebhookSubscription) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteSubscription provides a mock function with given fields: ctx, id
+func (_m *WebhookRepository) DeleteSubscription(ctx context.Context, id int) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteUserDeliveries provides a mock function with given fields: ctx, userId, before
+func (_m *WebhookRepository) DeleteUserDeliveries(ctx context.Context, userId int, before time.Time) error {
+	ret := _m.Called(ctx, userId, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserDeliveries")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) error); ok {
+		r0 = rf(ctx, userId, before)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindDeliveries provides a mock function with given fields: ctx, subscriptionId, page, limit
+func (_m *WebhookRepository) FindDeliveries(ctx context.Context, subscriptionId int, page int, limit int) ([]entity.WebhookDelivery, int64, error) {
+	ret := _m.Called(ctx, subscriptionId, page, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindDeliveries")
+	}
+
+	var r0 []entity.WebhookDelivery
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) ([]entity.WebhookDelivery, int64, error)); ok {
+		return rf(ctx, subscriptionId, page, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, int) []entity.WebhookDelivery); ok {
+		r0 = rf(ctx, subscriptionId, page, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, int) int64); ok {
+		r1 = rf(ctx, subscriptionId, page, limit)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int, int, int) error); ok {
+		r2 = rf(ctx, subscriptionId, page, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetActiveSubscriptions provides a mock function with given fields: ctx, userId
+func (_m *WebhookRepository) GetActiveSubscriptions(ctx context.Context, userId int) ([]entity.WebhookSubscription, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetActiveSubscriptions")
+	}
+
+	var r0 []entity.WebhookSubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]entity.WebhookSubscription, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.WebhookSubscription); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WebhookSubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDeliveryById provides a mock function with given fields: ctx, id
+func (_m *WebhookRepository) GetDeliveryById(ctx context.Context, id int) (*entity.WebhookDelivery, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeliveryById")
+	}
+
+	var r0 *entity.WebhookDelivery
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.WebhookDelivery, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.WebhookDelivery); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.WebhookDelivery)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptionById provides a mock function with given fields: ctx, id
+func (_m *WebhookRepository) GetSubscriptionById(ctx context.Context, id int) (*entity.WebhookSubscription, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscriptionById")
+	}
+
+	var r0 *entity.WebhookSubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*entity.WebhookSubscription, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *entity.WebhookSubscription); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.WebhookSubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptionsByUserId provides a mock function with given fields: ctx, userId
+func (_m *WebhookRepository) GetSubscriptionsByUserId(ctx context.Context, userId int) ([]entity.WebhookSubscription, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscriptionsByUserId")
+	}
+
+	var r0 []entity.WebhookSubscription
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]entity.WebhookSubscription, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []entity.WebhookSubscription); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.WebhookSubscription)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PruneDeliveries provides a mock function with given fields: ctx, before
+func (_m *WebhookRepository) PruneDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PruneDeliveries")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDelivery provides a mock function with given fields: ctx, req
+func (_m *WebhookRepository) UpdateDelivery(ctx context.Context, req *entity.WebhookDelivery) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateDelivery")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.WebhookDelivery) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateSubscription provides a mock function with given fields: ctx, req
+func (_m *WebhookRepository) UpdateSubscription(ctx context.Context, req *entity.WebhookSubscription) error {
+	ret := _m.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *entity.WebhookSubscription) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhookRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebhookRepository {
+	mock := &WebhookRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,199 @@
+// Code generated by mockery v2.38.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	dto "github.com/fadilahonespot/chatbot/usecase/dto"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// WebhookUsecase is an autogenerated mock type for the WebhookUsecase type
+type WebhookUsecase struct {
+	mock.Mock
+}
+
+// CreateSubscription provides a mock function with given fields: ctx, userId, req
+func (_m *WebhookUsecase) CreateSubscription(ctx context.Context, userId int, req dto.WebhookSubscriptionRequest) (dto.WebhookSubscriptionResponse, error) {
+	ret := _m.Called(ctx, userId, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSubscription")
+	}
+
+	var r0 dto.WebhookSubscriptionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.WebhookSubscriptionRequest) (dto.WebhookSubscriptionResponse, error)); ok {
+		return rf(ctx, userId, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.WebhookSubscriptionRequest) dto.WebhookSubscriptionResponse); ok {
+		r0 = rf(ctx, userId, req)
+	} else {
+		r0 = ret.Get(0).(dto.WebhookSubscriptionResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.WebhookSubscriptionRequest) error); ok {
+		r1 = rf(ctx, userId, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteSubscription provides a mock function with given fields: ctx, userId, id
+func (_m *WebhookUsecase) DeleteSubscription(ctx context.Context, userId int, id int) error {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSubscription")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetDeliveries provides a mock function with given fields: ctx, userId, filter
+func (_m *WebhookUsecase) GetDeliveries(ctx context.Context, userId int, filter dto.WebhookDeliveryFilter) (dto.WebhookDeliveryListResponse, error) {
+	ret := _m.Called(ctx, userId, filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDeliveries")
+	}
+
+	var r0 dto.WebhookDeliveryListResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.WebhookDeliveryFilter) (dto.WebhookDeliveryListResponse, error)); ok {
+		return rf(ctx, userId, filter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, dto.WebhookDeliveryFilter) dto.WebhookDeliveryListResponse); ok {
+		r0 = rf(ctx, userId, filter)
+	} else {
+		r0 = ret.Get(0).(dto.WebhookDeliveryListResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, dto.WebhookDeliveryFilter) error); ok {
+		r1 = rf(ctx, userId, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDelivery provides a mock function with given fields: ctx, userId, id
+func (_m *WebhookUsecase) GetDelivery(ctx context.Context, userId int, id int) (dto.WebhookDeliveryResponse, error) {
+	ret := _m.Called(ctx, userId, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDelivery")
+	}
+
+	var r0 dto.WebhookDeliveryResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) (dto.WebhookDeliveryResponse, error)); ok {
+		return rf(ctx, userId, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) dto.WebhookDeliveryResponse); ok {
+		r0 = rf(ctx, userId, id)
+	} else {
+		r0 = ret.Get(0).(dto.WebhookDeliveryResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, userId, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscriptions provides a mock function with given fields: ctx, userId
+func (_m *WebhookUsecase) GetSubscriptions(ctx context.Context, userId int) ([]dto.WebhookSubscriptionResponse, error) {
+	ret := _m.Called(ctx, userId)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscriptions")
+	}
+
+	var r0 []dto.WebhookSubscriptionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]dto.WebhookSubscriptionResponse, error)); ok {
+		return rf(ctx, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []dto.WebhookSubscriptionResponse); ok {
+		r0 = rf(ctx, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]dto.WebhookSubscriptionResponse)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Publish provides a mock function with given fields: ctx, userId, event, data
+func (_m *WebhookUsecase) Publish(ctx context.Context, userId int, event string, data interface{}) {
+	_m.Called(ctx, userId, event, data)
+}
+
+// StartDeliveries provides a mock function with given fields: ctx
+func (_m *WebhookUsecase) StartDeliveries(ctx context.Context) {
+	_m.Called(ctx)
+}
+
+// UpdateSubscription provides a mock function with given fields: ctx, userId, id, req
+func (_m *WebhookUsecase) UpdateSubscription(ctx context.Context, userId int, id int, req dto.WebhookSubscriptionRequest) (dto.WebhookSubscriptionResponse, error) {
+	ret := _m.Called(ctx, userId, id, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateSubscription")
+	}
+
+	var r0 dto.WebhookSubscriptionResponse
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.WebhookSubscriptionRequest) (dto.WebhookSubscriptionResponse, error)); ok {
+		return rf(ctx, userId, id, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, dto.WebhookSubscriptionRequest) dto.WebhookSubscriptionResponse); ok {
+		r0 = rf(ctx, userId, id, req)
+	} else {
+		r0 = ret.Get(0).(dto.WebhookSubscriptionResponse)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, dto.WebhookSubscriptionRequest) error); ok {
+		r1 = rf(ctx, userId, id, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewWebhookUsecase creates a new instance of WebhookUsecase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewWebhookUsecase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *WebhookUsecase {
+	mock := &WebhookUsecase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
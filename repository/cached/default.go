@@ -14,6 +14,11 @@ type cache struct {
 }
 
 func NewWrapper() CacheWrapper {
+	return &cache{client: newClient()}
+}
+
+// newClient connects to the redis server in the environment
+func newClient() *redis.Client {
 	fmt.Println("Connect Redis Client.....")
 
 	client := redis.NewClient(&redis.Options{
@@ -27,14 +32,19 @@ func NewWrapper() CacheWrapper {
 		panic(err)
 	}
 
-	return &cache{client: client}
+	return client
 }
 func (w *cache) Set(ctx context.Context, key, value string, duration time.Duration) (err error) {
-	fmt.Printf("[CACHED SET] key: %v, value: %v \n", key, value)
+	fmt.Printf("[CACHED SET] key: %v, length: %v \n", key, len(value))
 	err = w.client.Set(ctx, key, value, duration).Err()
 	return
 }
 
+func (w *cache) SetNX(ctx context.Context, key, value string, duration time.Duration) (ok bool, err error) {
+	ok, err = w.client.SetNX(ctx, key, value, duration).Result()
+	return
+}
+
 func (w *cache) Get(ctx context.Context, key string) (value string, err error) {
 	fmt.Printf("[CACHED GET] key: %v \n", key)
 	err = w.client.Get(ctx, key).Scan(&value)
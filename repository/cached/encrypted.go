@@ -0,0 +1,58 @@
+package cached
+
+import (
+	"context"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/utils/envelope"
+)
+
+type encryptedCache struct {
+	wrapper   CacheWrapper
+	encryptor *envelope.Encryptor
+}
+
+// NewEncryptedWrapper wraps a cache so values are stored encrypted by the
+// encryptor and decrypted when they are read. Values cached before
+// encryption was turned on are read as they are.
+func NewEncryptedWrapper(wrapper CacheWrapper, encryptor *envelope.Encryptor) CacheWrapper {
+	return &encryptedCache{
+		wrapper:   wrapper,
+		encryptor: encryptor,
+	}
+}
+
+func (w *encryptedCache) Set(ctx context.Context, key, value string, duration time.Duration) (err error) {
+	value, _, err = w.encryptor.Encrypt(ctx, value)
+	if err != nil {
+		return
+	}
+
+	err = w.wrapper.Set(ctx, key, value, duration)
+	return
+}
+
+func (w *encryptedCache) SetNX(ctx context.Context, key, value string, duration time.Duration) (ok bool, err error) {
+	value, _, err = w.encryptor.Encrypt(ctx, value)
+	if err != nil {
+		return
+	}
+
+	ok, err = w.wrapper.SetNX(ctx, key, value, duration)
+	return
+}
+
+func (w *encryptedCache) Get(ctx context.Context, key string) (value string, err error) {
+	value, err = w.wrapper.Get(ctx, key)
+	if err != nil {
+		return
+	}
+
+	value, err = w.encryptor.Decrypt(ctx, value)
+	return
+}
+
+func (w *encryptedCache) Delete(ctx context.Context, key string) (err error) {
+	err = w.wrapper.Delete(ctx, key)
+	return
+}
@@ -7,6 +7,9 @@ import (
 
 type CacheWrapper interface {
 	Set(ctx context.Context, key, value string, duration time.Duration) (err error)
+	// SetNX sets the key only when it is not set yet, and reports whether it
+	// was set
+	SetNX(ctx context.Context, key, value string, duration time.Duration) (ok bool, err error)
 	Get(ctx context.Context, key string) (value string, err error)
 	Delete(ctx context.Context, key string) (err error)
 }
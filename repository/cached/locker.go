@@ -0,0 +1,125 @@
+package cached
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrLocked is returned by Lock when another holder has the lock
+var ErrLocked = errors.New("lock is held")
+
+// Locker hands out expiring locks. Every lock taken on a key gets a higher
+// fencing token, so a holder whose lock expired can tell it was taken over
+// before it writes.
+type Locker interface {
+	Lock(ctx context.Context, key string, ttl time.Duration) (token int64, err error)
+	Held(ctx context.Context, key string, token int64) (ok bool, err error)
+	Unlock(ctx context.Context, key string, token int64) (err error)
+}
+
+// unlockScript deletes the lock only when it is still held with the token
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+type redisLocker struct {
+	client *redis.Client
+}
+
+// NewLocker creates a locker shared by every instance through redis. The lock
+// is taken with SET NX and the fencing token counted with INCR.
+func NewLocker() Locker {
+	return &redisLocker{client: newClient()}
+}
+
+func (l *redisLocker) Lock(ctx context.Context, key string, ttl time.Duration) (token int64, err error) {
+	token, err = l.client.Incr(ctx, key+"_fence").Result()
+	if err != nil {
+		return
+	}
+
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return
+	}
+	if !ok {
+		token, err = 0, ErrLocked
+	}
+	return
+}
+
+func (l *redisLocker) Held(ctx context.Context, key string, token int64) (ok bool, err error) {
+	value, err := l.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return
+	}
+	ok = value == strconv.FormatInt(token, 10)
+	return
+}
+
+func (l *redisLocker) Unlock(ctx context.Context, key string, token int64) (err error) {
+	err = unlockScript.Run(ctx, l.client, []string{key}, token).Err()
+	return
+}
+
+type localLock struct {
+	token     int64
+	expiresAt time.Time
+}
+
+type localLocker struct {
+	mu     sync.Mutex
+	locks  map[string]localLock
+	fences map[string]int64
+}
+
+// NewLocalLocker creates a locker that only locks within this process
+func NewLocalLocker() Locker {
+	return &localLocker{
+		locks:  map[string]localLock{},
+		fences: map[string]int64{},
+	}
+}
+
+func (l *localLocker) Lock(ctx context.Context, key string, ttl time.Duration) (token int64, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.fences[key]++
+	if lock, ok := l.locks[key]; ok && time.Now().Before(lock.expiresAt) {
+		return 0, ErrLocked
+	}
+
+	token = l.fences[key]
+	l.locks[key] = localLock{token: token, expiresAt: time.Now().Add(ttl)}
+	return
+}
+
+func (l *localLocker) Held(ctx context.Context, key string, token int64) (ok bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	lock, found := l.locks[key]
+	ok = found && lock.token == token && time.Now().Before(lock.expiresAt)
+	return
+}
+
+func (l *localLocker) Unlock(ctx context.Context, key string, token int64) (err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if lock, ok := l.locks[key]; ok && lock.token == token {
+		delete(l.locks, key)
+	}
+	return
+}
@@ -1,5 +1,7 @@
 package cached
 
+import "time"
+
 type StatusChat string
 
 type GenerateText struct {
@@ -12,3 +14,20 @@ type EmailVerifyCounter struct {
 	Data    string
 	Counter int
 }
+
+// ChatJob is a question answered in the background. Request and Response
+// are the question and its answer as json.
+type ChatJob struct {
+	ID             string
+	UserID         int
+	Status         string
+	Request        string
+	Response       string
+	Error          string
+	ErrorCode      int
+	CallbackUrl    string
+	CallbackStatus int
+	CallbackError  string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
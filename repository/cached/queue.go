@@ -0,0 +1,42 @@
+package cached
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Queue is a first in, first out list of values shared by every instance
+type Queue interface {
+	Push(ctx context.Context, queue, value string) (err error)
+	// Pop waits up to timeout for a value, and returns an empty value when
+	// none came
+	Pop(ctx context.Context, queue string, timeout time.Duration) (value string, err error)
+}
+
+type redisQueue struct {
+	client *redis.Client
+}
+
+// NewQueue creates a queue kept in a redis list
+func NewQueue() Queue {
+	return &redisQueue{client: newClient()}
+}
+
+func (q *redisQueue) Push(ctx context.Context, queue, value string) (err error) {
+	err = q.client.LPush(ctx, queue, value).Err()
+	return
+}
+
+func (q *redisQueue) Pop(ctx context.Context, queue string, timeout time.Duration) (value string, err error) {
+	values, err := q.client.BRPop(ctx, timeout, queue).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return
+	}
+	value = values[1]
+	return
+}
@@ -0,0 +1,75 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SendTimeout is how long the API of a service is waited on
+const SendTimeout = 10 * time.Second
+
+// postJSON posts the body as json to the API and decodes the response into
+// result, returning the status code of the response
+func postJSON(ctx context.Context, client *http.Client, endpoint, token string, body, result interface{}) (statusCode int, err error) {
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		// the url of some services holds the token, so only the cause is kept
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	statusCode = resp.StatusCode
+	json.NewDecoder(resp.Body).Decode(result)
+	return
+}
+
+// signature returns the hex HMAC-SHA256 of the message under the secret
+func signature(secret string, message ...[]byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, part := range message {
+		mac.Write(part)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// equalSecret compares secrets in constant time, and never matches an empty
+// secret, so a channel that is not configured verifies nothing
+func equalSecret(secret, given string) bool {
+	return secret != "" && hmac.Equal([]byte(secret), []byte(given))
+}
+
+// apiUrl returns the url in the env, or the default without a trailing slash
+func apiUrl(env, defaultUrl string) string {
+	if env == "" {
+		env = defaultUrl
+	}
+	return strings.TrimSuffix(env, "/")
+}
+
+func apiError(name string, statusCode int, description string) error {
+	return fmt.Errorf("%v error: %v %v", name, statusCode, description)
+}
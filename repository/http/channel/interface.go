@@ -0,0 +1,56 @@
+package channel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// ErrNotVerified is returned for webhook requests that were not sent by the
+// messaging service, or when the channel is not configured
+var ErrNotVerified = errors.New("webhook request not verified")
+
+// Channel is a messaging service the bot answers users on. The service posts
+// the messages sent to the bot to a webhook, and the answers are sent back
+// through its API.
+type Channel interface {
+	// Name is the name the channel is routed and stored under
+	Name() string
+	// Verify checks the webhook request was sent by the service. Handshake
+	// requests, made when the webhook is set up, are answered with the
+	// challenge returned, and carry no messages.
+	Verify(req Request) (challenge string, err error)
+	// Parse returns the messages users sent to the bot in a verified
+	// webhook request. A message without text is one the bot cannot read,
+	// like a photo or a sticker.
+	Parse(req Request) (messages []Message, err error)
+	// Send sends a text message to the user. Text over the limits of the
+	// channel is rejected by the service, so it must be split before.
+	Send(ctx context.Context, to string, text string) (err error)
+	Limits() Limits
+}
+
+// Request is a webhook request with its body already read
+type Request struct {
+	Method string
+	Header http.Header
+	Query  url.Values
+	Body   []byte
+}
+
+// Message is a message a user sent to the bot. Id is the id the service gave
+// the message, the same when the service posts it again. ExternalId
+// identifies the user on the channel and is where the answer is sent to.
+type Message struct {
+	Id         string
+	ExternalId string
+	Username   string
+	Text       string
+}
+
+// Limits are the limits the service puts on the messages of the bot
+type Limits struct {
+	// MessageLength is the most characters in one message
+	MessageLength int
+}
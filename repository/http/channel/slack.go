@@ -0,0 +1,163 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+const (
+	// SlackApiUrl is the Web API used when SLACK_API_URL is not set
+	SlackApiUrl = "https://slack.com/api"
+
+	// SlackSignatureHeader carries "v0=" followed by the hex HMAC-SHA256 of
+	// "v0:<SlackTimestampHeader>:<body>" under the signing secret
+	SlackSignatureHeader = "X-Slack-Signature"
+	SlackTimestampHeader = "X-Slack-Request-Timestamp"
+
+	// SlackMaxAge is how old a signed request can be, so recorded requests
+	// cannot be replayed
+	SlackMaxAge = 5 * time.Minute
+
+	// SlackMessageLength is the length Slack advises to keep messages under.
+	// Longer text is still taken, but cut at 40000 characters.
+	SlackMessageLength = 4000
+
+	// slackDirectMessage is the only type of channel the bot answers in
+	slackDirectMessage = "im"
+)
+
+var (
+	// Slack escapes these characters in the text of messages, and expects
+	// them escaped in the text sent
+	slackUnescaper = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
+	slackEscaper   = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+)
+
+type slackChannel struct {
+	client        *http.Client
+	apiUrl        string
+	token         string
+	signingSecret string
+}
+
+// NewSlack creates the channel of the Slack app with the SLACK_BOT_TOKEN,
+// whose Events API requests are signed with the SLACK_SIGNING_SECRET. The
+// Web API is read from SLACK_API_URL.
+func NewSlack() Channel {
+	return &slackChannel{
+		client:        &http.Client{Timeout: SendTimeout},
+		apiUrl:        apiUrl(os.Getenv("SLACK_API_URL"), SlackApiUrl),
+		token:         os.Getenv("SLACK_BOT_TOKEN"),
+		signingSecret: os.Getenv("SLACK_SIGNING_SECRET"),
+	}
+}
+
+// slackPayload is a request of the Events API: the url verification made
+// when the request url is set, or an event
+type slackPayload struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	EventId   string `json:"event_id"`
+	Event     struct {
+		Type        string `json:"type"`
+		Subtype     string `json:"subtype"`
+		ChannelType string `json:"channel_type"`
+		User        string `json:"user"`
+		BotId       string `json:"bot_id"`
+		Text        string `json:"text"`
+	} `json:"event"`
+}
+
+func (c *slackChannel) Name() string {
+	return constrans.ChannelSlack
+}
+
+// Verify checks the signature and age of the request, and answers the url
+// verification
+func (c *slackChannel) Verify(req Request) (challenge string, err error) {
+	timestamp := req.Header.Get(SlackTimestampHeader)
+	signedAt, errRes := strconv.ParseInt(timestamp, 10, 64)
+	age := time.Since(time.Unix(signedAt, 0))
+	if req.Method != http.MethodPost || errRes != nil || age > SlackMaxAge || age < -SlackMaxAge {
+		err = ErrNotVerified
+		return
+	}
+
+	expected := "v0=" + signature(c.signingSecret, []byte("v0:"+timestamp+":"), req.Body)
+	if c.signingSecret == "" || !equalSecret(expected, req.Header.Get(SlackSignatureHeader)) {
+		err = ErrNotVerified
+		return
+	}
+
+	var payload slackPayload
+	json.Unmarshal(req.Body, &payload)
+	if payload.Type == "url_verification" {
+		challenge = payload.Challenge
+	}
+	return
+}
+
+// Parse returns the messages sent to the bot in direct messages. Messages of
+// bots, the bot itself included, and edits are left out, and shared files
+// are returned without text.
+func (c *slackChannel) Parse(req Request) (messages []Message, err error) {
+	var payload slackPayload
+	err = json.Unmarshal(req.Body, &payload)
+	if err != nil || payload.Type != "event_callback" {
+		return
+	}
+
+	event := payload.Event
+	if event.Type != "message" || event.ChannelType != slackDirectMessage || event.BotId != "" || event.User == "" {
+		return
+	}
+
+	msg := Message{Id: payload.EventId, ExternalId: event.User}
+	switch event.Subtype {
+	case "":
+		msg.Text = slackUnescaper.Replace(event.Text)
+	case "file_share":
+	default:
+		return
+	}
+	messages = append(messages, msg)
+	return
+}
+
+// Send posts the message to the direct messages of the app with the user
+func (c *slackChannel) Send(ctx context.Context, to string, text string) (err error) {
+	logger.Info(ctx, "Slack chat.postMessage REQUEST", to, len(text))
+
+	var result struct {
+		Ok    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	statusCode, err := postJSON(ctx, c.client, c.apiUrl+"/chat.postMessage", c.token, map[string]string{
+		"channel": to,
+		"text":    slackEscaper.Replace(text),
+	}, &result)
+	if err != nil {
+		err = fmt.Errorf("slack error: %s", err.Error())
+		return
+	}
+	if !result.Ok {
+		err = apiError(constrans.ChannelSlack, statusCode, result.Error)
+		return
+	}
+
+	logger.Info(ctx, "Slack chat.postMessage RESPONSE", to)
+	return
+}
+
+func (c *slackChannel) Limits() Limits {
+	return Limits{MessageLength: SlackMessageLength}
+}
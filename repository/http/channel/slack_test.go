@@ -0,0 +1,157 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+// signedSlackRequest returns the body as Slack signs it at the time
+func signedSlackRequest(secret string, signedAt time.Time, body string) Request {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	header := http.Header{}
+	header.Set(SlackTimestampHeader, timestamp)
+	header.Set(SlackSignatureHeader, "v0="+signature(secret, []byte("v0:"+timestamp+":"), []byte(body)))
+	return Request{Method: http.MethodPost, Header: header, Body: []byte(body)}
+}
+
+func TestSlackChannel_Verify(t *testing.T) {
+	t.Setenv("SLACK_SIGNING_SECRET", "rahasia")
+	event := `{"type":"event_callback","event":{"type":"message","channel_type":"im","user":"U1","text":"halo"}}`
+
+	tests := []struct {
+		name          string
+		req           Request
+		wantChallenge string
+		wantErr       bool
+	}{
+		{
+			name:          "url verification",
+			req:           signedSlackRequest("rahasia", time.Now(), `{"type":"url_verification","challenge":"3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P"}`),
+			wantChallenge: "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P",
+		},
+		{
+			name: "signed event",
+			req:  signedSlackRequest("rahasia", time.Now(), event),
+		},
+		{
+			name:    "signed with another secret",
+			req:     signedSlackRequest("salah", time.Now(), event),
+			wantErr: true,
+		},
+		{
+			name:    "replayed",
+			req:     signedSlackRequest("rahasia", time.Now().Add(-10*time.Minute), event),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge, err := NewSlack().Verify(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("slackChannel.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if challenge != tt.wantChallenge {
+				t.Errorf("slackChannel.Verify() challenge = %v, want %v", challenge, tt.wantChallenge)
+			}
+		})
+	}
+}
+
+func TestSlackChannel_Parse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Message
+	}{
+		{
+			name: "direct message",
+			body: `{"type":"event_callback","event_id":"Ev1","event":{"type":"message","channel_type":"im","channel":"D1","user":"U1","text":"apa beda &lt;div&gt; &amp; &lt;span&gt;?"}}`,
+			want: []Message{{Id: "Ev1", ExternalId: "U1", Text: "apa beda <div> & <span>?"}},
+		},
+		{
+			name: "shared file",
+			body: `{"type":"event_callback","event_id":"Ev2","event":{"type":"message","subtype":"file_share","channel_type":"im","user":"U1","text":""}}`,
+			want: []Message{{Id: "Ev2", ExternalId: "U1"}},
+		},
+		{
+			name: "answer of the bot",
+			body: `{"type":"event_callback","event":{"type":"message","channel_type":"im","user":"U2","bot_id":"B1","text":"Text editor dan git."}}`,
+		},
+		{
+			name: "edited message",
+			body: `{"type":"event_callback","event":{"type":"message","subtype":"message_changed","channel_type":"im","text":""}}`,
+		},
+		{
+			name: "channel message",
+			body: `{"type":"event_callback","event":{"type":"message","channel_type":"channel","user":"U1","text":"halo"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSlack().Parse(Request{Body: []byte(tt.body)})
+			if err != nil {
+				t.Fatalf("slackChannel.Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("slackChannel.Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlackChannel_Send(t *testing.T) {
+	ctx := context.Background()
+	logger.NewLogger()
+
+	tests := []struct {
+		name    string
+		to      string
+		wantErr bool
+	}{
+		{
+			name: "sent",
+			to:   "U1",
+		},
+		{
+			name:    "user not found",
+			to:      "U9",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			var path, authorization string
+			// fake Web API that only knows user U1, answering errors with 200 like Slack
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				authorization = r.Header.Get("Authorization")
+				json.NewDecoder(r.Body).Decode(&got)
+				if got["channel"] != "U1" {
+					w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+					return
+				}
+				w.Write([]byte(`{"ok":true,"channel":"D1","ts":"1503435956.000247"}`))
+			}))
+			defer server.Close()
+			t.Setenv("SLACK_API_URL", server.URL)
+			t.Setenv("SLACK_BOT_TOKEN", "xoxb-rahasia")
+
+			err := NewSlack().Send(ctx, tt.to, "Pakai <div> & <span>.")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("slackChannel.Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if path != "/chat.postMessage" || authorization != "Bearer xoxb-rahasia" || got["text"] != "Pakai &lt;div&gt; &amp; &lt;span&gt;." {
+				t.Errorf("slackChannel.Send() sent %v to %v", got, path)
+			}
+		})
+	}
+}
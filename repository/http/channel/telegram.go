@@ -0,0 +1,122 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+const (
+	// TelegramApiUrl is the Bot API used when TELEGRAM_API_URL is not set
+	TelegramApiUrl = "https://api.telegram.org"
+
+	// TelegramSecretHeader carries the secret token the webhook was set with
+	TelegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	TelegramMessageLength = 4096
+
+	// telegramPrivateChat is the only type of chat the bot answers in, so
+	// the members of a group cannot ask questions as the user who linked it
+	telegramPrivateChat = "private"
+)
+
+type telegramChannel struct {
+	client *http.Client
+	apiUrl string
+	token  string
+	secret string
+}
+
+// NewTelegram creates the channel of the Telegram bot with the
+// TELEGRAM_BOT_TOKEN, whose webhook was set with the TELEGRAM_WEBHOOK_SECRET
+// as its secret token. The Bot API is read from TELEGRAM_API_URL, so a local
+// Bot API server can be used instead of Telegram.
+func NewTelegram() Channel {
+	return &telegramChannel{
+		client: &http.Client{Timeout: SendTimeout},
+		apiUrl: apiUrl(os.Getenv("TELEGRAM_API_URL"), TelegramApiUrl),
+		token:  os.Getenv("TELEGRAM_BOT_TOKEN"),
+		secret: os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
+	}
+}
+
+// telegramUpdate is an update posted to the webhook. Only messages are
+// handled, so the other kinds of update are left out.
+type telegramUpdate struct {
+	UpdateId int64 `json:"update_id"`
+	Message  *struct {
+		From *struct {
+			Username string `json:"username"`
+		} `json:"from"`
+		Chat struct {
+			Id   int64  `json:"id"`
+			Type string `json:"type"`
+		} `json:"chat"`
+		Text string `json:"text"`
+	} `json:"message"`
+}
+
+func (c *telegramChannel) Name() string {
+	return constrans.ChannelTelegram
+}
+
+func (c *telegramChannel) Verify(req Request) (challenge string, err error) {
+	if req.Method != http.MethodPost || !equalSecret(c.secret, req.Header.Get(TelegramSecretHeader)) {
+		err = ErrNotVerified
+	}
+	return
+}
+
+func (c *telegramChannel) Parse(req Request) (messages []Message, err error) {
+	var update telegramUpdate
+	err = json.Unmarshal(req.Body, &update)
+	if err != nil || update.Message == nil || update.Message.Chat.Type != telegramPrivateChat {
+		return
+	}
+
+	msg := Message{
+		Id:         strconv.FormatInt(update.UpdateId, 10),
+		ExternalId: strconv.FormatInt(update.Message.Chat.Id, 10),
+		Text:       update.Message.Text,
+	}
+	if update.Message.From != nil {
+		msg.Username = update.Message.From.Username
+	}
+	messages = append(messages, msg)
+	return
+}
+
+func (c *telegramChannel) Send(ctx context.Context, to string, text string) (err error) {
+	logger.Info(ctx, "Telegram sendMessage REQUEST", to, len(text))
+
+	var result struct {
+		Ok          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	endpoint := fmt.Sprintf("%v/bot%v/sendMessage", c.apiUrl, c.token)
+	statusCode, err := postJSON(ctx, c.client, endpoint, "", map[string]string{
+		"chat_id": to,
+		"text":    text,
+	}, &result)
+	if err != nil {
+		err = fmt.Errorf("telegram error: %s", err.Error())
+		return
+	}
+	if !result.Ok {
+		err = apiError(constrans.ChannelTelegram, statusCode, result.Description)
+		return
+	}
+
+	logger.Info(ctx, "Telegram sendMessage RESPONSE", to)
+	return
+}
+
+func (c *telegramChannel) Limits() Limits {
+	return Limits{MessageLength: TelegramMessageLength}
+}
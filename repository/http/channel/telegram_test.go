@@ -0,0 +1,115 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+func TestTelegramChannel_Verify(t *testing.T) {
+	t.Setenv("TELEGRAM_WEBHOOK_SECRET", "rahasia")
+	c := NewTelegram()
+
+	header := http.Header{}
+	header.Set(TelegramSecretHeader, "rahasia")
+	if _, err := c.Verify(Request{Method: http.MethodPost, Header: header}); err != nil {
+		t.Errorf("telegramChannel.Verify() error = %v", err)
+	}
+
+	header.Set(TelegramSecretHeader, "salah")
+	if _, err := c.Verify(Request{Method: http.MethodPost, Header: header}); err != ErrNotVerified {
+		t.Errorf("telegramChannel.Verify() with the wrong secret error = %v, want %v", err, ErrNotVerified)
+	}
+}
+
+func TestTelegramChannel_Parse(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []Message
+	}{
+		{
+			name: "text message",
+			body: `{"update_id":1,"message":{"message_id":2,"from":{"id":42,"username":"budi"},"chat":{"id":42,"type":"private"},"text":"halo"}}`,
+			want: []Message{{Id: "1", ExternalId: "42", Username: "budi", Text: "halo"}},
+		},
+		{
+			name: "photo",
+			body: `{"update_id":1,"message":{"message_id":2,"chat":{"id":42,"type":"private"},"photo":[{"file_id":"a"}]}}`,
+			want: []Message{{Id: "1", ExternalId: "42"}},
+		},
+		{
+			name: "group message",
+			body: `{"update_id":1,"message":{"message_id":2,"chat":{"id":-5,"type":"group"},"text":"halo"}}`,
+		},
+		{
+			name: "not a message",
+			body: `{"update_id":1,"edited_message":{"message_id":2,"chat":{"id":42,"type":"private"},"text":"halo"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTelegram().Parse(Request{Body: []byte(tt.body)})
+			if err != nil {
+				t.Fatalf("telegramChannel.Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("telegramChannel.Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTelegramChannel_Send(t *testing.T) {
+	ctx := context.Background()
+	logger.NewLogger()
+
+	tests := []struct {
+		name    string
+		to      string
+		wantErr bool
+	}{
+		{
+			name: "sent",
+			to:   "42",
+		},
+		{
+			name:    "chat not found",
+			to:      "7",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got map[string]string
+			var path string
+			// fake Bot API that only knows chat 42
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				json.NewDecoder(r.Body).Decode(&got)
+				if got["chat_id"] != "42" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+					return
+				}
+				w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+			}))
+			defer server.Close()
+			t.Setenv("TELEGRAM_API_URL", server.URL)
+			t.Setenv("TELEGRAM_BOT_TOKEN", "123:rahasia")
+
+			err := NewTelegram().Send(ctx, tt.to, "Text editor dan git.")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("telegramChannel.Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if path != "/bot123:rahasia/sendMessage" || got["text"] != "Text editor dan git." {
+				t.Errorf("telegramChannel.Send() sent %v to %v", got, path)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+const (
+	// WhatsAppApiUrl is the Graph API used when WHATSAPP_API_URL is not set
+	WhatsAppApiUrl = "https://graph.facebook.com/v18.0"
+
+	// WhatsAppSignatureHeader carries "sha256=" followed by the hex
+	// HMAC-SHA256 of the body under the app secret
+	WhatsAppSignatureHeader = "X-Hub-Signature-256"
+
+	WhatsAppMessageLength = 4096
+)
+
+type whatsAppChannel struct {
+	client        *http.Client
+	apiUrl        string
+	token         string
+	phoneNumberId string
+	appSecret     string
+	verifyToken   string
+}
+
+// NewWhatsApp creates the channel of the WhatsApp Business number with the
+// WHATSAPP_PHONE_NUMBER_ID, sending with the WHATSAPP_TOKEN. Webhook
+// requests are signed with the WHATSAPP_APP_SECRET, and the webhook is set up
+// with the WHATSAPP_VERIFY_TOKEN. The Graph API is read from
+// WHATSAPP_API_URL.
+func NewWhatsApp() Channel {
+	return &whatsAppChannel{
+		client:        &http.Client{Timeout: SendTimeout},
+		apiUrl:        apiUrl(os.Getenv("WHATSAPP_API_URL"), WhatsAppApiUrl),
+		token:         os.Getenv("WHATSAPP_TOKEN"),
+		phoneNumberId: os.Getenv("WHATSAPP_PHONE_NUMBER_ID"),
+		appSecret:     os.Getenv("WHATSAPP_APP_SECRET"),
+		verifyToken:   os.Getenv("WHATSAPP_VERIFY_TOKEN"),
+	}
+}
+
+// whatsAppPayload is a notification of the Cloud API webhook. Status updates
+// of the sent messages come without messages and are left out.
+type whatsAppPayload struct {
+	Entry []struct {
+		Changes []struct {
+			Field string `json:"field"`
+			Value struct {
+				Contacts []struct {
+					WaId    string `json:"wa_id"`
+					Profile struct {
+						Name string `json:"name"`
+					} `json:"profile"`
+				} `json:"contacts"`
+				Messages []struct {
+					Id   string `json:"id"`
+					From string `json:"from"`
+					Type string `json:"type"`
+					Text struct {
+						Body string `json:"body"`
+					} `json:"text"`
+				} `json:"messages"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+func (c *whatsAppChannel) Name() string {
+	return constrans.ChannelWhatsApp
+}
+
+// Verify answers the verification request made when the webhook is set up,
+// and checks the signature of the notifications
+func (c *whatsAppChannel) Verify(req Request) (challenge string, err error) {
+	if req.Method == http.MethodGet {
+		if req.Query.Get("hub.mode") != "subscribe" || !equalSecret(c.verifyToken, req.Query.Get("hub.verify_token")) {
+			err = ErrNotVerified
+			return
+		}
+		challenge = req.Query.Get("hub.challenge")
+		return
+	}
+
+	if c.appSecret == "" || !equalSecret("sha256="+signature(c.appSecret, req.Body), req.Header.Get(WhatsAppSignatureHeader)) {
+		err = ErrNotVerified
+	}
+	return
+}
+
+func (c *whatsAppChannel) Parse(req Request) (messages []Message, err error) {
+	var payload whatsAppPayload
+	err = json.Unmarshal(req.Body, &payload)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+
+			names := map[string]string{}
+			for _, contact := range change.Value.Contacts {
+				names[contact.WaId] = contact.Profile.Name
+			}
+			for _, msg := range change.Value.Messages {
+				message := Message{
+					Id:         msg.Id,
+					ExternalId: msg.From,
+					Username:   names[msg.From],
+				}
+				if msg.Type == "text" {
+					message.Text = msg.Text.Body
+				}
+				messages = append(messages, message)
+			}
+		}
+	}
+	return
+}
+
+func (c *whatsAppChannel) Send(ctx context.Context, to string, text string) (err error) {
+	logger.Info(ctx, "WhatsApp messages REQUEST", to, len(text))
+
+	var result struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	endpoint := fmt.Sprintf("%v/%v/messages", c.apiUrl, c.phoneNumberId)
+	statusCode, err := postJSON(ctx, c.client, endpoint, c.token, map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "text",
+		"text":              map[string]string{"body": text},
+	}, &result)
+	if err != nil {
+		err = fmt.Errorf("whatsapp error: %s", err.Error())
+		return
+	}
+	if statusCode < 200 || statusCode > 299 || result.Error != nil {
+		description := ""
+		if result.Error != nil {
+			description = result.Error.Message
+		}
+		err = apiError(constrans.ChannelWhatsApp, statusCode, description)
+		return
+	}
+
+	logger.Info(ctx, "WhatsApp messages RESPONSE", to)
+	return
+}
+
+func (c *whatsAppChannel) Limits() Limits {
+	return Limits{MessageLength: WhatsAppMessageLength}
+}
@@ -0,0 +1,136 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+func TestWhatsAppChannel_Verify(t *testing.T) {
+	t.Setenv("WHATSAPP_VERIFY_TOKEN", "token-verifikasi")
+	t.Setenv("WHATSAPP_APP_SECRET", "rahasia")
+	body := []byte(`{"object":"whatsapp_business_account","entry":[]}`)
+
+	tests := []struct {
+		name          string
+		req           Request
+		wantChallenge string
+		wantErr       bool
+	}{
+		{
+			name:          "webhook set up",
+			req:           Request{Method: http.MethodGet, Query: url.Values{"hub.mode": {"subscribe"}, "hub.verify_token": {"token-verifikasi"}, "hub.challenge": {"1158201444"}}},
+			wantChallenge: "1158201444",
+		},
+		{
+			name:    "wrong verify token",
+			req:     Request{Method: http.MethodGet, Query: url.Values{"hub.mode": {"subscribe"}, "hub.verify_token": {"salah"}, "hub.challenge": {"1158201444"}}},
+			wantErr: true,
+		},
+		{
+			name: "signed notification",
+			req:  Request{Method: http.MethodPost, Header: http.Header{WhatsAppSignatureHeader: {"sha256=" + signature("rahasia", body)}}, Body: body},
+		},
+		{
+			name:    "notification signed with another secret",
+			req:     Request{Method: http.MethodPost, Header: http.Header{WhatsAppSignatureHeader: {"sha256=" + signature("salah", body)}}, Body: body},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			challenge, err := NewWhatsApp().Verify(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("whatsAppChannel.Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if challenge != tt.wantChallenge {
+				t.Errorf("whatsAppChannel.Verify() challenge = %v, want %v", challenge, tt.wantChallenge)
+			}
+		})
+	}
+}
+
+func TestWhatsAppChannel_Parse(t *testing.T) {
+	body := `{"object":"whatsapp_business_account","entry":[{"id":"1","changes":[{"field":"messages","value":{
+		"messaging_product":"whatsapp",
+		"contacts":[{"profile":{"name":"Budi"},"wa_id":"6281234567890"}],
+		"messages":[
+			{"from":"6281234567890","id":"wamid.1","type":"text","text":{"body":"halo"}},
+			{"from":"6281234567890","id":"wamid.2","type":"image","image":{"id":"a"}}
+		]}}]},{"id":"1","changes":[{"field":"messages","value":{"statuses":[{"id":"wamid.0","status":"read"}]}}]}]}`
+
+	got, err := NewWhatsApp().Parse(Request{Body: []byte(body)})
+	if err != nil {
+		t.Fatalf("whatsAppChannel.Parse() error = %v", err)
+	}
+	want := []Message{
+		{Id: "wamid.1", ExternalId: "6281234567890", Username: "Budi", Text: "halo"},
+		{Id: "wamid.2", ExternalId: "6281234567890", Username: "Budi"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("whatsAppChannel.Parse() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWhatsAppChannel_Send(t *testing.T) {
+	ctx := context.Background()
+	logger.NewLogger()
+
+	tests := []struct {
+		name    string
+		to      string
+		wantErr bool
+	}{
+		{
+			name: "sent",
+			to:   "6281234567890",
+		},
+		{
+			name:    "not a whatsapp user",
+			to:      "62800",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				To   string `json:"to"`
+				Type string `json:"type"`
+				Text struct {
+					Body string `json:"body"`
+				} `json:"text"`
+			}
+			var path, authorization string
+			// fake Graph API that only knows one number
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				authorization = r.Header.Get("Authorization")
+				json.NewDecoder(r.Body).Decode(&got)
+				if got.To != "6281234567890" {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"error":{"message":"(#131026) Message undeliverable","code":131026}}`))
+					return
+				}
+				w.Write([]byte(`{"messaging_product":"whatsapp","messages":[{"id":"wamid.3"}]}`))
+			}))
+			defer server.Close()
+			t.Setenv("WHATSAPP_API_URL", server.URL)
+			t.Setenv("WHATSAPP_TOKEN", "rahasia")
+			t.Setenv("WHATSAPP_PHONE_NUMBER_ID", "106540352242922")
+
+			err := NewWhatsApp().Send(ctx, tt.to, "Text editor dan git.")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("whatsAppChannel.Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if path != "/106540352242922/messages" || authorization != "Bearer rahasia" || got.Type != "text" || got.Text.Body != "Text editor dan git." {
+				t.Errorf("whatsAppChannel.Send() sent %+v to %v", got, path)
+			}
+		})
+	}
+}
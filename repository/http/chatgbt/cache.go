@@ -0,0 +1,299 @@
+package chatgbt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/http/embedding"
+	"github.com/fadilahonespot/chatbot/repository/vectorstore"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/sashabaranov/go-openai"
+	"github.com/spf13/cast"
+)
+
+const (
+	// CacheModeExact serves a cached answer to the same request
+	CacheModeExact = "exact"
+	// CacheModeSemantic serves a cached answer to a similar standalone question
+	CacheModeSemantic = "semantic"
+
+	// CacheHeader is set to CacheHit on answers served from the cache
+	CacheHeader = "X-Cache"
+	CacheHit    = "hit"
+
+	// KeyResponseCache prefixes the keys of the cached answers
+	KeyResponseCache = "ResponseCache"
+
+	DefaultCacheTTL       = 24 * time.Hour
+	DefaultCacheThreshold = 0.95
+	// DefaultCacheEntries is the number of questions kept for each scope in
+	// the semantic mode, the latest first
+	DefaultCacheEntries = 50
+)
+
+// CacheConfig configures the response cache. The cache is off when Mode is
+// empty.
+type CacheConfig struct {
+	Mode       string
+	TTL        time.Duration
+	Threshold  float32
+	MaxEntries int
+}
+
+type cachingWrapper struct {
+	wrapper           OpenAIWrapper
+	cacheWrapper      cached.CacheWrapper
+	embeddingProvider embedding.EmbeddingProvider
+	config            CacheConfig
+}
+
+// cacheEntry is a question answered in the semantic mode. The embedding is
+// packed as little endian float32 so it is stored compact.
+type cacheEntry struct {
+	Embedding []byte                        `json:"embedding"`
+	Response  openai.ChatCompletionResponse `json:"response"`
+	ExpiresAt time.Time                     `json:"expiresAt"`
+}
+
+// NewCachingWrapper wraps an OpenAI wrapper so answers are served from the
+// cache. Only final answers, without tool calls, are cached.
+//
+// In the exact mode the key is a hash of the whole request. In the semantic
+// mode only standalone questions, a text question after the system messages,
+// are cached. The question is embedded and the answer of the most similar
+// earlier question in the same scope is served when the similarity reaches the
+// threshold. The scope is the request without the question, so answers are
+// only shared between requests with the same persona prompt, model, options
+// and sources.
+func NewCachingWrapper(wrapper OpenAIWrapper, cacheWrapper cached.CacheWrapper, embeddingProvider embedding.EmbeddingProvider, config CacheConfig) OpenAIWrapper {
+	if config.Mode == "" {
+		return wrapper
+	}
+	if config.TTL <= 0 {
+		config.TTL = DefaultCacheTTL
+	}
+	if config.Threshold <= 0 {
+		config.Threshold = DefaultCacheThreshold
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultCacheEntries
+	}
+
+	return &cachingWrapper{
+		wrapper:           wrapper,
+		cacheWrapper:      cacheWrapper,
+		embeddingProvider: embeddingProvider,
+		config:            config,
+	}
+}
+
+// NewCachingWrapperFromEnv wraps an OpenAI wrapper with the cache configured
+// in RESPONSE_CACHE_MODE, RESPONSE_CACHE_TTL and RESPONSE_CACHE_THRESHOLD. The
+// wrapper is returned as it is when the mode is empty.
+func NewCachingWrapperFromEnv(wrapper OpenAIWrapper, cacheWrapper cached.CacheWrapper, embeddingProvider embedding.EmbeddingProvider) (resp OpenAIWrapper, err error) {
+	config := CacheConfig{Mode: os.Getenv("RESPONSE_CACHE_MODE")}
+	if config.Mode != "" && config.Mode != CacheModeExact && config.Mode != CacheModeSemantic {
+		err = fmt.Errorf("unknown response cache mode %q", config.Mode)
+		return
+	}
+
+	if value := os.Getenv("RESPONSE_CACHE_TTL"); value != "" {
+		config.TTL, err = time.ParseDuration(value)
+		if err != nil {
+			err = fmt.Errorf("response cache ttl not valid: %w", err)
+			return
+		}
+	}
+
+	if value := os.Getenv("RESPONSE_CACHE_THRESHOLD"); value != "" {
+		config.Threshold = cast.ToFloat32(value)
+		if config.Threshold <= 0 || config.Threshold > 1 {
+			err = fmt.Errorf("response cache threshold must be between 0 and 1, got %q", value)
+			return
+		}
+	}
+
+	resp = NewCachingWrapper(wrapper, cacheWrapper, embeddingProvider, config)
+	return
+}
+
+// IsCached reports whether the answer was served from the cache
+func IsCached(resp openai.ChatCompletionResponse) bool {
+	return resp.Header().Get(CacheHeader) == CacheHit
+}
+
+// GenerateText serves the answer from the cache, or generates and caches it.
+// A failing cache is skipped.
+func (w *cachingWrapper) GenerateText(ctx context.Context, req openai.ChatCompletionRequest) (resp openai.ChatCompletionResponse, err error) {
+	if w.config.Mode == CacheModeExact {
+		return w.generateExact(ctx, req)
+	}
+	return w.generateSemantic(ctx, req)
+}
+
+// generateExact caches the answer under a hash of the whole request
+func (w *cachingWrapper) generateExact(ctx context.Context, req openai.ChatCompletionRequest) (resp openai.ChatCompletionResponse, err error) {
+	key := fmt.Sprintf("%v_%v_%v", KeyResponseCache, CacheModeExact, hashRequest(req))
+	value, _ := w.cacheWrapper.Get(ctx, key)
+	if value != "" && json.Unmarshal([]byte(value), &resp) == nil {
+		logger.Info(ctx, "GenerateText CACHE HIT", key)
+		return cachedResponse(resp), nil
+	}
+
+	resp, err = w.wrapper.GenerateText(ctx, req)
+	if err != nil || !cacheable(resp) {
+		return
+	}
+
+	data, _ := json.Marshal(resp)
+	errRes := w.cacheWrapper.Set(ctx, key, string(data), w.config.TTL)
+	if errRes != nil {
+		logger.Error(ctx, "error caching answer", errRes.Error())
+	}
+	return
+}
+
+// generateSemantic serves the answer of the most similar standalone question
+// in the scope of the request
+func (w *cachingWrapper) generateSemantic(ctx context.Context, req openai.ChatCompletionRequest) (resp openai.ChatCompletionResponse, err error) {
+	question, ok := standaloneQuestion(req)
+	if !ok {
+		return w.wrapper.GenerateText(ctx, req)
+	}
+
+	embeddings, errRes := w.embeddingProvider.Embed(ctx, []string{question})
+	if errRes != nil || len(embeddings) == 0 {
+		logger.Error(ctx, "error embedding question for the cache", fmt.Sprint(errRes))
+		return w.wrapper.GenerateText(ctx, req)
+	}
+	vector := embeddings[0]
+
+	scope := req
+	scope.Messages = req.Messages[:len(req.Messages)-1]
+	key := fmt.Sprintf("%v_%v_%v_%v", KeyResponseCache, CacheModeSemantic, w.embeddingProvider.Model(), hashRequest(scope))
+
+	now := time.Now()
+	entries := w.getEntries(ctx, key, now)
+	best, bestScore := -1, float32(0)
+	for i := 0; i < len(entries); i++ {
+		score := vectorstore.CosineSimilarity(vector, unpackEmbedding(entries[i].Embedding))
+		if score >= w.config.Threshold && score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best >= 0 {
+		logger.Info(ctx, "GenerateText CACHE HIT", key, bestScore)
+		return cachedResponse(entries[best].Response), nil
+	}
+
+	resp, err = w.wrapper.GenerateText(ctx, req)
+	if err != nil || !cacheable(resp) {
+		return
+	}
+
+	// concurrent answers in the same scope may overwrite each other, which
+	// only costs a cache miss later
+	entries = append([]cacheEntry{{
+		Embedding: packEmbedding(vector),
+		Response:  resp,
+		ExpiresAt: now.Add(w.config.TTL),
+	}}, entries...)
+	if len(entries) > w.config.MaxEntries {
+		entries = entries[:w.config.MaxEntries]
+	}
+
+	data, _ := json.Marshal(entries)
+	errRes = w.cacheWrapper.Set(ctx, key, string(data), w.config.TTL)
+	if errRes != nil {
+		logger.Error(ctx, "error caching answer", errRes.Error())
+	}
+	return
+}
+
+// getEntries returns the questions cached in the scope that have not expired
+func (w *cachingWrapper) getEntries(ctx context.Context, key string, now time.Time) (resp []cacheEntry) {
+	value, _ := w.cacheWrapper.Get(ctx, key)
+	if value == "" {
+		return
+	}
+
+	var entries []cacheEntry
+	err := json.Unmarshal([]byte(value), &entries)
+	if err != nil {
+		logger.Error(ctx, "error reading cached answers", err.Error())
+		return
+	}
+
+	for i := 0; i < len(entries); i++ {
+		if now.Before(entries[i].ExpiresAt) {
+			resp = append(resp, entries[i])
+		}
+	}
+	return
+}
+
+// standaloneQuestion returns the question of a request without earlier
+// messages: only system messages followed by a text question
+func standaloneQuestion(req openai.ChatCompletionRequest) (question string, ok bool) {
+	if len(req.Messages) == 0 {
+		return
+	}
+
+	for _, message := range req.Messages[:len(req.Messages)-1] {
+		if message.Role != openai.ChatMessageRoleSystem {
+			return
+		}
+	}
+
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role != openai.ChatMessageRoleUser || len(last.MultiContent) > 0 || last.Content == "" {
+		return
+	}
+	return last.Content, true
+}
+
+// cacheable reports whether the response is a final answer
+func cacheable(resp openai.ChatCompletionResponse) bool {
+	return len(resp.Choices) > 0 &&
+		resp.Choices[0].FinishReason == openai.FinishReasonStop &&
+		len(resp.Choices[0].Message.ToolCalls) == 0
+}
+
+// cachedResponse marks a cached answer, which used no tokens
+func cachedResponse(resp openai.ChatCompletionResponse) openai.ChatCompletionResponse {
+	resp.Usage = openai.Usage{}
+	resp.SetHeader(http.Header{CacheHeader: []string{CacheHit}})
+	return resp
+}
+
+// hashRequest returns a hash of the request
+func hashRequest(req openai.ChatCompletionRequest) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func packEmbedding(vector []float32) []byte {
+	data := make([]byte, 4*len(vector))
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(value))
+	}
+	return data
+}
+
+func unpackEmbedding(data []byte) []float32 {
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,175 @@
+package chatgbt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/sashabaranov/go-openai"
+)
+
+type memoryCache struct {
+	values map[string]string
+}
+
+func (c *memoryCache) Set(ctx context.Context, key, value string, duration time.Duration) (err error) {
+	c.values[key] = value
+	return
+}
+
+func (c *memoryCache) SetNX(ctx context.Context, key, value string, duration time.Duration) (ok bool, err error) {
+	if _, ok = c.values[key]; ok {
+		return false, nil
+	}
+	c.values[key] = value
+	return true, nil
+}
+
+func (c *memoryCache) Get(ctx context.Context, key string) (value string, err error) {
+	return c.values[key], nil
+}
+
+func (c *memoryCache) Delete(ctx context.Context, key string) (err error) {
+	delete(c.values, key)
+	return
+}
+
+// fakeEmbedding embeds the questions it knows, anything else far from them
+type fakeEmbedding struct {
+	vectors map[string][]float32
+}
+
+func (e *fakeEmbedding) Embed(ctx context.Context, inputs []string) (resp [][]float32, err error) {
+	for _, input := range inputs {
+		vector, ok := e.vectors[input]
+		if !ok {
+			vector = []float32{0, 0, 1}
+		}
+		resp = append(resp, vector)
+	}
+	return
+}
+
+func (e *fakeEmbedding) Model() string {
+	return "fake-embedding"
+}
+
+type countingWrapper struct {
+	calls int
+}
+
+func (w *countingWrapper) GenerateText(ctx context.Context, req openai.ChatCompletionRequest) (resp openai.ChatCompletionResponse, err error) {
+	w.calls++
+	resp.Choices = []openai.ChatCompletionChoice{{
+		Message:      openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "jawaban untuk " + req.Messages[len(req.Messages)-1].Content},
+		FinishReason: openai.FinishReasonStop,
+	}}
+	resp.Usage = openai.Usage{TotalTokens: 30}
+	return
+}
+
+func question(system, text string, history ...openai.ChatCompletionMessage) openai.ChatCompletionRequest {
+	messages := []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleSystem, Content: system}}
+	messages = append(messages, history...)
+	messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: text})
+	return openai.ChatCompletionRequest{Model: "gpt-3.5-turbo", Messages: messages}
+}
+
+func TestCachingWrapper_GenerateText(t *testing.T) {
+	ctx := context.Background()
+	logger.NewLogger()
+	embeddings := &fakeEmbedding{vectors: map[string][]float32{
+		"bagaimana cara reset password?":     {1, 0, 0},
+		"gimana cara mereset password saya?": {0.99, 0.05, 0},
+	}}
+	history := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "halo"}
+
+	tests := []struct {
+		name       string
+		mode       string
+		first      openai.ChatCompletionRequest
+		second     openai.ChatCompletionRequest
+		wantCached bool
+	}{
+		{
+			name:       "semantic similar question",
+			mode:       CacheModeSemantic,
+			first:      question("kamu asisten IT", "bagaimana cara reset password?"),
+			second:     question("kamu asisten IT", "gimana cara mereset password saya?"),
+			wantCached: true,
+		},
+		{
+			name:   "semantic other persona",
+			mode:   CacheModeSemantic,
+			first:  question("kamu asisten IT", "bagaimana cara reset password?"),
+			second: question("kamu asisten HR", "gimana cara mereset password saya?"),
+		},
+		{
+			name:   "semantic question far below the threshold",
+			mode:   CacheModeSemantic,
+			first:  question("kamu asisten IT", "bagaimana cara reset password?"),
+			second: question("kamu asisten IT", "resep nasi goreng"),
+		},
+		{
+			name:   "semantic question with earlier messages",
+			mode:   CacheModeSemantic,
+			first:  question("kamu asisten IT", "bagaimana cara reset password?", history),
+			second: question("kamu asisten IT", "bagaimana cara reset password?", history),
+		},
+		{
+			name:       "exact same request",
+			mode:       CacheModeExact,
+			first:      question("kamu asisten IT", "bagaimana cara reset password?", history),
+			second:     question("kamu asisten IT", "bagaimana cara reset password?", history),
+			wantCached: true,
+		},
+		{
+			name:   "exact similar question",
+			mode:   CacheModeExact,
+			first:  question("kamu asisten IT", "bagaimana cara reset password?"),
+			second: question("kamu asisten IT", "gimana cara mereset password saya?"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &countingWrapper{}
+			w := NewCachingWrapper(inner, &memoryCache{values: map[string]string{}}, embeddings, CacheConfig{Mode: tt.mode})
+
+			first, err := w.GenerateText(ctx, tt.first)
+			if err != nil || IsCached(first) {
+				t.Fatalf("GenerateText() first = %v, %v, want a generated answer", IsCached(first), err)
+			}
+
+			second, err := w.GenerateText(ctx, tt.second)
+			if err != nil {
+				t.Fatalf("GenerateText() error = %v", err)
+			}
+			if IsCached(second) != tt.wantCached {
+				t.Errorf("GenerateText() cached = %v, want %v", IsCached(second), tt.wantCached)
+			}
+			if tt.wantCached && (inner.calls != 1 || second.Choices[0].Message.Content != first.Choices[0].Message.Content || second.Usage.TotalTokens != 0) {
+				t.Errorf("GenerateText() = %+v after %v calls, want the first answer", second, inner.calls)
+			}
+			if !tt.wantCached && inner.calls != 2 {
+				t.Errorf("GenerateText() made %v calls, want 2", inner.calls)
+			}
+		})
+	}
+}
+
+func TestCachingWrapper_Expired(t *testing.T) {
+	ctx := context.Background()
+	logger.NewLogger()
+	embeddings := &fakeEmbedding{vectors: map[string][]float32{"halo": {1, 0, 0}}}
+	inner := &countingWrapper{}
+	cache := &memoryCache{values: map[string]string{}}
+	w := NewCachingWrapper(inner, cache, embeddings, CacheConfig{Mode: CacheModeSemantic, TTL: time.Nanosecond})
+
+	w.GenerateText(ctx, question("kamu asisten", "halo"))
+	time.Sleep(time.Millisecond)
+	resp, _ := w.GenerateText(ctx, question("kamu asisten", "halo"))
+	if IsCached(resp) || inner.calls != 2 {
+		t.Errorf("GenerateText() cached = %v after %v calls, want the expired answer generated again", IsCached(resp), inner.calls)
+	}
+}
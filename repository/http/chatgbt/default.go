@@ -2,7 +2,9 @@ package chatgbt
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/fadilahonespot/chatbot/utils/logger"
@@ -30,7 +32,7 @@ func (w *wrapper) GenerateText(ctx context.Context, req openai.ChatCompletionReq
 	resp, err = w.client.CreateChatCompletion(ctx, req)
 	if err != nil {
 		// handle the error
-		err = fmt.Errorf("chat completion error: %s", err.Error())
+		err = fmt.Errorf("chat completion error: %w", err)
 		return
 	}
 
@@ -40,3 +42,17 @@ func (w *wrapper) GenerateText(ctx context.Context, req openai.ChatCompletionReq
 	// return the response
 	return
 }
+
+// IsQuotaExceeded reports whether OpenAI refused the request because the rate
+// limit or the quota of the account is exceeded
+func IsQuotaExceeded(err error) bool {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
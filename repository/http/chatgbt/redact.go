@@ -0,0 +1,87 @@
+package chatgbt
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/utils/redact"
+	"github.com/sashabaranov/go-openai"
+)
+
+type redactingWrapper struct {
+	wrapper  OpenAIWrapper
+	redactor *redact.Redactor
+}
+
+// NewRedactingWrapper wraps an OpenAI wrapper so personal data is replaced
+// with placeholders before a request is logged and sent, and restored in the
+// response. The request of the caller is left untouched.
+func NewRedactingWrapper(wrapper OpenAIWrapper, redactor *redact.Redactor) OpenAIWrapper {
+	return &redactingWrapper{
+		wrapper:  wrapper,
+		redactor: redactor,
+	}
+}
+
+// GenerateText generates the answer to the redacted request
+func (w *redactingWrapper) GenerateText(ctx context.Context, req openai.ChatCompletionRequest) (resp openai.ChatCompletionResponse, err error) {
+	session := w.redactor.NewSession()
+	req.Messages = redactMessages(session, req.Messages)
+
+	resp, err = w.wrapper.GenerateText(ctx, req)
+	if err != nil || !session.Redacted() {
+		return
+	}
+
+	choices := make([]openai.ChatCompletionChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		choice.Message = restoreMessage(session, choice.Message)
+		choices[i] = choice
+	}
+	resp.Choices = choices
+	return
+}
+
+// redactMessages returns a copy of the messages with their text redacted
+func redactMessages(session *redact.Session, messages []openai.ChatCompletionMessage) []openai.ChatCompletionMessage {
+	resp := make([]openai.ChatCompletionMessage, len(messages))
+	for i, message := range messages {
+		message.Content = session.Redact(message.Content)
+
+		if message.MultiContent != nil {
+			parts := make([]openai.ChatMessagePart, len(message.MultiContent))
+			for j, part := range message.MultiContent {
+				if part.Type == openai.ChatMessagePartTypeText {
+					part.Text = session.Redact(part.Text)
+				}
+				parts[j] = part
+			}
+			message.MultiContent = parts
+		}
+
+		if message.ToolCalls != nil {
+			toolCalls := make([]openai.ToolCall, len(message.ToolCalls))
+			for j, toolCall := range message.ToolCalls {
+				toolCall.Function.Arguments = session.Redact(toolCall.Function.Arguments)
+				toolCalls[j] = toolCall
+			}
+			message.ToolCalls = toolCalls
+		}
+		resp[i] = message
+	}
+	return resp
+}
+
+// restoreMessage puts the personal data back in an answer, including the
+// arguments of the tools it calls so they run on the real values
+func restoreMessage(session *redact.Session, message openai.ChatCompletionMessage) openai.ChatCompletionMessage {
+	message.Content = session.Restore(message.Content)
+	if message.ToolCalls != nil {
+		toolCalls := make([]openai.ToolCall, len(message.ToolCalls))
+		for i, toolCall := range message.ToolCalls {
+			toolCall.Function.Arguments = session.Restore(toolCall.Function.Arguments)
+			toolCalls[i] = toolCall
+		}
+		message.ToolCalls = toolCalls
+	}
+	return message
+}
@@ -0,0 +1,78 @@
+package chatgbt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/utils/redact"
+	"github.com/sashabaranov/go-openai"
+)
+
+type fakeWrapper struct {
+	req  openai.ChatCompletionRequest
+	resp openai.ChatCompletionResponse
+}
+
+func (w *fakeWrapper) GenerateText(ctx context.Context, req openai.ChatCompletionRequest) (resp openai.ChatCompletionResponse, err error) {
+	w.req = req
+	return w.resp, nil
+}
+
+func TestRedactingWrapper_GenerateText(t *testing.T) {
+	redactor, err := redact.NewRedactor(redact.DefaultPatterns)
+	if err != nil {
+		t.Fatalf("redact.NewRedactor() error = %v", err)
+	}
+
+	inner := &fakeWrapper{
+		resp: openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: "Saya akan email [EMAIL_1] dan telepon [PHONE_1].",
+					ToolCalls: []openai.ToolCall{{
+						Function: openai.FunctionCall{Name: "search_past_chats", Arguments: `{"query":"[EMAIL_1]"}`},
+					}},
+				},
+			}},
+		},
+	}
+	req := openai.ChatCompletionRequest{
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleUser, Content: "pelanggan budi@example.com minta ditelepon"},
+			{Role: openai.ChatMessageRoleUser, MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: "nomornya 081234567890, email budi@example.com"},
+			}},
+		},
+	}
+
+	w := NewRedactingWrapper(inner, redactor)
+	resp, err := w.GenerateText(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("redactingWrapper.GenerateText() error = %v", err)
+	}
+
+	// the wrapped wrapper, which logs the request, only sees placeholders
+	if got := inner.req.Messages[0].Content; got != "pelanggan [EMAIL_1] minta ditelepon" {
+		t.Errorf("redactingWrapper.GenerateText() sent = %v", got)
+	}
+	if got := inner.req.Messages[1].MultiContent[0].Text; got != "nomornya [PHONE_1], email [EMAIL_1]" {
+		t.Errorf("redactingWrapper.GenerateText() sent = %v", got)
+	}
+
+	// the request of the caller is untouched
+	if req.Messages[0].Content != "pelanggan budi@example.com minta ditelepon" || req.Messages[1].MultiContent[0].Text != "nomornya 081234567890, email budi@example.com" {
+		t.Errorf("redactingWrapper.GenerateText() changed the request = %+v", req.Messages)
+	}
+
+	message := resp.Choices[0].Message
+	if message.Content != "Saya akan email budi@example.com dan telepon 081234567890." {
+		t.Errorf("redactingWrapper.GenerateText() answer = %v", message.Content)
+	}
+	if message.ToolCalls[0].Function.Arguments != `{"query":"budi@example.com"}` {
+		t.Errorf("redactingWrapper.GenerateText() tool arguments = %v", message.ToolCalls[0].Function.Arguments)
+	}
+	if inner.resp.Choices[0].Message.Content != "Saya akan email [EMAIL_1] dan telepon [PHONE_1]." {
+		t.Errorf("redactingWrapper.GenerateText() changed the wrapped response")
+	}
+}
@@ -0,0 +1,67 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/sashabaranov/go-openai"
+)
+
+// batchSize is the number of inputs sent in a single embedding request
+const batchSize = 100
+
+type openAIProvider struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAIProvider creates an embedding provider backed by the OpenAI API.
+// The model is read from EMBEDDING_MODEL and defaults to text-embedding-ada-002.
+func NewOpenAIProvider() EmbeddingProvider {
+	model := os.Getenv("EMBEDDING_MODEL")
+	if model == "" {
+		model = string(openai.AdaEmbeddingV2)
+	}
+
+	return &openAIProvider{
+		client: openai.NewClient(os.Getenv("OPEN_AI_TOKEN")),
+		model:  model,
+	}
+}
+
+// Embed returns the embedding of every input, in the order of the inputs
+func (p *openAIProvider) Embed(ctx context.Context, inputs []string) (resp [][]float32, err error) {
+	logger.Info(ctx, "Embed REQUEST", len(inputs))
+
+	for start := 0; start < len(inputs); start += batchSize {
+		end := start + batchSize
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+
+		dataResp, errRes := p.client.CreateEmbeddings(ctx, openai.EmbeddingRequest{
+			Input: inputs[start:end],
+			Model: openai.EmbeddingModel(p.model),
+		})
+		if errRes != nil {
+			err = fmt.Errorf("embedding error: %s", errRes.Error())
+			return
+		}
+
+		batch := make([][]float32, end-start)
+		for i := 0; i < len(dataResp.Data); i++ {
+			batch[dataResp.Data[i].Index] = dataResp.Data[i].Embedding
+		}
+		resp = append(resp, batch...)
+	}
+
+	logger.Info(ctx, "Embed RESPONSE", len(resp))
+	return
+}
+
+// Model returns the name of the embedding model
+func (p *openAIProvider) Model() string {
+	return p.model
+}
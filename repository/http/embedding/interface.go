@@ -0,0 +1,9 @@
+package embedding
+
+import "context"
+
+// EmbeddingProvider turns text into vectors for semantic search.
+type EmbeddingProvider interface {
+	Embed(ctx context.Context, inputs []string) (resp [][]float32, err error)
+	Model() string
+}
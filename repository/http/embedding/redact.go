@@ -0,0 +1,36 @@
+package embedding
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/utils/redact"
+)
+
+type redactingProvider struct {
+	provider EmbeddingProvider
+	redactor *redact.Redactor
+}
+
+// NewRedactingProvider wraps an embedding provider so personal data is
+// replaced with placeholders before the inputs are sent. Every input is
+// redacted on its own, so its embedding does not depend on the inputs sent
+// with it.
+func NewRedactingProvider(provider EmbeddingProvider, redactor *redact.Redactor) EmbeddingProvider {
+	return &redactingProvider{
+		provider: provider,
+		redactor: redactor,
+	}
+}
+
+// Embed returns the embedding of every redacted input
+func (p *redactingProvider) Embed(ctx context.Context, inputs []string) (resp [][]float32, err error) {
+	redacted := make([]string, len(inputs))
+	for i := range inputs {
+		redacted[i] = p.redactor.NewSession().Redact(inputs[i])
+	}
+	return p.provider.Embed(ctx, redacted)
+}
+
+func (p *redactingProvider) Model() string {
+	return p.provider.Model()
+}
@@ -0,0 +1,45 @@
+package embedding
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/utils/redact"
+)
+
+type recordingProvider struct {
+	inputs []string
+}
+
+func (p *recordingProvider) Embed(ctx context.Context, inputs []string) (resp [][]float32, err error) {
+	p.inputs = inputs
+	return make([][]float32, len(inputs)), nil
+}
+
+func (p *recordingProvider) Model() string {
+	return "test-model"
+}
+
+func TestRedactingProvider_Embed(t *testing.T) {
+	redactor, err := redact.NewRedactor(redact.DefaultPatterns)
+	if err != nil {
+		t.Fatalf("redact.NewRedactor() error = %v", err)
+	}
+
+	inner := &recordingProvider{}
+	provider := NewRedactingProvider(inner, redactor)
+	got, err := provider.Embed(context.Background(), []string{"email budi@example.com", "email ani@example.com"})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+	if want := []string{"email [EMAIL_1]", "email [EMAIL_1]"}; !reflect.DeepEqual(inner.inputs, want) {
+		t.Errorf("Embed() sent %q, want %q", inner.inputs, want)
+	}
+	if len(got) != 2 {
+		t.Errorf("Embed() returned %d embeddings, want 2", len(got))
+	}
+	if provider.Model() != "test-model" {
+		t.Errorf("Model() = %q, want the model of the provider", provider.Model())
+	}
+}
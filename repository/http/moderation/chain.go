@@ -0,0 +1,121 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/redact"
+)
+
+const (
+	ProviderOpenAI = "openai"
+	ProviderPolicy = "policy"
+)
+
+// actionLevels orders the actions from the least to the most strict
+var actionLevels = map[string]int{
+	constrans.ModerationLog:   1,
+	constrans.ModerationWarn:  2,
+	constrans.ModerationBlock: 3,
+}
+
+type chain struct {
+	moderators []Moderator
+}
+
+// NewChain creates a moderator that runs the text through every moderator and
+// merges their verdicts. A moderator that fails is skipped, so an outage of
+// a provider does not stop the chat.
+func NewChain(moderators ...Moderator) Moderator {
+	return &chain{
+		moderators: moderators,
+	}
+}
+
+// NewModerator creates the chain of the providers listed in
+// MODERATION_PROVIDERS, for example "policy,openai". The text sent to OpenAI
+// is redacted by the redactor first. The rules of the policy provider are
+// read from MODERATION_POLICY_FILE. Without providers nothing is moderated.
+func NewModerator(redactor *redact.Redactor) (resp Moderator, err error) {
+	var moderators []Moderator
+	for _, provider := range strings.Split(os.Getenv("MODERATION_PROVIDERS"), ",") {
+		switch strings.TrimSpace(provider) {
+		case "", "none":
+		case ProviderOpenAI:
+			moderators = append(moderators, NewRedactingModerator(NewOpenAIModerator(), redactor))
+		case ProviderPolicy:
+			rules, errRes := LoadRules(os.Getenv("MODERATION_POLICY_FILE"))
+			if errRes != nil {
+				err = errRes
+				return
+			}
+			policy, errRes := NewPolicyModerator(rules)
+			if errRes != nil {
+				err = errRes
+				return
+			}
+			moderators = append(moderators, policy)
+		default:
+			err = fmt.Errorf("unknown moderation provider %q", provider)
+			return
+		}
+	}
+
+	if len(moderators) == 0 {
+		resp = NewNoopModerator()
+		return
+	}
+	resp = NewChain(moderators...)
+	return
+}
+
+// Moderate runs the text through every moderator of the chain
+func (c *chain) Moderate(ctx context.Context, text string) (resp Result, err error) {
+	for _, moderator := range c.moderators {
+		result, errRes := moderator.Moderate(ctx, text)
+		if errRes != nil {
+			logger.Error(ctx, "error moderating text", errRes.Error())
+			continue
+		}
+		if !result.Flagged {
+			continue
+		}
+
+		resp.Flagged = true
+		resp.Action = strictest(resp.Action, result.Action)
+		for i := 0; i < len(result.Providers); i++ {
+			resp.Providers = appendUnique(resp.Providers, result.Providers[i])
+		}
+		for i := 0; i < len(result.Categories); i++ {
+			resp.Categories = appendUnique(resp.Categories, result.Categories[i])
+		}
+	}
+	return
+}
+
+// strictest returns the stricter of two actions
+func strictest(a, b string) string {
+	if actionLevels[b] > actionLevels[a] {
+		return b
+	}
+	return a
+}
+
+// validAction reports whether the action is known
+func validAction(action string) bool {
+	return actionLevels[action] > 0
+}
+
+// appendUnique appends the value when the list does not hold it yet
+func appendUnique(list []string, value string) []string {
+	for i := 0; i < len(list); i++ {
+		if list[i] == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
@@ -0,0 +1,82 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/sashabaranov/go-openai"
+)
+
+type openAIModerator struct {
+	client *openai.Client
+	action string
+}
+
+// NewOpenAIModerator creates a moderator backed by the OpenAI moderation
+// endpoint. Flagged text gets the action in MODERATION_OPENAI_ACTION, block
+// by default.
+func NewOpenAIModerator() Moderator {
+	action := os.Getenv("MODERATION_OPENAI_ACTION")
+	if !validAction(action) {
+		action = constrans.ModerationBlock
+	}
+
+	return &openAIModerator{
+		client: openai.NewClient(os.Getenv("OPEN_AI_TOKEN")),
+		action: action,
+	}
+}
+
+// Moderate asks OpenAI whether the text breaks its usage policies
+func (m *openAIModerator) Moderate(ctx context.Context, text string) (resp Result, err error) {
+	logger.Info(ctx, "Moderate REQUEST", len(text))
+
+	dataResp, err := m.client.Moderations(ctx, openai.ModerationRequest{
+		Input: text,
+		Model: openai.ModerationTextLatest,
+	})
+	if err != nil {
+		err = fmt.Errorf("moderation error: %s", err.Error())
+		return
+	}
+
+	for i := 0; i < len(dataResp.Results); i++ {
+		if !dataResp.Results[i].Flagged {
+			continue
+		}
+		resp.Flagged = true
+		resp.Categories = append(resp.Categories, openAICategories(dataResp.Results[i].Categories)...)
+	}
+	if resp.Flagged {
+		resp.Action = m.action
+		resp.Providers = []string{ProviderOpenAI}
+	}
+
+	logger.Info(ctx, "Moderate RESPONSE", resp)
+	return
+}
+
+// openAICategories returns the names of the flagged categories
+func openAICategories(categories openai.ResultCategories) (resp []string) {
+	flags := []struct {
+		name    string
+		flagged bool
+	}{
+		{"hate", categories.Hate},
+		{"hate/threatening", categories.HateThreatening},
+		{"self-harm", categories.SelfHarm},
+		{"sexual", categories.Sexual},
+		{"sexual/minors", categories.SexualMinors},
+		{"violence", categories.Violence},
+		{"violence/graphic", categories.ViolenceGraphic},
+	}
+	for _, flag := range flags {
+		if flag.flagged {
+			resp = append(resp, flag.name)
+		}
+	}
+	return
+}
@@ -0,0 +1,17 @@
+package moderation
+
+import "context"
+
+// Moderator checks text against a content policy.
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (resp Result, err error)
+}
+
+// Result is the verdict on a text. Action is the strictest action of the
+// categories the text was flagged for, and is empty when it was not flagged.
+type Result struct {
+	Flagged    bool
+	Action     string
+	Providers  []string
+	Categories []string
+}
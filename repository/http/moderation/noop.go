@@ -0,0 +1,15 @@
+package moderation
+
+import "context"
+
+type noopModerator struct{}
+
+// NewNoopModerator creates a moderator that lets every text through
+func NewNoopModerator() Moderator {
+	return &noopModerator{}
+}
+
+// Moderate never flags the text
+func (m *noopModerator) Moderate(ctx context.Context, text string) (resp Result, err error) {
+	return
+}
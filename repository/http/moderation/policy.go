@@ -0,0 +1,103 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+)
+
+// Rule flags text containing one of its keywords, matched as whole words in
+// any case, or matching its regular expression.
+type Rule struct {
+	Category string   `json:"category"`
+	Keywords []string `json:"keywords"`
+	Pattern  string   `json:"pattern"`
+	Action   string   `json:"action"`
+}
+
+type policyRule struct {
+	Rule
+	patterns []*regexp.Regexp
+}
+
+type policyModerator struct {
+	rules []policyRule
+}
+
+// NewPolicyModerator creates a moderator that checks text against local rules
+// without calling any API. A rule without an action logs.
+func NewPolicyModerator(rules []Rule) (resp Moderator, err error) {
+	moderator := &policyModerator{}
+	for i, rule := range rules {
+		if rule.Category == "" {
+			err = fmt.Errorf("moderation rule %v has no category", i)
+			return
+		}
+		if rule.Action == "" {
+			rule.Action = constrans.ModerationLog
+		}
+		if !validAction(rule.Action) {
+			err = fmt.Errorf("moderation rule %v has an unknown action %q", i, rule.Action)
+			return
+		}
+
+		compiled := policyRule{Rule: rule}
+		var keywords []string
+		for _, keyword := range rule.Keywords {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				keywords = append(keywords, regexp.QuoteMeta(keyword))
+			}
+		}
+		if len(keywords) > 0 {
+			compiled.patterns = append(compiled.patterns, regexp.MustCompile(`(?i)\b(`+strings.Join(keywords, "|")+`)\b`))
+		}
+		if rule.Pattern != "" {
+			pattern, errRes := regexp.Compile(rule.Pattern)
+			if errRes != nil {
+				err = fmt.Errorf("moderation rule %v has an invalid pattern: %s", i, errRes.Error())
+				return
+			}
+			compiled.patterns = append(compiled.patterns, pattern)
+		}
+		moderator.rules = append(moderator.rules, compiled)
+	}
+
+	resp = moderator
+	return
+}
+
+// LoadRules reads the rules of a policy from a json file holding a list of rules
+func LoadRules(path string) (resp []Rule, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &resp)
+	if err != nil {
+		err = fmt.Errorf("moderation policy %v is not valid: %s", path, err.Error())
+	}
+	return
+}
+
+// Moderate checks the text against every rule
+func (m *policyModerator) Moderate(ctx context.Context, text string) (resp Result, err error) {
+	for _, rule := range m.rules {
+		for _, pattern := range rule.patterns {
+			if !pattern.MatchString(text) {
+				continue
+			}
+			resp.Flagged = true
+			resp.Providers = []string{ProviderPolicy}
+			resp.Categories = appendUnique(resp.Categories, rule.Category)
+			resp.Action = strictest(resp.Action, rule.Action)
+			break
+		}
+	}
+	return
+}
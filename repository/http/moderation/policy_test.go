@@ -0,0 +1,110 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+type fakeModerator struct {
+	result Result
+	err    error
+}
+
+func (m *fakeModerator) Moderate(ctx context.Context, text string) (resp Result, err error) {
+	return m.result, m.err
+}
+
+func TestPolicyModerator_Moderate(t *testing.T) {
+	moderator, err := NewPolicyModerator([]Rule{
+		{Category: "weapons", Keywords: []string{"bom", "senjata api"}, Action: constrans.ModerationBlock},
+		{Category: "insult", Keywords: []string{"bodoh"}, Action: constrans.ModerationWarn},
+		{Category: "card", Pattern: `\b\d{4}-\d{4}-\d{4}-\d{4}\b`},
+	})
+	if err != nil {
+		t.Fatalf("NewPolicyModerator() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want Result
+	}{
+		{
+			name: "not flagged",
+			text: "resep nasi goreng",
+		},
+		{
+			name: "keyword only matched as a whole word",
+			text: "bombay itu bawang",
+		},
+		{
+			name: "keyword in any case",
+			text: "jual Senjata Api",
+			want: Result{Flagged: true, Action: constrans.ModerationBlock, Providers: []string{ProviderPolicy}, Categories: []string{"weapons"}},
+		},
+		{
+			name: "strictest action of several rules",
+			text: "dasar bodoh, nomor kartu 1234-5678-9012-3456 dan bom",
+			want: Result{Flagged: true, Action: constrans.ModerationBlock, Providers: []string{ProviderPolicy}, Categories: []string{"weapons", "insult", "card"}},
+		},
+		{
+			name: "rule without an action logs",
+			text: "kartu 1234-5678-9012-3456",
+			want: Result{Flagged: true, Action: constrans.ModerationLog, Providers: []string{ProviderPolicy}, Categories: []string{"card"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := moderator.Moderate(context.TODO(), tt.text)
+			if err != nil {
+				t.Fatalf("policyModerator.Moderate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("policyModerator.Moderate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewPolicyModerator(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+	}{
+		{name: "rule without category", rules: []Rule{{Keywords: []string{"bom"}}}},
+		{name: "unknown action", rules: []Rule{{Category: "weapons", Action: "ban"}}},
+		{name: "invalid pattern", rules: []Rule{{Category: "weapons", Pattern: "("}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPolicyModerator(tt.rules); err == nil {
+				t.Errorf("NewPolicyModerator() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestChain_Moderate(t *testing.T) {
+	logger.NewLogger()
+
+	moderator := NewChain(
+		&fakeModerator{err: errors.New("provider down")},
+		&fakeModerator{result: Result{Flagged: true, Action: constrans.ModerationWarn, Providers: []string{ProviderPolicy}, Categories: []string{"insult"}}},
+		&fakeModerator{},
+		&fakeModerator{result: Result{Flagged: true, Action: constrans.ModerationBlock, Providers: []string{ProviderOpenAI}, Categories: []string{"hate", "insult"}}},
+	)
+
+	got, err := moderator.Moderate(context.TODO(), "halo")
+	if err != nil {
+		t.Fatalf("chain.Moderate() error = %v", err)
+	}
+	want := Result{Flagged: true, Action: constrans.ModerationBlock, Providers: []string{ProviderPolicy, ProviderOpenAI}, Categories: []string{"insult", "hate"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chain.Moderate() = %+v, want %+v", got, want)
+	}
+}
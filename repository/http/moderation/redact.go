@@ -0,0 +1,27 @@
+package moderation
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/utils/redact"
+)
+
+type redactingModerator struct {
+	moderator Moderator
+	redactor  *redact.Redactor
+}
+
+// NewRedactingModerator wraps a moderator so personal data is replaced with
+// placeholders before the text is sent. The placeholders do not change what
+// the text is flagged for.
+func NewRedactingModerator(moderator Moderator, redactor *redact.Redactor) Moderator {
+	return &redactingModerator{
+		moderator: moderator,
+		redactor:  redactor,
+	}
+}
+
+// Moderate moderates the redacted text
+func (m *redactingModerator) Moderate(ctx context.Context, text string) (resp Result, err error) {
+	return m.moderator.Moderate(ctx, m.redactor.NewSession().Redact(text))
+}
@@ -0,0 +1,36 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/utils/redact"
+)
+
+type recordingModerator struct {
+	text string
+}
+
+func (m *recordingModerator) Moderate(ctx context.Context, text string) (resp Result, err error) {
+	m.text = text
+	return Result{Flagged: true}, nil
+}
+
+func TestRedactingModerator_Moderate(t *testing.T) {
+	redactor, err := redact.NewRedactor(redact.DefaultPatterns)
+	if err != nil {
+		t.Fatalf("redact.NewRedactor() error = %v", err)
+	}
+
+	inner := &recordingModerator{}
+	got, err := NewRedactingModerator(inner, redactor).Moderate(context.Background(), "kirim ancaman ke budi@example.com")
+	if err != nil {
+		t.Fatalf("Moderate() error = %v", err)
+	}
+	if want := "kirim ancaman ke [EMAIL_1]"; inner.text != want {
+		t.Errorf("Moderate() sent %q, want %q", inner.text, want)
+	}
+	if !got.Flagged {
+		t.Errorf("Moderate() = %+v, want the result of the moderator", got)
+	}
+}
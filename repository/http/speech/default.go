@@ -0,0 +1,88 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/sashabaranov/go-openai"
+)
+
+type openAIProvider struct {
+	client *openai.Client
+	voice  openai.SpeechVoice
+}
+
+// NewOpenAIProvider creates a speech provider backed by OpenAI Whisper for
+// transcription and the OpenAI speech API for synthesis. The voice is read
+// from SPEECH_VOICE and defaults to alloy.
+func NewOpenAIProvider() SpeechProvider {
+	voice := os.Getenv("SPEECH_VOICE")
+	if voice == "" {
+		voice = string(openai.VoiceAlloy)
+	}
+
+	return &openAIProvider{
+		client: openai.NewClient(os.Getenv("OPEN_AI_TOKEN")),
+		voice:  openai.SpeechVoice(voice),
+	}
+}
+
+// Transcribe returns the text spoken in the audio file. The file name is sent
+// along because Whisper detects the audio format from its extension.
+func (p *openAIProvider) Transcribe(ctx context.Context, fileName string, data []byte) (resp Transcription, err error) {
+	logger.Info(ctx, "Transcribe REQUEST", fileName, len(data))
+
+	dataResp, err := p.client.CreateTranscription(ctx, openai.AudioRequest{
+		Model:    openai.Whisper1,
+		FilePath: fileName,
+		Reader:   bytes.NewReader(data),
+		Format:   openai.AudioResponseFormatVerboseJSON,
+	})
+	if err != nil {
+		err = fmt.Errorf("transcription error: %s", err.Error())
+		return
+	}
+
+	resp = Transcription{
+		Text:     dataResp.Text,
+		Language: dataResp.Language,
+		Duration: dataResp.Duration,
+	}
+	logger.Info(ctx, "Transcribe RESPONSE", resp)
+	return
+}
+
+// Synthesize returns the text spoken as mp3 audio
+func (p *openAIProvider) Synthesize(ctx context.Context, text string) (resp Speech, err error) {
+	logger.Info(ctx, "Synthesize REQUEST", len(text))
+
+	body, err := p.client.CreateSpeech(ctx, openai.CreateSpeechRequest{
+		Model:          openai.TTSModel1,
+		Input:          text,
+		Voice:          p.voice,
+		ResponseFormat: openai.SpeechResponseFormatMp3,
+	})
+	if err != nil {
+		err = fmt.Errorf("speech error: %s", err.Error())
+		return
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		err = fmt.Errorf("speech error: %s", err.Error())
+		return
+	}
+
+	resp = Speech{
+		Data:        data,
+		ContentType: "audio/mpeg",
+		Extension:   ".mp3",
+	}
+	logger.Info(ctx, "Synthesize RESPONSE", len(data))
+	return
+}
@@ -0,0 +1,23 @@
+package speech
+
+import "context"
+
+// SpeechProvider turns audio into text and text back into audio.
+type SpeechProvider interface {
+	Transcribe(ctx context.Context, fileName string, data []byte) (resp Transcription, err error)
+	Synthesize(ctx context.Context, text string) (resp Speech, err error)
+}
+
+// Transcription is the text recognized in an audio file
+type Transcription struct {
+	Text     string
+	Language string
+	Duration float64
+}
+
+// Speech is synthesized audio with its content type and file extension
+type Speech struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
@@ -0,0 +1,32 @@
+package speech
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/utils/redact"
+)
+
+type redactingProvider struct {
+	provider SpeechProvider
+	redactor *redact.Redactor
+}
+
+// NewRedactingProvider wraps a speech provider so personal data is replaced
+// with placeholders before the text is synthesized, and is read out as the
+// placeholders. Audio to transcribe is sent as it is, since there is no text
+// to redact yet.
+func NewRedactingProvider(provider SpeechProvider, redactor *redact.Redactor) SpeechProvider {
+	return &redactingProvider{
+		provider: provider,
+		redactor: redactor,
+	}
+}
+
+func (p *redactingProvider) Transcribe(ctx context.Context, fileName string, data []byte) (resp Transcription, err error) {
+	return p.provider.Transcribe(ctx, fileName, data)
+}
+
+// Synthesize synthesizes the redacted text
+func (p *redactingProvider) Synthesize(ctx context.Context, text string) (resp Speech, err error) {
+	return p.provider.Synthesize(ctx, p.redactor.NewSession().Redact(text))
+}
@@ -0,0 +1,35 @@
+package speech
+
+import "context"
+
+type stubProvider struct {
+	transcript string
+}
+
+// NewStubProvider creates a speech provider that does not call any API. Every
+// audio file is transcribed as the given transcript, and synthesized speech
+// holds the text itself, which makes it usable in tests and local setups.
+func NewStubProvider(transcript string) SpeechProvider {
+	return &stubProvider{
+		transcript: transcript,
+	}
+}
+
+// Transcribe returns the configured transcript
+func (p *stubProvider) Transcribe(ctx context.Context, fileName string, data []byte) (resp Transcription, err error) {
+	resp = Transcription{
+		Text:     p.transcript,
+		Language: "english",
+	}
+	return
+}
+
+// Synthesize returns the text as plain text "audio"
+func (p *stubProvider) Synthesize(ctx context.Context, text string) (resp Speech, err error) {
+	resp = Speech{
+		Data:        []byte(text),
+		ContentType: "text/plain",
+		Extension:   ".txt",
+	}
+	return
+}
@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"syscall"
+)
+
+// ErrAddressNotAllowed is returned for urls and connections to loopback,
+// private, link-local and other internal addresses, so webhooks cannot be
+// used to reach the services next to the server
+var ErrAddressNotAllowed = errors.New("address not allowed")
+
+// blockedNetworks are the ranges not covered by the checks of net.IP that
+// are not reachable on the internet either
+var blockedNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"),
+	mustParseCIDR("192.0.0.0/24"),
+	mustParseCIDR("198.18.0.0/15"),
+	mustParseCIDR("240.0.0.0/4"),
+	mustParseCIDR("64:ff9b::/96"),
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// allowedAddress reports whether payloads can be sent to the address
+func allowedAddress(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkUrl resolves the host of the url and fails when any of its addresses
+// is not allowed
+func checkUrl(ctx context.Context, resolver *net.Resolver, rawUrl string) (err error) {
+	webhookUrl, err := url.Parse(rawUrl)
+	if err != nil {
+		return
+	}
+
+	addresses, err := resolver.LookupIPAddr(ctx, webhookUrl.Hostname())
+	if err != nil {
+		return
+	}
+	for _, address := range addresses {
+		if !allowedAddress(address.IP) {
+			return ErrAddressNotAllowed
+		}
+	}
+	return
+}
+
+// controlAddress is the Control of the dialer of the sender. It checks the
+// address actually connected to, so a host resolving to another address
+// after checkUrl cannot get around it.
+func controlAddress(network, address string, c syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !allowedAddress(ip) {
+		return ErrAddressNotAllowed
+	}
+	return nil
+}
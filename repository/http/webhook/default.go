@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+const (
+	// SignatureHeader carries the signature of the payload and
+	// TimestampHeader the unix time it was signed at
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+
+	// SendTimeout is how long a webhook url is waited on
+	SendTimeout = 10 * time.Second
+)
+
+type httpSender struct {
+	client   *http.Client
+	resolver *net.Resolver
+}
+
+// NewSender creates a sender that posts the payloads over http. It only
+// connects to addresses on the internet, and no proxy is used so the
+// address connected to is the one checked.
+func NewSender() Sender {
+	dialer := &net.Dialer{
+		Timeout: SendTimeout,
+		Control: controlAddress,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &httpSender{
+		client:   &http.Client{Timeout: SendTimeout, Transport: transport},
+		resolver: net.DefaultResolver,
+	}
+}
+
+func (s *httpSender) Send(ctx context.Context, url, secret string, payload []byte) (statusCode int, err error) {
+	logger.Info(ctx, "Webhook REQUEST", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("webhook error: %s", err.Error())
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	statusCode = resp.StatusCode
+	logger.Info(ctx, "Webhook RESPONSE", url, statusCode)
+	if statusCode < 200 || statusCode > 299 {
+		err = fmt.Errorf("webhook responded with status %v", statusCode)
+	}
+	return
+}
+
+func (s *httpSender) CheckUrl(ctx context.Context, url string) (err error) {
+	return checkUrl(ctx, s.resolver, url)
+}
+
+// Sign returns the signature of a payload sent at the timestamp: the hex
+// HMAC-SHA256 of "<timestamp>.<payload>" under the secret, prefixed with
+// "sha256=". Signing the timestamp lets receivers reject replayed payloads.
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%v.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature was made for the payload and
+// timestamp with the secret
+func Verify(secret string, timestamp int64, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+func TestHttpSender_Send(t *testing.T) {
+	ctx := context.Background()
+	logger.NewLogger()
+
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{
+			name:       "delivered",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "rejected",
+			statusCode: http.StatusInternalServerError,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified := false
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+				verified = Verify("rahasia", timestamp, body, r.Header.Get(SignatureHeader)) && string(body) == `{"id":"1"}`
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			// the test server listens on loopback, which NewSender refuses
+			sender := &httpSender{client: &http.Client{Timeout: SendTimeout}}
+			statusCode, err := sender.Send(ctx, server.URL, "rahasia", []byte(`{"id":"1"}`))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("httpSender.Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if statusCode != tt.statusCode || !verified {
+				t.Errorf("httpSender.Send() = %v, verified %v, want %v", statusCode, verified, tt.statusCode)
+			}
+		})
+	}
+}
+
+func TestHttpSender_Send_internalAddress(t *testing.T) {
+	ctx := context.Background()
+	logger.NewLogger()
+
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	_, err := NewSender().Send(ctx, server.URL, "rahasia", []byte(`{"id":"1"}`))
+	if err == nil || !strings.Contains(err.Error(), ErrAddressNotAllowed.Error()) || called {
+		t.Errorf("httpSender.Send() to loopback error = %v, called %v, want %v", err, called, ErrAddressNotAllowed)
+	}
+}
+
+func TestHttpSender_CheckUrl(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://93.184.216.34/hooks"},
+		{url: "https://[2606:2800:220:1:248:1893:25c8:1946]/hooks"},
+		{url: "http://127.0.0.1:8080/hooks", wantErr: true},
+		{url: "http://localhost/hooks", wantErr: true},
+		{url: "http://10.0.0.5/hooks", wantErr: true},
+		{url: "http://172.16.3.4/hooks", wantErr: true},
+		{url: "http://192.168.1.1/hooks", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{url: "http://100.64.0.1/hooks", wantErr: true},
+		{url: "http://0.0.0.0/hooks", wantErr: true},
+		{url: "http://[::1]/hooks", wantErr: true},
+		{url: "http://[fd00:ec2::254]/hooks", wantErr: true},
+		{url: "http://[::ffff:127.0.0.1]/hooks", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := NewSender().CheckUrl(ctx, tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("httpSender.CheckUrl() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{"id":"1"}`)
+	signature := Sign("rahasia", 1700000000, payload)
+
+	if !Verify("rahasia", 1700000000, payload, signature) {
+		t.Errorf("Verify() = false, want true")
+	}
+	if Verify("rahasia", 1700000001, payload, signature) {
+		t.Errorf("Verify() with another timestamp = true, want false")
+	}
+	if Verify("lain", 1700000000, payload, signature) {
+		t.Errorf("Verify() with another secret = true, want false")
+	}
+}
@@ -0,0 +1,15 @@
+package webhook
+
+import "context"
+
+// Sender posts signed json payloads to webhook urls
+type Sender interface {
+	// Send posts the payload signed with the secret and returns the status
+	// code of the response. A status code other than 2xx is returned as an
+	// error as well.
+	Send(ctx context.Context, url, secret string, payload []byte) (statusCode int, err error)
+
+	// CheckUrl returns ErrAddressNotAllowed when the host of the url resolves
+	// to an address payloads are not sent to
+	CheckUrl(ctx context.Context, url string) (err error)
+}
@@ -0,0 +1,147 @@
+package mysql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/utils/paginate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuditRepository stores the audit log. Records are only appended, never
+// changed or removed.
+type AuditRepository interface {
+	Create(ctx context.Context, req *entity.AuditLog) (err error)
+	Find(ctx context.Context, filter AuditFilter) (resp []entity.AuditLog, total int64, err error)
+	FindAfter(ctx context.Context, afterId, limit int) (resp []entity.AuditLog, err error)
+}
+
+// AuditFilter narrows the audit log. Zero fields are not filtered on, To is
+// exclusive.
+type AuditFilter struct {
+	ActorId    int
+	Action     string
+	TargetType string
+	TargetId   int
+	RequestId  string
+	From       time.Time
+	To         time.Time
+	Page       int
+	Limit      int
+}
+
+type defaultAuditRepo struct {
+	db        *gorm.DB
+	hashChain bool
+	mu        sync.Mutex
+}
+
+// NewAuditRepository creates the audit repository, chaining the records by
+// hash when AUDIT_HASH_CHAIN is true
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &defaultAuditRepo{
+		db:        db,
+		hashChain: os.Getenv("AUDIT_HASH_CHAIN") == "true",
+	}
+}
+
+func (s *defaultAuditRepo) Create(ctx context.Context, req *entity.AuditLog) (err error) {
+	if !s.hashChain {
+		err = s.db.WithContext(ctx).Create(req).Error
+		return
+	}
+
+	// The time is kept to the second so the hash can be computed again from
+	// the stored record
+	req.CreatedAt = time.Now().Truncate(time.Second)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last entity.AuditLog
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("hash <> ?", "").
+			Order("id DESC").Limit(1).
+			Find(&last).Error
+		if err != nil {
+			return err
+		}
+
+		req.PrevHash = last.Hash
+		req.Hash = AuditHash(req.PrevHash, *req)
+		return tx.Create(req).Error
+	})
+	return
+}
+
+// Find returns a page of records, the latest first, with the number of records
+// matching the filter
+func (s *defaultAuditRepo) Find(ctx context.Context, filter AuditFilter) (resp []entity.AuditLog, total int64, err error) {
+	query := s.db.WithContext(ctx).Model(&entity.AuditLog{})
+	if filter.ActorId != 0 {
+		query = query.Where("actor_id = ?", filter.ActorId)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetId != 0 {
+		query = query.Where("target_id = ?", filter.TargetId)
+	}
+	if filter.RequestId != "" {
+		query = query.Where("request_id = ?", filter.RequestId)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	err = query.Order("id DESC").Scopes(paginate.Paginate(filter.Page, filter.Limit)).Find(&resp).Error
+	return
+}
+
+// FindAfter returns the next records after afterId, the oldest first
+func (s *defaultAuditRepo) FindAfter(ctx context.Context, afterId, limit int) (resp []entity.AuditLog, err error) {
+	err = s.db.WithContext(ctx).
+		Where("id > ?", afterId).
+		Order("id ASC").Limit(limit).
+		Find(&resp).Error
+	return
+}
+
+// AuditHash returns the hash of the record chained to the hash of the record
+// before it. The id is left out as it is not known before the record is
+// stored.
+func AuditHash(prevHash string, log entity.AuditLog) string {
+	data, _ := json.Marshal([]interface{}{
+		prevHash,
+		log.ActorID,
+		log.Action,
+		log.TargetType,
+		log.TargetID,
+		log.IP,
+		log.UserAgent,
+		log.RequestID,
+		log.Before,
+		log.After,
+		log.CreatedAt.Unix(),
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
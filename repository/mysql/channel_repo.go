@@ -0,0 +1,55 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChannelRepository interface {
+	SaveIdentity(ctx context.Context, req *entity.ChannelIdentity) (err error)
+	GetIdentity(ctx context.Context, channel, externalId string) (resp *entity.ChannelIdentity, err error)
+	GetIdentitiesByUserId(ctx context.Context, userId int) (resp []entity.ChannelIdentity, err error)
+	UpdateConversation(ctx context.Context, id, conversationId int) (err error)
+	DeleteIdentity(ctx context.Context, id int) (err error)
+}
+
+type defaultChannelRepo struct {
+	db *gorm.DB
+}
+
+func NewChannelRepository(db *gorm.DB) ChannelRepository {
+	return &defaultChannelRepo{db}
+}
+
+// SaveIdentity links the external user to the user, replacing the user they
+// were linked to before and starting over their conversation
+func (s *defaultChannelRepo) SaveIdentity(ctx context.Context, req *entity.ChannelIdentity) (err error) {
+	err = s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel"}, {Name: "external_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "username", "conversation_id", "updated_at"}),
+	}).Create(req).Error
+	return
+}
+
+func (s *defaultChannelRepo) GetIdentity(ctx context.Context, channel, externalId string) (resp *entity.ChannelIdentity, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "channel = ? AND external_id = ?", channel, externalId).Error
+	return
+}
+
+func (s *defaultChannelRepo) GetIdentitiesByUserId(ctx context.Context, userId int) (resp []entity.ChannelIdentity, err error) {
+	err = s.db.WithContext(ctx).Order("id DESC").Find(&resp, "user_id = ?", userId).Error
+	return
+}
+
+func (s *defaultChannelRepo) UpdateConversation(ctx context.Context, id, conversationId int) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.ChannelIdentity{}).Where("id = ?", id).Update("conversation_id", conversationId).Error
+	return
+}
+
+func (s *defaultChannelRepo) DeleteIdentity(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.ChannelIdentity{}, "id = ?", id).Error
+	return
+}
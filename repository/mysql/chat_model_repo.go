@@ -0,0 +1,71 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type ChatModelRepository interface {
+	Create(ctx context.Context, req *entity.ChatModel) (err error)
+	Update(ctx context.Context, req *entity.ChatModel) (err error)
+	Delete(ctx context.Context, id int) (err error)
+	GetById(ctx context.Context, id int) (resp *entity.ChatModel, err error)
+	GetByName(ctx context.Context, name string) (resp *entity.ChatModel, err error)
+	GetDeletedByName(ctx context.Context, name string) (resp *entity.ChatModel, err error)
+	Restore(ctx context.Context, req *entity.ChatModel) (err error)
+	GetAll(ctx context.Context) (resp []entity.ChatModel, err error)
+}
+
+type defaultChatModelRepo struct {
+	db *gorm.DB
+}
+
+func NewChatModelRepository(db *gorm.DB) ChatModelRepository {
+	return &defaultChatModelRepo{db}
+}
+
+func (s *defaultChatModelRepo) Create(ctx context.Context, req *entity.ChatModel) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultChatModelRepo) Update(ctx context.Context, req *entity.ChatModel) (err error) {
+	err = s.db.WithContext(ctx).Save(req).Error
+	return
+}
+
+func (s *defaultChatModelRepo) Delete(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.ChatModel{}, "id = ?", id).Error
+	return
+}
+
+func (s *defaultChatModelRepo) GetById(ctx context.Context, id int) (resp *entity.ChatModel, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
+
+func (s *defaultChatModelRepo) GetByName(ctx context.Context, name string) (resp *entity.ChatModel, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "name = ?", name).Error
+	return
+}
+
+// GetDeletedByName returns the deleted model of the name, which still holds
+// the name in the unique index
+func (s *defaultChatModelRepo) GetDeletedByName(ctx context.Context, name string) (resp *entity.ChatModel, err error) {
+	err = s.db.WithContext(ctx).Unscoped().Take(&resp, "name = ? AND deleted_at IS NOT NULL", name).Error
+	return
+}
+
+// Restore saves a deleted model as not deleted
+func (s *defaultChatModelRepo) Restore(ctx context.Context, req *entity.ChatModel) (err error) {
+	req.DeletedAt = gorm.DeletedAt{}
+	err = s.db.WithContext(ctx).Unscoped().Save(req).Error
+	return
+}
+
+func (s *defaultChatModelRepo) GetAll(ctx context.Context) (resp []entity.ChatModel, err error) {
+	err = s.db.WithContext(ctx).Order("name ASC").Find(&resp).Error
+	return
+}
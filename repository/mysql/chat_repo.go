@@ -2,27 +2,73 @@ package mysql
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/utils/envelope"
 	"github.com/fadilahonespot/chatbot/utils/paginate"
 	"gorm.io/gorm"
 )
 
+// searchScanLimit is the number of latest messages searched in when messages
+// are encrypted and cannot be searched in the database
+const searchScanLimit = 1000
+
 type ChatRepository interface {
 	BeginsTrans() *gorm.DB
 	Commit(tx *gorm.DB) error
 	Rollback(tx *gorm.DB) error
 	Create(ctx context.Context, tx *gorm.DB, req *entity.Chat) (err error) 
 	GetByUserId(ctx context.Context, userId int) (resp []entity.Chat, err error)
-	GetHistoryChatByUserId(ctx context.Context, userId int) (resp []entity.Chat, err error) 
+	GetHistoryChatByUserId(ctx context.Context, userId int, filter HistoryFilter) (resp []entity.Chat, err error)
+	GetById(ctx context.Context, id int) (resp *entity.Chat, err error)
+	SetStarred(ctx context.Context, id int, starred bool) (err error)
+	Delete(ctx context.Context, id int) (err error)
+	DeleteByConversationId(ctx context.Context, conversationId int) (err error)
+	GetByConversationId(ctx context.Context, conversationId int) (resp []entity.Chat, err error)
+	SearchByUserId(ctx context.Context, userId int, query string, limit int) (resp []entity.Chat, err error)
+	CreateToolCall(ctx context.Context, tx *gorm.DB, req *entity.ToolCall) (err error)
+	CreateAttachment(ctx context.Context, tx *gorm.DB, req *entity.ChatAttachment) (err error)
+	GetAttachmentById(ctx context.Context, id int) (resp *entity.ChatAttachment, err error)
+	Find(ctx context.Context, filter ChatFilter) (resp []entity.Chat, err error)
+	GetExternalIds(ctx context.Context, conversationId int) (resp []string, err error)
+	CountOutdatedKey(ctx context.Context) (total int64, err error)
+	FindOutdatedKey(ctx context.Context, afterId, limit int) (resp []entity.Chat, err error)
+	Reencrypt(ctx context.Context, chat *entity.Chat) (err error)
+	CountExpired(ctx context.Context, userId int, before time.Time) (total int64, err error)
+	FindExpired(ctx context.Context, userId int, before time.Time, limit int) (resp []entity.Chat, err error)
+	PurgeChats(ctx context.Context, ids []int) (err error)
+	AnonymizeChats(ctx context.Context, ids []int) (err error)
+}
+
+// ChatFilter selects the messages of a user. Zero fields are not filtered on,
+// To is exclusive and MaxId inclusive.
+type ChatFilter struct {
+	UserId         int
+	ConversationId int
+	MaxId          int
+	From           time.Time
+	To             time.Time
+	Limit          int
+}
+
+// HistoryFilter narrows the history of a user. Zero fields are not filtered on.
+type HistoryFilter struct {
+	ConversationId int
+	Tag            string
+	Starred        bool
 }
 
 type defaultChatRepo struct {
-	db *gorm.DB
+	db        *gorm.DB
+	encryptor *envelope.Encryptor
 }
 
-func NewChatRepository(db *gorm.DB) ChatRepository {
-	return &defaultChatRepo{db}
+// NewChatRepository creates a chat repository that stores messages encrypted
+// by the encryptor and decrypts them when they are read
+func NewChatRepository(db *gorm.DB, encryptor *envelope.Encryptor) ChatRepository {
+	return &defaultChatRepo{db, encryptor}
 }
 
 func (s *defaultChatRepo) BeginsTrans() *gorm.DB {
@@ -37,8 +83,17 @@ func (s *defaultChatRepo) Rollback(tx *gorm.DB) error {
     return tx.Rollback().Error
 }
 
+// Create stores the message encrypted, leaving it readable in req
 func (s *defaultChatRepo) Create(ctx context.Context, tx *gorm.DB, req *entity.Chat) (err error) {
+	message := req.Message
+	req.Message, req.KeyID, err = s.encryptor.Encrypt(ctx, message)
+	if err != nil {
+		req.Message = message
+		return
+	}
+
 	err = tx.WithContext(ctx).Create(req).Error
+	req.Message = message
 	return
 }
 
@@ -46,12 +101,365 @@ func (s *defaultChatRepo) GetByUserId(ctx context.Context, userId int) (resp []e
 	err = s.db.WithContext(ctx).
 		Scopes(paginate.Paginate(1, 20)).
 		Find(&resp, "user_id = ?", userId).Error
+	if err != nil {
+		return
+	}
+
+	err = s.decryptChats(ctx, resp)
 	return
 }
 
-func (s *defaultChatRepo) GetHistoryChatByUserId(ctx context.Context, userId int) (resp []entity.Chat, err error) {
+func (s *defaultChatRepo) GetHistoryChatByUserId(ctx context.Context, userId int, filter HistoryFilter) (resp []entity.Chat, err error) {
+	query := s.db.WithContext(ctx).Where("user_id = ?", userId)
+	if filter.ConversationId != 0 {
+		query = query.Where("conversation_id = ?", filter.ConversationId)
+	}
+	if filter.Tag != "" {
+		query = query.Where("conversation_id IN (?)", s.db.Model(&entity.ConversationTag{}).
+			Select("conversation_id").
+			Where("user_id = ? AND name = ?", userId, filter.Tag))
+	}
+	if filter.Starred {
+		query = query.Where("starred = ?", true)
+	}
+
+	err = query.
+		Scopes(paginate.Paginate(1, 20)).Order("id DESC").
+		Preload("ToolCalls").Preload("Attachments").
+		Find(&resp).Error
+	if err != nil {
+		return
+	}
+
+	err = s.decryptChats(ctx, resp)
+	return
+}
+
+func (s *defaultChatRepo) GetById(ctx context.Context, id int) (resp *entity.Chat, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	if err != nil {
+		return
+	}
+
+	resp.Message, err = s.encryptor.Decrypt(ctx, resp.Message)
+	return
+}
+
+func (s *defaultChatRepo) SetStarred(ctx context.Context, id int, starred bool) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.Chat{}).Where("id = ?", id).Update("starred", starred).Error
+	return
+}
+
+// GetByConversationId returns the latest messages of a conversation in the
+// order they were written.
+func (s *defaultChatRepo) GetByConversationId(ctx context.Context, conversationId int) (resp []entity.Chat, err error) {
 	err = s.db.WithContext(ctx).
 		Scopes(paginate.Paginate(1, 20)).Order("id DESC").
-		Find(&resp, "user_id = ?", userId).Error
+		Find(&resp, "conversation_id = ?", conversationId).Error
+	if err != nil {
+		return
+	}
+	for i, j := 0, len(resp)-1; i < j; i, j = i+1, j-1 {
+		resp[i], resp[j] = resp[j], resp[i]
+	}
+
+	err = s.decryptChats(ctx, resp)
+	return
+}
+
+// SearchByUserId returns the latest messages of the user containing the query.
+// Encrypted messages are searched in memory, among the latest messages only.
+func (s *defaultChatRepo) SearchByUserId(ctx context.Context, userId int, query string, limit int) (resp []entity.Chat, err error) {
+	if !s.encryptor.Enabled() {
+		err = s.db.WithContext(ctx).
+			Scopes(paginate.Paginate(1, limit)).Order("id DESC").
+			Find(&resp, "user_id = ? AND message LIKE ?", userId, "%"+query+"%").Error
+		return
+	}
+
+	var chats []entity.Chat
+	err = s.db.WithContext(ctx).
+		Scopes(paginate.Paginate(1, searchScanLimit)).Order("id DESC").
+		Find(&chats, "user_id = ?", userId).Error
+	if err != nil {
+		return
+	}
+	err = s.decryptChats(ctx, chats)
+	if err != nil {
+		return
+	}
+
+	query = strings.ToLower(query)
+	for i := 0; i < len(chats) && len(resp) < limit; i++ {
+		if strings.Contains(strings.ToLower(chats[i].Message), query) {
+			resp = append(resp, chats[i])
+		}
+	}
+	return
+}
+
+// CreateToolCall stores the arguments and result encrypted, as they can hold
+// earlier messages, leaving them readable in req
+func (s *defaultChatRepo) CreateToolCall(ctx context.Context, tx *gorm.DB, req *entity.ToolCall) (err error) {
+	arguments, result := req.Arguments, req.Result
+	req.Arguments, _, err = s.encryptor.Encrypt(ctx, arguments)
+	if err == nil {
+		req.Result, _, err = s.encryptor.Encrypt(ctx, result)
+	}
+	if err == nil {
+		err = tx.WithContext(ctx).Create(req).Error
+	}
+	req.Arguments, req.Result = arguments, result
+	return
+}
+
+// CreateAttachment stores the transcript encrypted, leaving it readable in req
+func (s *defaultChatRepo) CreateAttachment(ctx context.Context, tx *gorm.DB, req *entity.ChatAttachment) (err error) {
+	transcript := req.Transcript
+	req.Transcript, _, err = s.encryptor.Encrypt(ctx, transcript)
+	if err == nil {
+		err = tx.WithContext(ctx).Create(req).Error
+	}
+	req.Transcript = transcript
+	return
+}
+
+func (s *defaultChatRepo) GetAttachmentById(ctx context.Context, id int) (resp *entity.ChatAttachment, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	if err != nil {
+		return
+	}
+
+	resp.Transcript, err = s.encryptor.Decrypt(ctx, resp.Transcript)
+	return
+}
+
+// Find returns the messages matching the filter in the order they were written.
+func (s *defaultChatRepo) Find(ctx context.Context, filter ChatFilter) (resp []entity.Chat, err error) {
+	query := s.db.WithContext(ctx).Where("user_id = ?", filter.UserId)
+	if filter.ConversationId != 0 {
+		query = query.Where("conversation_id = ?", filter.ConversationId)
+	}
+	if filter.MaxId != 0 {
+		query = query.Where("id <= ?", filter.MaxId)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	err = query.Order("id ASC").Find(&resp).Error
+	if err != nil {
+		return
+	}
+
+	err = s.decryptChats(ctx, resp)
+	return
+}
+
+// GetExternalIds returns the source ids of the messages imported into the
+// conversation.
+func (s *defaultChatRepo) GetExternalIds(ctx context.Context, conversationId int) (resp []string, err error) {
+	err = s.db.WithContext(ctx).Model(&entity.Chat{}).
+		Where("conversation_id = ? AND external_id <> ''", conversationId).
+		Pluck("external_id", &resp).Error
+	return
+}
+
+func (s *defaultChatRepo) Delete(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.Chat{}, "id = ?", id).Error
+	return
+}
+
+func (s *defaultChatRepo) DeleteByConversationId(ctx context.Context, conversationId int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.Chat{}, "conversation_id = ?", conversationId).Error
+	return
+}
+
+// outdatedKey selects the messages not encrypted under the current master
+// key and not anonymized. Messages written before the columns were added may
+// still hold NULL in them.
+const outdatedKey = "(key_id IS NULL OR key_id <> ?) AND (anonymized IS NULL OR anonymized = ?)"
+
+// CountOutdatedKey counts the messages, deleted ones included, that are not
+// encrypted under the current master key
+func (s *defaultChatRepo) CountOutdatedKey(ctx context.Context) (total int64, err error) {
+	err = s.db.WithContext(ctx).Unscoped().Model(&entity.Chat{}).
+		Where(outdatedKey, s.encryptor.CurrentKeyID(), false).
+		Count(&total).Error
+	return
+}
+
+// FindOutdatedKey returns the next messages after afterId, deleted ones
+// included, that are not encrypted under the current master key, with their
+// tool calls and attachments
+func (s *defaultChatRepo) FindOutdatedKey(ctx context.Context, afterId, limit int) (resp []entity.Chat, err error) {
+	err = s.db.WithContext(ctx).Unscoped().
+		Preload("ToolCalls").Preload("Attachments").
+		Where("id > ?", afterId).Where(outdatedKey, s.encryptor.CurrentKeyID(), false).
+		Order("id ASC").Limit(limit).
+		Find(&resp).Error
+	if err != nil {
+		return
+	}
+
+	err = s.decryptChats(ctx, resp)
+	return
+}
+
+// Reencrypt stores the message of the chat, with the tool calls and
+// attachments loaded with it, encrypted under the current master key, leaving
+// its timestamps as they are
+func (s *defaultChatRepo) Reencrypt(ctx context.Context, chat *entity.Chat) (err error) {
+	message, keyId, err := s.encryptor.Encrypt(ctx, chat.Message)
+	if err != nil {
+		return
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, toolCall := range chat.ToolCalls {
+			arguments, _, err := s.encryptor.Encrypt(ctx, toolCall.Arguments)
+			if err != nil {
+				return err
+			}
+			result, _, err := s.encryptor.Encrypt(ctx, toolCall.Result)
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&entity.ToolCall{}).Where("id = ?", toolCall.ID).UpdateColumns(map[string]interface{}{
+				"arguments": arguments,
+				"result":    result,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, attachment := range chat.Attachments {
+			transcript, _, err := s.encryptor.Encrypt(ctx, attachment.Transcript)
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&entity.ChatAttachment{}).Where("id = ?", attachment.ID).UpdateColumn("transcript", transcript).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Model(&entity.Chat{}).Where("id = ?", chat.ID).UpdateColumns(map[string]interface{}{
+			"message": message,
+			"key_id":  keyId,
+		}).Error
+	})
+	if err != nil {
+		return
+	}
+
+	chat.KeyID = keyId
+	return
+}
+
+// CountExpired counts the messages of the user, deleted ones included, written
+// before the given time and not anonymized yet
+func (s *defaultChatRepo) CountExpired(ctx context.Context, userId int, before time.Time) (total int64, err error) {
+	err = s.db.WithContext(ctx).Unscoped().Model(&entity.Chat{}).
+		Where("user_id = ? AND created_at < ? AND (anonymized IS NULL OR anonymized = ?)", userId, before, false).
+		Count(&total).Error
+	return
+}
+
+// FindExpired returns the oldest messages of the user, deleted ones included,
+// written before the given time and not anonymized yet, with their
+// attachments. The messages are not decrypted.
+func (s *defaultChatRepo) FindExpired(ctx context.Context, userId int, before time.Time, limit int) (resp []entity.Chat, err error) {
+	err = s.db.WithContext(ctx).Unscoped().
+		Preload("Attachments").
+		Where("user_id = ? AND created_at < ? AND (anonymized IS NULL OR anonymized = ?)", userId, before, false).
+		Order("id ASC").Limit(limit).
+		Find(&resp).Error
+	return
+}
+
+// PurgeChats hard deletes the messages with their tool calls and attachments,
+// and empties the content of their moderation flags
+func (s *defaultChatRepo) PurgeChats(ctx context.Context, ids []int) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := removeChatContent(tx, ids); err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&entity.Chat{}, "id IN ?", ids).Error
+	})
+	return
+}
+
+// AnonymizeChats empties the messages, keeping the rows for statistics, and
+// removes their tool calls, attachments and the content of their moderation
+// flags
+func (s *defaultChatRepo) AnonymizeChats(ctx context.Context, ids []int) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := removeChatContent(tx, ids); err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&entity.Chat{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+			"message":     "",
+			"key_id":      "",
+			"name":        "",
+			"external_id": "",
+			"anonymized":  true,
+		}).Error
+	})
+	return
+}
+
+// removeChatContent deletes what is kept next to the messages
+func removeChatContent(tx *gorm.DB, ids []int) (err error) {
+	err = tx.Delete(&entity.ToolCall{}, "chat_id IN ?", ids).Error
+	if err != nil {
+		return
+	}
+
+	err = tx.Delete(&entity.ChatAttachment{}, "chat_id IN ?", ids).Error
+	if err != nil {
+		return
+	}
+
+	err = tx.Model(&entity.ModerationFlag{}).Where("chat_id IN ?", ids).UpdateColumn("content", "").Error
+	return
+}
+
+// decryptChats decrypts the messages in place, with the tool calls and
+// attachments loaded with them
+func (s *defaultChatRepo) decryptChats(ctx context.Context, chats []entity.Chat) (err error) {
+	for i := 0; i < len(chats); i++ {
+		chats[i].Message, err = s.encryptor.Decrypt(ctx, chats[i].Message)
+		if err != nil {
+			return
+		}
+
+		for j := 0; j < len(chats[i].ToolCalls); j++ {
+			toolCall := &chats[i].ToolCalls[j]
+			toolCall.Arguments, err = s.encryptor.Decrypt(ctx, toolCall.Arguments)
+			if err != nil {
+				return
+			}
+			toolCall.Result, err = s.encryptor.Decrypt(ctx, toolCall.Result)
+			if err != nil {
+				return
+			}
+		}
+
+		for j := 0; j < len(chats[i].Attachments); j++ {
+			attachment := &chats[i].Attachments[j]
+			attachment.Transcript, err = s.encryptor.Decrypt(ctx, attachment.Transcript)
+			if err != nil {
+				return
+			}
+		}
+	}
 	return
 }
@@ -0,0 +1,133 @@
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fadilahonespot/chatbot/utils/envelope"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// newMockDB returns a MySQL gorm connection whose queries are answered by
+// the sqlmock
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	conn, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	return db, mock
+}
+
+func newTestEncryptor(t *testing.T) *envelope.Encryptor {
+	provider, err := envelope.NewKeyring(map[string][]byte{"k1": bytes.Repeat([]byte{1}, envelope.MasterKeySize)}, "k1")
+	if err != nil {
+		t.Fatalf("envelope.NewKeyring() error = %v", err)
+	}
+	return envelope.NewEncryptor(provider)
+}
+
+func Test_defaultChatRepo_FindOutdatedKey(t *testing.T) {
+	db, mock := newMockDB(t)
+	s := NewChatRepository(db, newTestEncryptor(t))
+
+	// a message written before the key_id and anonymized columns were added
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `chats` WHERE id > ? AND ((key_id IS NULL OR key_id <> ?) AND (anonymized IS NULL OR anonymized = ?)) ORDER BY id ASC LIMIT 10")).
+		WithArgs(0, "k1", false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "message", "key_id", "anonymized"}).
+			AddRow(1, 7, "resep nasi goreng", nil, nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `chat_attachments` WHERE `chat_attachments`.`chat_id` = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `tool_calls` WHERE `tool_calls`.`chat_id` = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	got, err := s.FindOutdatedKey(context.Background(), 0, 10)
+	if err != nil {
+		t.Fatalf("defaultChatRepo.FindOutdatedKey() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 1 || got[0].Message != "resep nasi goreng" {
+		t.Errorf("defaultChatRepo.FindOutdatedKey() = %+v, want the message with NULL columns", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet queries: %v", err)
+	}
+}
+
+func Test_defaultChatRepo_CountOutdatedKey(t *testing.T) {
+	db, mock := newMockDB(t)
+	s := NewChatRepository(db, newTestEncryptor(t))
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `chats` WHERE (key_id IS NULL OR key_id <> ?) AND (anonymized IS NULL OR anonymized = ?)")).
+		WithArgs("k1", false).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(3))
+
+	got, err := s.CountOutdatedKey(context.Background())
+	if err != nil {
+		t.Fatalf("defaultChatRepo.CountOutdatedKey() error = %v", err)
+	}
+	if got != 3 {
+		t.Errorf("defaultChatRepo.CountOutdatedKey() = %v, want 3", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet queries: %v", err)
+	}
+}
+
+func Test_defaultChatRepo_FindExpired(t *testing.T) {
+	db, mock := newMockDB(t)
+	s := NewChatRepository(db, newTestEncryptor(t))
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// a message written before the anonymized column was added
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `chats` WHERE user_id = ? AND created_at < ? AND (anonymized IS NULL OR anonymized = ?) ORDER BY id ASC LIMIT 10")).
+		WithArgs(7, before, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "message", "anonymized"}).
+			AddRow(1, 7, "resep nasi goreng", nil))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `chat_attachments` WHERE `chat_attachments`.`chat_id` = ?")).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	got, err := s.FindExpired(context.Background(), 7, before, 10)
+	if err != nil {
+		t.Fatalf("defaultChatRepo.FindExpired() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != 1 {
+		t.Errorf("defaultChatRepo.FindExpired() = %+v, want the message with a NULL column", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet queries: %v", err)
+	}
+}
+
+func Test_defaultChatRepo_CountExpired(t *testing.T) {
+	db, mock := newMockDB(t)
+	s := NewChatRepository(db, newTestEncryptor(t))
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `chats` WHERE user_id = ? AND created_at < ? AND (anonymized IS NULL OR anonymized = ?)")).
+		WithArgs(7, before, false).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(2))
+
+	got, err := s.CountExpired(context.Background(), 7, before)
+	if err != nil {
+		t.Fatalf("defaultChatRepo.CountExpired() error = %v", err)
+	}
+	if got != 2 {
+		t.Errorf("defaultChatRepo.CountExpired() = %v, want 2", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet queries: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type CollectionRepository interface {
+	Create(ctx context.Context, req *entity.Collection) (err error)
+	Update(ctx context.Context, req *entity.Collection) (err error)
+	Delete(ctx context.Context, id int) (err error)
+	GetById(ctx context.Context, id int) (resp *entity.Collection, err error)
+	GetAccessible(ctx context.Context, userId int, teamIds []int) (resp []entity.Collection, err error)
+	GetByEmbeddingModelNot(ctx context.Context, model string) (resp []entity.Collection, err error)
+	CreateEmbeddingJob(ctx context.Context, req *entity.EmbeddingJob) (err error)
+	UpdateEmbeddingJob(ctx context.Context, req *entity.EmbeddingJob) (err error)
+	GetEmbeddingJobById(ctx context.Context, id int) (resp *entity.EmbeddingJob, err error)
+}
+
+type defaultCollectionRepo struct {
+	db *gorm.DB
+}
+
+func NewCollectionRepository(db *gorm.DB) CollectionRepository {
+	return &defaultCollectionRepo{db}
+}
+
+func (s *defaultCollectionRepo) Create(ctx context.Context, req *entity.Collection) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultCollectionRepo) Update(ctx context.Context, req *entity.Collection) (err error) {
+	err = s.db.WithContext(ctx).Save(req).Error
+	return
+}
+
+// Delete removes the collection together with its documents and chunks.
+func (s *defaultCollectionRepo) Delete(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entity.DocumentChunk{}, "collection_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.Document{}, "collection_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.ConversationCollection{}, "collection_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.Collection{}, "id = ?", id).Error
+	})
+	return
+}
+
+func (s *defaultCollectionRepo) GetById(ctx context.Context, id int) (resp *entity.Collection, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
+
+// GetAccessible returns the personal collections of the user and the
+// collections of the given teams.
+func (s *defaultCollectionRepo) GetAccessible(ctx context.Context, userId int, teamIds []int) (resp []entity.Collection, err error) {
+	query := s.db.WithContext(ctx).Where("team_id = 0 AND user_id = ?", userId)
+	if len(teamIds) > 0 {
+		query = query.Or("team_id IN ?", teamIds)
+	}
+	err = query.Order("name ASC").Find(&resp).Error
+	return
+}
+
+// GetByEmbeddingModelNot returns the collections embedded with another model.
+func (s *defaultCollectionRepo) GetByEmbeddingModelNot(ctx context.Context, model string) (resp []entity.Collection, err error) {
+	err = s.db.WithContext(ctx).Find(&resp, "embedding_model <> ?", model).Error
+	return
+}
+
+func (s *defaultCollectionRepo) CreateEmbeddingJob(ctx context.Context, req *entity.EmbeddingJob) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultCollectionRepo) UpdateEmbeddingJob(ctx context.Context, req *entity.EmbeddingJob) (err error) {
+	err = s.db.WithContext(ctx).Save(req).Error
+	return
+}
+
+func (s *defaultCollectionRepo) GetEmbeddingJobById(ctx context.Context, id int) (resp *entity.EmbeddingJob, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
@@ -0,0 +1,172 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type ConversationRepository interface {
+	Create(ctx context.Context, req *entity.Conversation) (err error)
+	GetById(ctx context.Context, id int) (resp *entity.Conversation, err error)
+	GetLatestByUserId(ctx context.Context, userId int) (resp *entity.Conversation, err error)
+	Find(ctx context.Context, filter ConversationFilter) (resp []entity.Conversation, err error)
+	GetByExternalId(ctx context.Context, userId int, source, externalId string) (resp *entity.Conversation, err error)
+	UpdateTitle(ctx context.Context, id int, title string) (err error)
+	SetPinned(ctx context.Context, id int, pinned bool) (err error)
+	SetContextStart(ctx context.Context, id, chatId int) (err error)
+	SetTags(ctx context.Context, userId, conversationId int, tags []string) (err error)
+	GetTags(ctx context.Context, conversationIds []int) (resp []entity.ConversationTag, err error)
+	GetTagNames(ctx context.Context, userId int) (resp []string, err error)
+	SetGeneratedTitle(ctx context.Context, id int, title string) (err error)
+	SetCollectionIds(ctx context.Context, conversationId int, collectionIds []int) (err error)
+	GetCollectionIds(ctx context.Context, conversationId int) (resp []int, err error)
+}
+
+// ConversationFilter selects the conversations of a user. Zero fields are not
+// filtered on.
+type ConversationFilter struct {
+	UserId int
+	Tag    string
+	Pinned bool
+}
+
+type defaultConversationRepo struct {
+	db *gorm.DB
+}
+
+func NewConversationRepository(db *gorm.DB) ConversationRepository {
+	return &defaultConversationRepo{db}
+}
+
+func (s *defaultConversationRepo) Create(ctx context.Context, req *entity.Conversation) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultConversationRepo) GetById(ctx context.Context, id int) (resp *entity.Conversation, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
+
+func (s *defaultConversationRepo) GetLatestByUserId(ctx context.Context, userId int) (resp *entity.Conversation, err error) {
+	err = s.db.WithContext(ctx).Order("id DESC").Take(&resp, "user_id = ?", userId).Error
+	return
+}
+
+// Find returns the conversations matching the filter, pinned ones first and
+// then the latest first.
+func (s *defaultConversationRepo) Find(ctx context.Context, filter ConversationFilter) (resp []entity.Conversation, err error) {
+	query := s.db.WithContext(ctx).Where("user_id = ?", filter.UserId)
+	if filter.Tag != "" {
+		query = query.Where("id IN (?)", s.db.Model(&entity.ConversationTag{}).
+			Select("conversation_id").
+			Where("user_id = ? AND name = ?", filter.UserId, filter.Tag))
+	}
+	if filter.Pinned {
+		query = query.Where("pinned = ?", true)
+	}
+
+	err = query.Order("pinned DESC, id DESC").Find(&resp).Error
+	return
+}
+
+// GetByExternalId returns the conversation of the user imported from the
+// source with the given id.
+func (s *defaultConversationRepo) GetByExternalId(ctx context.Context, userId int, source, externalId string) (resp *entity.Conversation, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "user_id = ? AND source = ? AND external_id = ?", userId, source, externalId).Error
+	return
+}
+
+func (s *defaultConversationRepo) UpdateTitle(ctx context.Context, id int, title string) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.Conversation{}).Where("id = ?", id).Update("title", title).Error
+	return
+}
+
+func (s *defaultConversationRepo) SetPinned(ctx context.Context, id int, pinned bool) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.Conversation{}).Where("id = ?", id).Update("pinned", pinned).Error
+	return
+}
+
+func (s *defaultConversationRepo) SetContextStart(ctx context.Context, id, chatId int) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.Conversation{}).Where("id = ?", id).Update("context_start_id", chatId).Error
+	return
+}
+
+// SetTags replaces the tags of the conversation.
+func (s *defaultConversationRepo) SetTags(ctx context.Context, userId, conversationId int, tags []string) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entity.ConversationTag{}, "conversation_id = ?", conversationId).Error; err != nil {
+			return err
+		}
+
+		var rows []entity.ConversationTag
+		for i := 0; i < len(tags); i++ {
+			rows = append(rows, entity.ConversationTag{
+				UserID:         userId,
+				ConversationID: conversationId,
+				Name:           tags[i],
+			})
+		}
+		if len(rows) > 0 {
+			return tx.Create(&rows).Error
+		}
+		return nil
+	})
+	return
+}
+
+func (s *defaultConversationRepo) GetTags(ctx context.Context, conversationIds []int) (resp []entity.ConversationTag, err error) {
+	if len(conversationIds) == 0 {
+		return
+	}
+
+	err = s.db.WithContext(ctx).Order("name ASC").Find(&resp, "conversation_id IN ?", conversationIds).Error
+	return
+}
+
+// GetTagNames returns every tag the user has put on a conversation.
+func (s *defaultConversationRepo) GetTagNames(ctx context.Context, userId int) (resp []string, err error) {
+	err = s.db.WithContext(ctx).Model(&entity.ConversationTag{}).
+		Where("user_id = ?", userId).
+		Distinct().Order("name ASC").
+		Pluck("name", &resp).Error
+	return
+}
+
+// SetGeneratedTitle sets the title only while the conversation has none, so a
+// title set by the user is never replaced by a generated one.
+func (s *defaultConversationRepo) SetGeneratedTitle(ctx context.Context, id int, title string) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.Conversation{}).Where("id = ? AND title = ''", id).Update("title", title).Error
+	return
+}
+
+// SetCollectionIds replaces the collections attached to the conversation.
+func (s *defaultConversationRepo) SetCollectionIds(ctx context.Context, conversationId int, collectionIds []int) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&entity.ConversationCollection{}, "conversation_id = ?", conversationId).Error; err != nil {
+			return err
+		}
+
+		var rows []entity.ConversationCollection
+		for i := 0; i < len(collectionIds); i++ {
+			rows = append(rows, entity.ConversationCollection{
+				ConversationID: conversationId,
+				CollectionID:   collectionIds[i],
+			})
+		}
+		if len(rows) > 0 {
+			return tx.Create(&rows).Error
+		}
+		return nil
+	})
+	return
+}
+
+func (s *defaultConversationRepo) GetCollectionIds(ctx context.Context, conversationId int) (resp []int, err error) {
+	err = s.db.WithContext(ctx).Model(&entity.ConversationCollection{}).
+		Where("conversation_id = ?", conversationId).
+		Pluck("collection_id", &resp).Error
+	return
+}
@@ -0,0 +1,61 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type DocumentRepository interface {
+	Create(ctx context.Context, req *entity.Document) (err error)
+	Update(ctx context.Context, req *entity.Document) (err error)
+	Delete(ctx context.Context, id int) (err error)
+	GetById(ctx context.Context, id int) (resp *entity.Document, err error)
+	GetByUserId(ctx context.Context, userId int) (resp []entity.Document, err error)
+	GetByCollectionId(ctx context.Context, collectionId int) (resp []entity.Document, err error)
+	CreateVersion(ctx context.Context, req *entity.DocumentVersion) (err error)
+}
+
+type defaultDocumentRepo struct {
+	db *gorm.DB
+}
+
+func NewDocumentRepository(db *gorm.DB) DocumentRepository {
+	return &defaultDocumentRepo{db}
+}
+
+func (s *defaultDocumentRepo) Create(ctx context.Context, req *entity.Document) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultDocumentRepo) Update(ctx context.Context, req *entity.Document) (err error) {
+	err = s.db.WithContext(ctx).Save(req).Error
+	return
+}
+
+func (s *defaultDocumentRepo) Delete(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.Document{}, "id = ?", id).Error
+	return
+}
+
+func (s *defaultDocumentRepo) GetById(ctx context.Context, id int) (resp *entity.Document, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
+
+func (s *defaultDocumentRepo) GetByUserId(ctx context.Context, userId int) (resp []entity.Document, err error) {
+	err = s.db.WithContext(ctx).Order("id DESC").Find(&resp, "user_id = ? AND collection_id = 0", userId).Error
+	return
+}
+
+func (s *defaultDocumentRepo) GetByCollectionId(ctx context.Context, collectionId int) (resp []entity.Document, err error) {
+	err = s.db.WithContext(ctx).Order("name ASC").Find(&resp, "collection_id = ?", collectionId).Error
+	return
+}
+
+func (s *defaultDocumentRepo) CreateVersion(ctx context.Context, req *entity.DocumentVersion) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type EncryptionRepository interface {
+	CreateJob(ctx context.Context, req *entity.EncryptionJob) (err error)
+	UpdateJob(ctx context.Context, req *entity.EncryptionJob) (err error)
+	GetJobById(ctx context.Context, id int) (resp *entity.EncryptionJob, err error)
+}
+
+type defaultEncryptionRepo struct {
+	db *gorm.DB
+}
+
+func NewEncryptionRepository(db *gorm.DB) EncryptionRepository {
+	return &defaultEncryptionRepo{db}
+}
+
+func (s *defaultEncryptionRepo) CreateJob(ctx context.Context, req *entity.EncryptionJob) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultEncryptionRepo) UpdateJob(ctx context.Context, req *entity.EncryptionJob) (err error) {
+	err = s.db.WithContext(ctx).Save(req).Error
+	return
+}
+
+func (s *defaultEncryptionRepo) GetJobById(ctx context.Context, id int) (resp *entity.EncryptionJob, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
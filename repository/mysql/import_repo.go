@@ -0,0 +1,37 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type ImportRepository interface {
+	CreateJob(ctx context.Context, req *entity.ImportJob) (err error)
+	UpdateJob(ctx context.Context, req *entity.ImportJob) (err error)
+	GetJobById(ctx context.Context, id int) (resp *entity.ImportJob, err error)
+}
+
+type defaultImportRepo struct {
+	db *gorm.DB
+}
+
+func NewImportRepository(db *gorm.DB) ImportRepository {
+	return &defaultImportRepo{db}
+}
+
+func (s *defaultImportRepo) CreateJob(ctx context.Context, req *entity.ImportJob) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultImportRepo) UpdateJob(ctx context.Context, req *entity.ImportJob) (err error) {
+	err = s.db.WithContext(ctx).Save(req).Error
+	return
+}
+
+func (s *defaultImportRepo) GetJobById(ctx context.Context, id int) (resp *entity.ImportJob, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
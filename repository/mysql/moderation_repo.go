@@ -0,0 +1,117 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/utils/envelope"
+	"github.com/fadilahonespot/chatbot/utils/paginate"
+	"gorm.io/gorm"
+)
+
+// ModerationFilter narrows the flags listed in the review queue. Empty fields
+// are not filtered on.
+type ModerationFilter struct {
+	Status    string
+	Direction string
+	UserId    int
+	Page      int
+	Limit     int
+}
+
+type ModerationRepository interface {
+	CreateFlag(ctx context.Context, req *entity.ModerationFlag) (err error)
+	GetFlagById(ctx context.Context, id int) (resp *entity.ModerationFlag, err error)
+	FindFlags(ctx context.Context, filter ModerationFilter) (resp []entity.ModerationFlag, total int64, err error)
+	UpdateFlag(ctx context.Context, req *entity.ModerationFlag) (err error)
+	RemoveContent(ctx context.Context, userId int, before time.Time) (err error)
+}
+
+type defaultModerationRepo struct {
+	db        *gorm.DB
+	encryptor *envelope.Encryptor
+}
+
+// NewModerationRepository creates a moderation repository that stores the
+// flagged content encrypted by the encryptor, like the messages
+func NewModerationRepository(db *gorm.DB, encryptor *envelope.Encryptor) ModerationRepository {
+	return &defaultModerationRepo{db, encryptor}
+}
+
+// CreateFlag stores the content encrypted, leaving it readable in req
+func (s *defaultModerationRepo) CreateFlag(ctx context.Context, req *entity.ModerationFlag) (err error) {
+	content := req.Content
+	req.Content, _, err = s.encryptor.Encrypt(ctx, content)
+	if err == nil {
+		err = s.db.WithContext(ctx).Create(req).Error
+	}
+	req.Content = content
+	return
+}
+
+func (s *defaultModerationRepo) GetFlagById(ctx context.Context, id int) (resp *entity.ModerationFlag, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	if err != nil {
+		return
+	}
+
+	resp.Content, err = s.encryptor.Decrypt(ctx, resp.Content)
+	return
+}
+
+// FindFlags returns a page of flags, the oldest first so the queue is
+// reviewed in order, with the number of flags matching the filter
+func (s *defaultModerationRepo) FindFlags(ctx context.Context, filter ModerationFilter) (resp []entity.ModerationFlag, total int64, err error) {
+	query := s.db.WithContext(ctx).Model(&entity.ModerationFlag{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Direction != "" {
+		query = query.Where("direction = ?", filter.Direction)
+	}
+	if filter.UserId != 0 {
+		query = query.Where("user_id = ?", filter.UserId)
+	}
+
+	err = query.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	err = query.Scopes(paginate.Paginate(filter.Page, filter.Limit)).Order("id ASC").Find(&resp).Error
+	if err != nil {
+		return
+	}
+
+	for i := 0; i < len(resp); i++ {
+		resp[i].Content, err = s.encryptor.Decrypt(ctx, resp[i].Content)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateFlag saves the flag with its content encrypted again, leaving it
+// readable in req
+func (s *defaultModerationRepo) UpdateFlag(ctx context.Context, req *entity.ModerationFlag) (err error) {
+	content := req.Content
+	req.Content, _, err = s.encryptor.Encrypt(ctx, content)
+	if err == nil {
+		err = s.db.WithContext(ctx).Save(req).Error
+	}
+	req.Content = content
+	return
+}
+
+// RemoveContent empties the content of the flags of the user raised before
+// the given time, keeping the flags for the review statistics. The flags of
+// blocked questions belong to no message, so they are not emptied with the
+// messages.
+func (s *defaultModerationRepo) RemoveContent(ctx context.Context, userId int, before time.Time) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.ModerationFlag{}).
+		Where("user_id = ? AND created_at < ? AND content <> ?", userId, before, "").
+		UpdateColumn("content", "").Error
+	return
+}
@@ -0,0 +1,32 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func Test_defaultModerationRepo_RemoveContent(t *testing.T) {
+	db, mock := newMockDB(t)
+	s := NewModerationRepository(db, newTestEncryptor(t))
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// the flags of blocked questions have no message, so only the user and
+	// the age select them
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `moderation_flags` SET `content`=? WHERE user_id = ? AND created_at < ? AND content <> ?")).
+		WithArgs("", 7, before, "").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err := s.RemoveContent(context.Background(), 7, before)
+	if err != nil {
+		t.Fatalf("defaultModerationRepo.RemoveContent() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet queries: %v", err)
+	}
+}
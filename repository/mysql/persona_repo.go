@@ -0,0 +1,94 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type PersonaRepository interface {
+	Create(ctx context.Context, req *entity.Persona) (err error)
+	Update(ctx context.Context, req *entity.Persona) (err error)
+	Delete(ctx context.Context, id int) (err error)
+	GetById(ctx context.Context, id int) (resp *entity.Persona, err error)
+	GetAll(ctx context.Context) (resp []entity.Persona, err error)
+	GetPrompt(ctx context.Context, personaId, version int) (resp *entity.PersonaPrompt, err error)
+}
+
+type defaultPersonaRepo struct {
+	db *gorm.DB
+}
+
+func NewPersonaRepository(db *gorm.DB) PersonaRepository {
+	return &defaultPersonaRepo{db}
+}
+
+// Create stores the persona together with its allowed users and the first
+// version of its system prompt.
+func (s *defaultPersonaRepo) Create(ctx context.Context, req *entity.Persona) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(req).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&entity.PersonaPrompt{
+			PersonaID:    req.ID,
+			Version:      req.PromptVersion,
+			SystemPrompt: req.SystemPrompt,
+		}).Error
+	})
+	return
+}
+
+// Update saves the persona, replaces its allowed users and records the
+// system prompt when its version has not been stored yet.
+func (s *defaultPersonaRepo) Update(ctx context.Context, req *entity.Persona) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("AllowedUsers").Save(req).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&entity.PersonaUser{}, "persona_id = ?", req.ID).Error; err != nil {
+			return err
+		}
+
+		for i := range req.AllowedUsers {
+			req.AllowedUsers[i].ID = 0
+			req.AllowedUsers[i].PersonaID = req.ID
+		}
+		if len(req.AllowedUsers) > 0 {
+			if err := tx.Create(&req.AllowedUsers).Error; err != nil {
+				return err
+			}
+		}
+
+		prompt := entity.PersonaPrompt{
+			PersonaID:    req.ID,
+			Version:      req.PromptVersion,
+			SystemPrompt: req.SystemPrompt,
+		}
+		return tx.Where("persona_id = ? AND version = ?", req.ID, req.PromptVersion).FirstOrCreate(&prompt).Error
+	})
+	return
+}
+
+func (s *defaultPersonaRepo) Delete(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.Persona{}, "id = ?", id).Error
+	return
+}
+
+func (s *defaultPersonaRepo) GetById(ctx context.Context, id int) (resp *entity.Persona, err error) {
+	err = s.db.WithContext(ctx).Preload("AllowedUsers").Take(&resp, "id = ?", id).Error
+	return
+}
+
+func (s *defaultPersonaRepo) GetAll(ctx context.Context) (resp []entity.Persona, err error) {
+	err = s.db.WithContext(ctx).Preload("AllowedUsers").Order("name ASC").Find(&resp).Error
+	return
+}
+
+func (s *defaultPersonaRepo) GetPrompt(ctx context.Context, personaId, version int) (resp *entity.PersonaPrompt, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "persona_id = ? AND version = ?", personaId, version).Error
+	return
+}
@@ -0,0 +1,70 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"gorm.io/gorm"
+)
+
+type PromptTemplateRepository interface {
+	Create(ctx context.Context, req *entity.PromptTemplate) (err error)
+	Update(ctx context.Context, req *entity.PromptTemplate) (err error)
+	Delete(ctx context.Context, id int) (err error)
+	GetById(ctx context.Context, id int) (resp *entity.PromptTemplate, err error)
+	GetAccessibleByUserId(ctx context.Context, userId int) (resp []entity.PromptTemplate, err error)
+}
+
+type defaultPromptTemplateRepo struct {
+	db *gorm.DB
+}
+
+func NewPromptTemplateRepository(db *gorm.DB) PromptTemplateRepository {
+	return &defaultPromptTemplateRepo{db}
+}
+
+func (s *defaultPromptTemplateRepo) Create(ctx context.Context, req *entity.PromptTemplate) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+// Update saves the template and replaces its variables.
+func (s *defaultPromptTemplateRepo) Update(ctx context.Context, req *entity.PromptTemplate) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Variables").Save(req).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&entity.PromptTemplateVariable{}, "prompt_template_id = ?", req.ID).Error; err != nil {
+			return err
+		}
+
+		for i := range req.Variables {
+			req.Variables[i].ID = 0
+			req.Variables[i].PromptTemplateID = req.ID
+		}
+		if len(req.Variables) > 0 {
+			return tx.Create(&req.Variables).Error
+		}
+		return nil
+	})
+	return
+}
+
+func (s *defaultPromptTemplateRepo) Delete(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.PromptTemplate{}, "id = ?", id).Error
+	return
+}
+
+func (s *defaultPromptTemplateRepo) GetById(ctx context.Context, id int) (resp *entity.PromptTemplate, err error) {
+	err = s.db.WithContext(ctx).Preload("Variables").Take(&resp, "id = ?", id).Error
+	return
+}
+
+// GetAccessibleByUserId returns the templates owned by the user and the ones shared by others.
+func (s *defaultPromptTemplateRepo) GetAccessibleByUserId(ctx context.Context, userId int) (resp []entity.PromptTemplate, err error) {
+	err = s.db.WithContext(ctx).Preload("Variables").Order("name ASC").
+		Find(&resp, "user_id = ? OR visibility = ?", userId, constrans.VisibilityShared).Error
+	return
+}
@@ -0,0 +1,67 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type RetentionRepository interface {
+	CreatePolicy(ctx context.Context, req *entity.RetentionPolicy) (err error)
+	UpdatePolicy(ctx context.Context, req *entity.RetentionPolicy) (err error)
+	DeletePolicy(ctx context.Context, id int) (err error)
+	GetPolicyById(ctx context.Context, id int) (resp *entity.RetentionPolicy, err error)
+	GetPolicies(ctx context.Context) (resp []entity.RetentionPolicy, err error)
+	CreateReport(ctx context.Context, req *entity.PurgeReport) (err error)
+	UpdateReport(ctx context.Context, req *entity.PurgeReport) (err error)
+	GetReportById(ctx context.Context, id int) (resp *entity.PurgeReport, err error)
+}
+
+type defaultRetentionRepo struct {
+	db *gorm.DB
+}
+
+func NewRetentionRepository(db *gorm.DB) RetentionRepository {
+	return &defaultRetentionRepo{db}
+}
+
+func (s *defaultRetentionRepo) CreatePolicy(ctx context.Context, req *entity.RetentionPolicy) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultRetentionRepo) UpdatePolicy(ctx context.Context, req *entity.RetentionPolicy) (err error) {
+	err = s.db.WithContext(ctx).Save(req).Error
+	return
+}
+
+func (s *defaultRetentionRepo) DeletePolicy(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.RetentionPolicy{}, "id = ?", id).Error
+	return
+}
+
+func (s *defaultRetentionRepo) GetPolicyById(ctx context.Context, id int) (resp *entity.RetentionPolicy, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
+
+func (s *defaultRetentionRepo) GetPolicies(ctx context.Context) (resp []entity.RetentionPolicy, err error) {
+	err = s.db.WithContext(ctx).Order("id ASC").Find(&resp).Error
+	return
+}
+
+func (s *defaultRetentionRepo) CreateReport(ctx context.Context, req *entity.PurgeReport) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultRetentionRepo) UpdateReport(ctx context.Context, req *entity.PurgeReport) (err error) {
+	err = s.db.WithContext(ctx).Save(req).Error
+	return
+}
+
+func (s *defaultRetentionRepo) GetReportById(ctx context.Context, id int) (resp *entity.PurgeReport, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
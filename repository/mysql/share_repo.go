@@ -0,0 +1,61 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type ShareRepository interface {
+	Create(ctx context.Context, req *entity.ShareLink) (err error)
+	GetById(ctx context.Context, id int) (resp *entity.ShareLink, err error)
+	GetByToken(ctx context.Context, token string) (resp *entity.ShareLink, err error)
+	GetByUserId(ctx context.Context, userId int) (resp []entity.ShareLink, err error)
+	Revoke(ctx context.Context, id int) (err error)
+	AddView(ctx context.Context, id int) (err error)
+}
+
+type defaultShareRepo struct {
+	db *gorm.DB
+}
+
+func NewShareRepository(db *gorm.DB) ShareRepository {
+	return &defaultShareRepo{db}
+}
+
+func (s *defaultShareRepo) Create(ctx context.Context, req *entity.ShareLink) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultShareRepo) GetById(ctx context.Context, id int) (resp *entity.ShareLink, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
+
+func (s *defaultShareRepo) GetByToken(ctx context.Context, token string) (resp *entity.ShareLink, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "token = ?", token).Error
+	return
+}
+
+func (s *defaultShareRepo) GetByUserId(ctx context.Context, userId int) (resp []entity.ShareLink, err error) {
+	err = s.db.WithContext(ctx).Order("id DESC").Find(&resp, "user_id = ?", userId).Error
+	return
+}
+
+func (s *defaultShareRepo) Revoke(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.ShareLink{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", time.Now()).Error
+	return
+}
+
+// AddView counts a view of the link in the database, so views from
+// concurrent requests are not lost.
+func (s *defaultShareRepo) AddView(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.ShareLink{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"views":          gorm.Expr("views + 1"),
+		"last_viewed_at": time.Now(),
+	}).Error
+	return
+}
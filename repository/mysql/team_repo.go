@@ -0,0 +1,77 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type TeamRepository interface {
+	Create(ctx context.Context, req *entity.Team) (err error)
+	Update(ctx context.Context, req *entity.Team) (err error)
+	Delete(ctx context.Context, id int) (err error)
+	GetById(ctx context.Context, id int) (resp *entity.Team, err error)
+	GetAll(ctx context.Context) (resp []entity.Team, err error)
+	GetByUserId(ctx context.Context, userId int) (resp []entity.Team, err error)
+}
+
+type defaultTeamRepo struct {
+	db *gorm.DB
+}
+
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &defaultTeamRepo{db}
+}
+
+func (s *defaultTeamRepo) Create(ctx context.Context, req *entity.Team) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+// Update saves the team and replaces its members.
+func (s *defaultTeamRepo) Update(ctx context.Context, req *entity.Team) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Save(req).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&entity.TeamMember{}, "team_id = ?", req.ID).Error; err != nil {
+			return err
+		}
+
+		for i := range req.Members {
+			req.Members[i].ID = 0
+			req.Members[i].TeamID = req.ID
+		}
+		if len(req.Members) > 0 {
+			return tx.Create(&req.Members).Error
+		}
+		return nil
+	})
+	return
+}
+
+func (s *defaultTeamRepo) Delete(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.Team{}, "id = ?", id).Error
+	return
+}
+
+func (s *defaultTeamRepo) GetById(ctx context.Context, id int) (resp *entity.Team, err error) {
+	err = s.db.WithContext(ctx).Preload("Members").Take(&resp, "id = ?", id).Error
+	return
+}
+
+func (s *defaultTeamRepo) GetAll(ctx context.Context) (resp []entity.Team, err error) {
+	err = s.db.WithContext(ctx).Preload("Members").Order("name ASC").Find(&resp).Error
+	return
+}
+
+// GetByUserId returns the teams the user is a member of.
+func (s *defaultTeamRepo) GetByUserId(ctx context.Context, userId int) (resp []entity.Team, err error) {
+	err = s.db.WithContext(ctx).
+		Preload("Members").Order("name ASC").
+		Where("id IN (?)", s.db.Model(&entity.TeamMember{}).Select("team_id").Where("user_id = ?", userId)).
+		Find(&resp).Error
+	return
+}
@@ -2,8 +2,10 @@ package mysql
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
 	"gorm.io/gorm"
 )
 
@@ -11,6 +13,10 @@ type UserRepository interface {
 	Create(ctx context.Context, req *entity.User) (err error)
 	GetUserById(ctx context.Context, id int) (resp *entity.User, err error)
 	GetUserByEmail(ctx context.Context, email string) (resp *entity.User, err error)
+	FindUnscoped(ctx context.Context, afterId, limit int) (resp []entity.User, err error)
+	Purge(ctx context.Context, id int) (err error)
+	Anonymize(ctx context.Context, id int) (err error)
+	UpdateRole(ctx context.Context, id int, role string) (err error)
 }
 
 type defultUserRepo struct {
@@ -35,3 +41,84 @@ func (s *defultUserRepo) GetUserByEmail(ctx context.Context, email string) (resp
 	err = s.db.WithContext(ctx).Take(&resp, "email = ?", email).Error
 	return
 }
+
+func (s *defultUserRepo) UpdateRole(ctx context.Context, id int, role string) (err error) {
+	err = s.db.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).Update("role", role).Error
+	return
+}
+
+// FindUnscoped returns the next users after afterId, deleted ones included
+func (s *defultUserRepo) FindUnscoped(ctx context.Context, afterId, limit int) (resp []entity.User, err error) {
+	err = s.db.WithContext(ctx).Unscoped().
+		Where("id > ?", afterId).
+		Order("id ASC").Limit(limit).
+		Find(&resp).Error
+	return
+}
+
+// Purge hard deletes the user with their conversations, share links,
+// memberships, webhooks, channel identities, moderation flags, import jobs and
+// own documents. The messages are purged before.
+func (s *defultUserRepo) Purge(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&entity.ConversationTag{}, &entity.ShareLink{}, &entity.TeamMember{}, &entity.PersonaUser{}, &entity.ChannelIdentity{}, &entity.ModerationFlag{}, &entity.ImportJob{}} {
+			if err := tx.Delete(model, "user_id = ?", id).Error; err != nil {
+				return err
+			}
+		}
+		// the documents the user uploaded for themselves, the ones of
+		// collections belong to the collection
+		documents := tx.Unscoped().Model(&entity.Document{}).Select("id").Where("user_id = ? AND collection_id = ?", id, 0)
+		if err := tx.Delete(&entity.DocumentChunk{}, "document_id IN (?)", documents).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.DocumentVersion{}, "document_id IN (?)", documents).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&entity.Document{}, "user_id = ? AND collection_id = ?", id, 0).Error; err != nil {
+			return err
+		}
+		subscriptions := tx.Model(&entity.WebhookSubscription{}).Select("id").Where("user_id = ?", id)
+		deliveries := tx.Model(&entity.WebhookDelivery{}).Select("id").Where("subscription_id IN (?)", subscriptions)
+		if err := tx.Delete(&entity.WebhookAttempt{}, "delivery_id IN (?)", deliveries).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.WebhookDelivery{}, "subscription_id IN (?)", subscriptions).Error; err != nil {
+			return err
+		}
+		// the events about the user queued for the subscriptions of admins
+		// to all users
+		about := tx.Model(&entity.WebhookDelivery{}).Select("id").Where("user_id = ?", id)
+		if err := tx.Delete(&entity.WebhookAttempt{}, "delivery_id IN (?)", about).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.WebhookDelivery{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.WebhookSubscription{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Delete(&entity.Conversation{}, "user_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&entity.User{}, "id = ?", id).Error
+	})
+	return
+}
+
+// Anonymize replaces the email, name and password of the user, and empties
+// the titles of their conversations
+func (s *defultUserRepo) Anonymize(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Model(&entity.Conversation{}).Where("user_id = ?", id).UpdateColumn("title", "").Error
+		if err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&entity.User{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+			"email":    fmt.Sprintf("deleted-%v@%v", id, constrans.AnonymizedEmailDomain),
+			"name":     "",
+			"password": "",
+		}).Error
+	})
+	return
+}
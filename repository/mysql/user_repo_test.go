@@ -0,0 +1,43 @@
+package mysql
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func Test_defultUserRepo_Purge(t *testing.T) {
+	db, mock := newMockDB(t)
+	s := NewUserRepository(db)
+
+	mock.ExpectBegin()
+	for _, table := range []string{"conversation_tags", "share_links", "team_members", "persona_users", "channel_identities", "moderation_flags", "import_jobs"} {
+		mock.ExpectExec(regexp.QuoteMeta("`"+table+"`") + ".*user_id = ?").
+			WithArgs(7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	// only the documents the user uploaded for themselves
+	for _, table := range []string{"document_chunks", "document_versions"} {
+		mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `"+table+"` WHERE document_id IN (SELECT `id` FROM `documents` WHERE user_id = ? AND collection_id = ?)")).
+			WithArgs(7, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `documents` WHERE user_id = ? AND collection_id = ?")).
+		WithArgs(7, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	for _, table := range []string{"webhook_attempts", "webhook_deliveries", "webhook_attempts", "webhook_deliveries", "webhook_subscriptions", "conversations", "users"} {
+		mock.ExpectExec(regexp.QuoteMeta("`" + table + "`")).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+
+	err := s.Purge(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("defultUserRepo.Purge() error = %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("unmet queries: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package mysql
+
+import (
+	"context"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/envelope"
+	"github.com/fadilahonespot/chatbot/utils/paginate"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, req *entity.WebhookSubscription) (err error)
+	UpdateSubscription(ctx context.Context, req *entity.WebhookSubscription) (err error)
+	DeleteSubscription(ctx context.Context, id int) (err error)
+	GetSubscriptionById(ctx context.Context, id int) (resp *entity.WebhookSubscription, err error)
+	GetSubscriptionsByUserId(ctx context.Context, userId int) (resp []entity.WebhookSubscription, err error)
+	GetActiveSubscriptions(ctx context.Context, userId int) (resp []entity.WebhookSubscription, err error)
+	CreateDeliveries(ctx context.Context, req []entity.WebhookDelivery) (err error)
+	ClaimDeliveries(ctx context.Context, lease time.Duration, limit int) (resp []entity.WebhookDelivery, err error)
+	UpdateDelivery(ctx context.Context, req *entity.WebhookDelivery) (err error)
+	CreateAttempt(ctx context.Context, req *entity.WebhookAttempt) (err error)
+	FindDeliveries(ctx context.Context, subscriptionId, page, limit int) (resp []entity.WebhookDelivery, total int64, err error)
+	GetDeliveryById(ctx context.Context, id int) (resp *entity.WebhookDelivery, err error)
+	DeleteUserDeliveries(ctx context.Context, userId int, before time.Time) (err error)
+	PruneDeliveries(ctx context.Context, before time.Time) (total int64, err error)
+}
+
+type defaultWebhookRepo struct {
+	db        *gorm.DB
+	encryptor *envelope.Encryptor
+}
+
+// NewWebhookRepository creates a webhook repository that stores the payloads
+// of the deliveries encrypted by the encryptor and decrypts them when they are
+// read
+func NewWebhookRepository(db *gorm.DB, encryptor *envelope.Encryptor) WebhookRepository {
+	return &defaultWebhookRepo{db, encryptor}
+}
+
+func (s *defaultWebhookRepo) CreateSubscription(ctx context.Context, req *entity.WebhookSubscription) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+func (s *defaultWebhookRepo) UpdateSubscription(ctx context.Context, req *entity.WebhookSubscription) (err error) {
+	err = s.db.WithContext(ctx).Save(req).Error
+	return
+}
+
+// DeleteSubscription deletes the subscription with its deliveries and their
+// attempts
+func (s *defaultWebhookRepo) DeleteSubscription(ctx context.Context, id int) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&entity.WebhookDelivery{}).Select("id").Where("subscription_id = ?", id)
+		if err := tx.Delete(&entity.WebhookAttempt{}, "delivery_id IN (?)", deliveries).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&entity.WebhookDelivery{}, "subscription_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.WebhookSubscription{}, "id = ?", id).Error
+	})
+	return
+}
+
+func (s *defaultWebhookRepo) GetSubscriptionById(ctx context.Context, id int) (resp *entity.WebhookSubscription, err error) {
+	err = s.db.WithContext(ctx).Take(&resp, "id = ?", id).Error
+	return
+}
+
+func (s *defaultWebhookRepo) GetSubscriptionsByUserId(ctx context.Context, userId int) (resp []entity.WebhookSubscription, err error) {
+	err = s.db.WithContext(ctx).Order("id DESC").Find(&resp, "user_id = ?", userId).Error
+	return
+}
+
+// GetActiveSubscriptions returns the active subscriptions that get the events
+// of the user: their own and the ones made for all users
+func (s *defaultWebhookRepo) GetActiveSubscriptions(ctx context.Context, userId int) (resp []entity.WebhookSubscription, err error) {
+	err = s.db.WithContext(ctx).
+		Where("active = ? AND (user_id = ? OR all_users = ?)", true, userId, true).
+		Find(&resp).Error
+	return
+}
+
+// CreateDeliveries stores the payloads encrypted, leaving them readable in req
+func (s *defaultWebhookRepo) CreateDeliveries(ctx context.Context, req []entity.WebhookDelivery) (err error) {
+	payloads := make([]string, len(req))
+	defer func() {
+		for i := range req {
+			req[i].Payload = payloads[i]
+		}
+	}()
+	for i := range req {
+		payloads[i] = req[i].Payload
+		req[i].Payload, _, err = s.encryptor.Encrypt(ctx, payloads[i])
+		if err != nil {
+			return
+		}
+	}
+
+	err = s.db.WithContext(ctx).Create(&req).Error
+	return
+}
+
+// ClaimDeliveries returns the pending deliveries that are due, the longest
+// waiting first, and moves their next attempt past the lease so no other
+// instance claims them while they are sent. Rows claimed by another instance
+// at the same time are skipped.
+func (s *defaultWebhookRepo) ClaimDeliveries(ctx context.Context, lease time.Duration, limit int) (resp []entity.WebhookDelivery, err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", constrans.DeliveryPending, now).
+			Order("next_attempt_at ASC").Limit(limit).
+			Find(&resp).Error
+		if err != nil || len(resp) == 0 {
+			return err
+		}
+
+		ids := make([]int, len(resp))
+		for i := range resp {
+			ids[i] = resp[i].ID
+			resp[i].NextAttemptAt = now.Add(lease)
+		}
+		return tx.Model(&entity.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return
+	}
+
+	for i := range resp {
+		resp[i].Payload, err = s.encryptor.Decrypt(ctx, resp[i].Payload)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// UpdateDelivery saves the delivery without its payload, which is never
+// changed
+func (s *defaultWebhookRepo) UpdateDelivery(ctx context.Context, req *entity.WebhookDelivery) (err error) {
+	err = s.db.WithContext(ctx).Omit("AttemptLog", "Payload").Save(req).Error
+	return
+}
+
+func (s *defaultWebhookRepo) CreateAttempt(ctx context.Context, req *entity.WebhookAttempt) (err error) {
+	err = s.db.WithContext(ctx).Create(req).Error
+	return
+}
+
+// FindDeliveries returns a page of the deliveries of a subscription without
+// their payloads, the latest first, with the number of its deliveries
+func (s *defaultWebhookRepo) FindDeliveries(ctx context.Context, subscriptionId, page, limit int) (resp []entity.WebhookDelivery, total int64, err error) {
+	query := s.db.WithContext(ctx).Model(&entity.WebhookDelivery{}).Where("subscription_id = ?", subscriptionId)
+	err = query.Count(&total).Error
+	if err != nil {
+		return
+	}
+
+	err = query.Omit("Payload").Order("id DESC").Scopes(paginate.Paginate(page, limit)).Find(&resp).Error
+	return
+}
+
+// GetDeliveryById returns the delivery with its attempts
+func (s *defaultWebhookRepo) GetDeliveryById(ctx context.Context, id int) (resp *entity.WebhookDelivery, err error) {
+	err = s.db.WithContext(ctx).Preload("AttemptLog", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).Take(&resp, "id = ?", id).Error
+	if err != nil {
+		return
+	}
+
+	resp.Payload, err = s.encryptor.Decrypt(ctx, resp.Payload)
+	return
+}
+
+// DeleteUserDeliveries deletes the deliveries of the events about the user
+// queued before the given time, with their attempts, whatever subscription
+// they are for
+func (s *defaultWebhookRepo) DeleteUserDeliveries(ctx context.Context, userId int, before time.Time) (err error) {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&entity.WebhookDelivery{}).Select("id").Where("user_id = ? AND created_at < ?", userId, before)
+		if err := tx.Delete(&entity.WebhookAttempt{}, "delivery_id IN (?)", deliveries).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.WebhookDelivery{}, "user_id = ? AND created_at < ?", userId, before).Error
+	})
+	return
+}
+
+// PruneDeliveries deletes the deliveries that were delivered or given up on
+// before the given time, with their attempts
+func (s *defaultWebhookRepo) PruneDeliveries(ctx context.Context, before time.Time) (total int64, err error) {
+	done := []string{constrans.DeliveryDelivered, constrans.DeliveryFailed}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		deliveries := tx.Model(&entity.WebhookDelivery{}).Select("id").Where("status IN ? AND updated_at < ?", done, before)
+		if err := tx.Delete(&entity.WebhookAttempt{}, "delivery_id IN (?)", deliveries).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&entity.WebhookDelivery{}, "status IN ? AND updated_at < ?", done, before)
+		total = result.RowsAffected
+		return result.Error
+	})
+	return
+}
@@ -0,0 +1,10 @@
+package storage
+
+import "context"
+
+// BlobStorage keeps binary files such as uploaded images under a key.
+type BlobStorage interface {
+	Put(ctx context.Context, key string, data []byte) (err error)
+	Get(ctx context.Context, key string) (data []byte, err error)
+	Delete(ctx context.Context, key string) (err error)
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type localStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates a blob storage on the local filesystem. Files are
+// kept in BLOB_STORAGE_PATH, which defaults to ./storage.
+func NewLocalStorage() BlobStorage {
+	dir := os.Getenv("BLOB_STORAGE_PATH")
+	if dir == "" {
+		dir = "./storage"
+	}
+	return &localStorage{dir: dir}
+}
+
+func (s *localStorage) Put(ctx context.Context, key string, data []byte) (err error) {
+	path, err := s.path(key)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return
+	}
+
+	err = os.WriteFile(path, data, 0o644)
+	return
+}
+
+func (s *localStorage) Get(ctx context.Context, key string) (data []byte, err error) {
+	path, err := s.path(key)
+	if err != nil {
+		return
+	}
+
+	data, err = os.ReadFile(path)
+	return
+}
+
+func (s *localStorage) Delete(ctx context.Context, key string) (err error) {
+	path, err := s.path(key)
+	if err != nil {
+		return
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+	}
+	return
+}
+
+// path returns the file of the key, refusing keys that leave the storage directory
+func (s *localStorage) path(key string) (path string, err error) {
+	clean := filepath.Clean("/" + key)
+	if strings.Contains(key, "..") || clean == "/" {
+		err = errors.New("invalid storage key")
+		return
+	}
+
+	path = filepath.Join(s.dir, clean)
+	return
+}
@@ -0,0 +1,106 @@
+package vectorstore
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"gorm.io/gorm"
+)
+
+type databaseStore struct {
+	db *gorm.DB
+}
+
+// NewDatabaseStore creates a vector store that keeps the chunks in the
+// database and compares the query with every chunk of the user. It needs no
+// extra infrastructure and is fast enough for a few thousand chunks per user.
+func NewDatabaseStore(db *gorm.DB) VectorStore {
+	return &databaseStore{db}
+}
+
+func (s *databaseStore) Upsert(ctx context.Context, chunks []entity.DocumentChunk) (err error) {
+	if len(chunks) == 0 {
+		return
+	}
+	err = s.db.WithContext(ctx).Omit("Document").Save(&chunks).Error
+	return
+}
+
+func (s *databaseStore) Search(ctx context.Context, filter SearchFilter, embedding []float32, topK int) (resp []SearchResult, err error) {
+	if filter.UserId == 0 && len(filter.CollectionIds) == 0 {
+		return
+	}
+
+	scope := s.db.Where("document_chunks.collection_id = 0 AND document_chunks.user_id = ?", filter.UserId)
+	if len(filter.CollectionIds) > 0 {
+		scope = scope.Or("document_chunks.collection_id IN ?", filter.CollectionIds)
+	}
+
+	var chunks []entity.DocumentChunk
+	err = s.db.WithContext(ctx).
+		Joins("Document").
+		Where(scope).
+		Find(&chunks, "document_chunks.embedding_model = ?", filter.EmbeddingModel).Error
+	if err != nil {
+		return
+	}
+
+	for i := 0; i < len(chunks); i++ {
+		resp = append(resp, SearchResult{
+			Chunk: chunks[i],
+			Score: CosineSimilarity(embedding, chunks[i].Embedding),
+		})
+	}
+
+	sort.SliceStable(resp, func(i, j int) bool {
+		return resp[i].Score > resp[j].Score
+	})
+	if len(resp) > topK {
+		resp = resp[:topK]
+	}
+	return
+}
+
+func (s *databaseStore) GetByDocumentId(ctx context.Context, documentId int) (resp []entity.DocumentChunk, err error) {
+	err = s.db.WithContext(ctx).Order("chunk_index ASC").Find(&resp, "document_id = ?", documentId).Error
+	return
+}
+
+func (s *databaseStore) GetByCollectionId(ctx context.Context, collectionId int) (resp []entity.DocumentChunk, err error) {
+	err = s.db.WithContext(ctx).Order("id ASC").Find(&resp, "collection_id = ?", collectionId).Error
+	return
+}
+
+func (s *databaseStore) DeleteByIds(ctx context.Context, ids []int) (err error) {
+	if len(ids) == 0 {
+		return
+	}
+	err = s.db.WithContext(ctx).Delete(&entity.DocumentChunk{}, "id IN ?", ids).Error
+	return
+}
+
+func (s *databaseStore) DeleteByDocumentId(ctx context.Context, documentId int) (err error) {
+	err = s.db.WithContext(ctx).Delete(&entity.DocumentChunk{}, "document_id = ?", documentId).Error
+	return
+}
+
+// CosineSimilarity returns the cosine of the angle between two vectors, or 0
+// when their dimensions differ
+func CosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := 0; i < len(a); i++ {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,33 @@
+package vectorstore
+
+import (
+	"context"
+
+	"github.com/fadilahonespot/chatbot/entity"
+)
+
+// VectorStore keeps the embedded document chunks and finds the ones closest
+// to a query embedding.
+type VectorStore interface {
+	Upsert(ctx context.Context, chunks []entity.DocumentChunk) (err error)
+	Search(ctx context.Context, filter SearchFilter, embedding []float32, topK int) (resp []SearchResult, err error)
+	GetByDocumentId(ctx context.Context, documentId int) (resp []entity.DocumentChunk, err error)
+	GetByCollectionId(ctx context.Context, collectionId int) (resp []entity.DocumentChunk, err error)
+	DeleteByIds(ctx context.Context, ids []int) (err error)
+	DeleteByDocumentId(ctx context.Context, documentId int) (err error)
+}
+
+// SearchFilter limits a search to the user's own uploads and the given
+// collections. Only chunks embedded with EmbeddingModel are compared, since
+// vectors of different models cannot be compared with each other.
+type SearchFilter struct {
+	UserId         int
+	CollectionIds  []int
+	EmbeddingModel string
+}
+
+// SearchResult is a chunk with its similarity to the query.
+type SearchResult struct {
+	Chunk entity.DocumentChunk
+	Score float32
+}
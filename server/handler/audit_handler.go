@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/paginate"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type AuditHandler struct {
+	auditUsecase usecase.AuditUsecase
+}
+
+func NewAuditHandler(auditUsecase usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{
+		auditUsecase: auditUsecase,
+	}
+}
+
+// AuditLog returns a page of the audit log, narrowed by the actorId, action,
+// targetType, targetId, requestId, from and to queries
+func (h *AuditHandler) AuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	params := paginate.GetParams(query)
+	filter := dto.AuditFilter{
+		ActorId:    cast.ToInt(query.Get("actorId")),
+		Action:     query.Get("action"),
+		TargetType: query.Get("targetType"),
+		TargetId:   cast.ToInt(query.Get("targetId")),
+		RequestId:  query.Get("requestId"),
+		From:       query.Get("from"),
+		To:         query.Get("to"),
+		Page:       params.Page,
+		Limit:      params.Limit,
+	}
+	resp, err := h.auditUsecase.GetAuditLogs(r.Context(), filter)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
+
+// VerifyAuditLog checks the hash chain of the audit log
+func (h *AuditHandler) VerifyAuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	resp, err := h.auditUsecase.VerifyChain(r.Context())
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/repository/http/channel"
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type ChannelHandler struct {
+	channelUsecase usecase.ChannelUsecase
+}
+
+func NewChannelHandler(channelUsecase usecase.ChannelUsecase) *ChannelHandler {
+	return &ChannelHandler{
+		channelUsecase: channelUsecase,
+	}
+}
+
+// Webhook returns the handler of the webhook requests of the channel. The
+// handshakes made when the webhook is set up are answered with their
+// challenge as plain text.
+func (h *ChannelHandler) Webhook(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+			response.ResponseError(w, err)
+			return
+		}
+
+		ctx := r.Context()
+		body, err := request.GetBodyFromContext(ctx)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		challenge, err := h.channelUsecase.HandleWebhook(ctx, name, channel.Request{
+			Method: r.Method,
+			Header: r.Header,
+			Query:  r.URL.Query(),
+			Body:   body,
+		})
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		if challenge != "" {
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte(challenge))
+			return
+		}
+		response.ResponseSuccess(w, nil)
+	}
+}
+
+// ChannelLink handles the requests for linking the users of messaging channels to a user
+func (h *ChannelHandler) ChannelLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing the users of the channels linked to the user
+		resp, err := h.channelUsecase.GetIdentities(ctx, userId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for creating a code that links the sender on a channel
+		resp, err := h.channelUsecase.CreateLink(ctx, userId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for unlinking the channel user given in the id query
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		err := h.channelUsecase.Unlink(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/fadilahonespot/chatbot/usecase"
 	"github.com/fadilahonespot/chatbot/usecase/dto"
@@ -13,12 +15,14 @@ import (
 )
 
 type ChatHandler struct {
-	chatUsecase usecase.ChatUsecase
+	chatUsecase    usecase.ChatUsecase
+	chatJobUsecase usecase.ChatJobUsecase
 }
 
-func NewChatHandler(chatUsecase usecase.ChatUsecase) *ChatHandler {
+func NewChatHandler(chatUsecase usecase.ChatUsecase, chatJobUsecase usecase.ChatJobUsecase) *ChatHandler {
 	return &ChatHandler{
-		chatUsecase: chatUsecase,
+		chatUsecase:    chatUsecase,
+		chatJobUsecase: chatJobUsecase,
 	}
 }
 
@@ -26,10 +30,15 @@ func NewChatHandler(chatUsecase usecase.ChatUsecase) *ChatHandler {
 func (h *ChatHandler) Chat(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
-		// Post method for chatting
+		// Post method for chatting, answered in the background when the async query is true
 		var req dto.ChatQuestionRequest
 		ctx := r.Context()
-		err := request.GetRequestFromContext(ctx, &req)
+		var err error
+		if request.IsMultipart(r) {
+			req, err = getMultipartQuestion(r)
+		} else {
+			err = request.GetRequestFromContext(ctx, &req)
+		}
 		if err != nil {
 			logger.Error(ctx, "failed get request", err.Error())
 			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
@@ -38,6 +47,17 @@ func (h *ChatHandler) Chat(w http.ResponseWriter, r *http.Request) {
 		}
 
 		userId := ctx.Value("userId")
+		if cast.ToBool(r.URL.Query().Get("async")) {
+			resp, err := h.chatJobUsecase.EnqueueChat(ctx, cast.ToInt(userId), req)
+			if err != nil {
+				response.ResponseError(w, err)
+				return
+			}
+
+			response.ResponseSuccess(w, resp)
+			return
+		}
+
 		resp, err := h.chatUsecase.ChatQuestion(ctx, cast.ToInt(userId), req)
 		if err != nil {
 			response.ResponseError(w, err)
@@ -47,11 +67,17 @@ func (h *ChatHandler) Chat(w http.ResponseWriter, r *http.Request) {
 		response.ResponseSuccess(w, resp)
 
 	case http.MethodGet:
-		// Get method for getting chat history
+		// Get method for getting chat history, narrowed by the conversationId, tag and starred queries
 		ctx := r.Context()
 
 		userId := ctx.Value("userId")
-		resp, err := h.chatUsecase.GetHistoryChat(ctx, cast.ToInt(userId))
+		query := r.URL.Query()
+		filter := dto.ChatHistoryFilter{
+			ConversationId: cast.ToInt(query.Get("conversationId")),
+			Tag:            query.Get("tag"),
+			Starred:        cast.ToBool(query.Get("starred")),
+		}
+		resp, err := h.chatUsecase.GetHistoryChat(ctx, cast.ToInt(userId), filter)
 		if err != nil {
 			response.ResponseError(w, err)
 			return
@@ -65,3 +91,202 @@ func (h *ChatHandler) Chat(w http.ResponseWriter, r *http.Request) {
 		response.ResponseError(w, err)
 	}
 }
+
+// ChatJob returns the chat job given in the path, /jobs/{id}
+func (h *ChatHandler) ChatJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	resp, err := h.chatJobUsecase.GetChatJob(ctx, cast.ToInt(userId), id)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
+
+// ChatVoice handles questions asked with an audio recording
+func (h *ChatHandler) ChatVoice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	req, err := getVoiceQuestion(r)
+	if err != nil {
+		logger.Error(ctx, "failed get request", err.Error())
+		err = errors.SetError(http.StatusBadRequest, "audio must be uploaded as multipart form data")
+		response.ResponseError(w, err)
+		return
+	}
+
+	userId := ctx.Value("userId")
+	resp, err := h.chatUsecase.VoiceQuestion(ctx, cast.ToInt(userId), req)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
+
+// ChatStar stars or unstars the message given in the id query
+func (h *ChatHandler) ChatStar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	var req dto.ChatStarRequest
+	err := request.GetRequestFromContext(ctx, &req)
+	if err != nil {
+		logger.Error(ctx, "failed get request", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		response.ResponseError(w, err)
+		return
+	}
+
+	userId := ctx.Value("userId")
+	id := cast.ToInt(r.URL.Query().Get("id"))
+	err = h.chatUsecase.StarChat(ctx, cast.ToInt(userId), id, req)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, nil)
+}
+
+// ChatAudio returns the recording of a voice question or its spoken answer
+func (h *ChatHandler) ChatAudio(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	id := cast.ToInt(r.URL.Query().Get("id"))
+	data, contentType, err := h.chatUsecase.GetChatAudio(ctx, cast.ToInt(userId), id)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// ChatImage returns an image attached to one of the user's questions
+func (h *ChatHandler) ChatImage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	id := cast.ToInt(r.URL.Query().Get("id"))
+	data, contentType, err := h.chatUsecase.GetChatImage(ctx, cast.ToInt(userId), id)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// getMultipartQuestion reads a question sent as a multipart form. The
+// request field holds the question as json, or the question field holds
+// only its text, and the images field holds the attached images.
+func getMultipartQuestion(r *http.Request) (req dto.ChatQuestionRequest, err error) {
+	form, err := request.GetMultipartFromContext(r, usecase.MaxImages*usecase.MaxImageSize)
+	if err != nil {
+		return
+	}
+
+	if values := form.Value["request"]; len(values) > 0 {
+		err = json.Unmarshal([]byte(values[0]), &req)
+		if err != nil {
+			return
+		}
+	} else if values := form.Value["question"]; len(values) > 0 {
+		req.Question = values[0]
+	}
+
+	files, err := request.ReadFiles(form, "images")
+	if err != nil {
+		return
+	}
+	for i := 0; i < len(files); i++ {
+		req.Images = append(req.Images, dto.ImageInput{
+			Name:    files[i].Name,
+			Content: files[i].Data,
+		})
+	}
+	return
+}
+
+// getVoiceQuestion reads a voice question sent as a multipart form. The audio
+// field holds the recording, the optional request field the other options as
+// json, and the speak field whether the answer should be spoken back.
+func getVoiceQuestion(r *http.Request) (req dto.VoiceQuestionRequest, err error) {
+	if !request.IsMultipart(r) {
+		err = http.ErrNotMultipart
+		return
+	}
+
+	form, err := request.GetMultipartFromContext(r, usecase.MaxAudioSize+usecase.MaxImages*usecase.MaxImageSize)
+	if err != nil {
+		return
+	}
+
+	if values := form.Value["request"]; len(values) > 0 {
+		err = json.Unmarshal([]byte(values[0]), &req)
+		if err != nil {
+			return
+		}
+	}
+	if values := form.Value["speak"]; len(values) > 0 {
+		req.Speak = cast.ToBool(values[0])
+	}
+
+	files, err := request.ReadFiles(form, "audio")
+	if err != nil {
+		return
+	}
+	if len(files) > 0 {
+		req.Audio = dto.AudioInput{
+			Name:    files[0].Name,
+			Content: files[0].Data,
+		}
+	}
+
+	images, err := request.ReadFiles(form, "images")
+	if err != nil {
+		return
+	}
+	for i := 0; i < len(images); i++ {
+		req.Images = append(req.Images, dto.ImageInput{
+			Name:    images[i].Name,
+			Content: images[i].Data,
+		})
+	}
+	return
+}
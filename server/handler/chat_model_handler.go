@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type ChatModelHandler struct {
+	chatModelUsecase usecase.ChatModelUsecase
+}
+
+func NewChatModelHandler(chatModelUsecase usecase.ChatModelUsecase) *ChatModelHandler {
+	return &ChatModelHandler{
+		chatModelUsecase: chatModelUsecase,
+	}
+}
+
+// ChatModel handles the admin requests for managing the model allowlist
+func (h *ChatModelHandler) ChatModel(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing all models
+		resp, err := h.chatModelUsecase.GetChatModels(ctx)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for adding a model
+		var req dto.ChatModelRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		resp, err := h.chatModelUsecase.CreateChatModel(ctx, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPut:
+		// Put method for updating the model given in the id query
+		var req dto.ChatModelRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.chatModelUsecase.UpdateChatModel(ctx, id, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for removing the model given in the id query
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		err := h.chatModelUsecase.DeleteChatModel(ctx, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// AvailableChatModel returns the models users can currently request
+func (h *ChatModelHandler) AvailableChatModel(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	resp, err := h.chatModelUsecase.GetEnabledChatModels(r.Context())
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
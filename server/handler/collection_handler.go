@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type CollectionHandler struct {
+	collectionUsecase usecase.CollectionUsecase
+}
+
+func NewCollectionHandler(collectionUsecase usecase.CollectionUsecase) *CollectionHandler {
+	return &CollectionHandler{
+		collectionUsecase: collectionUsecase,
+	}
+}
+
+// Collection handles the requests for managing knowledge base collections
+func (h *CollectionHandler) Collection(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+	id := cast.ToInt(r.URL.Query().Get("id"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing the collections of the user and the user's teams
+		resp, err := h.collectionUsecase.GetCollections(ctx, userId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost, http.MethodPut:
+		// Post method for creating a collection, put method for updating the collection given in the id query
+		var req dto.CollectionRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		var resp dto.CollectionResponse
+		if r.Method == http.MethodPost {
+			resp, err = h.collectionUsecase.CreateCollection(ctx, userId, req)
+		} else {
+			resp, err = h.collectionUsecase.UpdateCollection(ctx, userId, id, req)
+		}
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for deleting the collection given in the id query
+		err := h.collectionUsecase.DeleteCollection(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// CollectionDocument handles the requests for managing the documents of a collection
+func (h *CollectionHandler) CollectionDocument(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+	id := cast.ToInt(r.URL.Query().Get("id"))
+	collectionId := cast.ToInt(r.URL.Query().Get("collectionId"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing the documents of the collection given in the collectionId query
+		resp, err := h.collectionUsecase.GetCollectionDocuments(ctx, userId, collectionId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost, http.MethodPut:
+		// Post method for adding a document to the collection given in the collectionId query,
+		// put method for uploading a new version of the document given in the id query
+		file, err := request.GetFileFromContext(r, "file", usecase.MaxDocumentSize)
+		if err != nil {
+			logger.Error(ctx, "failed get file", err.Error())
+			err = errors.SetError(http.StatusBadRequest, "file is required")
+			response.ResponseError(w, err)
+			return
+		}
+
+		req := dto.DocumentUploadRequest{
+			Name:        file.Name,
+			ContentType: file.ContentType,
+			Data:        file.Data,
+		}
+		var resp dto.CollectionDocumentResponse
+		if r.Method == http.MethodPost {
+			resp, err = h.collectionUsecase.AddDocument(ctx, userId, collectionId, req)
+		} else {
+			resp, err = h.collectionUsecase.UpdateDocument(ctx, userId, id, req)
+		}
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for deleting the document given in the id query
+		err := h.collectionUsecase.DeleteDocument(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// EmbeddingJob handles the requests for re-embedding a collection
+func (h *CollectionHandler) EmbeddingJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for the progress of the job given in the id query
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.collectionUsecase.GetEmbeddingJob(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for re-embedding the collection given in the collectionId query
+		collectionId := cast.ToInt(r.URL.Query().Get("collectionId"))
+		resp, err := h.collectionUsecase.ReembedCollection(ctx, userId, collectionId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
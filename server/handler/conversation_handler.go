@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type ConversationHandler struct {
+	conversationUsecase usecase.ConversationUsecase
+}
+
+func NewConversationHandler(conversationUsecase usecase.ConversationUsecase) *ConversationHandler {
+	return &ConversationHandler{
+		conversationUsecase: conversationUsecase,
+	}
+}
+
+// Conversation handles the requests for the user's conversations
+func (h *ConversationHandler) Conversation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+	id := cast.ToInt(r.URL.Query().Get("id"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing the conversations, narrowed by the tag and pinned queries
+		filter := dto.ConversationFilter{
+			Tag:    r.URL.Query().Get("tag"),
+			Pinned: cast.ToBool(r.URL.Query().Get("pinned")),
+		}
+		resp, err := h.conversationUsecase.GetConversations(ctx, userId, filter)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPut:
+		// Put method for renaming the conversation given in the id query
+		var req dto.ConversationTitleRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		resp, err := h.conversationUsecase.UpdateConversationTitle(ctx, userId, id, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for clearing the messages of the conversation given in the id query
+		err := h.conversationUsecase.ClearConversation(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// ConversationTag handles the requests for the tags of the user's conversations
+func (h *ConversationHandler) ConversationTag(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing every tag of the user
+		resp, err := h.conversationUsecase.GetTags(ctx, userId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPut:
+		// Put method for replacing the tags of the conversation given in the id query
+		var req dto.ConversationTagsRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.conversationUsecase.SetConversationTags(ctx, userId, id, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// ConversationPin pins or unpins the conversation given in the id query
+func (h *ConversationHandler) ConversationPin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	var req dto.ConversationPinRequest
+	err := request.GetRequestFromContext(ctx, &req)
+	if err != nil {
+		logger.Error(ctx, "failed get request", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		response.ResponseError(w, err)
+		return
+	}
+
+	userId := cast.ToInt(ctx.Value("userId"))
+	id := cast.ToInt(r.URL.Query().Get("id"))
+	resp, err := h.conversationUsecase.PinConversation(ctx, userId, id, req)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
+
+// ConversationMessage deletes the message given in the id query
+func (h *ConversationHandler) ConversationMessage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+	id := cast.ToInt(r.URL.Query().Get("id"))
+	err := h.conversationUsecase.DeleteChat(ctx, userId, id)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, nil)
+}
+
+// ConversationReset starts a fresh context in the conversation given in the id
+// query, keeping its history
+func (h *ConversationHandler) ConversationReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+	id := cast.ToInt(r.URL.Query().Get("id"))
+	resp, err := h.conversationUsecase.ResetContext(ctx, userId, id)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
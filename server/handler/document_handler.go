@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type DocumentHandler struct {
+	documentUsecase usecase.DocumentUsecase
+}
+
+func NewDocumentHandler(documentUsecase usecase.DocumentUsecase) *DocumentHandler {
+	return &DocumentHandler{
+		documentUsecase: documentUsecase,
+	}
+}
+
+// Document handles the requests for managing the documents the bot answers from
+func (h *DocumentHandler) Document(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing the documents of the user
+		resp, err := h.documentUsecase.GetDocuments(ctx, userId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for uploading a document in the file field of a multipart form
+		file, err := request.GetFileFromContext(r, "file", usecase.MaxDocumentSize)
+		if err != nil {
+			logger.Error(ctx, "failed get file", err.Error())
+			err = errors.SetError(http.StatusBadRequest, "file is required")
+			response.ResponseError(w, err)
+			return
+		}
+
+		resp, err := h.documentUsecase.UploadDocument(ctx, userId, dto.DocumentUploadRequest{
+			Name:        file.Name,
+			ContentType: file.ContentType,
+			Data:        file.Data,
+		})
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for deleting the document given in the id query
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		err := h.documentUsecase.DeleteDocument(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
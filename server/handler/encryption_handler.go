@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type EncryptionHandler struct {
+	encryptionUsecase usecase.EncryptionUsecase
+}
+
+func NewEncryptionHandler(encryptionUsecase usecase.EncryptionUsecase) *EncryptionHandler {
+	return &EncryptionHandler{
+		encryptionUsecase: encryptionUsecase,
+	}
+}
+
+// EncryptionJob handles the requests of admins for encrypting messages again
+func (h *EncryptionHandler) EncryptionJob(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for the progress of the job given in the id query
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.encryptionUsecase.GetEncryptionJob(ctx, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for encrypting the outdated messages again, rotating the master key first with the rotate query
+		rotate := cast.ToBool(r.URL.Query().Get("rotate"))
+		userId := cast.ToInt(ctx.Value("userId"))
+		resp, err := h.encryptionUsecase.StartReencryption(ctx, userId, rotate)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
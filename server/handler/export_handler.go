@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type ExportHandler struct {
+	exportUsecase usecase.ExportUsecase
+}
+
+func NewExportHandler(exportUsecase usecase.ExportUsecase) *ExportHandler {
+	return &ExportHandler{
+		exportUsecase: exportUsecase,
+	}
+}
+
+// Export downloads the messages of a conversation or a period as a file
+func (h *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	query := r.URL.Query()
+	req := dto.ExportRequest{
+		ConversationId: cast.ToInt(query.Get("conversationId")),
+		From:           query.Get("from"),
+		To:             query.Get("to"),
+		Format:         query.Get("format"),
+	}
+	resp, err := h.exportUsecase.ExportChats(ctx, cast.ToInt(userId), req)
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", resp.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resp.FileName))
+	w.WriteHeader(http.StatusOK)
+	w.Write(resp.Data)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type ImportHandler struct {
+	importUsecase usecase.ImportUsecase
+}
+
+func NewImportHandler(importUsecase usecase.ImportUsecase) *ImportHandler {
+	return &ImportHandler{
+		importUsecase: importUsecase,
+	}
+}
+
+// Import handles the requests for importing conversations from other chat services
+func (h *ImportHandler) Import(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for the progress of the import job given in the id query
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.importUsecase.GetImportJob(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for importing the uploaded export file in the format given in the format query
+		file, err := request.GetFileFromContext(r, "file", usecase.MaxImportSize)
+		if err != nil {
+			logger.Error(ctx, "failed get file", err.Error())
+			err = errors.SetError(http.StatusBadRequest, "file is required")
+			response.ResponseError(w, err)
+			return
+		}
+
+		req := dto.ImportRequest{
+			Format:   r.URL.Query().Get("format"),
+			FileName: file.Name,
+			Data:     file.Data,
+		}
+		resp, err := h.importUsecase.ImportChats(ctx, userId, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
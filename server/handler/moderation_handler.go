@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/paginate"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type ModerationHandler struct {
+	moderationUsecase usecase.ModerationUsecase
+}
+
+func NewModerationHandler(moderationUsecase usecase.ModerationUsecase) *ModerationHandler {
+	return &ModerationHandler{
+		moderationUsecase: moderationUsecase,
+	}
+}
+
+// Moderation handles the requests of admins for the moderation review queue
+func (h *ModerationHandler) Moderation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing the flags, narrowed by the status, direction and userId queries
+		params := paginate.GetParams(r.URL.Query())
+		filter := dto.ModerationFilter{
+			Status:    r.URL.Query().Get("status"),
+			Direction: r.URL.Query().Get("direction"),
+			UserId:    cast.ToInt(r.URL.Query().Get("userId")),
+			Page:      params.Page,
+			Limit:     params.Limit,
+		}
+		userId := cast.ToInt(ctx.Value("userId"))
+		resp, err := h.moderationUsecase.GetFlags(ctx, userId, filter)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPut:
+		// Put method for reviewing the flag given in the id query
+		var req dto.ModerationReviewRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		userId := cast.ToInt(ctx.Value("userId"))
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.moderationUsecase.ReviewFlag(ctx, userId, id, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
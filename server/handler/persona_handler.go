@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type PersonaHandler struct {
+	personaUsecase usecase.PersonaUsecase
+}
+
+func NewPersonaHandler(personaUsecase usecase.PersonaUsecase) *PersonaHandler {
+	return &PersonaHandler{
+		personaUsecase: personaUsecase,
+	}
+}
+
+// Persona handles the admin requests for managing personas
+func (h *PersonaHandler) Persona(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing all personas
+		resp, err := h.personaUsecase.GetPersonas(ctx)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for creating a persona
+		var req dto.PersonaRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		resp, err := h.personaUsecase.CreatePersona(ctx, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPut:
+		// Put method for updating the persona given in the id query
+		var req dto.PersonaRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.personaUsecase.UpdatePersona(ctx, id, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for deleting the persona given in the id query
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		err := h.personaUsecase.DeletePersona(ctx, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// AvailablePersona returns the personas the logged in user can chat with
+func (h *PersonaHandler) AvailablePersona(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	resp, err := h.personaUsecase.GetAvailablePersonas(ctx, cast.ToInt(userId))
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type PromptTemplateHandler struct {
+	promptTemplateUsecase usecase.PromptTemplateUsecase
+}
+
+func NewPromptTemplateHandler(promptTemplateUsecase usecase.PromptTemplateUsecase) *PromptTemplateHandler {
+	return &PromptTemplateHandler{
+		promptTemplateUsecase: promptTemplateUsecase,
+	}
+}
+
+// PromptTemplate handles the requests for managing prompt templates
+func (h *PromptTemplateHandler) PromptTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+	id := cast.ToInt(r.URL.Query().Get("id"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for a single template when an id is given, otherwise for listing templates
+		if id != 0 {
+			resp, err := h.promptTemplateUsecase.GetPromptTemplate(ctx, userId, id)
+			if err != nil {
+				response.ResponseError(w, err)
+				return
+			}
+
+			response.ResponseSuccess(w, resp)
+			return
+		}
+
+		resp, err := h.promptTemplateUsecase.GetPromptTemplates(ctx, userId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for creating a template
+		var req dto.PromptTemplateRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		resp, err := h.promptTemplateUsecase.CreatePromptTemplate(ctx, userId, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPut:
+		// Put method for updating the template given in the id query
+		var req dto.PromptTemplateRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		resp, err := h.promptTemplateUsecase.UpdatePromptTemplate(ctx, userId, id, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for deleting the template given in the id query
+		err := h.promptTemplateUsecase.DeletePromptTemplate(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
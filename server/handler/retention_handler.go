@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type RetentionHandler struct {
+	retentionUsecase usecase.RetentionUsecase
+}
+
+func NewRetentionHandler(retentionUsecase usecase.RetentionUsecase) *RetentionHandler {
+	return &RetentionHandler{
+		retentionUsecase: retentionUsecase,
+	}
+}
+
+// RetentionPolicy handles the admin requests for managing the retention policies
+func (h *RetentionHandler) RetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing all policies
+		resp, err := h.retentionUsecase.GetPolicies(ctx)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for adding a policy
+		var req dto.RetentionPolicyRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		userId := cast.ToInt(ctx.Value("userId"))
+		resp, err := h.retentionUsecase.CreatePolicy(ctx, userId, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPut:
+		// Put method for updating the policy given in the id query
+		var req dto.RetentionPolicyRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		userId := cast.ToInt(ctx.Value("userId"))
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.retentionUsecase.UpdatePolicy(ctx, userId, id, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for removing the policy given in the id query
+		userId := cast.ToInt(ctx.Value("userId"))
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		err := h.retentionUsecase.DeletePolicy(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// Purge handles the admin requests for running the retention policies
+func (h *RetentionHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for the report of the purge given in the id query
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.retentionUsecase.GetPurgeReport(ctx, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for starting a purge, only counting what would be purged with the dryRun query
+		dryRun := cast.ToBool(r.URL.Query().Get("dryRun"))
+		userId := cast.ToInt(ctx.Value("userId"))
+		resp, err := h.retentionUsecase.StartPurge(ctx, userId, dryRun)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
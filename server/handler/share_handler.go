@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+// SharePasswordHeader carries the password of a protected share link
+const SharePasswordHeader = "X-Share-Password"
+
+type ShareHandler struct {
+	shareUsecase usecase.ShareUsecase
+}
+
+func NewShareHandler(shareUsecase usecase.ShareUsecase) *ShareHandler {
+	return &ShareHandler{
+		shareUsecase: shareUsecase,
+	}
+}
+
+// Share handles the requests for managing the user's share links
+func (h *ShareHandler) Share(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userId := cast.ToInt(ctx.Value("userId"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing the share links with their views
+		resp, err := h.shareUsecase.GetShares(ctx, userId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for sharing a conversation
+		var req dto.ShareRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		resp, err := h.shareUsecase.CreateShare(ctx, userId, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for revoking the share link given in the id query
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		err := h.shareUsecase.RevokeShare(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// SharedConversation shows the shared conversation given in the token query
+// to anyone, without logging in
+func (h *ShareHandler) SharedConversation(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	token := r.URL.Query().Get("token")
+	resp, err := h.shareUsecase.ViewShare(ctx, token, r.Header.Get(SharePasswordHeader))
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type TeamHandler struct {
+	teamUsecase usecase.TeamUsecase
+}
+
+func NewTeamHandler(teamUsecase usecase.TeamUsecase) *TeamHandler {
+	return &TeamHandler{
+		teamUsecase: teamUsecase,
+	}
+}
+
+// Team handles the admin requests for managing teams
+func (h *TeamHandler) Team(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := cast.ToInt(r.URL.Query().Get("id"))
+
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing all teams
+		resp, err := h.teamUsecase.GetTeams(ctx)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost, http.MethodPut:
+		// Post method for creating a team, put method for updating the team given in the id query
+		var req dto.TeamRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		var resp dto.TeamResponse
+		if r.Method == http.MethodPost {
+			resp, err = h.teamUsecase.CreateTeam(ctx, req)
+		} else {
+			resp, err = h.teamUsecase.UpdateTeam(ctx, id, req)
+		}
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for deleting the team given in the id query
+		err := h.teamUsecase.DeleteTeam(ctx, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// UserTeam returns the teams of the logged in user
+func (h *TeamHandler) UserTeam(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+		return
+	}
+
+	ctx := r.Context()
+	userId := ctx.Value("userId")
+	resp, err := h.teamUsecase.GetUserTeams(ctx, cast.ToInt(userId))
+	if err != nil {
+		response.ResponseError(w, err)
+		return
+	}
+
+	response.ResponseSuccess(w, resp)
+}
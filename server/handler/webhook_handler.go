@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/usecase"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/chatbot/utils/response"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+type WebhookHandler struct {
+	webhookUsecase usecase.WebhookUsecase
+}
+
+func NewWebhookHandler(webhookUsecase usecase.WebhookUsecase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUsecase: webhookUsecase,
+	}
+}
+
+// Webhook handles the requests for managing the webhook subscriptions of a user
+func (h *WebhookHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		// Get method for listing the subscriptions of the user
+		userId := cast.ToInt(ctx.Value("userId"))
+		resp, err := h.webhookUsecase.GetSubscriptions(ctx, userId)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPost:
+		// Post method for subscribing a url to events, returning its signing secret
+		var req dto.WebhookSubscriptionRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		userId := cast.ToInt(ctx.Value("userId"))
+		resp, err := h.webhookUsecase.CreateSubscription(ctx, userId, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodPut:
+		// Put method for updating the subscription given in the id query
+		var req dto.WebhookSubscriptionRequest
+		err := request.GetRequestFromContext(ctx, &req)
+		if err != nil {
+			logger.Error(ctx, "failed get request", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			response.ResponseError(w, err)
+			return
+		}
+
+		userId := cast.ToInt(ctx.Value("userId"))
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		resp, err := h.webhookUsecase.UpdateSubscription(ctx, userId, id, req)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	case http.MethodDelete:
+		// Delete method for removing the subscription given in the id query with its deliveries
+		userId := cast.ToInt(ctx.Value("userId"))
+		id := cast.ToInt(r.URL.Query().Get("id"))
+		err := h.webhookUsecase.DeleteSubscription(ctx, userId, id)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, nil)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
+
+// WebhookDelivery handles the requests for viewing the deliveries of a subscription
+func (h *WebhookHandler) WebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	switch r.Method {
+	case http.MethodGet:
+		userId := cast.ToInt(ctx.Value("userId"))
+		query := r.URL.Query()
+
+		// Get method for the delivery given in the id query with its attempts
+		if query.Get("id") != "" {
+			resp, err := h.webhookUsecase.GetDelivery(ctx, userId, cast.ToInt(query.Get("id")))
+			if err != nil {
+				response.ResponseError(w, err)
+				return
+			}
+
+			response.ResponseSuccess(w, resp)
+			return
+		}
+
+		// Get method for a page of the deliveries of the subscription given in the subscriptionId query
+		filter := dto.WebhookDeliveryFilter{
+			SubscriptionId: cast.ToInt(query.Get("subscriptionId")),
+			Page:           cast.ToInt(query.Get("page")),
+			Limit:          cast.ToInt(query.Get("limit")),
+		}
+		resp, err := h.webhookUsecase.GetDeliveries(ctx, userId, filter)
+		if err != nil {
+			response.ResponseError(w, err)
+			return
+		}
+
+		response.ResponseSuccess(w, resp)
+
+	default:
+		// Return error if method is not allowed
+		err := errors.SetError(http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		response.ResponseError(w, err)
+	}
+}
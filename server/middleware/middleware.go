@@ -69,6 +69,8 @@ func SetLoggerMiddleware(next http.HandlerFunc) http.HandlerFunc {
 		ctx := logres.SetCtxLogger(r.Context(), ctxLogger)
 		// Set the request body in the context
 		ctx = request.SetRequestInContext(ctx, reqBody)
+		// Set the address of the client in the context
+		ctx = request.SetClientIPInContext(ctx, r)
 		// Set the updated request with the context
 		r = r.WithContext(ctx)
 		// Log the incoming request
@@ -113,8 +115,24 @@ func JwtMiddleware(next http.HandlerFunc) http.HandlerFunc {
 
 		// Set userId in context
 		ctx = context.WithValue(ctx, "userId", fmt.Sprintf("%v", claims["userId"]))
+		// Set role in context
+		ctx = context.WithValue(ctx, "role", fmt.Sprintf("%v", claims["role"]))
 		r = r.WithContext(ctx)
 
 		next.ServeHTTP(w, r)
 	}
 }
+
+// AdminMiddleware only lets requests through when the role set by JwtMiddleware is admin.
+func AdminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Context().Value("role") != constrans.RoleAdmin {
+			// If the user is not an admin, return a forbidden error.
+			err := errors.SetError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+			response.ResponseError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+}
@@ -5,11 +5,28 @@ import (
 
 	"github.com/fadilahonespot/chatbot/server/handler"
 	"github.com/fadilahonespot/chatbot/server/middleware"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
 )
 
 type Router struct {
-	userHandler *handler.UserHandler
-	chatHandler *handler.ChatHandler
+	userHandler           *handler.UserHandler
+	chatHandler           *handler.ChatHandler
+	personaHandler        *handler.PersonaHandler
+	chatModelHandler      *handler.ChatModelHandler
+	promptTemplateHandler *handler.PromptTemplateHandler
+	documentHandler       *handler.DocumentHandler
+	teamHandler           *handler.TeamHandler
+	collectionHandler     *handler.CollectionHandler
+	conversationHandler   *handler.ConversationHandler
+	exportHandler         *handler.ExportHandler
+	importHandler         *handler.ImportHandler
+	shareHandler          *handler.ShareHandler
+	moderationHandler     *handler.ModerationHandler
+	encryptionHandler     *handler.EncryptionHandler
+	retentionHandler      *handler.RetentionHandler
+	auditHandler          *handler.AuditHandler
+	webhookHandler        *handler.WebhookHandler
+	channelHandler        *handler.ChannelHandler
 }
 
 func NewRouter() *Router {
@@ -26,6 +43,86 @@ func (r *Router) SetChatHandler(handler *handler.ChatHandler) *Router {
 	return r
 }
 
+func (r *Router) SetPersonaHandler(handler *handler.PersonaHandler) *Router {
+	r.personaHandler = handler
+	return r
+}
+
+func (r *Router) SetChatModelHandler(handler *handler.ChatModelHandler) *Router {
+	r.chatModelHandler = handler
+	return r
+}
+
+func (r *Router) SetPromptTemplateHandler(handler *handler.PromptTemplateHandler) *Router {
+	r.promptTemplateHandler = handler
+	return r
+}
+
+func (r *Router) SetDocumentHandler(handler *handler.DocumentHandler) *Router {
+	r.documentHandler = handler
+	return r
+}
+
+func (r *Router) SetTeamHandler(handler *handler.TeamHandler) *Router {
+	r.teamHandler = handler
+	return r
+}
+
+func (r *Router) SetCollectionHandler(handler *handler.CollectionHandler) *Router {
+	r.collectionHandler = handler
+	return r
+}
+
+func (r *Router) SetConversationHandler(handler *handler.ConversationHandler) *Router {
+	r.conversationHandler = handler
+	return r
+}
+
+func (r *Router) SetExportHandler(handler *handler.ExportHandler) *Router {
+	r.exportHandler = handler
+	return r
+}
+
+func (r *Router) SetImportHandler(handler *handler.ImportHandler) *Router {
+	r.importHandler = handler
+	return r
+}
+
+func (r *Router) SetShareHandler(handler *handler.ShareHandler) *Router {
+	r.shareHandler = handler
+	return r
+}
+
+func (r *Router) SetModerationHandler(handler *handler.ModerationHandler) *Router {
+	r.moderationHandler = handler
+	return r
+}
+
+func (r *Router) SetEncryptionHandler(handler *handler.EncryptionHandler) *Router {
+	r.encryptionHandler = handler
+	return r
+}
+
+func (r *Router) SetRetentionHandler(handler *handler.RetentionHandler) *Router {
+	r.retentionHandler = handler
+	return r
+}
+
+func (r *Router) SetAuditHandler(handler *handler.AuditHandler) *Router {
+	r.auditHandler = handler
+	return r
+}
+
+func (r *Router) SetWebhookHandler(handler *handler.WebhookHandler) *Router {
+	r.webhookHandler = handler
+	return r
+}
+
+func (r *Router) SetChannelHandler(handler *handler.ChannelHandler) *Router {
+	r.channelHandler = handler
+	return r
+}
+
 func (r *Router) Validate() *Router {
 	if r.userHandler == nil {
 		panic("user handler is nil")
@@ -35,6 +132,70 @@ func (r *Router) Validate() *Router {
 		panic("chat handler is nil")
 	}
 
+	if r.personaHandler == nil {
+		panic("persona handler is nil")
+	}
+
+	if r.chatModelHandler == nil {
+		panic("chat model handler is nil")
+	}
+
+	if r.promptTemplateHandler == nil {
+		panic("prompt template handler is nil")
+	}
+
+	if r.documentHandler == nil {
+		panic("document handler is nil")
+	}
+
+	if r.teamHandler == nil {
+		panic("team handler is nil")
+	}
+
+	if r.collectionHandler == nil {
+		panic("collection handler is nil")
+	}
+
+	if r.conversationHandler == nil {
+		panic("conversation handler is nil")
+	}
+
+	if r.exportHandler == nil {
+		panic("export handler is nil")
+	}
+
+	if r.importHandler == nil {
+		panic("import handler is nil")
+	}
+
+	if r.shareHandler == nil {
+		panic("share handler is nil")
+	}
+
+	if r.moderationHandler == nil {
+		panic("moderation handler is nil")
+	}
+
+	if r.encryptionHandler == nil {
+		panic("encryption handler is nil")
+	}
+
+	if r.retentionHandler == nil {
+		panic("retention handler is nil")
+	}
+
+	if r.auditHandler == nil {
+		panic("audit handler is nil")
+	}
+
+	if r.webhookHandler == nil {
+		panic("webhook handler is nil")
+	}
+
+	if r.channelHandler == nil {
+		panic("channel handler is nil")
+	}
+
 	return r
 }
 
@@ -47,4 +208,89 @@ func (r *Router) SetupRouter() {
 
 	// Register route for handling chat requests
 	http.Handle("/chat", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.chatHandler.Chat)))
+	// Register route for getting the images attached to questions
+	http.Handle("/chat/images", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.chatHandler.ChatImage)))
+	// Register route for asking questions by voice
+	http.Handle("/chat/voice", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.chatHandler.ChatVoice)))
+	// Register route for polling the questions answered in the background
+	http.Handle("/jobs/", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.chatHandler.ChatJob)))
+	// Register route for starring messages
+	http.Handle("/chat/star", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.chatHandler.ChatStar)))
+	// Register route for getting the recordings and spoken answers of voice questions
+	http.Handle("/chat/audio", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.chatHandler.ChatAudio)))
+
+	// Register route for exporting chats as a file
+	http.Handle("/chat/export", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.exportHandler.Export)))
+	// Register route for importing conversations from other chat services
+	http.Handle("/chat/import", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.importHandler.Import)))
+	// Register route for sharing conversations by link
+	http.Handle("/shares", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.shareHandler.Share)))
+	// Register route for viewing a shared conversation, open without logging in
+	http.HandleFunc("/shared", middleware.SetLoggerMiddleware(r.shareHandler.SharedConversation))
+	// Register route for listing, renaming and clearing conversations
+	http.Handle("/conversations", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.conversationHandler.Conversation)))
+	// Register route for tagging conversations
+	http.Handle("/conversations/tags", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.conversationHandler.ConversationTag)))
+	// Register route for pinning conversations
+	http.Handle("/conversations/pin", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.conversationHandler.ConversationPin)))
+	// Register route for deleting a message of a conversation
+	http.Handle("/conversations/messages", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.conversationHandler.ConversationMessage)))
+	// Register route for resetting the context of a conversation
+	http.Handle("/conversations/reset", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.conversationHandler.ConversationReset)))
+
+	// Register route for listing the personas a user can chat with
+	http.Handle("/personas", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.personaHandler.AvailablePersona)))
+	// Register route for managing personas
+	http.Handle("/admin/personas", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(middleware.AdminMiddleware(r.personaHandler.Persona))))
+
+	// Register route for listing the models a user can request
+	http.Handle("/models", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.chatModelHandler.AvailableChatModel)))
+	// Register route for managing the model allowlist
+	http.Handle("/admin/models", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(middleware.AdminMiddleware(r.chatModelHandler.ChatModel))))
+
+	// Register route for managing prompt templates
+	http.Handle("/templates", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.promptTemplateHandler.PromptTemplate)))
+
+	// Register route for managing the documents the bot answers from
+	http.Handle("/documents", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.documentHandler.Document)))
+
+	// Register route for listing the teams of a user
+	http.Handle("/teams", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.teamHandler.UserTeam)))
+	// Register route for managing teams
+	http.Handle("/admin/teams", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(middleware.AdminMiddleware(r.teamHandler.Team))))
+
+	// Register route for reviewing the content flagged by moderation
+	http.Handle("/admin/moderation", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(middleware.AdminMiddleware(r.moderationHandler.Moderation))))
+
+	// Register route for encrypting messages again after a master key rotation
+	http.Handle("/admin/encryption/jobs", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(middleware.AdminMiddleware(r.encryptionHandler.EncryptionJob))))
+
+	// Register routes for the retention policies and their purges
+	http.Handle("/admin/retention/policies", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(middleware.AdminMiddleware(r.retentionHandler.RetentionPolicy))))
+	http.Handle("/admin/retention/purges", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(middleware.AdminMiddleware(r.retentionHandler.Purge))))
+
+	// Register routes for querying the audit log and checking its hash chain
+	http.Handle("/admin/audit", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(middleware.AdminMiddleware(r.auditHandler.AuditLog))))
+	http.Handle("/admin/audit/verify", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(middleware.AdminMiddleware(r.auditHandler.VerifyAuditLog))))
+
+	// Register route for subscribing urls to the events of a user
+	http.Handle("/webhooks", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.webhookHandler.Webhook)))
+	// Register route for viewing the deliveries of a subscription and their attempts
+	http.Handle("/webhooks/deliveries", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.webhookHandler.WebhookDelivery)))
+
+	// Register routes for the webhooks of the messaging channels, verified by each channel
+	http.HandleFunc("/channels/telegram", middleware.SetLoggerMiddleware(r.channelHandler.Webhook(constrans.ChannelTelegram)))
+	http.HandleFunc("/channels/whatsapp", middleware.SetLoggerMiddleware(r.channelHandler.Webhook(constrans.ChannelWhatsApp)))
+	http.HandleFunc("/channels/slack", middleware.SetLoggerMiddleware(r.channelHandler.Webhook(constrans.ChannelSlack)))
+	// Register the former Telegram webhook, so bots whose webhook was set before keep working
+	http.HandleFunc("/telegram/webhook", middleware.SetLoggerMiddleware(r.channelHandler.Webhook(constrans.ChannelTelegram)))
+	// Register route for linking the users of the messaging channels to a user
+	http.Handle("/channels/link", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.channelHandler.ChannelLink)))
+
+	// Register route for managing knowledge base collections
+	http.Handle("/collections", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.collectionHandler.Collection)))
+	// Register route for managing the documents of a collection
+	http.Handle("/collections/documents", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.collectionHandler.CollectionDocument)))
+	// Register route for re-embedding a collection
+	http.Handle("/collections/jobs", middleware.SetLoggerMiddleware(middleware.JwtMiddleware(r.collectionHandler.EmbeddingJob)))
 }
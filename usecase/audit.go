@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/library/logres"
+)
+
+// recordAudit records an action with the client and the request id of the
+// context. Before and after are stored as json when given. A failure is only
+// logged, the action itself is already done.
+func recordAudit(ctx context.Context, auditRepo mysql.AuditRepository, actorId int, action, targetType string, targetId int, before, after interface{}) {
+	ctxLogger := logres.GetCtxLogger(ctx)
+	header, _ := ctxLogger.Header.(http.Header)
+
+	log := entity.AuditLog{
+		ActorID:    actorId,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetId,
+		IP:         request.GetClientIPFromContext(ctx),
+		UserAgent:  truncateRunes(header.Get("User-Agent"), 255),
+		RequestID:  ctxLogger.ThreadID,
+		Before:     auditJson(before),
+		After:      auditJson(after),
+	}
+	err := auditRepo.Create(ctx, &log)
+	if err != nil {
+		logger.Error(ctx, "error recording audit log", action, err.Error())
+	}
+}
+
+// auditJson returns the value as json, or empty when there is none
+func auditJson(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	data, _ := json.Marshal(value)
+	return string(data)
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/request"
+	"github.com/fadilahonespot/library/logres"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_recordAudit(t *testing.T) {
+	logger.NewLogger()
+	t.Setenv("TRUSTED_PROXIES", "192.0.2.1,10.0.0.0/8")
+
+	r := httptest.NewRequest(http.MethodPost, "/login", nil)
+	r.Header.Set("User-Agent", "curl/8.0")
+	r.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+	ctx := logres.SetCtxLogger(context.TODO(), logres.Context{ThreadID: "thread-1", Header: r.Header})
+	ctx = request.SetClientIPInContext(ctx, r)
+
+	var got entity.AuditLog
+	auditRepo := new(mocks.AuditRepository)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		got = *args.Get(1).(*entity.AuditLog)
+	}).Return(nil).Once()
+
+	recordAudit(ctx, auditRepo, 3, "user.login", "user", 3, nil, map[string]string{"email": "a@b.com"})
+
+	want := entity.AuditLog{
+		ActorID:    3,
+		Action:     "user.login",
+		TargetType: "user",
+		TargetID:   3,
+		IP:         "203.0.113.7",
+		UserAgent:  "curl/8.0",
+		RequestID:  "thread-1",
+		After:      `{"email":"a@b.com"}`,
+	}
+	if got != want {
+		t.Errorf("recordAudit() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_defaultAuditUsecase_VerifyChain(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	chain := func() []entity.AuditLog {
+		createdAt := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+		logs := []entity.AuditLog{
+			{ID: 1, Action: "user.login", ActorID: 1},
+			{ID: 2, Action: "user.login", ActorID: 2},
+			{ID: 3, Action: "chat.deleted", ActorID: 2, Before: `{"id":5}`},
+		}
+		prevHash := ""
+		for i := range logs {
+			logs[i].CreatedAt = createdAt
+			logs[i].PrevHash = prevHash
+			logs[i].Hash = mysql.AuditHash(prevHash, logs[i])
+			prevHash = logs[i].Hash
+		}
+		return logs
+	}
+
+	tests := []struct {
+		name   string
+		logs   func() []entity.AuditLog
+		want   bool
+		broken int
+	}{
+		{
+			name: "whole chain",
+			logs: chain,
+			want: true,
+		},
+		{
+			name: "changed record",
+			logs: func() []entity.AuditLog {
+				logs := chain()
+				logs[1].ActorID = 9
+				return logs
+			},
+			broken: 2,
+		},
+		{
+			name: "removed record",
+			logs: func() []entity.AuditLog {
+				logs := chain()
+				return append(logs[:1], logs[2])
+			},
+			broken: 3,
+		},
+		{
+			name: "records written before chaining",
+			logs: func() []entity.AuditLog {
+				logs := chain()
+				for i := range logs {
+					logs[i].ID++
+				}
+				return append([]entity.AuditLog{{ID: 1, Action: "user.registered"}}, logs...)
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := tt.logs()
+			auditRepo := new(mocks.AuditRepository)
+			auditRepo.On("FindAfter", mock.Anything, 0, AuditVerifyBatchSize).Return(logs, nil).Once()
+			auditRepo.On("FindAfter", mock.Anything, logs[len(logs)-1].ID, AuditVerifyBatchSize).Return([]entity.AuditLog{}, nil).Once()
+
+			s := NewAuditUsecase(auditRepo)
+			gotResp, err := s.VerifyChain(ctx)
+			if err != nil {
+				t.Fatalf("defaultAuditUsecase.VerifyChain() error = %v", err)
+			}
+			if gotResp.Valid != tt.want || gotResp.BrokenId != tt.broken {
+				t.Errorf("defaultAuditUsecase.VerifyChain() = %+v, want valid %v broken %v", gotResp, tt.want, tt.broken)
+			}
+		})
+	}
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/http/channel"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/message"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+	"gorm.io/gorm"
+)
+
+const (
+	// KeyChannelLink prefixes the keys of the codes that link the user of a
+	// channel to a user, which can be used once within ChannelLinkTTL
+	KeyChannelLink      = "ChannelLink"
+	ChannelLinkTTL      = 10 * time.Minute
+	ChannelLinkCodeSize = 16
+
+	// KeyChannelMessage prefixes the keys of the ids of the messages
+	// received, kept for ChannelMessageTTL so a message the service posts
+	// again is answered once
+	KeyChannelMessage = "ChannelMessage"
+	ChannelMessageTTL = 24 * time.Hour
+)
+
+// replies of the bot to the commands and to the messages it cannot answer
+const (
+	channelHelp        = "Send me a question and I will answer it.\n/new starts a new conversation\n/unlink unlinks you from your account"
+	channelNotLinked   = "You are not linked to an account yet. Create a link code in the app and send it here as /link <code>."
+	channelLinked      = "You are now linked to your account. Send me a question and I will answer it."
+	channelLinkInvalid = "The link code is not valid or has expired. Create a new one in the app."
+	channelUnlinked    = "You are unlinked from your account."
+	channelNewChat     = "Started a new conversation."
+	channelTextOnly    = "I can only answer text messages."
+	channelError       = "Something went wrong, please try again."
+)
+
+type ChannelUsecase interface {
+	CreateLink(ctx context.Context, userId int) (resp dto.ChannelLinkResponse, err error)
+	GetIdentities(ctx context.Context, userId int) (resp []dto.ChannelIdentityResponse, err error)
+	Unlink(ctx context.Context, userId, id int) (err error)
+	HandleWebhook(ctx context.Context, name string, req channel.Request) (challenge string, err error)
+}
+
+type defaultChannelUsecase struct {
+	chatUsecase  ChatUsecase
+	channelRepo  mysql.ChannelRepository
+	cacheWrapper cached.CacheWrapper
+	channels     map[string]channel.Channel
+}
+
+// NewChannelUsecase creates a new instance of ChannelUsecase answering on
+// the channels
+func NewChannelUsecase(chatUsecase ChatUsecase, channelRepo mysql.ChannelRepository, cacheWrapper cached.CacheWrapper, channels []channel.Channel) ChannelUsecase {
+	byName := map[string]channel.Channel{}
+	for _, ch := range channels {
+		byName[ch.Name()] = ch
+	}
+
+	return &defaultChannelUsecase{
+		chatUsecase:  chatUsecase,
+		channelRepo:  channelRepo,
+		cacheWrapper: cacheWrapper,
+		channels:     byName,
+	}
+}
+
+// CreateLink returns a code that links the user of a channel it is sent from
+// to the user. The code can be used once, within ChannelLinkTTL.
+func (s *defaultChannelUsecase) CreateLink(ctx context.Context, userId int) (resp dto.ChannelLinkResponse, err error) {
+	random := make([]byte, ChannelLinkCodeSize)
+	_, err = rand.Read(random)
+	if err != nil {
+		logger.Error(ctx, "error generating channel link code", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	code := base64.RawURLEncoding.EncodeToString(random)
+
+	err = s.cacheWrapper.Set(ctx, fmt.Sprintf("%v_%v", KeyChannelLink, code), cast.ToString(userId), ChannelLinkTTL)
+	if err != nil {
+		logger.Error(ctx, "error saving channel link code", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = dto.ChannelLinkResponse{
+		Code:      code,
+		Links:     map[string]string{},
+		ExpiresAt: time.Now().Add(ChannelLinkTTL),
+	}
+	if username := os.Getenv("TELEGRAM_BOT_USERNAME"); username != "" {
+		resp.Links[constrans.ChannelTelegram] = fmt.Sprintf("https://t.me/%v?start=%v", username, code)
+	}
+	if number := os.Getenv("WHATSAPP_PHONE_NUMBER"); number != "" {
+		resp.Links[constrans.ChannelWhatsApp] = fmt.Sprintf("https://wa.me/%v?text=%v", number, url.QueryEscape("/link "+code))
+	}
+	return
+}
+
+// GetIdentities returns the users of the channels linked to the user
+func (s *defaultChannelUsecase) GetIdentities(ctx context.Context, userId int) (resp []dto.ChannelIdentityResponse, err error) {
+	identities, err := s.channelRepo.GetIdentitiesByUserId(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting channel identities", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = []dto.ChannelIdentityResponse{}
+	for _, identity := range identities {
+		resp = append(resp, dto.ChannelIdentityResponse{
+			Id:             identity.ID,
+			Channel:        identity.Channel,
+			ExternalId:     identity.ExternalID,
+			Username:       identity.Username,
+			ConversationId: identity.ConversationID,
+			CreatedAt:      identity.CreatedAt,
+		})
+	}
+	return
+}
+
+// Unlink removes a user of a channel linked to the user
+func (s *defaultChannelUsecase) Unlink(ctx context.Context, userId, id int) (err error) {
+	identities, err := s.channelRepo.GetIdentitiesByUserId(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting channel identities", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for _, identity := range identities {
+		if identity.ID != id {
+			continue
+		}
+
+		err = s.channelRepo.DeleteIdentity(ctx, id)
+		if err != nil {
+			logger.Error(ctx, "error deleting channel identity", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		}
+		return
+	}
+
+	logger.Error(ctx, "channel identity not found", id)
+	err = errors.SetError(http.StatusNotFound, "channel identity not found")
+	return
+}
+
+// HandleWebhook verifies a webhook request of the channel and answers its
+// handshake with the challenge. The messages of other requests are handled
+// in the background, one after the other, so the service is not kept
+// waiting on the answers.
+func (s *defaultChannelUsecase) HandleWebhook(ctx context.Context, name string, req channel.Request) (challenge string, err error) {
+	ch, ok := s.channels[name]
+	if !ok {
+		logger.Error(ctx, "channel not found", name)
+		err = errors.SetError(http.StatusNotFound, "channel not found")
+		return
+	}
+
+	challenge, err = ch.Verify(req)
+	if err != nil {
+		logger.Error(ctx, "channel webhook not verified", name, err.Error())
+		err = errors.SetError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+	if challenge != "" {
+		return
+	}
+
+	messages, err := ch.Parse(req)
+	if err != nil {
+		logger.Error(ctx, "error parsing channel webhook", name, err.Error())
+		err = errors.SetError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		return
+	}
+	messages = s.newMessages(ctx, ch, messages)
+	if len(messages) == 0 {
+		return
+	}
+
+	go func(ctx context.Context) {
+		for _, msg := range messages {
+			s.handleMessage(ctx, ch, msg)
+		}
+	}(context.WithoutCancel(ctx))
+	return
+}
+
+// newMessages leaves out the messages received before. Services post a
+// message again when the webhook was slow to answer or failed, and each
+// posting would otherwise be answered. Messages without an id, or whose id
+// cannot be kept, are handled as new.
+func (s *defaultChannelUsecase) newMessages(ctx context.Context, ch channel.Channel, messages []channel.Message) (resp []channel.Message) {
+	for _, msg := range messages {
+		if msg.Id != "" {
+			key := fmt.Sprintf("%v_%v_%v", KeyChannelMessage, ch.Name(), msg.Id)
+			ok, err := s.cacheWrapper.SetNX(ctx, key, msg.ExternalId, ChannelMessageTTL)
+			if err != nil {
+				logger.Error(ctx, "error keeping channel message id", ch.Name(), err.Error())
+			} else if !ok {
+				logger.Info(ctx, "channel message received before", ch.Name(), msg.Id)
+				continue
+			}
+		}
+		resp = append(resp, msg)
+	}
+	return
+}
+
+// handleMessage runs a command, or asks the text as a question for the user
+// the sender is linked to and sends back the answer
+func (s *defaultChannelUsecase) handleMessage(ctx context.Context, ch channel.Channel, msg channel.Message) {
+	text := strings.TrimSpace(msg.Text)
+	if text == "" {
+		s.reply(ctx, ch, msg.ExternalId, channelTextOnly)
+		return
+	}
+
+	command, argument := parseChannelCommand(text)
+	if (command == "/start" || command == "/link") && argument != "" {
+		s.link(ctx, ch, msg, argument)
+		return
+	}
+
+	identity, err := s.channelRepo.GetIdentity(ctx, ch.Name(), msg.ExternalId)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			s.reply(ctx, ch, msg.ExternalId, channelNotLinked)
+			return
+		}
+		logger.Error(ctx, "error getting channel identity", ch.Name(), msg.ExternalId, err.Error())
+		s.reply(ctx, ch, msg.ExternalId, channelError)
+		return
+	}
+
+	if command != "" {
+		s.runCommand(ctx, ch, *identity, command)
+		return
+	}
+
+	resp, err := s.chatUsecase.ChatQuestion(ctx, identity.UserID, dto.ChatQuestionRequest{
+		ConversationId: identity.ConversationID,
+		Question:       text,
+	})
+	if err != nil {
+		logger.Error(ctx, "error answering channel message", ch.Name(), msg.ExternalId, err.Error())
+		if code := errors.GetErrorCode(err); code >= http.StatusBadRequest && code < http.StatusInternalServerError {
+			s.reply(ctx, ch, msg.ExternalId, err.Error())
+			return
+		}
+		s.reply(ctx, ch, msg.ExternalId, channelError)
+		return
+	}
+
+	if resp.ConversationId != identity.ConversationID {
+		err = s.channelRepo.UpdateConversation(ctx, identity.ID, resp.ConversationId)
+		if err != nil {
+			logger.Error(ctx, "error saving channel conversation", ch.Name(), msg.ExternalId, err.Error())
+		}
+	}
+	s.reply(ctx, ch, msg.ExternalId, resp.Answer)
+}
+
+// runCommand runs a command sent by a linked user
+func (s *defaultChannelUsecase) runCommand(ctx context.Context, ch channel.Channel, identity entity.ChannelIdentity, command string) {
+	var err error
+	switch command {
+	case "/new":
+		err = s.channelRepo.UpdateConversation(ctx, identity.ID, 0)
+		if err == nil {
+			s.reply(ctx, ch, identity.ExternalID, channelNewChat)
+		}
+	case "/unlink":
+		err = s.channelRepo.DeleteIdentity(ctx, identity.ID)
+		if err == nil {
+			s.reply(ctx, ch, identity.ExternalID, channelUnlinked)
+		}
+	default:
+		s.reply(ctx, ch, identity.ExternalID, channelHelp)
+	}
+	if err != nil {
+		logger.Error(ctx, "error running channel command", ch.Name(), identity.ExternalID, command, err.Error())
+		s.reply(ctx, ch, identity.ExternalID, channelError)
+	}
+}
+
+// link links the sender to the user the code was made for
+func (s *defaultChannelUsecase) link(ctx context.Context, ch channel.Channel, msg channel.Message, code string) {
+	key := fmt.Sprintf("%v_%v", KeyChannelLink, code)
+	value, err := s.cacheWrapper.Get(ctx, key)
+	if err != nil || cast.ToInt(value) == 0 {
+		logger.Error(ctx, "channel link code not valid", ch.Name(), msg.ExternalId)
+		s.reply(ctx, ch, msg.ExternalId, channelLinkInvalid)
+		return
+	}
+	s.cacheWrapper.Delete(ctx, key)
+
+	identity := entity.ChannelIdentity{
+		Channel:    ch.Name(),
+		ExternalID: msg.ExternalId,
+		UserID:     cast.ToInt(value),
+		Username:   msg.Username,
+	}
+	err = s.channelRepo.SaveIdentity(ctx, &identity)
+	if err != nil {
+		logger.Error(ctx, "error saving channel identity", ch.Name(), msg.ExternalId, err.Error())
+		s.reply(ctx, ch, msg.ExternalId, channelError)
+		return
+	}
+
+	s.reply(ctx, ch, msg.ExternalId, channelLinked)
+}
+
+// reply sends the text to the user, split into as many messages as the
+// limits of the channel take
+func (s *defaultChannelUsecase) reply(ctx context.Context, ch channel.Channel, to, text string) {
+	for _, part := range message.Split(text, ch.Limits().MessageLength) {
+		err := ch.Send(ctx, to, part)
+		if err != nil {
+			logger.Error(ctx, "error sending channel message", ch.Name(), to, err.Error())
+			return
+		}
+	}
+}
+
+// parseChannelCommand returns the command a message starts with, without
+// the bot username Telegram can add to it, and the text after it
+func parseChannelCommand(text string) (command, argument string) {
+	if !strings.HasPrefix(text, "/") {
+		return
+	}
+
+	command, argument, _ = strings.Cut(text, " ")
+	command, _, _ = strings.Cut(strings.ToLower(command), "@")
+	argument = strings.TrimSpace(argument)
+	return
+}
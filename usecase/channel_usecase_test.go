@@ -0,0 +1,284 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/http/channel"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	liberrors "github.com/fadilahonespot/library/errors"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+// newChannel returns a channel mock that keeps the messages sent on it
+func newChannel(name string, messageLength int, sent *[]string) *mocks.Channel {
+	ch := new(mocks.Channel)
+	ch.On("Name").Return(name)
+	ch.On("Limits").Return(channel.Limits{MessageLength: messageLength})
+	ch.On("Send", mock.Anything, "u-42", mock.Anything).Run(func(args mock.Arguments) {
+		*sent = append(*sent, args.String(2))
+	}).Return(nil)
+	return ch
+}
+
+func Test_defaultChannelUsecase_HandleWebhook(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name          string
+		channel       string
+		verifyErr     error
+		challenge     string
+		wantChallenge string
+		wantErrCode   int
+	}{
+		{
+			name:        "unknown channel",
+			channel:     "line",
+			wantErrCode: http.StatusNotFound,
+		},
+		{
+			name:        "not verified",
+			channel:     constrans.ChannelSlack,
+			verifyErr:   channel.ErrNotVerified,
+			wantErrCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "handshake",
+			channel:       constrans.ChannelSlack,
+			challenge:     "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P",
+			wantChallenge: "3eZbrw1aBm2rZgRNFdxV2595E9CY3gmdALWMmHkvFXO7tYXAYM8P",
+		},
+		{
+			name:    "messages",
+			channel: constrans.ChannelSlack,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			ch := newChannel(constrans.ChannelSlack, channel.SlackMessageLength, &sent)
+			ch.On("Verify", mock.Anything).Return(tt.challenge, tt.verifyErr)
+			ch.On("Parse", mock.Anything).Return([]channel.Message(nil), nil)
+
+			s := NewChannelUsecase(nil, nil, nil, []channel.Channel{ch})
+			challenge, err := s.HandleWebhook(ctx, tt.channel, channel.Request{Method: http.MethodPost})
+			if liberrors.GetErrorCode(err) != tt.wantErrCode && !(err == nil && tt.wantErrCode == 0) {
+				t.Errorf("defaultChannelUsecase.HandleWebhook() error = %v, want code %v", err, tt.wantErrCode)
+			}
+			if challenge != tt.wantChallenge {
+				t.Errorf("defaultChannelUsecase.HandleWebhook() challenge = %v, want %v", challenge, tt.wantChallenge)
+			}
+			if tt.challenge != "" || tt.verifyErr != nil {
+				ch.AssertNotCalled(t, "Parse", mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_defaultChannelUsecase_newMessages(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	var sent []string
+	ch := newChannel(constrans.ChannelWhatsApp, channel.WhatsAppMessageLength, &sent)
+	cacheWrapper := new(mocks.CacheWrapper)
+	cacheWrapper.On("SetNX", mock.Anything, "ChannelMessage_whatsapp_wamid.1", "u-42", ChannelMessageTTL).Return(false, nil).Once()
+	cacheWrapper.On("SetNX", mock.Anything, "ChannelMessage_whatsapp_wamid.2", "u-42", ChannelMessageTTL).Return(true, nil).Once()
+	cacheWrapper.On("SetNX", mock.Anything, "ChannelMessage_whatsapp_wamid.3", "u-42", ChannelMessageTTL).Return(false, errors.New("redis down")).Once()
+
+	s := &defaultChannelUsecase{cacheWrapper: cacheWrapper}
+	got := s.newMessages(ctx, ch, []channel.Message{
+		{Id: "wamid.1", ExternalId: "u-42", Text: "received before"},
+		{Id: "wamid.2", ExternalId: "u-42", Text: "new"},
+		{Id: "wamid.3", ExternalId: "u-42", Text: "id not kept"},
+		{ExternalId: "u-42", Text: "without id"},
+	})
+	want := []channel.Message{
+		{Id: "wamid.2", ExternalId: "u-42", Text: "new"},
+		{Id: "wamid.3", ExternalId: "u-42", Text: "id not kept"},
+		{ExternalId: "u-42", Text: "without id"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("defaultChannelUsecase.newMessages() = %+v, want %+v", got, want)
+	}
+	cacheWrapper.AssertExpectations(t)
+}
+
+func Test_defaultChannelUsecase_link(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+	t.Setenv("TELEGRAM_BOT_USERNAME", "contoh_bot")
+	t.Setenv("WHATSAPP_PHONE_NUMBER", "6281234567890")
+
+	values := map[string]string{}
+	cacheWrapper := new(mocks.CacheWrapper)
+	cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		values[args.String(1)] = args.String(2)
+	}).Return(nil)
+	cacheWrapper.On("Get", mock.Anything, mock.Anything).Return(func(ctx context.Context, key string) (string, error) {
+		value, ok := values[key]
+		if !ok {
+			return "", errors.New("redis: nil")
+		}
+		return value, nil
+	})
+	cacheWrapper.On("Delete", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		delete(values, args.String(1))
+	}).Return(nil)
+	channelRepo := new(mocks.ChannelRepository)
+	channelRepo.On("SaveIdentity", mock.Anything, &entity.ChannelIdentity{Channel: constrans.ChannelWhatsApp, ExternalID: "u-42", UserID: 1, Username: "Budi"}).Return(nil).Once()
+
+	var sent []string
+	ch := newChannel(constrans.ChannelWhatsApp, channel.WhatsAppMessageLength, &sent)
+	s := &defaultChannelUsecase{
+		channelRepo:  channelRepo,
+		cacheWrapper: cacheWrapper,
+	}
+	link, err := s.CreateLink(ctx, 1)
+	if err != nil {
+		t.Fatalf("defaultChannelUsecase.CreateLink() error = %v", err)
+	}
+	wantLinks := map[string]string{
+		constrans.ChannelTelegram: "https://t.me/contoh_bot?start=" + link.Code,
+		constrans.ChannelWhatsApp: "https://wa.me/6281234567890?text=%2Flink+" + link.Code,
+	}
+	if !reflect.DeepEqual(link.Links, wantLinks) {
+		t.Errorf("defaultChannelUsecase.CreateLink() links = %v, want %v", link.Links, wantLinks)
+	}
+
+	// the code links the sender once
+	msg := channel.Message{ExternalId: "u-42", Username: "Budi", Text: "/link " + link.Code}
+	s.handleMessage(ctx, ch, msg)
+	s.handleMessage(ctx, ch, msg)
+
+	channelRepo.AssertNumberOfCalls(t, "SaveIdentity", 1)
+	if want := []string{channelLinked, channelLinkInvalid}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("defaultChannelUsecase.handleMessage() sent %q, want %q", sent, want)
+	}
+}
+
+func Test_defaultChannelUsecase_handleMessage(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	longAnswer := strings.Repeat("kata ", 790) + "\n\n" + strings.Repeat("lagi ", 200)
+	tests := []struct {
+		name        string
+		text        string
+		linked      bool
+		answer      string
+		answerErr   error
+		wantSent    []string
+		wantAsked   bool
+		wantUpdated int
+	}{
+		{
+			name:     "sender not linked",
+			text:     "tools yang di butuhkan untuk koding?",
+			wantSent: []string{channelNotLinked},
+		},
+		{
+			name:        "question answered",
+			text:        "tools yang di butuhkan untuk koding?",
+			linked:      true,
+			answer:      "Text editor dan git.",
+			wantSent:    []string{"Text editor dan git."},
+			wantAsked:   true,
+			wantUpdated: 3,
+		},
+		{
+			name:        "long answer split",
+			text:        "tools yang di butuhkan untuk koding?",
+			linked:      true,
+			answer:      longAnswer,
+			wantSent:    []string{strings.TrimSpace(strings.Repeat("kata ", 790)), strings.TrimSpace(strings.Repeat("lagi ", 200))},
+			wantAsked:   true,
+			wantUpdated: 3,
+		},
+		{
+			name:      "answer refused",
+			text:      "tools yang di butuhkan untuk koding?",
+			linked:    true,
+			answerErr: liberrors.SetError(http.StatusConflict, "another question is being answered in this conversation"),
+			wantSent:  []string{"another question is being answered in this conversation"},
+			wantAsked: true,
+		},
+		{
+			name:      "answer failed",
+			text:      "tools yang di butuhkan untuk koding?",
+			linked:    true,
+			answerErr: liberrors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)),
+			wantSent:  []string{channelError},
+			wantAsked: true,
+		},
+		{
+			name:     "new conversation",
+			text:     "/new@contoh_bot",
+			linked:   true,
+			wantSent: []string{channelNewChat},
+		},
+		{
+			name:     "unknown command",
+			text:     "/help",
+			linked:   true,
+			wantSent: []string{channelHelp},
+		},
+		{
+			name:     "not text",
+			wantSent: []string{channelTextOnly},
+		},
+	}
+	// every channel answers the same, only split to its own limits
+	channels := map[string]int{
+		constrans.ChannelTelegram: channel.TelegramMessageLength,
+		constrans.ChannelWhatsApp: channel.WhatsAppMessageLength,
+		constrans.ChannelSlack:    channel.SlackMessageLength,
+	}
+	for name, messageLength := range channels {
+		for _, tt := range tests {
+			t.Run(name+" "+tt.name, func(t *testing.T) {
+				var sent []string
+				ch := newChannel(name, messageLength, &sent)
+				channelRepo := new(mocks.ChannelRepository)
+				if tt.linked {
+					channelRepo.On("GetIdentity", mock.Anything, name, "u-42").Return(&entity.ChannelIdentity{ID: 5, Channel: name, ExternalID: "u-42", UserID: 1}, nil)
+				} else {
+					channelRepo.On("GetIdentity", mock.Anything, name, "u-42").Return(nil, gorm.ErrRecordNotFound)
+				}
+				channelRepo.On("UpdateConversation", mock.Anything, 5, mock.Anything).Return(nil)
+				chatUsecase := new(mocks.ChatUsecase)
+				chatUsecase.On("ChatQuestion", mock.Anything, 1, dto.ChatQuestionRequest{Question: tt.text}).
+					Return(dto.ChatQuestionResponse{ConversationId: 3, Answer: tt.answer}, tt.answerErr)
+
+				s := &defaultChannelUsecase{
+					chatUsecase: chatUsecase,
+					channelRepo: channelRepo,
+				}
+				s.handleMessage(ctx, ch, channel.Message{ExternalId: "u-42", Text: tt.text})
+
+				if !reflect.DeepEqual(sent, tt.wantSent) {
+					t.Errorf("defaultChannelUsecase.handleMessage() sent %q, want %q", sent, tt.wantSent)
+				}
+				if tt.wantAsked {
+					chatUsecase.AssertCalled(t, "ChatQuestion", mock.Anything, 1, dto.ChatQuestionRequest{Question: tt.text})
+				} else {
+					chatUsecase.AssertNotCalled(t, "ChatQuestion", mock.Anything, mock.Anything, mock.Anything)
+				}
+				if tt.wantUpdated != 0 {
+					channelRepo.AssertCalled(t, "UpdateConversation", mock.Anything, 5, tt.wantUpdated)
+				}
+			})
+		}
+	}
+}
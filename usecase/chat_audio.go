@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/google/uuid"
+)
+
+// audioContentTypes are the recording formats accepted for voice questions,
+// by file extension, as the speech provider detects the format from it
+var audioContentTypes = map[string]string{
+	".flac": "audio/flac",
+	".m4a":  "audio/mp4",
+	".mp3":  "audio/mpeg",
+	".mp4":  "audio/mp4",
+	".mpeg": "audio/mpeg",
+	".mpga": "audio/mpeg",
+	".ogg":  "audio/ogg",
+	".wav":  "audio/wav",
+	".webm": "audio/webm",
+}
+
+// chatVoice is the recording a question was asked with. When speak is set the
+// answer is spoken back into speech.
+type chatVoice struct {
+	audio  chatFile
+	speak  bool
+	speech *chatFile
+}
+
+// decodeAudio validates the recording of a voice question and assigns it a
+// storage key
+func decodeAudio(ctx context.Context, userId int, input dto.AudioInput) (audio chatFile, err error) {
+	if len(input.Content) == 0 || len(input.Content) > MaxAudioSize {
+		logger.Error(ctx, "audio size not valid")
+		err = errors.SetError(http.StatusBadRequest, fmt.Sprintf("audio must not be empty or larger than %v MB", MaxAudioSize>>20))
+		return
+	}
+
+	extension := strings.ToLower(filepath.Ext(input.Name))
+	contentType, ok := audioContentTypes[extension]
+	if !ok {
+		logger.Error(ctx, "audio type not valid", input.Name)
+		err = errors.SetError(http.StatusBadRequest, "audio must be a flac, m4a, mp3, mp4, mpeg, mpga, ogg, wav or webm file")
+		return
+	}
+
+	audio = chatFile{
+		attachment: entity.ChatAttachment{
+			UserID:      userId,
+			Kind:        constrans.AttachmentAudio,
+			Name:        input.Name,
+			ContentType: contentType,
+			Size:        len(input.Content),
+			StorageKey:  fmt.Sprintf("audio/%v/%v%v", userId, uuid.New().String(), extension),
+		},
+		data: input.Content,
+	}
+	return
+}
+
+// speakAnswer synthesizes the answer to a voice question. Long answers are
+// cut to MaxSpeechLength characters. Failing to speak is only logged, since
+// the answer itself is still returned.
+func (s *defaultChatUsecase) speakAnswer(ctx context.Context, userId int, answer string) (speechFile chatFile, err error) {
+	text := truncateRunes(answer, MaxSpeechLength)
+	speech, err := s.speechProvider.Synthesize(ctx, text)
+	if err != nil {
+		logger.Error(ctx, "error synthesizing speech", err.Error())
+		return
+	}
+
+	speechFile = chatFile{
+		attachment: entity.ChatAttachment{
+			UserID:      userId,
+			Kind:        constrans.AttachmentSpeech,
+			Name:        "answer" + speech.Extension,
+			ContentType: speech.ContentType,
+			Size:        len(speech.Data),
+			StorageKey:  fmt.Sprintf("audio/%v/%v%v", userId, uuid.New().String(), speech.Extension),
+			Transcript:  text,
+		},
+		data: speech.Data,
+	}
+	return
+}
+
+// toAudioResponses converts the recordings and spoken answers of a message
+// to their dto
+func toAudioResponses(attachments []entity.ChatAttachment) (resp []dto.AudioResponse) {
+	for i := 0; i < len(attachments); i++ {
+		if attachments[i].Kind != constrans.AttachmentAudio && attachments[i].Kind != constrans.AttachmentSpeech {
+			continue
+		}
+		resp = append(resp, toAudioResponse(attachments[i]))
+	}
+	return
+}
+
+// toAudioResponse converts an audio attachment to its dto
+func toAudioResponse(attachment entity.ChatAttachment) dto.AudioResponse {
+	return dto.AudioResponse{
+		Id:          attachment.ID,
+		Kind:        attachment.Kind,
+		Name:        attachment.Name,
+		ContentType: attachment.ContentType,
+		Language:    attachment.Language,
+		Duration:    attachment.Duration,
+		Transcript:  attachment.Transcript,
+		Url:         fmt.Sprintf("/chat/audio?id=%v", attachment.ID),
+	}
+}
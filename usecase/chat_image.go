@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/google/uuid"
+	"github.com/sashabaranov/go-openai"
+)
+
+// imageExtensions are the image types accepted in questions
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// chatFile is a file attached to a message with its decoded content
+type chatFile struct {
+	attachment entity.ChatAttachment
+	data       []byte
+}
+
+// decodeImages validates the images of a question and assigns each of them a
+// storage key. The type is detected from the content, not from the request.
+func decodeImages(ctx context.Context, userId int, inputs []dto.ImageInput) (images []chatFile, err error) {
+	if len(inputs) > MaxImages {
+		logger.Error(ctx, "too many images")
+		err = errors.SetError(http.StatusBadRequest, fmt.Sprintf("at most %v images are allowed", MaxImages))
+		return
+	}
+
+	for i := 0; i < len(inputs); i++ {
+		data := inputs[i].Content
+		if data == nil {
+			encoded := inputs[i].Data
+			if strings.HasPrefix(encoded, "data:") {
+				encoded = encoded[strings.Index(encoded, ",")+1:]
+			}
+
+			data, err = base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				logger.Error(ctx, "image not valid base64", err.Error())
+				err = errors.SetError(http.StatusBadRequest, "image must be base64 encoded")
+				return
+			}
+		}
+
+		if len(data) == 0 || len(data) > MaxImageSize {
+			logger.Error(ctx, "image size not valid")
+			err = errors.SetError(http.StatusBadRequest, fmt.Sprintf("image must not be empty or larger than %v MB", MaxImageSize>>20))
+			return
+		}
+
+		contentType := http.DetectContentType(data)
+		extension, ok := imageExtensions[contentType]
+		if !ok {
+			logger.Error(ctx, "image type not valid", contentType)
+			err = errors.SetError(http.StatusBadRequest, "image must be a png, jpeg, gif or webp file")
+			return
+		}
+
+		images = append(images, chatFile{
+			attachment: entity.ChatAttachment{
+				UserID:      userId,
+				Kind:        constrans.AttachmentImage,
+				Name:        inputs[i].Name,
+				ContentType: contentType,
+				Size:        len(data),
+				StorageKey:  fmt.Sprintf("images/%v/%v%v", userId, uuid.New().String(), extension),
+			},
+			data: data,
+		})
+	}
+	return
+}
+
+// questionMessage builds the user message of a question. Images are sent as
+// data URLs next to the text so vision models can see them.
+func questionMessage(question string, images []chatFile) openai.ChatCompletionMessage {
+	if len(images) == 0 {
+		return openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleUser,
+			Content: question,
+		}
+	}
+
+	message := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser}
+	if question != "" {
+		message.MultiContent = append(message.MultiContent, openai.ChatMessagePart{
+			Type: openai.ChatMessagePartTypeText,
+			Text: question,
+		})
+	}
+	for i := 0; i < len(images); i++ {
+		message.MultiContent = append(message.MultiContent, openai.ChatMessagePart{
+			Type: openai.ChatMessagePartTypeImageURL,
+			ImageURL: &openai.ChatMessageImageURL{
+				URL:    fmt.Sprintf("data:%v;base64,%v", images[i].attachment.ContentType, base64.StdEncoding.EncodeToString(images[i].data)),
+				Detail: openai.ImageURLDetailAuto,
+			},
+		})
+	}
+	return message
+}
+
+// contextMessages returns the messages kept in the cached context, with the
+// images of the questions left out. An image is sent once, with its question,
+// as the context rebuilt from the history has only the text of the questions.
+func contextMessages(messages []openai.ChatCompletionMessage) (resp []openai.ChatCompletionMessage) {
+	for _, message := range messages {
+		if len(message.MultiContent) > 0 {
+			var texts []string
+			for _, part := range message.MultiContent {
+				if part.Type == openai.ChatMessagePartTypeText {
+					texts = append(texts, part.Text)
+				}
+			}
+			message.Content = strings.Join(texts, "\n")
+			message.MultiContent = nil
+		}
+		resp = append(resp, message)
+	}
+	return
+}
+
+// toImageResponses converts the image attachments of a message to their dto
+func toImageResponses(attachments []entity.ChatAttachment) (resp []dto.ImageResponse) {
+	for i := 0; i < len(attachments); i++ {
+		if attachments[i].Kind != constrans.AttachmentImage {
+			continue
+		}
+		resp = append(resp, dto.ImageResponse{
+			Id:          attachments[i].ID,
+			Name:        attachments[i].Name,
+			ContentType: attachments[i].ContentType,
+			Url:         fmt.Sprintf("/chat/images?id=%v", attachments[i].ID),
+		})
+	}
+	return
+}
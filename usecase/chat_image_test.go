@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+)
+
+func Test_decodeImages(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	encoded := base64.StdEncoding.EncodeToString(png)
+
+	tests := []struct {
+		name            string
+		inputs          []dto.ImageInput
+		wantContentType string
+		wantErr         bool
+	}{
+		{
+			name:   "no images",
+			inputs: nil,
+		},
+		{
+			name:    "too many images",
+			inputs:  make([]dto.ImageInput, MaxImages+1),
+			wantErr: true,
+		},
+		{
+			name:    "data is not base64",
+			inputs:  []dto.ImageInput{{Data: "bukan base64!"}},
+			wantErr: true,
+		},
+		{
+			name:    "content is not an image",
+			inputs:  []dto.ImageInput{{Content: []byte("teks biasa")}},
+			wantErr: true,
+		},
+		{
+			name:    "image too large",
+			inputs:  []dto.ImageInput{{Content: append(png, make([]byte, MaxImageSize)...)}},
+			wantErr: true,
+		},
+		{
+			name:            "base64 data url",
+			inputs:          []dto.ImageInput{{Name: "label.png", Data: "data:image/png;base64," + encoded}},
+			wantContentType: "image/png",
+		},
+		{
+			name:            "uploaded content",
+			inputs:          []dto.ImageInput{{Name: "label.png", Content: png}},
+			wantContentType: "image/png",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeImages(ctx, 1, tt.inputs)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeImages() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != len(tt.inputs) && !tt.wantErr {
+				t.Errorf("decodeImages() = %v images, want %v", len(got), len(tt.inputs))
+				return
+			}
+			for i := 0; i < len(got); i++ {
+				attachment := got[i].attachment
+				if attachment.ContentType != tt.wantContentType || attachment.Size != len(png) || !strings.HasPrefix(attachment.StorageKey, "images/1/") {
+					t.Errorf("decodeImages() = %+v", attachment)
+				}
+			}
+		})
+	}
+}
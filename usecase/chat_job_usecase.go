@@ -0,0 +1,276 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/http/webhook"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/google/uuid"
+	"github.com/spf13/cast"
+)
+
+const (
+	// KeyChatJob prefixes the keys of the chat jobs and ChatJobQueue is the
+	// queue of the jobs waiting for a worker
+	KeyChatJob   = "ChatJob"
+	ChatJobQueue = "ChatJobQueue"
+
+	DefaultChatWorkers = 4
+	DefaultChatJobTTL  = 24 * time.Hour
+
+	// ChatJobPollTimeout is how long a worker waits on the queue before it
+	// checks whether it was stopped
+	ChatJobPollTimeout = 5 * time.Second
+
+	// ChatJobCallbackAttempts is the number of times the callback is sent
+	// before it is given up, waiting a second longer after every attempt
+	ChatJobCallbackAttempts = 3
+)
+
+type ChatJobUsecase interface {
+	EnqueueChat(ctx context.Context, userId int, req dto.ChatQuestionRequest) (resp dto.ChatJobResponse, err error)
+	GetChatJob(ctx context.Context, userId int, id string) (resp dto.ChatJobResponse, err error)
+	StartWorkers(ctx context.Context)
+}
+
+type defaultChatJobUsecase struct {
+	chatUsecase   ChatUsecase
+	cacheWrapper  cached.CacheWrapper
+	queue         cached.Queue
+	webhookSender webhook.Sender
+}
+
+// NewChatJobUsecase creates a new instance of ChatJobUsecase
+func NewChatJobUsecase(chatUsecase ChatUsecase, cacheWrapper cached.CacheWrapper, queue cached.Queue, webhookSender webhook.Sender) ChatJobUsecase {
+	return &defaultChatJobUsecase{
+		chatUsecase:   chatUsecase,
+		cacheWrapper:  cacheWrapper,
+		queue:         queue,
+		webhookSender: webhookSender,
+	}
+}
+
+// EnqueueChat queues a question to be answered by the workers and returns
+// the pending job. When a callback url is given, the finished job is posted
+// to it signed with CHAT_WEBHOOK_SECRET.
+func (s *defaultChatJobUsecase) EnqueueChat(ctx context.Context, userId int, req dto.ChatQuestionRequest) (resp dto.ChatJobResponse, err error) {
+	if req.CallbackUrl != "" {
+		callbackUrl, errRes := url.Parse(req.CallbackUrl)
+		if errRes != nil || (callbackUrl.Scheme != "http" && callbackUrl.Scheme != "https") || callbackUrl.Host == "" {
+			logger.Error(ctx, "callback url not valid", req.CallbackUrl)
+			err = errors.SetError(http.StatusBadRequest, "callbackUrl must be an http or https url")
+			return
+		}
+		errRes = s.webhookSender.CheckUrl(ctx, req.CallbackUrl)
+		if errRes != nil {
+			logger.Error(ctx, "callback url not allowed", req.CallbackUrl, errRes.Error())
+			err = errors.SetError(http.StatusBadRequest, "callbackUrl must resolve to a public address")
+			return
+		}
+		if os.Getenv("CHAT_WEBHOOK_SECRET") == "" {
+			logger.Error(ctx, "chat webhook secret is not set")
+			err = errors.SetError(http.StatusBadRequest, "callbacks are not enabled")
+			return
+		}
+	}
+
+	// uploaded images are kept base64 encoded with the request
+	for i := 0; i < len(req.Images); i++ {
+		if req.Images[i].Content != nil {
+			req.Images[i].Data = base64.StdEncoding.EncodeToString(req.Images[i].Content)
+			req.Images[i].Content = nil
+		}
+	}
+
+	request, _ := json.Marshal(req)
+	now := time.Now()
+	job := cached.ChatJob{
+		ID:          uuid.New().String(),
+		UserID:      userId,
+		Status:      constrans.JobStatusPending,
+		Request:     string(request),
+		CallbackUrl: req.CallbackUrl,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = s.saveJob(ctx, job)
+	if err != nil {
+		logger.Error(ctx, "error creating chat job", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	err = s.queue.Push(ctx, ChatJobQueue, job.ID)
+	if err != nil {
+		logger.Error(ctx, "error queueing chat job", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toChatJobResponse(job)
+	return
+}
+
+// GetChatJob returns a chat job of the user
+func (s *defaultChatJobUsecase) GetChatJob(ctx context.Context, userId int, id string) (resp dto.ChatJobResponse, err error) {
+	job, err := s.getJob(ctx, id)
+	if err != nil || job.UserID != userId {
+		logger.Error(ctx, "chat job not found", id)
+		err = errors.SetError(http.StatusNotFound, "chat job not found")
+		return
+	}
+
+	resp = toChatJobResponse(job)
+	return
+}
+
+// StartWorkers starts the CHAT_WORKERS workers that answer the queued
+// questions until the context is done. A job that was running when its
+// instance stopped is not picked up again, and stays running until it
+// expires.
+func (s *defaultChatJobUsecase) StartWorkers(ctx context.Context) {
+	workers := cast.ToInt(os.Getenv("CHAT_WORKERS"))
+	if workers <= 0 {
+		workers = DefaultChatWorkers
+	}
+
+	for i := 0; i < workers; i++ {
+		go s.work(ctx)
+	}
+}
+
+// work answers the queued questions one at a time
+func (s *defaultChatJobUsecase) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		id, err := s.queue.Pop(ctx, ChatJobQueue, ChatJobPollTimeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error(ctx, "error reading chat job queue", err.Error())
+				time.Sleep(ChatJobPollTimeout)
+			}
+			continue
+		}
+		if id == "" {
+			continue
+		}
+
+		s.runChatJob(ctx, id)
+	}
+}
+
+// runChatJob answers the question of a job through the chat usecase, as if
+// the user asked it, and sends the finished job to its callback url
+func (s *defaultChatJobUsecase) runChatJob(ctx context.Context, id string) {
+	job, err := s.getJob(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "chat job not found", id, err.Error())
+		return
+	}
+
+	job.Status = constrans.JobStatusRunning
+	job.UpdatedAt = time.Now()
+	s.saveJob(ctx, job)
+
+	var req dto.ChatQuestionRequest
+	json.Unmarshal([]byte(job.Request), &req)
+	answer, err := s.chatUsecase.ChatQuestion(ctx, job.UserID, req)
+	if err != nil {
+		logger.Error(ctx, "chat job failed", id, err.Error())
+		job.Status = constrans.JobStatusFailed
+		job.Error = err.Error()
+		job.ErrorCode = errors.GetErrorCode(err)
+	} else {
+		result, _ := json.Marshal(answer)
+		job.Status = constrans.JobStatusCompleted
+		job.Response = string(result)
+	}
+	job.UpdatedAt = time.Now()
+	s.saveJob(ctx, job)
+
+	if job.CallbackUrl != "" {
+		s.sendCallback(ctx, job)
+	}
+}
+
+// sendCallback posts the finished job to its callback url, and records how
+// the callback went on the job
+func (s *defaultChatJobUsecase) sendCallback(ctx context.Context, job cached.ChatJob) {
+	payload, _ := json.Marshal(toChatJobResponse(job))
+	secret := os.Getenv("CHAT_WEBHOOK_SECRET")
+
+	var err error
+	for attempt := 1; attempt <= ChatJobCallbackAttempts; attempt++ {
+		job.CallbackStatus, err = s.webhookSender.Send(ctx, job.CallbackUrl, secret, payload)
+		if err == nil {
+			break
+		}
+		logger.Error(ctx, "error sending chat job callback", job.ID, attempt, err.Error())
+		if attempt < ChatJobCallbackAttempts {
+			time.Sleep(time.Duration(attempt) * time.Second)
+		}
+	}
+
+	job.CallbackError = ""
+	if err != nil {
+		job.CallbackError = err.Error()
+	}
+	s.saveJob(ctx, job)
+}
+
+// getJob returns the chat job with the id
+func (s *defaultChatJobUsecase) getJob(ctx context.Context, id string) (job cached.ChatJob, err error) {
+	value, err := s.cacheWrapper.Get(ctx, fmt.Sprintf("%v_%v", KeyChatJob, id))
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal([]byte(value), &job)
+	return
+}
+
+// saveJob stores the chat job for CHAT_JOB_TTL after it was created
+func (s *defaultChatJobUsecase) saveJob(ctx context.Context, job cached.ChatJob) (err error) {
+	ttl := cast.ToDuration(os.Getenv("CHAT_JOB_TTL"))
+	if ttl <= 0 {
+		ttl = DefaultChatJobTTL
+	}
+	expiry := ttl - time.Since(job.CreatedAt)
+	if expiry < time.Second {
+		expiry = time.Second
+	}
+
+	data, _ := json.Marshal(job)
+	err = s.cacheWrapper.Set(ctx, fmt.Sprintf("%v_%v", KeyChatJob, job.ID), string(data), expiry)
+	return
+}
+
+func toChatJobResponse(job cached.ChatJob) (resp dto.ChatJobResponse) {
+	resp = dto.ChatJobResponse{
+		Id:             job.ID,
+		Status:         job.Status,
+		Error:          job.Error,
+		ErrorCode:      job.ErrorCode,
+		CallbackUrl:    job.CallbackUrl,
+		CallbackStatus: job.CallbackStatus,
+		CallbackError:  job.CallbackError,
+		CreatedAt:      job.CreatedAt,
+		UpdatedAt:      job.UpdatedAt,
+	}
+	if job.Response != "" {
+		var result dto.ChatQuestionResponse
+		json.Unmarshal([]byte(job.Response), &result)
+		resp.Result = &result
+	}
+	return
+}
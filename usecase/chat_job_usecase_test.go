@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/http/webhook"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	liberrors "github.com/fadilahonespot/library/errors"
+	"github.com/stretchr/testify/mock"
+)
+
+// newJobCache returns a cache mock that keeps the values set in it
+func newJobCache() *mocks.CacheWrapper {
+	values := map[string]string{}
+	cacheWrapper := new(mocks.CacheWrapper)
+	cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		values[args.String(1)] = args.String(2)
+	}).Return(nil)
+	cacheWrapper.On("Get", mock.Anything, mock.Anything).Return(func(ctx context.Context, key string) (string, error) {
+		value, ok := values[key]
+		if !ok {
+			return "", errors.New("redis: nil")
+		}
+		return value, nil
+	})
+	return cacheWrapper
+}
+
+func Test_defaultChatJobUsecase_EnqueueChat(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+	t.Setenv("CHAT_WEBHOOK_SECRET", "rahasia")
+
+	tests := []struct {
+		name    string
+		req     dto.ChatQuestionRequest
+		wantErr bool
+	}{
+		{
+			name: "without callback",
+			req:  dto.ChatQuestionRequest{Question: "tools yang di butuhkan untuk koding?"},
+		},
+		{
+			name: "with callback",
+			req:  dto.ChatQuestionRequest{Question: "tools yang di butuhkan untuk koding?", CallbackUrl: "https://example.com/hooks/chat"},
+		},
+		{
+			name:    "callback not http",
+			req:     dto.ChatQuestionRequest{Question: "tools yang di butuhkan untuk koding?", CallbackUrl: "file:///etc/passwd"},
+			wantErr: true,
+		},
+		{
+			name:    "callback to an internal address",
+			req:     dto.ChatQuestionRequest{Question: "tools yang di butuhkan untuk koding?", CallbackUrl: "http://127.0.0.1:6379/"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheWrapper := newJobCache()
+			queue := new(mocks.Queue)
+			queue.On("Push", mock.Anything, ChatJobQueue, mock.Anything).Return(nil)
+			webhookSender := new(mocks.Sender)
+			webhookSender.On("CheckUrl", mock.Anything, "https://example.com/hooks/chat").Return(nil)
+			webhookSender.On("CheckUrl", mock.Anything, mock.Anything).Return(webhook.ErrAddressNotAllowed)
+
+			s := NewChatJobUsecase(nil, cacheWrapper, queue, webhookSender)
+			gotResp, err := s.EnqueueChat(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("defaultChatJobUsecase.EnqueueChat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				queue.AssertNotCalled(t, "Push", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+
+			queue.AssertCalled(t, "Push", mock.Anything, ChatJobQueue, gotResp.Id)
+			if gotResp.Status != constrans.JobStatusPending {
+				t.Errorf("defaultChatJobUsecase.EnqueueChat() status = %v, want %v", gotResp.Status, constrans.JobStatusPending)
+			}
+
+			// the job is only visible to the user who asked
+			_, err = s.GetChatJob(ctx, 1, gotResp.Id)
+			if err != nil {
+				t.Errorf("defaultChatJobUsecase.GetChatJob() error = %v", err)
+			}
+			_, err = s.GetChatJob(ctx, 2, gotResp.Id)
+			if err == nil {
+				t.Errorf("defaultChatJobUsecase.GetChatJob() of another user error = nil, want not found")
+			}
+		})
+	}
+}
+
+func Test_defaultChatJobUsecase_runChatJob(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+	t.Setenv("CHAT_WEBHOOK_SECRET", "rahasia")
+
+	tests := []struct {
+		name           string
+		callbackUrl    string
+		answerErr      error
+		callbackErr    error
+		wantStatus     string
+		wantErrorCode  int
+		wantCallbacks  int
+		wantCallbackOk bool
+	}{
+		{
+			name:       "answered",
+			wantStatus: constrans.JobStatusCompleted,
+		},
+		{
+			name:           "answered with callback",
+			callbackUrl:    "https://example.com/hooks/chat",
+			wantStatus:     constrans.JobStatusCompleted,
+			wantCallbacks:  1,
+			wantCallbackOk: true,
+		},
+		{
+			name:           "conversation locked",
+			callbackUrl:    "https://example.com/hooks/chat",
+			answerErr:      liberrors.SetError(http.StatusConflict, "another question is being answered in this conversation"),
+			wantStatus:     constrans.JobStatusFailed,
+			wantErrorCode:  http.StatusConflict,
+			wantCallbacks:  1,
+			wantCallbackOk: true,
+		},
+		{
+			name:          "callback keeps failing",
+			callbackUrl:   "https://example.com/hooks/chat",
+			callbackErr:   errors.New("webhook responded with status 500"),
+			wantStatus:    constrans.JobStatusCompleted,
+			wantCallbacks: ChatJobCallbackAttempts,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cacheWrapper := newJobCache()
+			chatUsecase := new(mocks.ChatUsecase)
+			chatUsecase.On("ChatQuestion", mock.Anything, 1, dto.ChatQuestionRequest{Question: "tools yang di butuhkan untuk koding?", CallbackUrl: tt.callbackUrl}).
+				Return(dto.ChatQuestionResponse{ConversationId: 3, Answer: "Text editor dan git."}, tt.answerErr).Once()
+			webhookSender := new(mocks.Sender)
+			webhookSender.On("Send", mock.Anything, tt.callbackUrl, "rahasia", mock.Anything).Return(200, tt.callbackErr)
+
+			s := &defaultChatJobUsecase{
+				chatUsecase:   chatUsecase,
+				cacheWrapper:  cacheWrapper,
+				webhookSender: webhookSender,
+			}
+			request, _ := json.Marshal(dto.ChatQuestionRequest{Question: "tools yang di butuhkan untuk koding?", CallbackUrl: tt.callbackUrl})
+			s.saveJob(ctx, cached.ChatJob{ID: "job-1", UserID: 1, Status: constrans.JobStatusPending, Request: string(request), CallbackUrl: tt.callbackUrl, CreatedAt: time.Now()})
+
+			s.runChatJob(ctx, "job-1")
+
+			gotResp, err := s.GetChatJob(ctx, 1, "job-1")
+			if err != nil {
+				t.Fatalf("defaultChatJobUsecase.GetChatJob() error = %v", err)
+			}
+			if gotResp.Status != tt.wantStatus || gotResp.ErrorCode != tt.wantErrorCode {
+				t.Errorf("defaultChatJobUsecase.runChatJob() = %v, %v, want %v, %v", gotResp.Status, gotResp.ErrorCode, tt.wantStatus, tt.wantErrorCode)
+			}
+			if tt.answerErr == nil && (gotResp.Result == nil || gotResp.Result.Answer != "Text editor dan git.") {
+				t.Errorf("defaultChatJobUsecase.runChatJob() result = %+v, want the answer", gotResp.Result)
+			}
+			if (gotResp.CallbackError == "") != (tt.wantCallbackOk || tt.callbackUrl == "") {
+				t.Errorf("defaultChatJobUsecase.runChatJob() callback error = %q", gotResp.CallbackError)
+			}
+			webhookSender.AssertNumberOfCalls(t, "Send", tt.wantCallbacks)
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+)
+
+type ChatModelUsecase interface {
+	CreateChatModel(ctx context.Context, req dto.ChatModelRequest) (resp dto.ChatModelResponse, err error)
+	UpdateChatModel(ctx context.Context, id int, req dto.ChatModelRequest) (resp dto.ChatModelResponse, err error)
+	DeleteChatModel(ctx context.Context, id int) (err error)
+	GetChatModels(ctx context.Context) (resp []dto.ChatModelResponse, err error)
+	GetEnabledChatModels(ctx context.Context) (resp []dto.ChatModelResponse, err error)
+}
+
+type defaultChatModelUsecase struct {
+	chatModelRepo mysql.ChatModelRepository
+}
+
+// NewChatModelUsecase creates a new instance of ChatModelUsecase
+func NewChatModelUsecase(chatModelRepo mysql.ChatModelRepository) ChatModelUsecase {
+	return &defaultChatModelUsecase{
+		chatModelRepo: chatModelRepo,
+	}
+}
+
+// CreateChatModel adds a model to the allowlist
+func (s *defaultChatModelUsecase) CreateChatModel(ctx context.Context, req dto.ChatModelRequest) (resp dto.ChatModelResponse, err error) {
+	err = validateChatModel(ctx, req)
+	if err != nil {
+		return
+	}
+
+	existing, _ := s.chatModelRepo.GetByName(ctx, req.Name)
+	if existing != nil && existing.ID != 0 {
+		logger.Error(ctx, "model already exists")
+		err = errors.SetError(http.StatusBadRequest, "model already exists")
+		return
+	}
+
+	// a deleted model still holds its name, so it is restored with the
+	// limits of the request instead
+	chatModel, errRes := s.chatModelRepo.GetDeletedByName(ctx, req.Name)
+	if errRes != nil {
+		chatModel = &entity.ChatModel{}
+	}
+	applyChatModelRequest(chatModel, req)
+	if chatModel.ID != 0 {
+		err = s.chatModelRepo.Restore(ctx, chatModel)
+	} else {
+		err = s.chatModelRepo.Create(ctx, chatModel)
+	}
+	if err != nil {
+		logger.Error(ctx, "error creating model", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toChatModelResponse(*chatModel)
+	return
+}
+
+// UpdateChatModel changes the limits of an allowlisted model
+func (s *defaultChatModelUsecase) UpdateChatModel(ctx context.Context, id int, req dto.ChatModelRequest) (resp dto.ChatModelResponse, err error) {
+	err = validateChatModel(ctx, req)
+	if err != nil {
+		return
+	}
+
+	chatModel, err := s.chatModelRepo.GetById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "model not found", err.Error())
+		err = errors.SetError(http.StatusNotFound, "model not found")
+		return
+	}
+
+	// the name cannot be taken from another model, deleted ones included
+	if req.Name != chatModel.Name {
+		existing, _ := s.chatModelRepo.GetByName(ctx, req.Name)
+		if existing != nil && existing.ID != 0 {
+			logger.Error(ctx, "model already exists")
+			err = errors.SetError(http.StatusBadRequest, "model already exists")
+			return
+		}
+
+		deleted, _ := s.chatModelRepo.GetDeletedByName(ctx, req.Name)
+		if deleted != nil && deleted.ID != 0 {
+			logger.Error(ctx, "model name belongs to a deleted model")
+			err = errors.SetError(http.StatusConflict, "model name belongs to a deleted model, create it again to restore it")
+			return
+		}
+	}
+
+	applyChatModelRequest(chatModel, req)
+	err = s.chatModelRepo.Update(ctx, chatModel)
+	if err != nil {
+		logger.Error(ctx, "error updating model", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toChatModelResponse(*chatModel)
+	return
+}
+
+// DeleteChatModel removes a model from the allowlist
+func (s *defaultChatModelUsecase) DeleteChatModel(ctx context.Context, id int) (err error) {
+	_, err = s.chatModelRepo.GetById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "model not found", err.Error())
+		err = errors.SetError(http.StatusNotFound, "model not found")
+		return
+	}
+
+	err = s.chatModelRepo.Delete(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "error deleting model", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	return
+}
+
+// GetChatModels returns the whole allowlist
+func (s *defaultChatModelUsecase) GetChatModels(ctx context.Context) (resp []dto.ChatModelResponse, err error) {
+	chatModels, err := s.chatModelRepo.GetAll(ctx)
+	if err != nil {
+		logger.Error(ctx, "error getting models", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(chatModels); i++ {
+		resp = append(resp, toChatModelResponse(chatModels[i]))
+	}
+	return
+}
+
+// GetEnabledChatModels returns the models users can currently request
+func (s *defaultChatModelUsecase) GetEnabledChatModels(ctx context.Context) (resp []dto.ChatModelResponse, err error) {
+	chatModels, err := s.chatModelRepo.GetAll(ctx)
+	if err != nil {
+		logger.Error(ctx, "error getting models", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(chatModels); i++ {
+		if !chatModels[i].Enabled {
+			continue
+		}
+		resp = append(resp, toChatModelResponse(chatModels[i]))
+	}
+	return
+}
+
+// validateChatModel checks the limits an admin sets for a model
+func validateChatModel(ctx context.Context, req dto.ChatModelRequest) (err error) {
+	if strings.TrimSpace(req.Name) == "" {
+		logger.Error(ctx, "model name is required")
+		err = errors.SetError(http.StatusBadRequest, "model name is required")
+		return
+	}
+
+	if req.MaxTokens < 0 {
+		logger.Error(ctx, "max tokens not valid")
+		err = errors.SetError(http.StatusBadRequest, "max tokens must not be negative")
+		return
+	}
+
+	if req.MaxTemperature < 0 || req.MaxTemperature > 2 {
+		logger.Error(ctx, "max temperature not valid")
+		err = errors.SetError(http.StatusBadRequest, "max temperature must be between 0 and 2")
+		return
+	}
+	return
+}
+
+// applyChatModelRequest copies the request fields into the model
+func applyChatModelRequest(chatModel *entity.ChatModel, req dto.ChatModelRequest) {
+	chatModel.Name = req.Name
+	chatModel.MaxTokens = req.MaxTokens
+	chatModel.MaxTemperature = req.MaxTemperature
+	chatModel.SupportsJsonFormat = req.SupportsJsonFormat
+	chatModel.SupportsVision = req.SupportsVision
+	chatModel.Enabled = req.Enabled
+}
+
+// toChatModelResponse converts a model to its dto
+func toChatModelResponse(chatModel entity.ChatModel) dto.ChatModelResponse {
+	return dto.ChatModelResponse{
+		Id:                 chatModel.ID,
+		Name:               chatModel.Name,
+		MaxTokens:          chatModel.MaxTokens,
+		MaxTemperature:     chatModel.MaxTemperature,
+		SupportsJsonFormat: chatModel.SupportsJsonFormat,
+		SupportsVision:     chatModel.SupportsVision,
+		Enabled:            chatModel.Enabled,
+	}
+}
@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	liberrors "github.com/fadilahonespot/library/errors"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultChatModelUsecase_CreateChatModel(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	type args struct {
+		ctx context.Context
+		req dto.ChatModelRequest
+	}
+	tests := []struct {
+		name          string
+		args          args
+		getByNameResp *entity.ChatModel
+		getByNameErr  error
+		getDeleted    *entity.ChatModel
+		createErr     error
+		wantResp      dto.ChatModelResponse
+		wantErr       bool
+	}{
+		{
+			name: "name is empty",
+			args: args{
+				ctx: ctx,
+				req: dto.ChatModelRequest{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "max temperature not valid",
+			args: args{
+				ctx: ctx,
+				req: dto.ChatModelRequest{
+					Name:           "gpt-4",
+					MaxTemperature: 5,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "model already exists",
+			args: args{
+				ctx: ctx,
+				req: dto.ChatModelRequest{
+					Name: "gpt-4",
+				},
+			},
+			getByNameResp: &entity.ChatModel{
+				ID:   1,
+				Name: "gpt-4",
+			},
+			wantErr: true,
+		},
+		{
+			name: "create model error",
+			args: args{
+				ctx: ctx,
+				req: dto.ChatModelRequest{
+					Name: "gpt-4",
+				},
+			},
+			getByNameErr: errors.New("record not found"),
+			createErr:    errors.New("create model error"),
+			wantErr:      true,
+		},
+		{
+			name: "success create model",
+			args: args{
+				ctx: ctx,
+				req: dto.ChatModelRequest{
+					Name:               "gpt-4",
+					MaxTokens:          1000,
+					MaxTemperature:     1,
+					SupportsJsonFormat: true,
+					Enabled:            true,
+				},
+			},
+			getByNameErr: errors.New("record not found"),
+			wantResp: dto.ChatModelResponse{
+				Name:               "gpt-4",
+				MaxTokens:          1000,
+				MaxTemperature:     1,
+				SupportsJsonFormat: true,
+				Enabled:            true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "success restore deleted model",
+			args: args{
+				ctx: ctx,
+				req: dto.ChatModelRequest{
+					Name:      "gpt-4",
+					MaxTokens: 2000,
+					Enabled:   true,
+				},
+			},
+			getByNameErr: errors.New("record not found"),
+			getDeleted: &entity.ChatModel{
+				ID:        3,
+				Name:      "gpt-4",
+				MaxTokens: 1000,
+			},
+			wantResp: dto.ChatModelResponse{
+				Id:        3,
+				Name:      "gpt-4",
+				MaxTokens: 2000,
+				Enabled:   true,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var getDeletedErr error
+			if tt.getDeleted == nil {
+				getDeletedErr = errors.New("record not found")
+			}
+
+			chatModelRepo := new(mocks.ChatModelRepository)
+			chatModelRepo.On("GetByName", mock.Anything, mock.Anything).Return(tt.getByNameResp, tt.getByNameErr).Once()
+			chatModelRepo.On("GetDeletedByName", mock.Anything, mock.Anything).Return(tt.getDeleted, getDeletedErr).Once()
+			chatModelRepo.On("Create", mock.Anything, mock.Anything).Return(tt.createErr).Once()
+			chatModelRepo.On("Restore", mock.Anything, mock.Anything).Return(nil).Once()
+
+			s := NewChatModelUsecase(chatModelRepo)
+			gotResp, err := s.CreateChatModel(tt.args.ctx, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultChatModelUsecase.CreateChatModel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultChatModelUsecase.CreateChatModel() = %v, want %v", gotResp, tt.wantResp)
+			}
+			if tt.getDeleted != nil {
+				chatModelRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_defaultChatModelUsecase_UpdateChatModel(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name          string
+		req           dto.ChatModelRequest
+		getByNameResp *entity.ChatModel
+		getDeleted    *entity.ChatModel
+		wantErrCode   int
+	}{
+		{
+			name: "name of another model",
+			req:  dto.ChatModelRequest{Name: "gpt-4o"},
+			getByNameResp: &entity.ChatModel{
+				ID:   2,
+				Name: "gpt-4o",
+			},
+			wantErrCode: http.StatusBadRequest,
+		},
+		{
+			name: "name of a deleted model",
+			req:  dto.ChatModelRequest{Name: "gpt-4o"},
+			getDeleted: &entity.ChatModel{
+				ID:   3,
+				Name: "gpt-4o",
+			},
+			wantErrCode: http.StatusConflict,
+		},
+		{
+			name: "success rename model",
+			req:  dto.ChatModelRequest{Name: "gpt-4o", Enabled: true},
+		},
+		{
+			name: "success keep name",
+			req:  dto.ChatModelRequest{Name: "gpt-4", MaxTokens: 500},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var getByNameErr, getDeletedErr error
+			if tt.getByNameResp == nil {
+				getByNameErr = errors.New("record not found")
+			}
+			if tt.getDeleted == nil {
+				getDeletedErr = errors.New("record not found")
+			}
+
+			chatModelRepo := new(mocks.ChatModelRepository)
+			chatModelRepo.On("GetById", mock.Anything, 1).Return(&entity.ChatModel{ID: 1, Name: "gpt-4"}, nil).Once()
+			chatModelRepo.On("GetByName", mock.Anything, tt.req.Name).Return(tt.getByNameResp, getByNameErr).Once()
+			chatModelRepo.On("GetDeletedByName", mock.Anything, tt.req.Name).Return(tt.getDeleted, getDeletedErr).Once()
+			chatModelRepo.On("Update", mock.Anything, mock.Anything).Return(nil).Once()
+
+			s := NewChatModelUsecase(chatModelRepo)
+			gotResp, err := s.UpdateChatModel(ctx, 1, tt.req)
+			if liberrors.GetErrorCode(err) != tt.wantErrCode && !(err == nil && tt.wantErrCode == 0) {
+				t.Errorf("defaultChatModelUsecase.UpdateChatModel() error = %v, want code %v", err, tt.wantErrCode)
+				return
+			}
+			if tt.wantErrCode == 0 && gotResp.Name != tt.req.Name {
+				t.Errorf("defaultChatModelUsecase.UpdateChatModel() = %v, want the name %v", gotResp, tt.req.Name)
+			}
+		})
+	}
+}
+
+func Test_defaultChatModelUsecase_GetEnabledChatModels(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name       string
+		getAllResp []entity.ChatModel
+		getAllErr  error
+		wantResp   []dto.ChatModelResponse
+		wantErr    bool
+	}{
+		{
+			name:      "get models error",
+			getAllErr: errors.New("error getting models"),
+			wantErr:   true,
+		},
+		{
+			name: "disabled models are hidden",
+			getAllResp: []entity.ChatModel{
+				{ID: 1, Name: "gpt-3.5-turbo", Enabled: true},
+				{ID: 2, Name: "gpt-4", Enabled: false},
+			},
+			wantResp: []dto.ChatModelResponse{
+				{Id: 1, Name: "gpt-3.5-turbo", Enabled: true},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatModelRepo := new(mocks.ChatModelRepository)
+			chatModelRepo.On("GetAll", mock.Anything).Return(tt.getAllResp, tt.getAllErr).Once()
+
+			s := NewChatModelUsecase(chatModelRepo)
+			gotResp, err := s.GetEnabledChatModels(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultChatModelUsecase.GetEnabledChatModels() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultChatModelUsecase.GetEnabledChatModels() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
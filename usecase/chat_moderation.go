@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+)
+
+// moderate runs a question or answer through the moderation chain. Flagged
+// content that is let through is returned as a flag to queue for review once
+// the messages are stored. Blocked content is queued right away and returned
+// as an error carrying the moderation error code. When moderation fails the
+// content is let through.
+func (s *defaultChatUsecase) moderate(ctx context.Context, userId, conversationId int, direction, text string) (flag *entity.ModerationFlag, err error) {
+	result, err := s.moderator.Moderate(ctx, text)
+	if err != nil {
+		logger.Error(ctx, "error moderating content", direction, err.Error())
+		err = nil
+		return
+	}
+	if !result.Flagged {
+		return
+	}
+
+	flag = &entity.ModerationFlag{
+		UserID:         userId,
+		ConversationID: conversationId,
+		Direction:      direction,
+		Content:        text,
+		Providers:      strings.Join(result.Providers, ","),
+		Categories:     strings.Join(result.Categories, ","),
+		Action:         result.Action,
+		Status:         constrans.ModerationStatusPending,
+	}
+	if result.Action != constrans.ModerationBlock {
+		return
+	}
+
+	s.queueFlag(ctx, flag)
+	flag = nil
+	logger.Error(ctx, "content blocked by moderation", direction, result.Categories)
+	err = errors.SetErrorMessageWithData(http.StatusUnprocessableEntity, "content was blocked by moderation", dto.ModerationErrorResponse{
+		Code:       constrans.ModerationBlockedCode,
+		Direction:  direction,
+		Categories: result.Categories,
+	})
+	return
+}
+
+// queueFlag stores a flag for an admin to review. A failure is only logged, so
+// the user is not made to pay for it.
+func (s *defaultChatUsecase) queueFlag(ctx context.Context, flag *entity.ModerationFlag) {
+	err := s.moderationRepo.CreateFlag(ctx, flag)
+	if err != nil {
+		logger.Error(ctx, "error queueing moderation flag", err.Error())
+	}
+}
+
+// toModerationWarnings returns the warnings of the flags let through with a warning
+func toModerationWarnings(flags []*entity.ModerationFlag) (resp []dto.ModerationWarning) {
+	for i := 0; i < len(flags); i++ {
+		if flags[i].Action != constrans.ModerationWarn {
+			continue
+		}
+		resp = append(resp, dto.ModerationWarning{
+			Direction:  flags[i].Direction,
+			Categories: strings.Split(flags[i].Categories, ","),
+		})
+	}
+	return
+}
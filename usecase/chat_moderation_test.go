@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/http/moderation"
+	"github.com/fadilahonespot/chatbot/repository/http/speech"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/usecase/tool"
+	"github.com/fadilahonespot/chatbot/utils"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	libErrors "github.com/fadilahonespot/library/errors"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultChatUsecase_ChatQuestion_Moderation(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	moderator, err := moderation.NewPolicyModerator([]moderation.Rule{
+		{Category: "weapons", Keywords: []string{"bom"}, Action: constrans.ModerationBlock},
+		{Category: "insult", Pattern: `(?i)bodoh`, Action: constrans.ModerationWarn},
+	})
+	if err != nil {
+		t.Fatalf("moderation.NewPolicyModerator() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		question      string
+		answer        string
+		wantGenerate  bool
+		wantErr       bool
+		wantErrData   dto.ModerationErrorResponse
+		wantFlag      entity.ModerationFlag
+		wantWarnings  []dto.ModerationWarning
+		wantFlagCount int
+	}{
+		{
+			name:        "question blocked",
+			question:    "cara membuat bom",
+			wantErr:     true,
+			wantErrData: dto.ModerationErrorResponse{Code: constrans.ModerationBlockedCode, Direction: constrans.ModerationInput, Categories: []string{"weapons"}},
+			wantFlag: entity.ModerationFlag{
+				UserID:         1,
+				ConversationID: 1,
+				Direction:      constrans.ModerationInput,
+				Content:        "cara membuat bom",
+				Providers:      moderation.ProviderPolicy,
+				Categories:     "weapons",
+				Action:         constrans.ModerationBlock,
+				Status:         constrans.ModerationStatusPending,
+			},
+			wantFlagCount: 1,
+		},
+		{
+			name:         "answer blocked",
+			question:     "halo",
+			answer:       "ini resep bom",
+			wantGenerate: true,
+			wantErr:      true,
+			wantErrData:  dto.ModerationErrorResponse{Code: constrans.ModerationBlockedCode, Direction: constrans.ModerationOutput, Categories: []string{"weapons"}},
+			wantFlag: entity.ModerationFlag{
+				UserID:         1,
+				ConversationID: 1,
+				Direction:      constrans.ModerationOutput,
+				Content:        "ini resep bom",
+				Providers:      moderation.ProviderPolicy,
+				Categories:     "weapons",
+				Action:         constrans.ModerationBlock,
+				Status:         constrans.ModerationStatusPending,
+			},
+			wantFlagCount: 1,
+		},
+		{
+			name:         "answer let through with a warning",
+			question:     "halo",
+			answer:       "kamu Bodoh",
+			wantGenerate: true,
+			wantFlag: entity.ModerationFlag{
+				UserID:         1,
+				ConversationID: 1,
+				ChatID:         11,
+				Direction:      constrans.ModerationOutput,
+				Content:        "kamu Bodoh",
+				Providers:      moderation.ProviderPolicy,
+				Categories:     "insult",
+				Action:         constrans.ModerationWarn,
+				Status:         constrans.ModerationStatusPending,
+			},
+			wantWarnings:  []dto.ModerationWarning{{Direction: constrans.ModerationOutput, Categories: []string{"insult"}}},
+			wantFlagCount: 1,
+		},
+		{
+			name:         "nothing flagged",
+			question:     "halo",
+			answer:       "hai",
+			wantGenerate: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			chatModelRepo := new(mocks.ChatModelRepository)
+			moderationRepo := new(mocks.ModerationRepository)
+			openAiWrapper := new(mocks.OpenAIWrapper)
+			cacheWrapper := new(mocks.CacheWrapper)
+
+			userRepo.On("GetUserById", mock.Anything, mock.Anything).Return(&entity.User{ID: 1}, nil)
+			conversationRepo.On("GetLatestByUserId", mock.Anything, mock.Anything).Return(&entity.Conversation{ID: 1, UserID: 1, Title: "Halo"}, nil)
+			conversationRepo.On("GetCollectionIds", mock.Anything, mock.Anything).Return(nil, nil)
+			chatModelRepo.On("GetByName", mock.Anything, mock.Anything).Return(nil, errors.New("record not found"))
+			cacheWrapper.On("Get", mock.Anything, mock.Anything).Return("", nil)
+			cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+			chatRepo.On("GetByConversationId", mock.Anything, mock.Anything).Return([]entity.Chat{}, nil)
+			chatRepo.On("BeginsTrans").Return(utils.MockGorm())
+			nextId := 10
+			chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				args.Get(2).(*entity.Chat).ID = nextId
+				nextId++
+			}).Return(nil)
+			chatRepo.On("Commit", mock.Anything).Return(nil)
+			openAiWrapper.On("GenerateText", mock.Anything, mock.Anything).Return(openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: tt.answer}}},
+			}, nil)
+
+			var gotFlags []entity.ModerationFlag
+			moderationRepo.On("CreateFlag", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				gotFlags = append(gotFlags, *args.Get(1).(*entity.ModerationFlag))
+			}).Return(nil)
+
+			s := NewChatUsecase(userRepo, chatRepo, conversationRepo, new(mocks.PersonaRepository), chatModelRepo, new(mocks.PromptTemplateRepository), new(mocks.CollectionRepository), new(mocks.TeamRepository), moderationRepo, tool.NewRegistry(), new(mocks.EmbeddingProvider), new(mocks.VectorStore), new(mocks.BlobStorage), speech.NewStubProvider(""), openAiWrapper, cacheWrapper, cached.NewLocalLocker(), moderator, newEventPublisher())
+			gotResp, err := s.ChatQuestion(ctx, 1, dto.ChatQuestionRequest{Question: tt.question})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultChatUsecase.ChatQuestion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				var appErr *libErrors.ApplicationError
+				if !errors.As(err, &appErr) || appErr.ErrorCode != 422 || !reflect.DeepEqual(appErr.Data, tt.wantErrData) {
+					t.Errorf("defaultChatUsecase.ChatQuestion() error = %+v, want data %+v", err, tt.wantErrData)
+				}
+				chatRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if !tt.wantGenerate {
+				openAiWrapper.AssertNotCalled(t, "GenerateText", mock.Anything, mock.Anything)
+			}
+			if len(gotFlags) != tt.wantFlagCount {
+				t.Fatalf("defaultChatUsecase.ChatQuestion() flags = %+v, want %v", gotFlags, tt.wantFlagCount)
+			}
+			if tt.wantFlagCount > 0 && !reflect.DeepEqual(gotFlags[0], tt.wantFlag) {
+				t.Errorf("defaultChatUsecase.ChatQuestion() flag = %+v, want %+v", gotFlags[0], tt.wantFlag)
+			}
+			if !reflect.DeepEqual(gotResp.Warnings, tt.wantWarnings) {
+				t.Errorf("defaultChatUsecase.ChatQuestion() warnings = %+v, want %+v", gotResp.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// TitlePrompt asks the model for the title of a conversation
+	TitlePrompt = "Write a short title of at most six words for the conversation below, " +
+		"in the same language as the user's question. Reply with the title only, without quotes."
+
+	// TitleTimeout bounds how long a title is waited for
+	TitleTimeout = 30 * time.Second
+
+	// titleExcerptLength is the number of characters of the question and the
+	// answer the title is generated from
+	titleExcerptLength = 1000
+)
+
+// generateTitle asks the model for a short title of a new conversation from
+// its first exchange. It runs in the background once the answer has been
+// returned, so when the model is unavailable the conversation is only left
+// untitled. A title the user set in the meantime is kept.
+func (s *defaultChatUsecase) generateTitle(ctx context.Context, conversationId int, question, answer string) {
+	ctx, cancel := context.WithTimeout(ctx, TitleTimeout)
+	defer cancel()
+
+	dataResp, err := s.openAiWrapper.GenerateText(ctx, openai.ChatCompletionRequest{
+		Model:       openai.GPT3Dot5Turbo,
+		Temperature: 0.3,
+		MaxTokens:   20,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: TitlePrompt,
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: fmt.Sprintf("Question: %v\n\nAnswer: %v", truncateRunes(question, titleExcerptLength), truncateRunes(answer, titleExcerptLength)),
+			},
+		},
+	})
+	if err != nil || len(dataResp.Choices) == 0 {
+		logger.Info(ctx, "conversation title skipped", fmt.Sprint(err))
+		return
+	}
+
+	title := cleanTitle(dataResp.Choices[0].Message.Content)
+	if title == "" {
+		return
+	}
+
+	err = s.conversationRepo.SetGeneratedTitle(ctx, conversationId, title)
+	if err != nil {
+		logger.Error(ctx, "error saving conversation title", err.Error())
+	}
+}
+
+// truncateRunes cuts the text to at most length characters
+func truncateRunes(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) > length {
+		return string(runes[:length])
+	}
+	return text
+}
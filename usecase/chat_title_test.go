@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultChatUsecase_generateTitle(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name            string
+		generateResp    openai.ChatCompletionResponse
+		generateErr     error
+		wantTitle       string
+		wantTitleStored bool
+	}{
+		{
+			name:            "model unavailable",
+			generateErr:     errors.New("model unavailable"),
+			wantTitleStored: false,
+		},
+		{
+			name: "empty title",
+			generateResp: openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{Message: openai.ChatCompletionMessage{Content: " \"\" "}},
+				},
+			},
+			wantTitleStored: false,
+		},
+		{
+			name: "success",
+			generateResp: openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{Message: openai.ChatCompletionMessage{Content: "\"Tools untuk Belajar Koding.\"\n"}},
+				},
+			},
+			wantTitle:       "Tools untuk Belajar Koding",
+			wantTitleStored: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := new(mocks.ConversationRepository)
+			openAiWrapper := new(mocks.OpenAIWrapper)
+
+			openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+				return req.Messages[0].Content == TitlePrompt &&
+					req.Messages[1].Content == "Question: tools yang di butuhkan untuk koding?\n\nAnswer: Text editor dan git."
+			})).Return(tt.generateResp, tt.generateErr).Once()
+			if tt.wantTitleStored {
+				conversationRepo.On("SetGeneratedTitle", mock.Anything, 1, tt.wantTitle).Return(nil).Once()
+			}
+
+			s := &defaultChatUsecase{
+				conversationRepo: conversationRepo,
+				openAiWrapper:    openAiWrapper,
+			}
+			s.generateTitle(ctx, 1, "tools yang di butuhkan untuk koding?", "Text editor dan git.")
+
+			openAiWrapper.AssertExpectations(t)
+			conversationRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -4,14 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/fadilahonespot/chatbot/entity"
 	"github.com/fadilahonespot/chatbot/repository/cached"
 	"github.com/fadilahonespot/chatbot/repository/http/chatgbt"
+	"github.com/fadilahonespot/chatbot/repository/http/embedding"
+	"github.com/fadilahonespot/chatbot/repository/http/moderation"
+	"github.com/fadilahonespot/chatbot/repository/http/speech"
 	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/repository/storage"
+	"github.com/fadilahonespot/chatbot/repository/vectorstore"
 	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/usecase/tool"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
 	"github.com/fadilahonespot/chatbot/utils/logger"
 	"github.com/fadilahonespot/library/errors"
 	"github.com/sashabaranov/go-openai"
@@ -19,32 +28,146 @@ import (
 
 type ChatUsecase interface {
 	ChatQuestion(ctx context.Context, userId int, req dto.ChatQuestionRequest) (resp dto.ChatQuestionResponse, err error)
-	GetHistoryChat(ctx context.Context, userId int) (resp []dto.ChatHistoryResponse, err error)
+	VoiceQuestion(ctx context.Context, userId int, req dto.VoiceQuestionRequest) (resp dto.VoiceQuestionResponse, err error)
+	GetHistoryChat(ctx context.Context, userId int, filter dto.ChatHistoryFilter) (resp []dto.ChatHistoryResponse, err error)
+	StarChat(ctx context.Context, userId, id int, req dto.ChatStarRequest) (err error)
+	GetChatImage(ctx context.Context, userId, id int) (data []byte, contentType string, err error)
+	GetChatAudio(ctx context.Context, userId, id int) (data []byte, contentType string, err error)
 }
 
 type defaultChatUsecase struct {
-	userRepo      mysql.UserRepository
-	chatRepo      mysql.ChatRepository
-	openAiWrapper chatgbt.OpenAIWrapper
-	cacheWrapper  cached.CacheWrapper
+	userRepo           mysql.UserRepository
+	chatRepo           mysql.ChatRepository
+	conversationRepo   mysql.ConversationRepository
+	personaRepo        mysql.PersonaRepository
+	chatModelRepo      mysql.ChatModelRepository
+	promptTemplateRepo mysql.PromptTemplateRepository
+	collectionRepo     mysql.CollectionRepository
+	teamRepo           mysql.TeamRepository
+	moderationRepo     mysql.ModerationRepository
+	toolRegistry       *tool.Registry
+	embeddingProvider  embedding.EmbeddingProvider
+	vectorStore        vectorstore.VectorStore
+	blobStorage        storage.BlobStorage
+	speechProvider     speech.SpeechProvider
+	openAiWrapper      chatgbt.OpenAIWrapper
+	cacheWrapper       cached.CacheWrapper
+	locker             cached.Locker
+	moderator          moderation.Moderator
+	eventPublisher     EventPublisher
 }
 
 const (
 	KeyHistory = "getHistory"
+	KeyChatBot = "ChatBot"
+	BotName    = "Bot"
+
+	// KeyChatLock locks a conversation while a question is answered in it.
+	// ChatLockTTL is how long the lock is held when it is not released.
+	KeyChatLock = "ChatLock"
+	ChatLockTTL = 2 * time.Minute
+
+	// MaxToolIterations is the number of tool call rounds allowed before
+	// the model is made to answer without tools
+	MaxToolIterations = 5
+
+	// MaxImages and MaxImageSize limit the images attached to a question
+	MaxImages    = 4
+	MaxImageSize = 5 << 20
+
+	// MaxAudioSize is the largest recording accepted for a voice question,
+	// and MaxSpeechLength the number of characters of an answer spoken back
+	MaxAudioSize    = 25 << 20
+	MaxSpeechLength = 4096
 )
 
 // NewChatUsecase creates a new instance of ChatUsecase
-func NewChatUsecase(userRepo mysql.UserRepository, chatRepo mysql.ChatRepository, openAiWrapper chatgbt.OpenAIWrapper, cacheWrapper cached.CacheWrapper) ChatUsecase {
+func NewChatUsecase(userRepo mysql.UserRepository, chatRepo mysql.ChatRepository, conversationRepo mysql.ConversationRepository, personaRepo mysql.PersonaRepository, chatModelRepo mysql.ChatModelRepository, promptTemplateRepo mysql.PromptTemplateRepository, collectionRepo mysql.CollectionRepository, teamRepo mysql.TeamRepository, moderationRepo mysql.ModerationRepository, toolRegistry *tool.Registry, embeddingProvider embedding.EmbeddingProvider, vectorStore vectorstore.VectorStore, blobStorage storage.BlobStorage, speechProvider speech.SpeechProvider, openAiWrapper chatgbt.OpenAIWrapper, cacheWrapper cached.CacheWrapper, locker cached.Locker, moderator moderation.Moderator, eventPublisher EventPublisher) ChatUsecase {
 	return &defaultChatUsecase{
-		userRepo:      userRepo,
-		chatRepo:      chatRepo,
-		openAiWrapper: openAiWrapper,
-		cacheWrapper:  cacheWrapper,
+		userRepo:           userRepo,
+		chatRepo:           chatRepo,
+		conversationRepo:   conversationRepo,
+		personaRepo:        personaRepo,
+		chatModelRepo:      chatModelRepo,
+		promptTemplateRepo: promptTemplateRepo,
+		collectionRepo:     collectionRepo,
+		teamRepo:           teamRepo,
+		moderationRepo:     moderationRepo,
+		toolRegistry:       toolRegistry,
+		embeddingProvider:  embeddingProvider,
+		vectorStore:        vectorStore,
+		blobStorage:        blobStorage,
+		speechProvider:     speechProvider,
+		openAiWrapper:      openAiWrapper,
+		cacheWrapper:       cacheWrapper,
+		locker:             locker,
+		moderator:          moderator,
+		eventPublisher:     eventPublisher,
 	}
 }
 
 // ChatQuestion handles the chat question from the user
 func (s *defaultChatUsecase) ChatQuestion(ctx context.Context, userId int, req dto.ChatQuestionRequest) (resp dto.ChatQuestionResponse, err error) {
+	resp, err = s.askQuestion(ctx, userId, req, nil)
+	return
+}
+
+// VoiceQuestion transcribes a recorded question and answers the transcript
+// like a typed question. The recording is stored with the question, and the
+// answer is spoken back when asked for.
+func (s *defaultChatUsecase) VoiceQuestion(ctx context.Context, userId int, req dto.VoiceQuestionRequest) (resp dto.VoiceQuestionResponse, err error) {
+	if req.Question != "" || req.TemplateId != 0 {
+		logger.Error(ctx, "question given with audio")
+		err = errors.SetError(http.StatusBadRequest, "a voice question cannot be combined with a question or template")
+		return
+	}
+
+	audio, err := decodeAudio(ctx, userId, req.Audio)
+	if err != nil {
+		return
+	}
+
+	transcription, err := s.speechProvider.Transcribe(ctx, audio.attachment.Name, audio.data)
+	if err != nil {
+		logger.Error(ctx, "error transcribing audio", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	transcript := strings.TrimSpace(transcription.Text)
+	if transcript == "" {
+		logger.Error(ctx, "no speech in audio")
+		err = errors.SetError(http.StatusBadRequest, "no speech was recognized in the audio")
+		return
+	}
+	audio.attachment.Transcript = transcript
+	audio.attachment.Language = transcription.Language
+	audio.attachment.Duration = transcription.Duration
+
+	voice := &chatVoice{
+		audio: audio,
+		speak: req.Speak,
+	}
+	req.Question = transcript
+	answer, err := s.askQuestion(ctx, userId, req.ChatQuestionRequest, voice)
+	if err != nil {
+		return
+	}
+
+	resp.ChatQuestionResponse = answer
+	resp.Transcript = transcript
+	resp.Audio = toAudioResponse(voice.audio.attachment)
+	if voice.speech != nil {
+		speechResp := toAudioResponse(voice.speech.attachment)
+		resp.Speech = &speechResp
+	}
+	return
+}
+
+// askQuestion answers a question and stores it with the answer. A question
+// asked by voice also stores its recording and, when asked for, the spoken
+// answer, and hands the stored attachments back through voice.
+func (s *defaultChatUsecase) askQuestion(ctx context.Context, userId int, req dto.ChatQuestionRequest, voice *chatVoice) (resp dto.ChatQuestionResponse, err error) {
 	// get user data
 	userData, err := s.userRepo.GetUserById(ctx, userId)
 	if err != nil {
@@ -53,99 +176,303 @@ func (s *defaultChatUsecase) ChatQuestion(ctx context.Context, userId int, req d
 		return
 	}
 
-	// create the initial chat request
+	// get the question, rendering it from a prompt template when one is given
+	question, err := s.getQuestion(ctx, userId, req)
+	if err != nil {
+		return
+	}
+
+	// decode the images attached to the question
+	images, err := decodeImages(ctx, userId, req.Images)
+	if err != nil {
+		return
+	}
+
+	// get the conversation the question belongs to
+	conversation, err := s.getConversation(ctx, userId, req)
+	if err != nil {
+		return
+	}
+
+	// get the persona that answers in this conversation. When the latest
+	// conversation is continued without being asked for, and its persona was
+	// deleted or is no longer allowed, a new conversation is started with the
+	// default assistant instead.
+	persona, err := s.getPersona(ctx, userId, conversation.PersonaID)
+	if err != nil && req.ConversationId == 0 && req.PersonaId == 0 {
+		logger.Info(ctx, "persona of the latest conversation unavailable, starting a new conversation", conversation.ID)
+		conversation = &entity.Conversation{UserID: userId}
+		persona, err = s.getPersona(ctx, userId, 0)
+	}
+	if err != nil {
+		return
+	}
+
+	// run the question through moderation before anything is stored
+	var flags []*entity.ModerationFlag
+	inputFlag, err := s.moderate(ctx, userId, conversation.ID, constrans.ModerationInput, question)
+	if err != nil {
+		return
+	}
+	if inputFlag != nil {
+		flags = append(flags, inputFlag)
+	}
+
+	// start a new conversation when the user has none to continue
+	if conversation.ID == 0 {
+		err = s.conversationRepo.Create(ctx, conversation)
+		if err != nil {
+			logger.Error(ctx, "error creating conversation", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
+	// answer one question at a time in a conversation, so concurrent
+	// questions do not overwrite each other's context
+	lockKey := fmt.Sprintf("%v_%v_%v", KeyChatLock, userId, conversation.ID)
+	lockToken, err := s.locker.Lock(ctx, lockKey, ChatLockTTL)
+	if err == cached.ErrLocked {
+		logger.Error(ctx, "conversation is locked", lockKey)
+		err = errors.SetError(http.StatusConflict, "another question is being answered in this conversation")
+		return
+	}
+	if err != nil {
+		logger.Error(ctx, "error locking conversation", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	defer s.locker.Unlock(context.WithoutCancel(ctx), lockKey, lockToken)
+
+	// get the knowledge base collections searched in this conversation
+	collectionIds, err := s.getConversationCollections(ctx, userId, conversation.ID, req.CollectionIds)
+	if err != nil {
+		return
+	}
+
+	// create the initial chat request from the persona
+	systemMessage := openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleSystem,
+		Content: persona.SystemPrompt,
+	}
 	reqChat := openai.ChatCompletionRequest{
-		Model: openai.GPT3Dot5Turbo,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "Halo! Perkenalkan aku adalah ChatBot Assistant. Bagimana aku bisa membantumu hari ini?",
-			},
-		},
+		Model:       persona.Model,
+		Temperature: persona.Temperature,
+		MaxTokens:   persona.MaxTokens,
+		Messages:    []openai.ChatCompletionMessage{systemMessage},
 	}
 
-	// append the user's question to the request
-	reqChat.Messages = append(reqChat.Messages, openai.ChatCompletionMessage{
-		Role:    openai.ChatMessageRoleUser,
-		Content: req.Question,
-	})
+	// apply the generation options the user asked for
+	err = s.applyGenerationOptions(ctx, &reqChat, req)
+	if err != nil {
+		return
+	}
 
 	// get the previous chat request from cache
-	key := fmt.Sprintf("ChatBot_%v", userId)
+	key := fmt.Sprintf("%v_%v_%v", KeyChatBot, userId, conversation.ID)
 	value, _ := s.cacheWrapper.Get(ctx, key)
 	if value != "" {
 		// unmarshall the previous chat request
 		var reqData openai.ChatCompletionRequest
 		err = json.Unmarshal([]byte(value), &reqData)
 		if err != nil {
-			fmt.Println("error unmarshalling chat: ", err.Error())
+			logger.Error(ctx, "error unmarshalling chat", err.Error())
 			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 			return
 		}
-		// append the user's question to the previous chat request
-		reqData.Messages = append(reqData.Messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: req.Question,
-		})
 
-		// update the previous chat request with the new question
-		reqChat = reqData
+		// keep the previous context but always answer with the current persona prompt
+		if len(reqData.Messages) > 0 && reqData.Messages[0].Role == openai.ChatMessageRoleSystem {
+			reqData.Messages = reqData.Messages[1:]
+		}
+		reqChat.Messages = append(reqChat.Messages, reqData.Messages...)
 	} else {
-		// get the history of chats with the user
-		historyData, errRes := s.chatRepo.GetByUserId(ctx, userData.ID)
+		// get the history of chats in the conversation
+		historyData, errRes := s.chatRepo.GetByConversationId(ctx, conversation.ID)
 		if errRes != nil {
 			logger.Error(ctx, "error getting chat history")
 			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 			return
 		}
 
-		// append the history of chats to the initial chat request
+		// append the history of chats to the initial chat request, leaving out
+		// the messages written before the context was reset
 		for i := 0; i < len(historyData); i++ {
+			if historyData[i].ID <= conversation.ContextStartID {
+				continue
+			}
+			role := openai.ChatMessageRoleUser
+			if historyData[i].Name == BotName {
+				role = openai.ChatMessageRoleAssistant
+			}
 			reqChat.Messages = append(reqChat.Messages, openai.ChatCompletionMessage{
-				Role:    openai.ChatMessageRoleUser,
+				Role:    role,
 				Content: historyData[i].Message,
 			})
 		}
+	}
 
-		// append the user's question to the end of the chat request
-		reqChat.Messages = append(reqChat.Messages, openai.ChatCompletionMessage{
-			Role:    openai.ChatMessageRoleUser,
-			Content: req.Question,
-		})
+	// a conversation without earlier messages is titled after this exchange
+	firstExchange := len(reqChat.Messages) == 1
+
+	// give the model the document chunks closest to the question
+	sourcesIndex := -1
+	var sources []vectorstore.SearchResult
+	if req.UseDocuments || len(collectionIds) > 0 {
+		filter := vectorstore.SearchFilter{
+			CollectionIds:  collectionIds,
+			EmbeddingModel: s.embeddingProvider.Model(),
+		}
+		if req.UseDocuments {
+			filter.UserId = userId
+		}
+		sources, err = s.retrieveSources(ctx, filter, question)
+		if err != nil {
+			return
+		}
+		if len(sources) > 0 {
+			sourcesIndex = len(reqChat.Messages)
+			reqChat.Messages = append(reqChat.Messages, openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: buildSourcesPrompt(sources),
+			})
+		}
+	}
+
+	// append the user's question to the end of the chat request
+	reqChat.Messages = append(reqChat.Messages, questionMessage(question, images))
+
+	// offer the registered tools to the model
+	reqChat.Tools = s.toolRegistry.Definitions()
+
+	// generate the response from OpenAI, running the tools the model asks for
+	// and sending their results back until the model gives its final answer
+	var dataResp openai.ChatCompletionResponse
+	var toolCalls []entity.ToolCall
+	for iteration := 0; ; iteration++ {
+		if iteration == MaxToolIterations {
+			// stop offering tools so the model has to answer
+			reqChat.ToolChoice = "none"
+		}
+
+		dataResp, err = s.openAiWrapper.GenerateText(ctx, reqChat)
+		if chatgbt.IsQuotaExceeded(err) {
+			logger.Error(ctx, "openai quota exceeded", err.Error())
+			s.eventPublisher.Publish(ctx, userId, constrans.EventQuotaExceeded, dto.QuotaEvent{
+				ConversationId: conversation.ID,
+				Model:          reqChat.Model,
+				Reason:         err.Error(),
+			})
+			err = errors.SetError(http.StatusTooManyRequests, "the bot is over its quota, try again later")
+			return
+		}
+		if err != nil {
+			logger.Error(ctx, "error generating text", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+
+		// append the response from OpenAI to the chat request
+		message := dataResp.Choices[0].Message
+		reqChat.Messages = append(reqChat.Messages, message)
+		if len(message.ToolCalls) == 0 || iteration == MaxToolIterations {
+			break
+		}
+
+		// run the requested tools and append their results to the chat request
+		for i := 0; i < len(message.ToolCalls); i++ {
+			toolCall := s.executeToolCall(ctx, userId, message.ToolCalls[i])
+			toolCalls = append(toolCalls, toolCall)
+			reqChat.Messages = append(reqChat.Messages, openai.ChatCompletionMessage{
+				Role:       openai.ChatMessageRoleTool,
+				Content:    toolCall.Result,
+				ToolCallID: toolCall.ToolCallID,
+			})
+		}
 	}
 
-	// generate the response from OpenAI
-	dataResp, err := s.openAiWrapper.GenerateText(ctx, reqChat)
+	// run the answer through moderation before it is kept or shown
+	outputFlag, err := s.moderate(ctx, userId, conversation.ID, constrans.ModerationOutput, dataResp.Choices[0].Message.Content)
 	if err != nil {
-		fmt.Println("error generating text: ", err.Error())
-		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 		return
 	}
+	if outputFlag != nil {
+		flags = append(flags, outputFlag)
+	}
+
+	// the sources only apply to this question, so they are not kept in the context
+	if sourcesIndex >= 0 {
+		reqChat.Messages = append(reqChat.Messages[:sourcesIndex], reqChat.Messages[sourcesIndex+1:]...)
+	}
 
-	// append the response from OpenAI to the chat request
-	reqChat.Messages = append(reqChat.Messages, dataResp.Choices[0].Message)
+	// keep the answer only while the lock is still ours, an answer that took
+	// longer than the lock gives way to the question that took it over
+	held, err := s.locker.Held(ctx, lockKey, lockToken)
+	if err != nil || !held {
+		logger.Error(ctx, "conversation lock lost", lockKey, fmt.Sprint(err))
+		err = errors.SetError(http.StatusConflict, "the conversation changed while the question was answered")
+		return
+	}
 
-	// marshall the chat request and cache it
-	dataByte, _ := json.Marshal(reqChat)
+	// marshall the chat request and cache it without the images
+	cachedChat := reqChat
+	cachedChat.Messages = contextMessages(reqChat.Messages)
+	dataByte, _ := json.Marshal(cachedChat)
 	s.cacheWrapper.Set(ctx, key, string(dataByte), time.Duration(60)*time.Minute)
 
 	// get the response from OpenAI
 	answer := dataResp.Choices[0].Message.Content
+	modelUsed := dataResp.Model
+	if modelUsed == "" {
+		modelUsed = reqChat.Model
+	}
 
 	// create the history of chats
 	reqHistory := []entity.Chat{
 		{
-			UserID:  userData.ID,
-			Name:    userData.Name,
-			Message: req.Question,
+			UserID:         userData.ID,
+			ConversationID: conversation.ID,
+			PersonaID:      persona.ID,
+			PromptVersion:  persona.PromptVersion,
+			TemplateID:     req.TemplateId,
+			Name:           userData.Name,
+			Message:        question,
 		},
 		{
-			UserID:  userData.ID,
-			Name:    "Bot",
-			Message: answer,
+			UserID:         userData.ID,
+			ConversationID: conversation.ID,
+			PersonaID:      persona.ID,
+			PromptVersion:  persona.PromptVersion,
+			Model:          modelUsed,
+			Name:           BotName,
+			Message:        answer,
 		},
 	}
 
+	// collect the files stored with the messages, speaking the answer of a
+	// voice question when asked for
+	files := append([]chatFile{}, images...)
+	if voice != nil {
+		files = append(files, voice.audio)
+		if voice.speak {
+			speechFile, errRes := s.speakAnswer(ctx, userId, answer)
+			if errRes == nil {
+				files = append(files, speechFile)
+			}
+		}
+	}
+
+	// store the files before recording them with the messages
+	for i := 0; i < len(files); i++ {
+		err = s.blobStorage.Put(ctx, files[i].attachment.StorageKey, files[i].data)
+		if err != nil {
+			s.deleteFiles(ctx, files[:i])
+			logger.Error(ctx, "error storing file", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
 	// start a transaction
 	tx := s.chatRepo.BeginsTrans()
 	// loop through the history of chats and create them
@@ -153,44 +480,423 @@ func (s *defaultChatUsecase) ChatQuestion(ctx context.Context, userId int, req d
 		err = s.chatRepo.Create(ctx, tx, &reqHistory[i])
 		if err != nil {
 			s.chatRepo.Rollback(tx)
+			s.deleteFiles(ctx, files)
 			logger.Error(ctx, "error creating chat history", err.Error())
 			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 			return
 		}
 	}
 
+	// record the tools called for the answer
+	for i := 0; i < len(toolCalls); i++ {
+		toolCalls[i].ChatID = reqHistory[1].ID
+		err = s.chatRepo.CreateToolCall(ctx, tx, &toolCalls[i])
+		if err != nil {
+			s.chatRepo.Rollback(tx)
+			s.deleteFiles(ctx, files)
+			logger.Error(ctx, "error creating tool call", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
+	// record the files sent with the question and the spoken answer
+	for i := 0; i < len(files); i++ {
+		files[i].attachment.ChatID = reqHistory[0].ID
+		if files[i].attachment.Kind == constrans.AttachmentSpeech {
+			files[i].attachment.ChatID = reqHistory[1].ID
+		}
+		err = s.chatRepo.CreateAttachment(ctx, tx, &files[i].attachment)
+		if err != nil {
+			s.chatRepo.Rollback(tx)
+			s.deleteFiles(ctx, files)
+			logger.Error(ctx, "error creating attachment", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
 	// commit the transaction
 	s.chatRepo.Commit(tx)
 
+	// hand the stored recordings back to the voice question
+	if voice != nil {
+		for i := 0; i < len(files); i++ {
+			switch files[i].attachment.Kind {
+			case constrans.AttachmentAudio:
+				voice.audio = files[i]
+			case constrans.AttachmentSpeech:
+				voice.speech = &files[i]
+			}
+		}
+	}
+
+	// queue the flagged question and answer for review with the stored messages
+	for i := 0; i < len(flags); i++ {
+		flags[i].ConversationID = conversation.ID
+		flags[i].ChatID = reqHistory[0].ID
+		if flags[i].Direction == constrans.ModerationOutput {
+			flags[i].ChatID = reqHistory[1].ID
+		}
+		s.queueFlag(ctx, flags[i])
+	}
+
 	// delete the history of chats cache
 	keyHistory := fmt.Sprintf("%v_%v", KeyHistory, userId)
 	s.cacheWrapper.Delete(ctx, keyHistory)
 
+	// publish the stored messages and the answer to the user's webhooks
+	for i := 0; i < len(reqHistory); i++ {
+		role := openai.ChatMessageRoleUser
+		if reqHistory[i].Name == BotName {
+			role = openai.ChatMessageRoleAssistant
+		}
+		s.eventPublisher.Publish(ctx, userId, constrans.EventMessageCreated, dto.MessageEvent{
+			ChatId:         reqHistory[i].ID,
+			ConversationId: conversation.ID,
+			Role:           role,
+			Name:           reqHistory[i].Name,
+			Message:        reqHistory[i].Message,
+			CreatedAt:      reqHistory[i].CreatedAt,
+		})
+	}
+	s.eventPublisher.Publish(ctx, userId, constrans.EventAnswerCompleted, dto.AnswerEvent{
+		ConversationId: conversation.ID,
+		QuestionId:     reqHistory[0].ID,
+		AnswerId:       reqHistory[1].ID,
+		Question:       question,
+		Answer:         answer,
+		Model:          modelUsed,
+		FinishReason:   string(dataResp.Choices[0].FinishReason),
+		Cached:         chatgbt.IsCached(dataResp),
+	})
+
+	// title the conversation in the background so the answer is not delayed
+	if firstExchange && conversation.Title == "" {
+		go s.generateTitle(context.WithoutCancel(ctx), conversation.ID, question, answer)
+	}
+
 	// set the response
+	resp.ConversationId = conversation.ID
 	resp.Answer = answer
+	resp.Model = modelUsed
+	resp.FinishReason = string(dataResp.Choices[0].FinishReason)
+	resp.Cached = chatgbt.IsCached(dataResp)
+	resp.ToolCalls = toToolCallResponses(toolCalls)
+	resp.Sources = toSourceResponses(sources)
+	resp.CollectionIds = collectionIds
+	resp.Warnings = toModerationWarnings(flags)
 	return
 }
 
-// GetHistoryChat returns the history of chats between the user and the bot
-func (s *defaultChatUsecase) GetHistoryChat(ctx context.Context, userId int) (resp []dto.ChatHistoryResponse, err error) {
-	key := fmt.Sprintf("%v_%v", KeyHistory, userId)
-	value, _ := s.cacheWrapper.Get(ctx, key)
-	if value == "" {
-		// get history from database
-		historyData, errRes := s.chatRepo.GetHistoryChatByUserId(ctx, userId)
+// retrieveSources embeds the question and returns the document chunks
+// closest to it
+func (s *defaultChatUsecase) retrieveSources(ctx context.Context, filter vectorstore.SearchFilter, question string) (resp []vectorstore.SearchResult, err error) {
+	embeddings, err := s.embeddingProvider.Embed(ctx, []string{question})
+	if err != nil || len(embeddings) == 0 {
+		logger.Error(ctx, "error embedding question", fmt.Sprint(err))
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp, err = s.vectorStore.Search(ctx, filter, embeddings[0], ragTopK())
+	if err != nil {
+		logger.Error(ctx, "error searching documents", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	return
+}
+
+// getConversationCollections returns the collections searched in the
+// conversation. Requested collections replace the attached ones, and an
+// empty list detaches them all. Attached collections the user can no longer
+// access, for example after leaving a team, are skipped.
+func (s *defaultChatUsecase) getConversationCollections(ctx context.Context, userId, conversationId int, requested []int) (resp []int, err error) {
+	attached := requested
+	if requested == nil {
+		attached, err = s.conversationRepo.GetCollectionIds(ctx, conversationId)
+		if err != nil {
+			logger.Error(ctx, "error getting conversation collections", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
+	if len(attached) > 0 {
+		collections, errRes := getAccessibleCollections(ctx, s.collectionRepo, s.teamRepo, userId)
 		if errRes != nil {
-			logger.Error(ctx, "error getting chat history", errRes.Error())
+			err = errRes
+			return
+		}
+
+		accessible := map[int]bool{}
+		for i := 0; i < len(collections); i++ {
+			accessible[collections[i].ID] = true
+		}
+
+		for i := 0; i < len(attached); i++ {
+			if !accessible[attached[i]] {
+				if requested != nil {
+					logger.Error(ctx, "collection not found", attached[i])
+					err = errors.SetError(http.StatusNotFound, fmt.Sprintf("collection %v not found", attached[i]))
+					resp = nil
+					return
+				}
+				continue
+			}
+			if !containsInt(resp, attached[i]) {
+				resp = append(resp, attached[i])
+			}
+		}
+	}
+
+	if requested != nil {
+		err = s.conversationRepo.SetCollectionIds(ctx, conversationId, resp)
+		if err != nil {
+			logger.Error(ctx, "error attaching collections", err.Error())
 			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 			return
 		}
+	}
+	return
+}
 
-		// convert to dto
-		for i := 0; i < len(historyData); i++ {
-			resp = append(resp, dto.ChatHistoryResponse{
-				Id:      historyData[i].ID,
-				Name:    historyData[i].Name,
-				Message: historyData[i].Message,
-			})
+// executeToolCall runs a tool requested by the model. A failing or unknown
+// tool is reported back to the model as the result so it can recover.
+func (s *defaultChatUsecase) executeToolCall(ctx context.Context, userId int, call openai.ToolCall) entity.ToolCall {
+	toolCall := entity.ToolCall{
+		ToolCallID: call.ID,
+		Name:       call.Function.Name,
+		Arguments:  call.Function.Arguments,
+	}
+
+	registered, ok := s.toolRegistry.Get(call.Function.Name)
+	if !ok {
+		logger.Error(ctx, "unknown tool", call.Function.Name)
+		toolCall.Result = fmt.Sprintf("error: unknown tool %v", call.Function.Name)
+		return toolCall
+	}
+
+	result, err := registered.Execute(ctx, userId, call.Function.Arguments)
+	if err != nil {
+		logger.Error(ctx, "error executing tool", call.Function.Name, err.Error())
+		result = fmt.Sprintf("error: %v", err.Error())
+	}
+
+	toolCall.Result = result
+	return toolCall
+}
+
+// toToolCallResponses converts the recorded tool calls to their dto
+func toToolCallResponses(toolCalls []entity.ToolCall) (resp []dto.ToolCallResponse) {
+	for i := 0; i < len(toolCalls); i++ {
+		resp = append(resp, dto.ToolCallResponse{
+			Name:      toolCalls[i].Name,
+			Arguments: toolCalls[i].Arguments,
+			Result:    toolCalls[i].Result,
+		})
+	}
+	return
+}
+
+// getQuestion returns the question of the request, or renders it from the
+// prompt template when a template id is given
+func (s *defaultChatUsecase) getQuestion(ctx context.Context, userId int, req dto.ChatQuestionRequest) (question string, err error) {
+	if req.TemplateId == 0 {
+		question = req.Question
+		return
+	}
+
+	if req.Question != "" {
+		logger.Error(ctx, "question and template both given")
+		err = errors.SetError(http.StatusBadRequest, "question cannot be combined with a template")
+		return
+	}
+
+	template, err := s.promptTemplateRepo.GetById(ctx, req.TemplateId)
+	if err != nil || !isPromptTemplateAccessible(template, userId) {
+		logger.Error(ctx, "template not found")
+		err = errors.SetError(http.StatusNotFound, "template not found")
+		return
+	}
+
+	question, err = renderPromptTemplate(ctx, template, req.Variables)
+	return
+}
+
+// applyGenerationOptions overrides the persona defaults with the options in
+// the request. A requested model must be enabled in the allowlist, and the
+// limits of the allowlist entry apply to whichever model ends up being used.
+func (s *defaultChatUsecase) applyGenerationOptions(ctx context.Context, reqChat *openai.ChatCompletionRequest, req dto.ChatQuestionRequest) (err error) {
+	if req.Model != "" {
+		reqChat.Model = req.Model
+	}
+
+	chatModel, errRes := s.chatModelRepo.GetByName(ctx, reqChat.Model)
+	if req.Model != "" && (errRes != nil || !chatModel.Enabled) {
+		logger.Error(ctx, "model not allowed")
+		err = errors.SetError(http.StatusBadRequest, "model not allowed")
+		return
+	}
+	if errRes != nil {
+		chatModel = &entity.ChatModel{}
+	}
+
+	if req.Temperature != nil {
+		temperature := *req.Temperature
+		if temperature < 0 || temperature > 2 || (chatModel.MaxTemperature > 0 && temperature > chatModel.MaxTemperature) {
+			logger.Error(ctx, "temperature not valid")
+			err = errors.SetError(http.StatusBadRequest, "temperature exceeds the limit of the model")
+			return
+		}
+		reqChat.Temperature = sentOption(temperature)
+	}
+
+	if req.TopP != nil {
+		topP := *req.TopP
+		if topP < 0 || topP > 1 {
+			logger.Error(ctx, "top p not valid")
+			err = errors.SetError(http.StatusBadRequest, "top p must be between 0 and 1")
+			return
+		}
+		reqChat.TopP = sentOption(topP)
+	}
+
+	if req.MaxTokens != 0 {
+		if req.MaxTokens < 0 || (chatModel.MaxTokens > 0 && req.MaxTokens > chatModel.MaxTokens) {
+			logger.Error(ctx, "max tokens not valid")
+			err = errors.SetError(http.StatusBadRequest, "max tokens exceeds the limit of the model")
+			return
+		}
+		reqChat.MaxTokens = req.MaxTokens
+	}
+	if chatModel.MaxTokens > 0 && (reqChat.MaxTokens == 0 || reqChat.MaxTokens > chatModel.MaxTokens) {
+		reqChat.MaxTokens = chatModel.MaxTokens
+	}
+
+	if len(req.Images) > 0 && !chatModel.SupportsVision {
+		logger.Error(ctx, "images not supported")
+		err = errors.SetError(http.StatusBadRequest, "model does not support images")
+		return
+	}
+
+	if len(req.Stop) > 4 {
+		logger.Error(ctx, "too many stop sequences")
+		err = errors.SetError(http.StatusBadRequest, "at most 4 stop sequences are allowed")
+		return
+	}
+	reqChat.Stop = req.Stop
+
+	switch req.ResponseFormat {
+	case "", dto.ResponseFormatText:
+	case dto.ResponseFormatJson:
+		if !chatModel.SupportsJsonFormat {
+			logger.Error(ctx, "json format not supported")
+			err = errors.SetError(http.StatusBadRequest, "model does not support json response format")
+			return
+		}
+		reqChat.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+		// the json mode requires the prompt to ask for json explicitly
+		reqChat.Messages[0].Content += "\nRespond only with a valid JSON object."
+	default:
+		logger.Error(ctx, "response format not valid")
+		err = errors.SetError(http.StatusBadRequest, "response format must be text or json")
+		return
+	}
+	return
+}
+
+// sentOption returns the value a generation option is sent with. The client
+// leaves out options that are zero, which the model then takes as unset, so
+// zero is sent as the smallest value above it.
+func sentOption(value float32) float32 {
+	if value == 0 {
+		return math.SmallestNonzeroFloat32
+	}
+	return value
+}
+
+// getConversation returns the conversation the question is asked in.
+// Without a conversation id the user's latest conversation is continued,
+// unless a persona is picked, which always starts a new conversation.
+// A conversation that still has to be created is returned with a zero id.
+func (s *defaultChatUsecase) getConversation(ctx context.Context, userId int, req dto.ChatQuestionRequest) (conversation *entity.Conversation, err error) {
+	if req.ConversationId != 0 {
+		conversation, err = s.conversationRepo.GetById(ctx, req.ConversationId)
+		if err != nil || conversation.UserID != userId {
+			logger.Error(ctx, "conversation not found")
+			err = errors.SetError(http.StatusNotFound, "conversation not found")
+			return
+		}
+
+		if req.PersonaId != 0 && req.PersonaId != conversation.PersonaID {
+			logger.Error(ctx, "persona cannot be changed")
+			err = errors.SetError(http.StatusBadRequest, "persona cannot be changed in an existing conversation")
+			return
+		}
+		return
+	}
+
+	if req.PersonaId == 0 {
+		conversation, err = s.conversationRepo.GetLatestByUserId(ctx, userId)
+		if err == nil {
+			return
+		}
+	}
+
+	conversation = &entity.Conversation{
+		UserID:    userId,
+		PersonaID: req.PersonaId,
+	}
+	err = nil
+	return
+}
+
+// getPersona returns the persona of a conversation, falling back to the
+// default assistant when the conversation has none
+func (s *defaultChatUsecase) getPersona(ctx context.Context, userId, personaId int) (persona *entity.Persona, err error) {
+	if personaId == 0 {
+		persona = &entity.Persona{
+			SystemPrompt: DefaultSystemPrompt,
+			Model:        openai.GPT3Dot5Turbo,
+		}
+		return
+	}
+
+	persona, err = s.personaRepo.GetById(ctx, personaId)
+	if err != nil {
+		logger.Error(ctx, "persona not found", err.Error())
+		err = errors.SetError(http.StatusNotFound, "persona not found")
+		return
+	}
+
+	if !isPersonaAllowed(persona, userId) {
+		logger.Error(ctx, "persona not allowed")
+		err = errors.SetError(http.StatusForbidden, "persona not allowed")
+		return
+	}
+	return
+}
+
+// GetHistoryChat returns the history of chats between the user and the bot.
+// Only the unfiltered history is cached.
+func (s *defaultChatUsecase) GetHistoryChat(ctx context.Context, userId int, filter dto.ChatHistoryFilter) (resp []dto.ChatHistoryResponse, err error) {
+	filter.Tag = cleanTag(filter.Tag)
+	if filter != (dto.ChatHistoryFilter{}) {
+		resp, err = s.findHistoryChat(ctx, userId, filter)
+		return
+	}
+
+	key := fmt.Sprintf("%v_%v", KeyHistory, userId)
+	value, _ := s.cacheWrapper.Get(ctx, key)
+	if value == "" {
+		// get history from database
+		resp, err = s.findHistoryChat(ctx, userId, filter)
+		if err != nil {
+			return
 		}
 
 		// convert to json and cache
@@ -209,3 +915,96 @@ func (s *defaultChatUsecase) GetHistoryChat(ctx context.Context, userId int) (re
 
 	return
 }
+
+// findHistoryChat reads the history matching the filter from the database
+func (s *defaultChatUsecase) findHistoryChat(ctx context.Context, userId int, filter dto.ChatHistoryFilter) (resp []dto.ChatHistoryResponse, err error) {
+	historyData, err := s.chatRepo.GetHistoryChatByUserId(ctx, userId, mysql.HistoryFilter{
+		ConversationId: filter.ConversationId,
+		Tag:            filter.Tag,
+		Starred:        filter.Starred,
+	})
+	if err != nil {
+		logger.Error(ctx, "error getting chat history", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	// convert to dto
+	for i := 0; i < len(historyData); i++ {
+		resp = append(resp, dto.ChatHistoryResponse{
+			Id:             historyData[i].ID,
+			ConversationId: historyData[i].ConversationID,
+			Name:           historyData[i].Name,
+			Message:        historyData[i].Message,
+			Starred:        historyData[i].Starred,
+			ToolCalls:      toToolCallResponses(historyData[i].ToolCalls),
+			Images:         toImageResponses(historyData[i].Attachments),
+			Audio:          toAudioResponses(historyData[i].Attachments),
+		})
+	}
+	return
+}
+
+// StarChat stars or unstars a message of the user
+func (s *defaultChatUsecase) StarChat(ctx context.Context, userId, id int, req dto.ChatStarRequest) (err error) {
+	chat, err := s.chatRepo.GetById(ctx, id)
+	if err != nil || chat.UserID != userId {
+		logger.Error(ctx, "chat not found")
+		err = errors.SetError(http.StatusNotFound, "chat not found")
+		return
+	}
+
+	err = s.chatRepo.SetStarred(ctx, chat.ID, req.Starred)
+	if err != nil {
+		logger.Error(ctx, "error starring chat", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	// delete the history of chats cache
+	keyHistory := fmt.Sprintf("%v_%v", KeyHistory, userId)
+	s.cacheWrapper.Delete(ctx, keyHistory)
+	return
+}
+
+// GetChatImage returns an image the user attached to one of their questions
+func (s *defaultChatUsecase) GetChatImage(ctx context.Context, userId, id int) (data []byte, contentType string, err error) {
+	return s.getAttachmentFile(ctx, userId, id, "image", constrans.AttachmentImage)
+}
+
+// GetChatAudio returns the recording of one of the user's voice questions or
+// a spoken answer to one of them
+func (s *defaultChatUsecase) GetChatAudio(ctx context.Context, userId, id int) (data []byte, contentType string, err error) {
+	return s.getAttachmentFile(ctx, userId, id, "audio", constrans.AttachmentAudio, constrans.AttachmentSpeech)
+}
+
+// getAttachmentFile returns the content of an attachment the user owns when
+// it is one of the given kinds
+func (s *defaultChatUsecase) getAttachmentFile(ctx context.Context, userId, id int, name string, kinds ...string) (data []byte, contentType string, err error) {
+	attachment, err := s.chatRepo.GetAttachmentById(ctx, id)
+	if err != nil || attachment.UserID != userId || !containsString(kinds, attachment.Kind) {
+		logger.Error(ctx, name+" not found")
+		err = errors.SetError(http.StatusNotFound, name+" not found")
+		return
+	}
+
+	data, err = s.blobStorage.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		logger.Error(ctx, "error reading "+name, err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	contentType = attachment.ContentType
+	return
+}
+
+// deleteFiles removes stored files of messages that could not be saved
+func (s *defaultChatUsecase) deleteFiles(ctx context.Context, files []chatFile) {
+	for i := 0; i < len(files); i++ {
+		err := s.blobStorage.Delete(ctx, files[i].attachment.StorageKey)
+		if err != nil {
+			logger.Error(ctx, "error deleting file", err.Error())
+		}
+	}
+}
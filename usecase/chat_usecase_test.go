@@ -4,18 +4,35 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/fadilahonespot/chatbot/entity"
 	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/http/moderation"
+	"github.com/fadilahonespot/chatbot/repository/http/speech"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/repository/vectorstore"
 	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/usecase/tool"
 	"github.com/fadilahonespot/chatbot/utils"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
 	"github.com/fadilahonespot/chatbot/utils/logger"
 	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/mock"
 )
 
+// newEventPublisher returns a publisher mock that takes any event
+func newEventPublisher() *mocks.EventPublisher {
+	eventPublisher := new(mocks.EventPublisher)
+	eventPublisher.On("Publish", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Maybe()
+	return eventPublisher
+}
+
 func Test_defaultChatUsecase_ChatQuestion(t *testing.T) {
 	ctx := context.TODO()
 	logger.NewLogger()
@@ -30,13 +47,25 @@ func Test_defaultChatUsecase_ChatQuestion(t *testing.T) {
 		args             args
 		getUserResp      *entity.User
 		getUserErr       error
+		getConvResp      *entity.Conversation
+		getConvErr       error
+		latestConvResp   *entity.Conversation
+		latestConvErr    error
+		createConvErr    error
+		getPersonaResp   *entity.Persona
+		getPersonaErr    error
+		getModelResp     *entity.ChatModel
+		getTemplateResp  *entity.PromptTemplate
+		getTemplateErr   error
 		cacheGetResp     string
 		cacheGetErr      error
 		getChatResp      []entity.Chat
 		getChatErr       error
+		toolCallResp     *openai.ChatCompletionResponse
 		generateTextResp openai.ChatCompletionResponse
 		generateTextErr  error
 		createChatErr    error
+		createToolErr    error
 		wantResp         dto.ChatQuestionResponse
 		wantErr          bool
 	}{
@@ -61,6 +90,10 @@ func Test_defaultChatUsecase_ChatQuestion(t *testing.T) {
 					Question: "bagaimana cara memasak nasi goreng?",
 				},
 			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
 			cacheGetResp: "users value",
 			wantErr:      true,
 		},
@@ -76,6 +109,10 @@ func Test_defaultChatUsecase_ChatQuestion(t *testing.T) {
 			getUserResp: &entity.User{
 				ID: 1,
 			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
 			getChatErr: errors.New("error getting data chat"),
 			wantErr:    true,
 		},
@@ -91,6 +128,10 @@ func Test_defaultChatUsecase_ChatQuestion(t *testing.T) {
 			getUserResp: &entity.User{
 				ID: 1,
 			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
 			getChatResp: []entity.Chat{
 				{
 					ID:      1,
@@ -113,6 +154,10 @@ func Test_defaultChatUsecase_ChatQuestion(t *testing.T) {
 			getUserResp: &entity.User{
 				ID: 1,
 			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
 			getChatResp: []entity.Chat{
 				{
 					ID:      1,
@@ -145,6 +190,10 @@ func Test_defaultChatUsecase_ChatQuestion(t *testing.T) {
 			getUserResp: &entity.User{
 				ID: 1,
 			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
 			getChatResp: []entity.Chat{
 				{
 					ID:      1,
@@ -163,134 +212,1590 @@ func Test_defaultChatUsecase_ChatQuestion(t *testing.T) {
 				},
 			},
 			wantResp: dto.ChatQuestionResponse{
-				Answer: "cara memasak nasi goreng yaitu nasi harus di goreng",
+				ConversationId: 1,
+				Answer:         "cara memasak nasi goreng yaitu nasi harus di goreng",
+				Model:          openai.GPT3Dot5Turbo,
 			},
 			wantErr: false,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			userRepo := new(mocks.UserRepository)
-			chatRepo := new(mocks.ChatRepository)
-			openAiWrapper := new(mocks.OpenAIWrapper)
-			cacheWrapper := new(mocks.CacheWrapper)
-
-			mockDb := utils.MockGorm()
-
-			userRepo.On("GetUserById", mock.Anything, mock.Anything).Return(tt.getUserResp, tt.getUserErr).Once()
-			cacheWrapper.On("Get", mock.Anything, mock.Anything).Return(tt.cacheGetResp, tt.cacheGetErr).Once()
-			chatRepo.On("GetByUserId", mock.Anything, mock.Anything).Return(tt.getChatResp, tt.getChatErr).Once()
-			openAiWrapper.On("GenerateText", mock.Anything, mock.Anything).Return(tt.generateTextResp, tt.generateTextErr).Once()
-			chatRepo.On("BeginsTrans").Return(mockDb).Once()
-			chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(tt.createChatErr)
-			chatRepo.On("Rollback", mock.Anything).Return(nil).Once()
-			chatRepo.On("Commit", mock.Anything).Return(nil).Once()
-			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil).Once()
-			cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
-
-			s := NewChatUsecase(userRepo, chatRepo, openAiWrapper, cacheWrapper)
-			gotResp, err := s.ChatQuestion(tt.args.ctx, tt.args.userId, tt.args.req)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("defaultChatUsecase.ChatQuestion() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(gotResp, tt.wantResp) {
-				t.Errorf("defaultChatUsecase.ChatQuestion() = %v, want %v", gotResp, tt.wantResp)
-			}
-		})
-	}
-}
-
-func Test_defaultChatUsecase_GetHistoryChat(t *testing.T) {
-	ctx := context.TODO()
-	logger.NewLogger()
-	getChat := []entity.Chat{
 		{
-			ID:      1,
-			Name:    "testing",
-			Message: "testing",
+			name: "conversation belongs to another user",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					ConversationId: 2,
+					Question:       "bagaimana cara memasak nasi goreng?",
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getConvResp: &entity.Conversation{
+				ID:     2,
+				UserID: 3,
+			},
+			wantErr: true,
 		},
-	}
-
-	historyChatResp := []dto.ChatHistoryResponse{
 		{
-			Id:      1,
-			Name:    "testing",
-			Message: "testing",
+			name: "persona changed in an existing conversation",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					ConversationId: 2,
+					PersonaId:      5,
+					Question:       "bagaimana cara memasak nasi goreng?",
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getConvResp: &entity.Conversation{
+				ID:        2,
+				UserID:    1,
+				PersonaID: 4,
+			},
+			wantErr: true,
 		},
-	}
-
-	historyByte, _ := json.Marshal(historyChatResp)
-	type args struct {
-		ctx    context.Context
-		userId int
-	}
-	tests := []struct {
-		name           string
-		args           args
-		cacheGetResp   string
-		cacheGetErr    error
-		getHistoryResp []entity.Chat
-		getHistoryErr  error
-		wantResp       []dto.ChatHistoryResponse
-		wantErr        bool
-	}{
 		{
-			name: "get history error",
+			name: "persona not found",
 			args: args{
 				ctx:    ctx,
 				userId: 1,
+				req: dto.ChatQuestionRequest{
+					PersonaId: 4,
+					Question:  "bagaimana cara memasak nasi goreng?",
+				},
 			},
-			cacheGetResp:  "",
-			getHistoryErr: errors.New("error getting chat history"),
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getPersonaErr: errors.New("record not found"),
 			wantErr:       true,
 		},
 		{
-			name:           "cache is null but succes get data chat history",
-			cacheGetResp:   "",
-			getHistoryResp: getChat,
-			wantResp:       historyChatResp,
-			wantErr:        false,
+			name: "persona not allowed for user",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					PersonaId: 4,
+					Question:  "bagaimana cara memasak nasi goreng?",
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getPersonaResp: &entity.Persona{
+				ID:           4,
+				AllowedUsers: []entity.PersonaUser{{PersonaID: 4, UserID: 2}},
+			},
+			wantErr: true,
 		},
 		{
-			name: "cache is not empty but unmarshal error",
+			name: "persona of the continued conversation deleted starts a new conversation",
 			args: args{
 				ctx:    ctx,
 				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question: "bagaimana cara memasak nasi goreng?",
+				},
 			},
-			cacheGetResp: "datas",
-			wantErr:      true,
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:        3,
+				UserID:    1,
+				PersonaID: 4,
+			},
+			getPersonaErr: errors.New("record not found"),
+			generateTextResp: openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:    openai.ChatMessageRoleAssistant,
+							Content: "nasi harus di goreng",
+						},
+					},
+				},
+			},
+			wantResp: dto.ChatQuestionResponse{
+				Answer: "nasi harus di goreng",
+				Model:  openai.GPT3Dot5Turbo,
+			},
+			wantErr: false,
 		},
 		{
-			name: "cache is not empty but succes get data chat history",
+			name: "persona of the requested conversation deleted",
 			args: args{
 				ctx:    ctx,
 				userId: 1,
+				req: dto.ChatQuestionRequest{
+					ConversationId: 3,
+					Question:       "bagaimana cara memasak nasi goreng?",
+				},
 			},
-			cacheGetResp: string(historyByte),
-			wantResp:     historyChatResp,
-			wantErr:      false,
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getConvResp: &entity.Conversation{
+				ID:        3,
+				UserID:    1,
+				PersonaID: 4,
+			},
+			getPersonaErr: errors.New("record not found"),
+			wantErr:       true,
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			userRepo := new(mocks.UserRepository)
-			chatRepo := new(mocks.ChatRepository)
-			openAiWrapper := new(mocks.OpenAIWrapper)
-			cacheWrapper := new(mocks.CacheWrapper)
-
-			cacheWrapper.On("Get", mock.Anything, mock.Anything).Return(tt.cacheGetResp, tt.cacheGetErr).Once()
-			chatRepo.On("GetHistoryChatByUserId", mock.Anything, mock.Anything).Return(tt.getHistoryResp, tt.getHistoryErr).Once()
-			cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
-
-			s := NewChatUsecase(userRepo, chatRepo, openAiWrapper, cacheWrapper)
-			gotResp, err := s.GetHistoryChat(tt.args.ctx, tt.args.userId)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("defaultChatUsecase.GetHistoryChat() error = %v, wantErr %v", err, tt.wantErr)
-				return
-			}
-			if !reflect.DeepEqual(gotResp, tt.wantResp) {
-				t.Errorf("defaultChatUsecase.GetHistoryChat() = %v, want %v", gotResp, tt.wantResp)
+		{
+			name: "create conversation error",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					PersonaId: 4,
+					Question:  "bagaimana cara memasak nasi goreng?",
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getPersonaResp: &entity.Persona{
+				ID:           4,
+				AllowedUsers: []entity.PersonaUser{{PersonaID: 4, UserID: 1}},
+			},
+			createConvErr: errors.New("create conversation error"),
+			wantErr:       true,
+		},
+		{
+			name: "success generate chat with persona in new conversation",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					PersonaId: 4,
+					Question:  "bagaimana cara memasak nasi goreng?",
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getPersonaResp: &entity.Persona{
+				ID:            4,
+				SystemPrompt:  "kamu adalah koki",
+				PromptVersion: 2,
+				Model:         openai.GPT4,
+			},
+			generateTextResp: openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:    openai.ChatMessageRoleAssistant,
+							Content: "nasi harus di goreng",
+						},
+					},
+				},
+			},
+			wantResp: dto.ChatQuestionResponse{
+				Answer: "nasi harus di goreng",
+				Model:  openai.GPT4,
+			},
+			wantErr: false,
+		},
+		{
+			name: "requested model not in allowlist",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question: "bagaimana cara memasak nasi goreng?",
+					Model:    "gpt-unknown",
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "requested model disabled",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question: "bagaimana cara memasak nasi goreng?",
+					Model:    openai.GPT4,
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
+			getModelResp: &entity.ChatModel{
+				Name: openai.GPT4,
+			},
+			wantErr: true,
+		},
+		{
+			name: "max tokens exceeds model limit",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question:  "bagaimana cara memasak nasi goreng?",
+					Model:     openai.GPT4,
+					MaxTokens: 2000,
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
+			getModelResp: &entity.ChatModel{
+				Name:      openai.GPT4,
+				MaxTokens: 1000,
+				Enabled:   true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "json format not supported by model",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question:       "bagaimana cara memasak nasi goreng?",
+					Model:          openai.GPT4,
+					ResponseFormat: dto.ResponseFormatJson,
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
+			getModelResp: &entity.ChatModel{
+				Name:    openai.GPT4,
+				Enabled: true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "success generate chat with requested model and options",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question:       "bagaimana cara memasak nasi goreng?",
+					Model:          openai.GPT4,
+					Temperature:    float32Ptr(0.2),
+					TopP:           float32Ptr(0.9),
+					MaxTokens:      500,
+					Stop:           []string{"###"},
+					ResponseFormat: dto.ResponseFormatJson,
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
+			getModelResp: &entity.ChatModel{
+				Name:               openai.GPT4,
+				MaxTokens:          1000,
+				MaxTemperature:     1,
+				SupportsJsonFormat: true,
+				Enabled:            true,
+			},
+			generateTextResp: openai.ChatCompletionResponse{
+				Model: "gpt-4-0613",
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:    openai.ChatMessageRoleAssistant,
+							Content: `{"jawaban": "nasi harus di goreng"}`,
+						},
+						FinishReason: openai.FinishReasonStop,
+					},
+				},
+			},
+			wantResp: dto.ChatQuestionResponse{
+				ConversationId: 1,
+				Answer:         `{"jawaban": "nasi harus di goreng"}`,
+				Model:          "gpt-4-0613",
+				FinishReason:   "stop",
+			},
+			wantErr: false,
+		},
+		{
+			name: "question combined with template",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question:   "bagaimana cara memasak nasi goreng?",
+					TemplateId: 3,
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "template not found",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					TemplateId: 3,
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getTemplateErr: errors.New("record not found"),
+			wantErr:        true,
+		},
+		{
+			name: "private template of another user",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					TemplateId: 3,
+					Variables:  map[string]string{"teks": "halo"},
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getTemplateResp: &entity.PromptTemplate{
+				ID:         3,
+				UserID:     2,
+				Content:    "{{teks}}",
+				Visibility: "private",
+			},
+			wantErr: true,
+		},
+		{
+			name: "template missing required variable",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					TemplateId: 3,
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			getTemplateResp: &entity.PromptTemplate{
+				ID:         3,
+				UserID:     2,
+				Content:    "terjemahkan ke bahasa {{bahasa}}: {{teks}}",
+				Visibility: "shared",
+				Variables: []entity.PromptTemplateVariable{
+					{Name: "bahasa", DefaultValue: "Indonesia formal"},
+					{Name: "teks", Required: true},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "success generate chat from template",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					TemplateId: 3,
+					Variables:  map[string]string{"teks": "apa kabar"},
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
+			getTemplateResp: &entity.PromptTemplate{
+				ID:         3,
+				UserID:     2,
+				Content:    "terjemahkan ke bahasa {{bahasa}}: {{teks}}",
+				Visibility: "shared",
+				Variables: []entity.PromptTemplateVariable{
+					{Name: "bahasa", DefaultValue: "Indonesia formal"},
+					{Name: "teks", Required: true},
+				},
+			},
+			generateTextResp: openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:    openai.ChatMessageRoleAssistant,
+							Content: "bagaimana keadaan Anda",
+						},
+					},
+				},
+			},
+			wantResp: dto.ChatQuestionResponse{
+				ConversationId: 1,
+				Answer:         "bagaimana keadaan Anda",
+				Model:          openai.GPT3Dot5Turbo,
+			},
+			wantErr: false,
+		},
+		{
+			name: "success generate chat after calling a tool",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question: "berapa 12 kali 7?",
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
+			toolCallResp: &openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role: openai.ChatMessageRoleAssistant,
+							ToolCalls: []openai.ToolCall{
+								{
+									ID:   "call_1",
+									Type: openai.ToolTypeFunction,
+									Function: openai.FunctionCall{
+										Name:      "calculate",
+										Arguments: `{"expression": "12 * (3 + 4)"}`,
+									},
+								},
+							},
+						},
+						FinishReason: openai.FinishReasonToolCalls,
+					},
+				},
+			},
+			generateTextResp: openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:    openai.ChatMessageRoleAssistant,
+							Content: "hasilnya 84",
+						},
+						FinishReason: openai.FinishReasonStop,
+					},
+				},
+			},
+			wantResp: dto.ChatQuestionResponse{
+				ConversationId: 1,
+				Answer:         "hasilnya 84",
+				Model:          openai.GPT3Dot5Turbo,
+				FinishReason:   "stop",
+				ToolCalls: []dto.ToolCallResponse{
+					{
+						Name:      "calculate",
+						Arguments: `{"expression": "12 * (3 + 4)"}`,
+						Result:    "84",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown tool is reported back to the model",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question: "berapa 12 kali 7?",
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
+			toolCallResp: &openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role: openai.ChatMessageRoleAssistant,
+							ToolCalls: []openai.ToolCall{
+								{
+									ID:   "call_1",
+									Type: openai.ToolTypeFunction,
+									Function: openai.FunctionCall{
+										Name:      "hitung",
+										Arguments: `{"expression": "12 * (3 + 4)"}`,
+									},
+								},
+							},
+						},
+						FinishReason: openai.FinishReasonToolCalls,
+					},
+				},
+			},
+			generateTextResp: openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:    openai.ChatMessageRoleAssistant,
+							Content: "hasilnya 84",
+						},
+						FinishReason: openai.FinishReasonStop,
+					},
+				},
+			},
+			wantResp: dto.ChatQuestionResponse{
+				ConversationId: 1,
+				Answer:         "hasilnya 84",
+				Model:          openai.GPT3Dot5Turbo,
+				FinishReason:   "stop",
+				ToolCalls: []dto.ToolCallResponse{
+					{
+						Name:      "hitung",
+						Arguments: `{"expression": "12 * (3 + 4)"}`,
+						Result:    "error: unknown tool hitung",
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "create tool call error",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.ChatQuestionRequest{
+					Question: "berapa 12 kali 7?",
+				},
+			},
+			getUserResp: &entity.User{
+				ID: 1,
+			},
+			latestConvResp: &entity.Conversation{
+				ID:     1,
+				UserID: 1,
+			},
+			toolCallResp: &openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role: openai.ChatMessageRoleAssistant,
+							ToolCalls: []openai.ToolCall{
+								{
+									ID:   "call_1",
+									Type: openai.ToolTypeFunction,
+									Function: openai.FunctionCall{
+										Name:      "calculate",
+										Arguments: `{"expression": "12 * (3 + 4)"}`,
+									},
+								},
+							},
+						},
+						FinishReason: openai.FinishReasonToolCalls,
+					},
+				},
+			},
+			generateTextResp: openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:    openai.ChatMessageRoleAssistant,
+							Content: "hasilnya 84",
+						},
+						FinishReason: openai.FinishReasonStop,
+					},
+				},
+			},
+			createToolErr: errors.New("create tool call error"),
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			personaRepo := new(mocks.PersonaRepository)
+			chatModelRepo := new(mocks.ChatModelRepository)
+			promptTemplateRepo := new(mocks.PromptTemplateRepository)
+			collectionRepo := new(mocks.CollectionRepository)
+			teamRepo := new(mocks.TeamRepository)
+			openAiWrapper := new(mocks.OpenAIWrapper)
+			cacheWrapper := new(mocks.CacheWrapper)
+			embeddingProvider := new(mocks.EmbeddingProvider)
+			vectorStore := new(mocks.VectorStore)
+			blobStorage := new(mocks.BlobStorage)
+			speechProvider := speech.NewStubProvider("")
+
+			mockDb := utils.MockGorm()
+
+			var getModelErr error
+			if tt.getModelResp == nil {
+				getModelErr = errors.New("record not found")
+			}
+
+			userRepo.On("GetUserById", mock.Anything, mock.Anything).Return(tt.getUserResp, tt.getUserErr).Once()
+			conversationRepo.On("GetById", mock.Anything, mock.Anything).Return(tt.getConvResp, tt.getConvErr).Once()
+			conversationRepo.On("GetLatestByUserId", mock.Anything, mock.Anything).Return(tt.latestConvResp, tt.latestConvErr).Once()
+			conversationRepo.On("Create", mock.Anything, mock.Anything).Return(tt.createConvErr).Once()
+			conversationRepo.On("GetCollectionIds", mock.Anything, mock.Anything).Return(nil, nil).Once()
+			personaRepo.On("GetById", mock.Anything, mock.Anything).Return(tt.getPersonaResp, tt.getPersonaErr).Once()
+			chatModelRepo.On("GetByName", mock.Anything, mock.Anything).Return(tt.getModelResp, getModelErr).Once()
+			promptTemplateRepo.On("GetById", mock.Anything, mock.Anything).Return(tt.getTemplateResp, tt.getTemplateErr).Once()
+			cacheWrapper.On("Get", mock.Anything, mock.Anything).Return(tt.cacheGetResp, tt.cacheGetErr).Once()
+			chatRepo.On("GetByConversationId", mock.Anything, mock.Anything).Return(tt.getChatResp, tt.getChatErr).Once()
+			// the background title generation finds the model unavailable
+			openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+				return req.Messages[0].Content == TitlePrompt
+			})).Return(openai.ChatCompletionResponse{}, errors.New("model unavailable")).Maybe()
+			if tt.toolCallResp != nil {
+				openAiWrapper.On("GenerateText", mock.Anything, mock.Anything).Return(*tt.toolCallResp, nil).Once()
+			}
+			openAiWrapper.On("GenerateText", mock.Anything, mock.Anything).Return(tt.generateTextResp, tt.generateTextErr).Once()
+			chatRepo.On("BeginsTrans").Return(mockDb).Once()
+			chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(tt.createChatErr)
+			chatRepo.On("CreateToolCall", mock.Anything, mock.Anything, mock.Anything).Return(tt.createToolErr)
+			chatRepo.On("Rollback", mock.Anything).Return(nil).Once()
+			chatRepo.On("Commit", mock.Anything).Return(nil).Once()
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil).Once()
+			cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+			s := NewChatUsecase(userRepo, chatRepo, conversationRepo, personaRepo, chatModelRepo, promptTemplateRepo, collectionRepo, teamRepo, new(mocks.ModerationRepository), tool.NewRegistry(tool.NewCalculatorTool()), embeddingProvider, vectorStore, blobStorage, speechProvider, openAiWrapper, cacheWrapper, cached.NewLocalLocker(), moderation.NewNoopModerator(), newEventPublisher())
+			gotResp, err := s.ChatQuestion(tt.args.ctx, tt.args.userId, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultChatUsecase.ChatQuestion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultChatUsecase.ChatQuestion() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
+
+func float32Ptr(value float32) *float32 {
+	return &value
+}
+
+func Test_defaultChatUsecase_applyGenerationOptions(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name            string
+		req             dto.ChatQuestionRequest
+		wantTemperature float32
+		wantTopP        float32
+		wantErr         bool
+	}{
+		{
+			name:            "options not set keep the persona's",
+			wantTemperature: 0.7,
+		},
+		{
+			name:            "zero temperature",
+			req:             dto.ChatQuestionRequest{Temperature: float32Ptr(0), TopP: float32Ptr(0)},
+			wantTemperature: math.SmallestNonzeroFloat32,
+			wantTopP:        math.SmallestNonzeroFloat32,
+		},
+		{
+			name:            "temperature and top p",
+			req:             dto.ChatQuestionRequest{Temperature: float32Ptr(1.2), TopP: float32Ptr(0.5)},
+			wantTemperature: 1.2,
+			wantTopP:        0.5,
+		},
+		{
+			name:    "negative temperature",
+			req:     dto.ChatQuestionRequest{Temperature: float32Ptr(-0.1)},
+			wantErr: true,
+		},
+		{
+			name:    "temperature over the limit of the model",
+			req:     dto.ChatQuestionRequest{Temperature: float32Ptr(1.6)},
+			wantErr: true,
+		},
+		{
+			name:    "top p over one",
+			req:     dto.ChatQuestionRequest{TopP: float32Ptr(1.1)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatModelRepo := new(mocks.ChatModelRepository)
+			chatModelRepo.On("GetByName", mock.Anything, openai.GPT3Dot5Turbo).Return(&entity.ChatModel{Name: openai.GPT3Dot5Turbo, MaxTemperature: 1.5, Enabled: true}, nil)
+
+			s := &defaultChatUsecase{chatModelRepo: chatModelRepo}
+			reqChat := openai.ChatCompletionRequest{
+				Model:       openai.GPT3Dot5Turbo,
+				Temperature: 0.7,
+				Messages:    []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleSystem}},
+			}
+			err := s.applyGenerationOptions(ctx, &reqChat, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("defaultChatUsecase.applyGenerationOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if reqChat.Temperature != tt.wantTemperature || reqChat.TopP != tt.wantTopP {
+				t.Errorf("defaultChatUsecase.applyGenerationOptions() temperature = %v, top p = %v, want %v, %v", reqChat.Temperature, reqChat.TopP, tt.wantTemperature, tt.wantTopP)
+			}
+		})
+	}
+}
+
+func Test_defaultChatUsecase_ChatQuestion_ToolIterationGuard(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	userRepo := new(mocks.UserRepository)
+	chatRepo := new(mocks.ChatRepository)
+	conversationRepo := new(mocks.ConversationRepository)
+	personaRepo := new(mocks.PersonaRepository)
+	chatModelRepo := new(mocks.ChatModelRepository)
+	promptTemplateRepo := new(mocks.PromptTemplateRepository)
+	collectionRepo := new(mocks.CollectionRepository)
+	teamRepo := new(mocks.TeamRepository)
+	openAiWrapper := new(mocks.OpenAIWrapper)
+	cacheWrapper := new(mocks.CacheWrapper)
+	embeddingProvider := new(mocks.EmbeddingProvider)
+	vectorStore := new(mocks.VectorStore)
+	blobStorage := new(mocks.BlobStorage)
+	speechProvider := speech.NewStubProvider("")
+
+	userRepo.On("GetUserById", mock.Anything, mock.Anything).Return(&entity.User{ID: 1}, nil)
+	conversationRepo.On("GetLatestByUserId", mock.Anything, mock.Anything).Return(&entity.Conversation{ID: 1, UserID: 1}, nil)
+	conversationRepo.On("GetCollectionIds", mock.Anything, mock.Anything).Return(nil, nil)
+	chatModelRepo.On("GetByName", mock.Anything, mock.Anything).Return(nil, errors.New("record not found"))
+	cacheWrapper.On("Get", mock.Anything, mock.Anything).Return("", nil)
+	chatRepo.On("GetByConversationId", mock.Anything, mock.Anything).Return([]entity.Chat{}, nil)
+	chatRepo.On("BeginsTrans").Return(utils.MockGorm())
+	chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	chatRepo.On("CreateToolCall", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	chatRepo.On("Commit", mock.Anything).Return(nil)
+	cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+	// the model keeps asking for a tool until it is no longer offered
+	// the background title generation finds the model unavailable
+	openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+		return req.Messages[0].Content == TitlePrompt
+	})).Return(openai.ChatCompletionResponse{}, errors.New("model unavailable")).Maybe()
+	openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+		return req.ToolChoice == nil
+	})).Return(openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Message: openai.ChatCompletionMessage{
+					Role: openai.ChatMessageRoleAssistant,
+					ToolCalls: []openai.ToolCall{
+						{
+							ID:       "call_1",
+							Type:     openai.ToolTypeFunction,
+							Function: openai.FunctionCall{Name: "calculate", Arguments: `{"expression": "1 + 1"}`},
+						},
+					},
+				},
+			},
+		},
+	}, nil)
+	openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+		return req.ToolChoice == "none"
+	})).Return(openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: "hasilnya 2",
+				},
+			},
+		},
+	}, nil).Once()
+
+	s := NewChatUsecase(userRepo, chatRepo, conversationRepo, personaRepo, chatModelRepo, promptTemplateRepo, collectionRepo, teamRepo, new(mocks.ModerationRepository), tool.NewRegistry(tool.NewCalculatorTool()), embeddingProvider, vectorStore, blobStorage, speechProvider, openAiWrapper, cacheWrapper, cached.NewLocalLocker(), moderation.NewNoopModerator(), newEventPublisher())
+	gotResp, err := s.ChatQuestion(ctx, 1, dto.ChatQuestionRequest{Question: "berapa 1 tambah 1?"})
+	if err != nil {
+		t.Fatalf("defaultChatUsecase.ChatQuestion() error = %v", err)
+	}
+	if gotResp.Answer != "hasilnya 2" {
+		t.Errorf("defaultChatUsecase.ChatQuestion() = %v, want %v", gotResp.Answer, "hasilnya 2")
+	}
+	if len(gotResp.ToolCalls) != MaxToolIterations {
+		t.Errorf("defaultChatUsecase.ChatQuestion() tool calls = %v, want %v", len(gotResp.ToolCalls), MaxToolIterations)
+	}
+	openAiWrapper.AssertNumberOfCalls(t, "GenerateText", MaxToolIterations+1)
+}
+
+func Test_defaultChatUsecase_ChatQuestion_Sources(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	userRepo := new(mocks.UserRepository)
+	chatRepo := new(mocks.ChatRepository)
+	conversationRepo := new(mocks.ConversationRepository)
+	personaRepo := new(mocks.PersonaRepository)
+	chatModelRepo := new(mocks.ChatModelRepository)
+	promptTemplateRepo := new(mocks.PromptTemplateRepository)
+	collectionRepo := new(mocks.CollectionRepository)
+	teamRepo := new(mocks.TeamRepository)
+	openAiWrapper := new(mocks.OpenAIWrapper)
+	cacheWrapper := new(mocks.CacheWrapper)
+	embeddingProvider := new(mocks.EmbeddingProvider)
+	vectorStore := new(mocks.VectorStore)
+	blobStorage := new(mocks.BlobStorage)
+	speechProvider := speech.NewStubProvider("")
+
+	userRepo.On("GetUserById", mock.Anything, mock.Anything).Return(&entity.User{ID: 1}, nil)
+	conversationRepo.On("GetLatestByUserId", mock.Anything, mock.Anything).Return(&entity.Conversation{ID: 1, UserID: 1}, nil)
+	conversationRepo.On("GetCollectionIds", mock.Anything, mock.Anything).Return(nil, nil)
+	chatModelRepo.On("GetByName", mock.Anything, mock.Anything).Return(nil, errors.New("record not found"))
+	cacheWrapper.On("Get", mock.Anything, mock.Anything).Return("", nil)
+	chatRepo.On("GetByConversationId", mock.Anything, mock.Anything).Return([]entity.Chat{}, nil)
+	chatRepo.On("BeginsTrans").Return(utils.MockGorm())
+	chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	chatRepo.On("Commit", mock.Anything).Return(nil)
+	cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+	embeddingProvider.On("Model").Return("text-embedding-ada-002")
+	embeddingProvider.On("Embed", mock.Anything, []string{"berapa lama cuti tahunan?"}).Return([][]float32{{1, 0}}, nil).Once()
+	vectorStore.On("Search", mock.Anything, vectorstore.SearchFilter{UserId: 1, EmbeddingModel: "text-embedding-ada-002"}, []float32{1, 0}, DefaultRagTopK).Return([]vectorstore.SearchResult{
+		{
+			Chunk: entity.DocumentChunk{
+				DocumentID: 3,
+				ChunkIndex: 2,
+				Content:    "Cuti tahunan karyawan adalah 12 hari.",
+				Document:   entity.Document{ID: 3, Name: "handbook.md"},
+			},
+			Score: 0.9,
+		},
+	}, nil).Once()
+
+	// the sources are given to the model right before the question
+	// the background title generation finds the model unavailable
+	openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+		return req.Messages[0].Content == TitlePrompt
+	})).Return(openai.ChatCompletionResponse{}, errors.New("model unavailable")).Maybe()
+	openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+		sources := req.Messages[len(req.Messages)-2]
+		return sources.Role == openai.ChatMessageRoleSystem && strings.Contains(sources.Content, "[1] handbook.md")
+	})).Return(openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Message: openai.ChatCompletionMessage{
+					Role:    openai.ChatMessageRoleAssistant,
+					Content: "Cuti tahunan adalah 12 hari [1].",
+				},
+			},
+		},
+	}, nil).Once()
+
+	// but are not kept in the cached context
+	cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.MatchedBy(func(value string) bool {
+		return !strings.Contains(value, "handbook.md")
+	}), mock.Anything).Return(nil).Once()
+
+	s := NewChatUsecase(userRepo, chatRepo, conversationRepo, personaRepo, chatModelRepo, promptTemplateRepo, collectionRepo, teamRepo, new(mocks.ModerationRepository), tool.NewRegistry(), embeddingProvider, vectorStore, blobStorage, speechProvider, openAiWrapper, cacheWrapper, cached.NewLocalLocker(), moderation.NewNoopModerator(), newEventPublisher())
+	gotResp, err := s.ChatQuestion(ctx, 1, dto.ChatQuestionRequest{Question: "berapa lama cuti tahunan?", UseDocuments: true})
+	if err != nil {
+		t.Fatalf("defaultChatUsecase.ChatQuestion() error = %v", err)
+	}
+
+	wantSources := []dto.SourceResponse{
+		{
+			Index:        1,
+			DocumentId:   3,
+			DocumentName: "handbook.md",
+			ChunkIndex:   2,
+			Score:        0.9,
+			Excerpt:      "Cuti tahunan karyawan adalah 12 hari.",
+		},
+	}
+	if !reflect.DeepEqual(gotResp.Sources, wantSources) {
+		t.Errorf("defaultChatUsecase.ChatQuestion() sources = %v, want %v", gotResp.Sources, wantSources)
+	}
+	cacheWrapper.AssertExpectations(t)
+}
+
+func Test_defaultChatUsecase_ChatQuestion_Images(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+	tests := []struct {
+		name         string
+		getModelResp *entity.ChatModel
+		putErr       error
+		wantErr      bool
+	}{
+		{
+			name:         "model does not support images",
+			getModelResp: &entity.ChatModel{Name: "gpt-4", Enabled: true},
+			wantErr:      true,
+		},
+		{
+			name:         "store image error",
+			getModelResp: &entity.ChatModel{Name: "gpt-4", Enabled: true, SupportsVision: true},
+			putErr:       errors.New("disk full"),
+			wantErr:      true,
+		},
+		{
+			name:         "success",
+			getModelResp: &entity.ChatModel{Name: "gpt-4", Enabled: true, SupportsVision: true},
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			personaRepo := new(mocks.PersonaRepository)
+			chatModelRepo := new(mocks.ChatModelRepository)
+			promptTemplateRepo := new(mocks.PromptTemplateRepository)
+			collectionRepo := new(mocks.CollectionRepository)
+			teamRepo := new(mocks.TeamRepository)
+			openAiWrapper := new(mocks.OpenAIWrapper)
+			cacheWrapper := new(mocks.CacheWrapper)
+			embeddingProvider := new(mocks.EmbeddingProvider)
+			vectorStore := new(mocks.VectorStore)
+			blobStorage := new(mocks.BlobStorage)
+			speechProvider := speech.NewStubProvider("")
+
+			userRepo.On("GetUserById", mock.Anything, mock.Anything).Return(&entity.User{ID: 1}, nil)
+			conversationRepo.On("GetLatestByUserId", mock.Anything, mock.Anything).Return(&entity.Conversation{ID: 1, UserID: 1}, nil)
+			conversationRepo.On("GetCollectionIds", mock.Anything, mock.Anything).Return(nil, nil)
+			chatModelRepo.On("GetByName", mock.Anything, "gpt-4").Return(tt.getModelResp, nil)
+			cacheWrapper.On("Get", mock.Anything, mock.Anything).Return("", nil)
+			chatRepo.On("GetByConversationId", mock.Anything, mock.Anything).Return([]entity.Chat{}, nil)
+			var cachedContext string
+			cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				if args.String(1) == "ChatBot_1_1" {
+					cachedContext = args.String(2)
+				}
+			})
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+			chatRepo.On("BeginsTrans").Return(utils.MockGorm())
+			chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(2).(*entity.Chat).ID = 10
+			})
+			chatRepo.On("Commit", mock.Anything).Return(nil)
+			blobStorage.On("Put", mock.Anything, mock.Anything, png).Return(tt.putErr).Once()
+
+			// the image is sent to the model next to the question
+			// the background title generation finds the model unavailable
+			openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+				return req.Messages[0].Content == TitlePrompt
+			})).Return(openai.ChatCompletionResponse{}, errors.New("model unavailable")).Maybe()
+			openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+				question := req.Messages[len(req.Messages)-1]
+				return len(question.MultiContent) == 2 &&
+					question.MultiContent[0].Text == "apa tulisan di label ini?" &&
+					strings.HasPrefix(question.MultiContent[1].ImageURL.URL, "data:image/png;base64,")
+			})).Return(openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:    openai.ChatMessageRoleAssistant,
+							Content: "Label bertuliskan 220V.",
+						},
+					},
+				},
+			}, nil).Once()
+
+			// and recorded with the question
+			chatRepo.On("CreateAttachment", mock.Anything, mock.Anything, mock.MatchedBy(func(attachment *entity.ChatAttachment) bool {
+				return attachment.ChatID == 10 && attachment.ContentType == "image/png" && attachment.Name == "label.png"
+			})).Return(nil).Once()
+
+			s := NewChatUsecase(userRepo, chatRepo, conversationRepo, personaRepo, chatModelRepo, promptTemplateRepo, collectionRepo, teamRepo, new(mocks.ModerationRepository), tool.NewRegistry(), embeddingProvider, vectorStore, blobStorage, speechProvider, openAiWrapper, cacheWrapper, cached.NewLocalLocker(), moderation.NewNoopModerator(), newEventPublisher())
+			_, err := s.ChatQuestion(ctx, 1, dto.ChatQuestionRequest{
+				Question: "apa tulisan di label ini?",
+				Model:    "gpt-4",
+				Images:   []dto.ImageInput{{Name: "label.png", Content: png}},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultChatUsecase.ChatQuestion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				chatRepo.AssertExpectations(t)
+
+				// the cached context keeps the question but not the image
+				var cachedChat openai.ChatCompletionRequest
+				json.Unmarshal([]byte(cachedContext), &cachedChat)
+				question := cachedChat.Messages[len(cachedChat.Messages)-2]
+				if strings.Contains(cachedContext, "data:") || question.Content != "apa tulisan di label ini?" || len(question.MultiContent) > 0 {
+					t.Errorf("defaultChatUsecase.ChatQuestion() cached context = %v, want the question without the image", cachedContext)
+				}
+			}
+		})
+	}
+}
+
+func Test_defaultChatUsecase_VoiceQuestion(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name           string
+		transcript     string
+		req            dto.VoiceQuestionRequest
+		wantTranscript string
+		wantSpeech     bool
+		wantErr        bool
+	}{
+		{
+			name:       "audio type not valid",
+			transcript: "jam berapa sekarang?",
+			req: dto.VoiceQuestionRequest{
+				Audio: dto.AudioInput{Name: "question.txt", Content: []byte("audio")},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "question given with audio",
+			transcript: "jam berapa sekarang?",
+			req: dto.VoiceQuestionRequest{
+				ChatQuestionRequest: dto.ChatQuestionRequest{Question: "halo"},
+				Audio:               dto.AudioInput{Name: "question.mp3", Content: []byte("audio")},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "no speech recognized",
+			transcript: " ",
+			req: dto.VoiceQuestionRequest{
+				Audio: dto.AudioInput{Name: "question.mp3", Content: []byte("audio")},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "success without speech",
+			transcript: "jam berapa sekarang?",
+			req: dto.VoiceQuestionRequest{
+				Audio: dto.AudioInput{Name: "question.mp3", Content: []byte("audio")},
+			},
+			wantTranscript: "jam berapa sekarang?",
+			wantErr:        false,
+		},
+		{
+			name:       "success with speech",
+			transcript: "jam berapa sekarang?",
+			req: dto.VoiceQuestionRequest{
+				Speak: true,
+				Audio: dto.AudioInput{Name: "question.mp3", Content: []byte("audio")},
+			},
+			wantTranscript: "jam berapa sekarang?",
+			wantSpeech:     true,
+			wantErr:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			personaRepo := new(mocks.PersonaRepository)
+			chatModelRepo := new(mocks.ChatModelRepository)
+			promptTemplateRepo := new(mocks.PromptTemplateRepository)
+			collectionRepo := new(mocks.CollectionRepository)
+			teamRepo := new(mocks.TeamRepository)
+			openAiWrapper := new(mocks.OpenAIWrapper)
+			cacheWrapper := new(mocks.CacheWrapper)
+			embeddingProvider := new(mocks.EmbeddingProvider)
+			vectorStore := new(mocks.VectorStore)
+			blobStorage := new(mocks.BlobStorage)
+			speechProvider := speech.NewStubProvider(tt.transcript)
+
+			userRepo.On("GetUserById", mock.Anything, mock.Anything).Return(&entity.User{ID: 1}, nil)
+			conversationRepo.On("GetLatestByUserId", mock.Anything, mock.Anything).Return(&entity.Conversation{ID: 1, UserID: 1}, nil)
+			conversationRepo.On("GetCollectionIds", mock.Anything, mock.Anything).Return(nil, nil)
+			chatModelRepo.On("GetByName", mock.Anything, mock.Anything).Return(nil, errors.New("record not found"))
+			cacheWrapper.On("Get", mock.Anything, mock.Anything).Return("", nil)
+			chatRepo.On("GetByConversationId", mock.Anything, mock.Anything).Return([]entity.Chat{}, nil)
+			cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+			chatRepo.On("BeginsTrans").Return(utils.MockGorm())
+			chatId := 10
+			chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+				args.Get(2).(*entity.Chat).ID = chatId
+				chatId++
+			})
+			chatRepo.On("Commit", mock.Anything).Return(nil)
+			blobStorage.On("Put", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			// the transcript is asked as the question
+			// the background title generation finds the model unavailable
+			openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+				return req.Messages[0].Content == TitlePrompt
+			})).Return(openai.ChatCompletionResponse{}, errors.New("model unavailable")).Maybe()
+			openAiWrapper.On("GenerateText", mock.Anything, mock.MatchedBy(func(req openai.ChatCompletionRequest) bool {
+				return req.Messages[len(req.Messages)-1].Content == tt.transcript
+			})).Return(openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{
+						Message: openai.ChatCompletionMessage{
+							Role:    openai.ChatMessageRoleAssistant,
+							Content: "Sekarang jam 10 pagi.",
+						},
+					},
+				},
+			}, nil).Once()
+
+			// the recording is kept with the question and the speech with the answer
+			chatRepo.On("CreateAttachment", mock.Anything, mock.Anything, mock.MatchedBy(func(attachment *entity.ChatAttachment) bool {
+				return attachment.ChatID == 10 && attachment.Kind == constrans.AttachmentAudio &&
+					attachment.ContentType == "audio/mpeg" && attachment.Transcript == tt.transcript
+			})).Return(nil).Once()
+			if tt.wantSpeech {
+				chatRepo.On("CreateAttachment", mock.Anything, mock.Anything, mock.MatchedBy(func(attachment *entity.ChatAttachment) bool {
+					return attachment.ChatID == 11 && attachment.Kind == constrans.AttachmentSpeech &&
+						attachment.Transcript == "Sekarang jam 10 pagi."
+				})).Return(nil).Once()
+			}
+
+			s := NewChatUsecase(userRepo, chatRepo, conversationRepo, personaRepo, chatModelRepo, promptTemplateRepo, collectionRepo, teamRepo, new(mocks.ModerationRepository), tool.NewRegistry(), embeddingProvider, vectorStore, blobStorage, speechProvider, openAiWrapper, cacheWrapper, cached.NewLocalLocker(), moderation.NewNoopModerator(), newEventPublisher())
+			gotResp, err := s.VoiceQuestion(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultChatUsecase.VoiceQuestion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotResp.Transcript != tt.wantTranscript || gotResp.Answer != "Sekarang jam 10 pagi." {
+				t.Errorf("defaultChatUsecase.VoiceQuestion() = %v, want transcript %v", gotResp, tt.wantTranscript)
+			}
+			if (gotResp.Speech != nil) != tt.wantSpeech {
+				t.Errorf("defaultChatUsecase.VoiceQuestion() speech = %v, want %v", gotResp.Speech, tt.wantSpeech)
+			}
+			chatRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func Test_defaultChatUsecase_GetHistoryChat(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+	getChat := []entity.Chat{
+		{
+			ID:      1,
+			Name:    "testing",
+			Message: "testing",
+		},
+	}
+
+	historyChatResp := []dto.ChatHistoryResponse{
+		{
+			Id:      1,
+			Name:    "testing",
+			Message: "testing",
+		},
+	}
+
+	historyByte, _ := json.Marshal(historyChatResp)
+	type args struct {
+		ctx    context.Context
+		userId int
+		filter dto.ChatHistoryFilter
+	}
+	tests := []struct {
+		name           string
+		args           args
+		wantFilter     mysql.HistoryFilter
+		cacheGetResp   string
+		cacheGetErr    error
+		getHistoryResp []entity.Chat
+		getHistoryErr  error
+		wantResp       []dto.ChatHistoryResponse
+		wantErr        bool
+	}{
+		{
+			name: "get history error",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+			},
+			cacheGetResp:  "",
+			getHistoryErr: errors.New("error getting chat history"),
+			wantErr:       true,
+		},
+		{
+			name:           "cache is null but succes get data chat history",
+			cacheGetResp:   "",
+			getHistoryResp: getChat,
+			wantResp:       historyChatResp,
+			wantErr:        false,
+		},
+		{
+			name: "cache is not empty but unmarshal error",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+			},
+			cacheGetResp: "datas",
+			wantErr:      true,
+		},
+		{
+			name: "cache is not empty but succes get data chat history",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+			},
+			cacheGetResp: string(historyByte),
+			wantResp:     historyChatResp,
+			wantErr:      false,
+		},
+		{
+			name: "filtered history is not read from cache",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				filter: dto.ChatHistoryFilter{Tag: " Riset  Pasar ", Starred: true},
+			},
+			wantFilter:     mysql.HistoryFilter{Tag: "riset pasar", Starred: true},
+			cacheGetResp:   string(historyByte),
+			getHistoryResp: getChat,
+			wantResp:       historyChatResp,
+			wantErr:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			personaRepo := new(mocks.PersonaRepository)
+			chatModelRepo := new(mocks.ChatModelRepository)
+			promptTemplateRepo := new(mocks.PromptTemplateRepository)
+			collectionRepo := new(mocks.CollectionRepository)
+			teamRepo := new(mocks.TeamRepository)
+			openAiWrapper := new(mocks.OpenAIWrapper)
+			cacheWrapper := new(mocks.CacheWrapper)
+			embeddingProvider := new(mocks.EmbeddingProvider)
+			vectorStore := new(mocks.VectorStore)
+			blobStorage := new(mocks.BlobStorage)
+			speechProvider := speech.NewStubProvider("")
+
+			cacheWrapper.On("Get", mock.Anything, mock.Anything).Return(tt.cacheGetResp, tt.cacheGetErr).Once()
+			chatRepo.On("GetHistoryChatByUserId", mock.Anything, mock.Anything, tt.wantFilter).Return(tt.getHistoryResp, tt.getHistoryErr).Once()
+			cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+			s := NewChatUsecase(userRepo, chatRepo, conversationRepo, personaRepo, chatModelRepo, promptTemplateRepo, collectionRepo, teamRepo, new(mocks.ModerationRepository), tool.NewRegistry(), embeddingProvider, vectorStore, blobStorage, speechProvider, openAiWrapper, cacheWrapper, cached.NewLocalLocker(), moderation.NewNoopModerator(), newEventPublisher())
+			gotResp, err := s.GetHistoryChat(tt.args.ctx, tt.args.userId, tt.args.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultChatUsecase.GetHistoryChat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultChatUsecase.GetHistoryChat() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
+
+func Test_defaultChatUsecase_StarChat(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name       string
+		getResp    *entity.Chat
+		getErr     error
+		starredErr error
+		wantErr    bool
+	}{
+		{
+			name:    "chat not found",
+			getErr:  errors.New("record not found"),
+			wantErr: true,
+		},
+		{
+			name:    "chat of another user",
+			getResp: &entity.Chat{ID: 5, UserID: 3},
+			wantErr: true,
+		},
+		{
+			name:       "set starred error",
+			getResp:    &entity.Chat{ID: 5, UserID: 1},
+			starredErr: errors.New("error"),
+			wantErr:    true,
+		},
+		{
+			name:    "success star chat",
+			getResp: &entity.Chat{ID: 5, UserID: 1},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatRepo := new(mocks.ChatRepository)
+			cacheWrapper := new(mocks.CacheWrapper)
+
+			chatRepo.On("GetById", mock.Anything, 5).Return(tt.getResp, tt.getErr).Once()
+			chatRepo.On("SetStarred", mock.Anything, 5, true).Return(tt.starredErr).Once()
+			cacheWrapper.On("Delete", mock.Anything, "getHistory_1").Return(nil).Once()
+
+			s := NewChatUsecase(new(mocks.UserRepository), chatRepo, new(mocks.ConversationRepository), new(mocks.PersonaRepository), new(mocks.ChatModelRepository), new(mocks.PromptTemplateRepository), new(mocks.CollectionRepository), new(mocks.TeamRepository), new(mocks.ModerationRepository), tool.NewRegistry(), new(mocks.EmbeddingProvider), new(mocks.VectorStore), new(mocks.BlobStorage), speech.NewStubProvider(""), new(mocks.OpenAIWrapper), cacheWrapper, cached.NewLocalLocker(), moderation.NewNoopModerator(), newEventPublisher())
+			err := s.StarChat(ctx, 1, 5, dto.ChatStarRequest{Starred: true})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultChatUsecase.StarChat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				cacheWrapper.AssertExpectations(t)
+			}
+		})
+	}
+}
+
+func Test_defaultChatUsecase_getConversationCollections(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	accessible := []entity.Collection{{ID: 1, UserID: 1}, {ID: 2, TeamID: 4}}
+
+	type args struct {
+		requested []int
+	}
+	tests := []struct {
+		name     string
+		args     args
+		attached []int
+		wantSet  bool
+		wantResp []int
+		wantErr  bool
+	}{
+		{
+			name:     "no collections attached",
+			wantResp: nil,
+		},
+		{
+			name:     "attached collections without access are skipped",
+			attached: []int{1, 3},
+			wantResp: []int{1},
+		},
+		{
+			name:    "requested collection without access",
+			args:    args{requested: []int{1, 3}},
+			wantErr: true,
+		},
+		{
+			name:     "requested collections replace the attached ones",
+			args:     args{requested: []int{2, 2, 1}},
+			wantSet:  true,
+			wantResp: []int{2, 1},
+		},
+		{
+			name:    "empty request detaches all collections",
+			args:    args{requested: []int{}},
+			wantSet: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := new(mocks.ConversationRepository)
+			collectionRepo := new(mocks.CollectionRepository)
+			teamRepo := new(mocks.TeamRepository)
+
+			conversationRepo.On("GetCollectionIds", mock.Anything, 7).Return(tt.attached, nil).Once()
+			teamRepo.On("GetByUserId", mock.Anything, 1).Return([]entity.Team{{ID: 4}}, nil).Once()
+			collectionRepo.On("GetAccessible", mock.Anything, 1, []int{4}).Return(accessible, nil).Once()
+			conversationRepo.On("SetCollectionIds", mock.Anything, 7, tt.wantResp).Return(nil).Once()
+
+			s := &defaultChatUsecase{
+				conversationRepo: conversationRepo,
+				collectionRepo:   collectionRepo,
+				teamRepo:         teamRepo,
+			}
+			gotResp, err := s.getConversationCollections(ctx, 1, 7, tt.args.requested)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultChatUsecase.getConversationCollections() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultChatUsecase.getConversationCollections() = %v, want %v", gotResp, tt.wantResp)
+			}
+			if tt.wantSet {
+				conversationRepo.AssertCalled(t, "SetCollectionIds", mock.Anything, 7, tt.wantResp)
+			} else {
+				conversationRepo.AssertNotCalled(t, "SetCollectionIds", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_defaultChatUsecase_ChatQuestion_Locked(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name         string
+		lockErr      error
+		held         bool
+		wantGenerate bool
+		wantErr      bool
+	}{
+		{
+			name:         "answered while holding the lock",
+			held:         true,
+			wantGenerate: true,
+		},
+		{
+			name:    "another question is being answered",
+			lockErr: cached.ErrLocked,
+			wantErr: true,
+		},
+		{
+			name:         "lock taken over while answering",
+			wantGenerate: true,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			chatModelRepo := new(mocks.ChatModelRepository)
+			openAiWrapper := new(mocks.OpenAIWrapper)
+			cacheWrapper := new(mocks.CacheWrapper)
+			locker := new(mocks.Locker)
+
+			userRepo.On("GetUserById", mock.Anything, mock.Anything).Return(&entity.User{ID: 1}, nil)
+			conversationRepo.On("GetLatestByUserId", mock.Anything, mock.Anything).Return(&entity.Conversation{ID: 3, UserID: 1, Title: "Koding"}, nil)
+			conversationRepo.On("GetCollectionIds", mock.Anything, mock.Anything).Return(nil, nil)
+			chatModelRepo.On("GetByName", mock.Anything, mock.Anything).Return(nil, errors.New("record not found"))
+			cacheWrapper.On("Get", mock.Anything, mock.Anything).Return("", nil)
+			chatRepo.On("GetByConversationId", mock.Anything, mock.Anything).Return([]entity.Chat{}, nil)
+			chatRepo.On("BeginsTrans").Return(utils.MockGorm())
+			chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			chatRepo.On("Commit", mock.Anything).Return(nil)
+			cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+			openAiWrapper.On("GenerateText", mock.Anything, mock.Anything).Return(openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{
+					{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Text editor dan git."}},
+				},
+			}, nil)
+
+			locker.On("Lock", mock.Anything, "ChatLock_1_3", ChatLockTTL).Return(int64(7), tt.lockErr).Once()
+			locker.On("Held", mock.Anything, "ChatLock_1_3", int64(7)).Return(tt.held, nil)
+			locker.On("Unlock", mock.Anything, "ChatLock_1_3", int64(7)).Return(nil)
+
+			s := NewChatUsecase(userRepo, chatRepo, conversationRepo, new(mocks.PersonaRepository), chatModelRepo, new(mocks.PromptTemplateRepository), new(mocks.CollectionRepository), new(mocks.TeamRepository), new(mocks.ModerationRepository), tool.NewRegistry(), new(mocks.EmbeddingProvider), new(mocks.VectorStore), new(mocks.BlobStorage), speech.NewStubProvider(""), openAiWrapper, cacheWrapper, locker, moderation.NewNoopModerator(), newEventPublisher())
+			_, err := s.ChatQuestion(ctx, 1, dto.ChatQuestionRequest{Question: "tools yang di butuhkan untuk koding?"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("defaultChatUsecase.ChatQuestion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantGenerate {
+				openAiWrapper.AssertCalled(t, "GenerateText", mock.Anything, mock.Anything)
+				locker.AssertCalled(t, "Unlock", mock.Anything, "ChatLock_1_3", int64(7))
+			} else {
+				openAiWrapper.AssertNotCalled(t, "GenerateText", mock.Anything, mock.Anything)
+				locker.AssertNotCalled(t, "Unlock", mock.Anything, mock.Anything, mock.Anything)
+			}
+			if tt.wantErr {
+				cacheWrapper.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				chatRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func Test_defaultChatUsecase_ChatQuestion_Events(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name       string
+		generateFn func() (openai.ChatCompletionResponse, error)
+		wantErr    bool
+		wantEvents []string
+	}{
+		{
+			name: "answered",
+			generateFn: func() (openai.ChatCompletionResponse, error) {
+				return openai.ChatCompletionResponse{
+					Choices: []openai.ChatCompletionChoice{
+						{Message: openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: "Text editor dan git."}, FinishReason: openai.FinishReasonStop},
+					},
+				}, nil
+			},
+			wantEvents: []string{constrans.EventMessageCreated, constrans.EventMessageCreated, constrans.EventAnswerCompleted},
+		},
+		{
+			name: "openai quota exceeded",
+			generateFn: func() (openai.ChatCompletionResponse, error) {
+				return openai.ChatCompletionResponse{}, fmt.Errorf("chat completion error: %w", &openai.APIError{HTTPStatusCode: 429, Message: "You exceeded your current quota"})
+			},
+			wantErr:    true,
+			wantEvents: []string{constrans.EventQuotaExceeded},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			chatModelRepo := new(mocks.ChatModelRepository)
+			openAiWrapper := new(mocks.OpenAIWrapper)
+			cacheWrapper := new(mocks.CacheWrapper)
+
+			userRepo.On("GetUserById", mock.Anything, mock.Anything).Return(&entity.User{ID: 1, Name: "Budi"}, nil)
+			conversationRepo.On("GetLatestByUserId", mock.Anything, mock.Anything).Return(&entity.Conversation{ID: 3, UserID: 1, Title: "Koding"}, nil)
+			conversationRepo.On("GetCollectionIds", mock.Anything, mock.Anything).Return(nil, nil)
+			chatModelRepo.On("GetByName", mock.Anything, mock.Anything).Return(nil, errors.New("record not found"))
+			cacheWrapper.On("Get", mock.Anything, mock.Anything).Return("", nil)
+			chatRepo.On("GetByConversationId", mock.Anything, mock.Anything).Return([]entity.Chat{}, nil)
+			chatRepo.On("BeginsTrans").Return(utils.MockGorm())
+			chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			chatRepo.On("Commit", mock.Anything).Return(nil)
+			cacheWrapper.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+			resp, err := tt.generateFn()
+			openAiWrapper.On("GenerateText", mock.Anything, mock.Anything).Return(resp, err)
+
+			var events []string
+			eventPublisher := new(mocks.EventPublisher)
+			eventPublisher.On("Publish", mock.Anything, 1, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				events = append(events, args.String(2))
+			})
+
+			s := NewChatUsecase(userRepo, chatRepo, conversationRepo, new(mocks.PersonaRepository), chatModelRepo, new(mocks.PromptTemplateRepository), new(mocks.CollectionRepository), new(mocks.TeamRepository), new(mocks.ModerationRepository), tool.NewRegistry(), new(mocks.EmbeddingProvider), new(mocks.VectorStore), new(mocks.BlobStorage), speech.NewStubProvider(""), openAiWrapper, cacheWrapper, cached.NewLocalLocker(), moderation.NewNoopModerator(), eventPublisher)
+			_, err = s.ChatQuestion(ctx, 1, dto.ChatQuestionRequest{Question: "tools yang di butuhkan untuk koding?"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("defaultChatUsecase.ChatQuestion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("defaultChatUsecase.ChatQuestion() events = %v, want %v", events, tt.wantEvents)
 			}
 		})
 	}
@@ -0,0 +1,563 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/http/embedding"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/repository/vectorstore"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+)
+
+// EmbeddingBatchSize is the number of chunks re-embedded at once by an embedding job
+const EmbeddingBatchSize = 100
+
+type CollectionUsecase interface {
+	CreateCollection(ctx context.Context, userId int, req dto.CollectionRequest) (resp dto.CollectionResponse, err error)
+	UpdateCollection(ctx context.Context, userId, id int, req dto.CollectionRequest) (resp dto.CollectionResponse, err error)
+	DeleteCollection(ctx context.Context, userId, id int) (err error)
+	GetCollections(ctx context.Context, userId int) (resp []dto.CollectionResponse, err error)
+	GetCollectionDocuments(ctx context.Context, userId, collectionId int) (resp []dto.CollectionDocumentResponse, err error)
+	AddDocument(ctx context.Context, userId, collectionId int, req dto.DocumentUploadRequest) (resp dto.CollectionDocumentResponse, err error)
+	UpdateDocument(ctx context.Context, userId, documentId int, req dto.DocumentUploadRequest) (resp dto.CollectionDocumentResponse, err error)
+	DeleteDocument(ctx context.Context, userId, documentId int) (err error)
+	ReembedCollection(ctx context.Context, userId, collectionId int) (resp dto.EmbeddingJobResponse, err error)
+	ReembedOutdatedCollections(ctx context.Context) (err error)
+	GetEmbeddingJob(ctx context.Context, userId, id int) (resp dto.EmbeddingJobResponse, err error)
+}
+
+type defaultCollectionUsecase struct {
+	collectionRepo    mysql.CollectionRepository
+	documentRepo      mysql.DocumentRepository
+	teamRepo          mysql.TeamRepository
+	embeddingProvider embedding.EmbeddingProvider
+	vectorStore       vectorstore.VectorStore
+}
+
+// NewCollectionUsecase creates a new instance of CollectionUsecase
+func NewCollectionUsecase(collectionRepo mysql.CollectionRepository, documentRepo mysql.DocumentRepository, teamRepo mysql.TeamRepository, embeddingProvider embedding.EmbeddingProvider, vectorStore vectorstore.VectorStore) CollectionUsecase {
+	return &defaultCollectionUsecase{
+		collectionRepo:    collectionRepo,
+		documentRepo:      documentRepo,
+		teamRepo:          teamRepo,
+		embeddingProvider: embeddingProvider,
+		vectorStore:       vectorStore,
+	}
+}
+
+// CreateCollection creates a collection owned by the user, or by one of the
+// user's teams when a team id is given
+func (s *defaultCollectionUsecase) CreateCollection(ctx context.Context, userId int, req dto.CollectionRequest) (resp dto.CollectionResponse, err error) {
+	err = s.validateCollection(ctx, userId, req)
+	if err != nil {
+		return
+	}
+
+	collection := entity.Collection{
+		UserID:         userId,
+		TeamID:         req.TeamId,
+		Name:           req.Name,
+		Description:    req.Description,
+		EmbeddingModel: s.embeddingProvider.Model(),
+	}
+	err = s.collectionRepo.Create(ctx, &collection)
+	if err != nil {
+		logger.Error(ctx, "error creating collection", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toCollectionResponse(collection)
+	return
+}
+
+// UpdateCollection renames a collection or moves it to another scope
+func (s *defaultCollectionUsecase) UpdateCollection(ctx context.Context, userId, id int, req dto.CollectionRequest) (resp dto.CollectionResponse, err error) {
+	collection, err := s.getCollection(ctx, userId, id)
+	if err != nil {
+		return
+	}
+
+	err = s.validateCollection(ctx, userId, req)
+	if err != nil {
+		return
+	}
+
+	collection.TeamID = req.TeamId
+	collection.Name = req.Name
+	collection.Description = req.Description
+	err = s.collectionRepo.Update(ctx, collection)
+	if err != nil {
+		logger.Error(ctx, "error updating collection", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toCollectionResponse(*collection)
+	return
+}
+
+// DeleteCollection deletes a collection with its documents
+func (s *defaultCollectionUsecase) DeleteCollection(ctx context.Context, userId, id int) (err error) {
+	_, err = s.getCollection(ctx, userId, id)
+	if err != nil {
+		return
+	}
+
+	err = s.collectionRepo.Delete(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "error deleting collection", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	return
+}
+
+// GetCollections returns the personal collections of the user and the
+// collections of the user's teams
+func (s *defaultCollectionUsecase) GetCollections(ctx context.Context, userId int) (resp []dto.CollectionResponse, err error) {
+	collections, err := getAccessibleCollections(ctx, s.collectionRepo, s.teamRepo, userId)
+	if err != nil {
+		return
+	}
+
+	for i := 0; i < len(collections); i++ {
+		resp = append(resp, toCollectionResponse(collections[i]))
+	}
+	return
+}
+
+// GetCollectionDocuments returns the documents in a collection
+func (s *defaultCollectionUsecase) GetCollectionDocuments(ctx context.Context, userId, collectionId int) (resp []dto.CollectionDocumentResponse, err error) {
+	_, err = s.getCollection(ctx, userId, collectionId)
+	if err != nil {
+		return
+	}
+
+	documents, err := s.documentRepo.GetByCollectionId(ctx, collectionId)
+	if err != nil {
+		logger.Error(ctx, "error getting documents", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(documents); i++ {
+		resp = append(resp, toCollectionDocumentResponse(documents[i], 0))
+	}
+	return
+}
+
+// AddDocument adds the first version of a document to a collection
+func (s *defaultCollectionUsecase) AddDocument(ctx context.Context, userId, collectionId int, req dto.DocumentUploadRequest) (resp dto.CollectionDocumentResponse, err error) {
+	collection, err := s.getCollection(ctx, userId, collectionId)
+	if err != nil {
+		return
+	}
+
+	doc := entity.Document{
+		UserID:       userId,
+		CollectionID: collection.ID,
+	}
+	resp, err = s.indexDocument(ctx, userId, &doc, req)
+	return
+}
+
+// UpdateDocument stores a new version of a document. Only the chunks that
+// changed since the previous version are embedded again.
+func (s *defaultCollectionUsecase) UpdateDocument(ctx context.Context, userId, documentId int, req dto.DocumentUploadRequest) (resp dto.CollectionDocumentResponse, err error) {
+	doc, err := s.getCollectionDocument(ctx, userId, documentId)
+	if err != nil {
+		return
+	}
+
+	if doc.ContentHash == hashContent(string(req.Data)) {
+		resp = toCollectionDocumentResponse(*doc, 0)
+		return
+	}
+
+	resp, err = s.indexDocument(ctx, userId, doc, req)
+	return
+}
+
+// DeleteDocument deletes a document of a collection with its chunks
+func (s *defaultCollectionUsecase) DeleteDocument(ctx context.Context, userId, documentId int) (err error) {
+	doc, err := s.getCollectionDocument(ctx, userId, documentId)
+	if err != nil {
+		return
+	}
+
+	err = s.vectorStore.DeleteByDocumentId(ctx, doc.ID)
+	if err != nil {
+		logger.Error(ctx, "error deleting document chunks", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	err = s.documentRepo.Delete(ctx, doc.ID)
+	if err != nil {
+		logger.Error(ctx, "error deleting document", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	return
+}
+
+// ReembedCollection starts a job that embeds the chunks of a collection again
+// with the current embedding model
+func (s *defaultCollectionUsecase) ReembedCollection(ctx context.Context, userId, collectionId int) (resp dto.EmbeddingJobResponse, err error) {
+	collection, err := s.getCollection(ctx, userId, collectionId)
+	if err != nil {
+		return
+	}
+
+	job, err := s.startEmbeddingJob(ctx, collection)
+	if err != nil {
+		logger.Error(ctx, "error creating embedding job", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toEmbeddingJobResponse(*job)
+	return
+}
+
+// ReembedOutdatedCollections starts an embedding job for every collection
+// embedded with another model than the current one
+func (s *defaultCollectionUsecase) ReembedOutdatedCollections(ctx context.Context) (err error) {
+	collections, err := s.collectionRepo.GetByEmbeddingModelNot(ctx, s.embeddingProvider.Model())
+	if err != nil {
+		logger.Error(ctx, "error getting outdated collections", err.Error())
+		return
+	}
+
+	for i := 0; i < len(collections); i++ {
+		_, err = s.startEmbeddingJob(ctx, &collections[i])
+		if err != nil {
+			logger.Error(ctx, "error creating embedding job", err.Error())
+			return
+		}
+	}
+	return
+}
+
+// GetEmbeddingJob returns the progress of an embedding job
+func (s *defaultCollectionUsecase) GetEmbeddingJob(ctx context.Context, userId, id int) (resp dto.EmbeddingJobResponse, err error) {
+	job, err := s.collectionRepo.GetEmbeddingJobById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "embedding job not found")
+		err = errors.SetError(http.StatusNotFound, "embedding job not found")
+		return
+	}
+
+	_, err = s.getCollection(ctx, userId, job.CollectionID)
+	if err != nil {
+		err = errors.SetError(http.StatusNotFound, "embedding job not found")
+		return
+	}
+
+	resp = toEmbeddingJobResponse(*job)
+	return
+}
+
+// startEmbeddingJob records a pending job and runs it in the background
+func (s *defaultCollectionUsecase) startEmbeddingJob(ctx context.Context, collection *entity.Collection) (job *entity.EmbeddingJob, err error) {
+	job = &entity.EmbeddingJob{
+		CollectionID:   collection.ID,
+		EmbeddingModel: s.embeddingProvider.Model(),
+		Status:         constrans.JobStatusPending,
+	}
+	err = s.collectionRepo.CreateEmbeddingJob(ctx, job)
+	if err != nil {
+		return
+	}
+
+	go s.runEmbeddingJob(context.WithoutCancel(ctx), *job, *collection)
+	return
+}
+
+// runEmbeddingJob embeds every chunk of the collection that was embedded
+// with another model, recording the progress on the job. Searches skip the
+// outdated chunks until they are embedded again.
+func (s *defaultCollectionUsecase) runEmbeddingJob(ctx context.Context, job entity.EmbeddingJob, collection entity.Collection) {
+	fail := func(err error) {
+		logger.Error(ctx, "embedding job failed", job.ID, err.Error())
+		job.Status = constrans.JobStatusFailed
+		job.Error = err.Error()
+		s.collectionRepo.UpdateEmbeddingJob(ctx, &job)
+	}
+
+	chunks, err := s.vectorStore.GetByCollectionId(ctx, collection.ID)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	var outdated []entity.DocumentChunk
+	for i := 0; i < len(chunks); i++ {
+		if chunks[i].EmbeddingModel != job.EmbeddingModel {
+			outdated = append(outdated, chunks[i])
+		}
+	}
+
+	job.Status = constrans.JobStatusRunning
+	job.Total = len(outdated)
+	s.collectionRepo.UpdateEmbeddingJob(ctx, &job)
+
+	for start := 0; start < len(outdated); start += EmbeddingBatchSize {
+		end := start + EmbeddingBatchSize
+		if end > len(outdated) {
+			end = len(outdated)
+		}
+
+		batch := outdated[start:end]
+		inputs := make([]string, len(batch))
+		for i := 0; i < len(batch); i++ {
+			inputs[i] = batch[i].Content
+		}
+
+		embeddings, errRes := s.embeddingProvider.Embed(ctx, inputs)
+		if errRes != nil {
+			fail(errRes)
+			return
+		}
+
+		for i := 0; i < len(batch) && i < len(embeddings); i++ {
+			batch[i].Embedding = embeddings[i]
+			batch[i].EmbeddingModel = job.EmbeddingModel
+		}
+
+		err = s.vectorStore.Upsert(ctx, batch)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		job.Processed = end
+		s.collectionRepo.UpdateEmbeddingJob(ctx, &job)
+	}
+
+	collection.EmbeddingModel = job.EmbeddingModel
+	err = s.collectionRepo.Update(ctx, &collection)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	job.Status = constrans.JobStatusCompleted
+	s.collectionRepo.UpdateEmbeddingJob(ctx, &job)
+}
+
+// indexDocument stores a new version of the document, embedding only the
+// chunks that are not already stored for it
+func (s *defaultCollectionUsecase) indexDocument(ctx context.Context, userId int, doc *entity.Document, req dto.DocumentUploadRequest) (resp dto.CollectionDocumentResponse, err error) {
+	contents, err := extractChunks(ctx, req)
+	if err != nil {
+		return
+	}
+
+	var existing []entity.DocumentChunk
+	if doc.ID != 0 {
+		existing, err = s.vectorStore.GetByDocumentId(ctx, doc.ID)
+		if err != nil {
+			logger.Error(ctx, "error getting document chunks", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+	}
+
+	chunks, stale, err := indexChunks(ctx, s.embeddingProvider, contents, existing)
+	if err != nil {
+		return
+	}
+
+	embedded := 0
+	for i := 0; i < len(chunks); i++ {
+		if chunks[i].ID == 0 {
+			embedded++
+		}
+	}
+
+	doc.Name = req.Name
+	doc.ContentType = req.ContentType
+	doc.Size = len(req.Data)
+	doc.ChunkCount = len(chunks)
+	doc.Version++
+	doc.ContentHash = hashContent(string(req.Data))
+	if doc.ID == 0 {
+		err = s.documentRepo.Create(ctx, doc)
+	} else {
+		err = s.documentRepo.Update(ctx, doc)
+	}
+	if err != nil {
+		logger.Error(ctx, "error saving document", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(chunks); i++ {
+		chunks[i].DocumentID = doc.ID
+		chunks[i].UserID = doc.UserID
+		chunks[i].CollectionID = doc.CollectionID
+	}
+
+	err = s.vectorStore.Upsert(ctx, chunks)
+	if err == nil {
+		err = s.vectorStore.DeleteByIds(ctx, stale)
+	}
+	if err != nil {
+		logger.Error(ctx, "error storing document chunks", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	err = s.documentRepo.CreateVersion(ctx, &entity.DocumentVersion{
+		DocumentID:  doc.ID,
+		Version:     doc.Version,
+		UserID:      userId,
+		ContentHash: doc.ContentHash,
+		Size:        doc.Size,
+		ChunkCount:  doc.ChunkCount,
+	})
+	if err != nil {
+		logger.Error(ctx, "error creating document version", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toCollectionDocumentResponse(*doc, embedded)
+	return
+}
+
+// getCollection returns a collection the user can manage
+func (s *defaultCollectionUsecase) getCollection(ctx context.Context, userId, id int) (collection *entity.Collection, err error) {
+	collection, err = s.collectionRepo.GetById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "collection not found")
+		err = errors.SetError(http.StatusNotFound, "collection not found")
+		return
+	}
+
+	if collection.TeamID == 0 {
+		if collection.UserID != userId {
+			logger.Error(ctx, "collection not found")
+			err = errors.SetError(http.StatusNotFound, "collection not found")
+		}
+		return
+	}
+
+	team, err := s.teamRepo.GetById(ctx, collection.TeamID)
+	if err != nil || !isTeamMember(team, userId) {
+		logger.Error(ctx, "collection not found")
+		err = errors.SetError(http.StatusNotFound, "collection not found")
+		return
+	}
+	return
+}
+
+// getCollectionDocument returns a document of a collection the user can manage
+func (s *defaultCollectionUsecase) getCollectionDocument(ctx context.Context, userId, documentId int) (doc *entity.Document, err error) {
+	doc, err = s.documentRepo.GetById(ctx, documentId)
+	if err != nil || doc.CollectionID == 0 {
+		logger.Error(ctx, "document not found")
+		err = errors.SetError(http.StatusNotFound, "document not found")
+		return
+	}
+
+	_, err = s.getCollection(ctx, userId, doc.CollectionID)
+	if err != nil {
+		err = errors.SetError(http.StatusNotFound, "document not found")
+		return
+	}
+	return
+}
+
+// validateCollection checks the collection request. A team collection can
+// only be placed in a team the user is a member of.
+func (s *defaultCollectionUsecase) validateCollection(ctx context.Context, userId int, req dto.CollectionRequest) (err error) {
+	if strings.TrimSpace(req.Name) == "" {
+		logger.Error(ctx, "collection name is required")
+		err = errors.SetError(http.StatusBadRequest, "collection name is required")
+		return
+	}
+
+	if req.TeamId == 0 {
+		return
+	}
+
+	team, err := s.teamRepo.GetById(ctx, req.TeamId)
+	if err != nil || !isTeamMember(team, userId) {
+		logger.Error(ctx, "team not found")
+		err = errors.SetError(http.StatusBadRequest, "team not found")
+		return
+	}
+	return
+}
+
+// getAccessibleCollections returns the personal collections of the user and
+// the collections of the user's teams
+func getAccessibleCollections(ctx context.Context, collectionRepo mysql.CollectionRepository, teamRepo mysql.TeamRepository, userId int) (resp []entity.Collection, err error) {
+	teams, err := teamRepo.GetByUserId(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting teams", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	var teamIds []int
+	for i := 0; i < len(teams); i++ {
+		teamIds = append(teamIds, teams[i].ID)
+	}
+
+	resp, err = collectionRepo.GetAccessible(ctx, userId, teamIds)
+	if err != nil {
+		logger.Error(ctx, "error getting collections", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	return
+}
+
+// toCollectionResponse converts a collection to its dto
+func toCollectionResponse(collection entity.Collection) dto.CollectionResponse {
+	return dto.CollectionResponse{
+		Id:             collection.ID,
+		UserId:         collection.UserID,
+		TeamId:         collection.TeamID,
+		Name:           collection.Name,
+		Description:    collection.Description,
+		EmbeddingModel: collection.EmbeddingModel,
+	}
+}
+
+// toCollectionDocumentResponse converts a collection document to its dto
+func toCollectionDocumentResponse(doc entity.Document, embedded int) dto.CollectionDocumentResponse {
+	return dto.CollectionDocumentResponse{
+		Id:          doc.ID,
+		Name:        doc.Name,
+		ContentType: doc.ContentType,
+		Size:        doc.Size,
+		ChunkCount:  doc.ChunkCount,
+		Version:     doc.Version,
+		Embedded:    embedded,
+		UpdatedAt:   doc.UpdatedAt,
+	}
+}
+
+// toEmbeddingJobResponse converts an embedding job to its dto
+func toEmbeddingJobResponse(job entity.EmbeddingJob) dto.EmbeddingJobResponse {
+	return dto.EmbeddingJobResponse{
+		Id:             job.ID,
+		CollectionId:   job.CollectionID,
+		EmbeddingModel: job.EmbeddingModel,
+		Status:         job.Status,
+		Total:          job.Total,
+		Processed:      job.Processed,
+		Error:          job.Error,
+	}
+}
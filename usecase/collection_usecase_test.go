@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultCollectionUsecase_CreateCollection(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	type args struct {
+		ctx    context.Context
+		userId int
+		req    dto.CollectionRequest
+	}
+	tests := []struct {
+		name        string
+		args        args
+		getTeamResp *entity.Team
+		getTeamErr  error
+		createErr   error
+		wantResp    dto.CollectionResponse
+		wantErr     bool
+	}{
+		{
+			name: "name is empty",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.CollectionRequest{},
+			},
+			wantErr: true,
+		},
+		{
+			name: "team not found",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.CollectionRequest{Name: "SOP", TeamId: 2},
+			},
+			getTeamErr: errors.New("record not found"),
+			wantErr:    true,
+		},
+		{
+			name: "user is not a team member",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.CollectionRequest{Name: "SOP", TeamId: 2},
+			},
+			getTeamResp: &entity.Team{ID: 2, Members: []entity.TeamMember{{TeamID: 2, UserID: 5}}},
+			wantErr:     true,
+		},
+		{
+			name: "create collection error",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.CollectionRequest{Name: "SOP"},
+			},
+			createErr: errors.New("create collection error"),
+			wantErr:   true,
+		},
+		{
+			name: "success create team collection",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.CollectionRequest{Name: "SOP", TeamId: 2},
+			},
+			getTeamResp: &entity.Team{ID: 2, Members: []entity.TeamMember{{TeamID: 2, UserID: 1}}},
+			wantResp: dto.CollectionResponse{
+				UserId:         1,
+				TeamId:         2,
+				Name:           "SOP",
+				EmbeddingModel: "text-embedding-ada-002",
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collectionRepo := new(mocks.CollectionRepository)
+			documentRepo := new(mocks.DocumentRepository)
+			teamRepo := new(mocks.TeamRepository)
+			embeddingProvider := new(mocks.EmbeddingProvider)
+			vectorStore := new(mocks.VectorStore)
+
+			teamRepo.On("GetById", mock.Anything, mock.Anything).Return(tt.getTeamResp, tt.getTeamErr).Once()
+			embeddingProvider.On("Model").Return("text-embedding-ada-002")
+			collectionRepo.On("Create", mock.Anything, mock.Anything).Return(tt.createErr).Once()
+
+			s := NewCollectionUsecase(collectionRepo, documentRepo, teamRepo, embeddingProvider, vectorStore)
+			gotResp, err := s.CreateCollection(tt.args.ctx, tt.args.userId, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultCollectionUsecase.CreateCollection() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultCollectionUsecase.CreateCollection() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
+
+func Test_indexChunks(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	model := "text-embedding-ada-002"
+	unchanged := "Cuti tahunan adalah 12 hari."
+	removed := "Lembur dibayar dua kali."
+	added := "Lembur tidak dibayar."
+	existing := []entity.DocumentChunk{
+		{ID: 20, ChunkIndex: 0, Content: unchanged, ContentHash: hashContent(unchanged), EmbeddingModel: model, Embedding: entity.Vector{1, 0}},
+		{ID: 21, ChunkIndex: 1, Content: removed, ContentHash: hashContent(removed), EmbeddingModel: model, Embedding: entity.Vector{0, 1}},
+		{ID: 22, ChunkIndex: 2, Content: added, ContentHash: hashContent(added), EmbeddingModel: "old-model", Embedding: entity.Vector{1, 1}},
+	}
+
+	embeddingProvider := new(mocks.EmbeddingProvider)
+	embeddingProvider.On("Model").Return(model)
+	// only the chunk that is not stored with the current model is embedded
+	embeddingProvider.On("Embed", mock.Anything, []string{added}).Return([][]float32{{0.5, 0.5}}, nil).Once()
+
+	chunks, stale, err := indexChunks(ctx, embeddingProvider, []string{added, unchanged}, existing)
+	if err != nil {
+		t.Fatalf("indexChunks() error = %v", err)
+	}
+
+	wantChunks := []entity.DocumentChunk{
+		{ChunkIndex: 0, Content: added, ContentHash: hashContent(added), EmbeddingModel: model, Embedding: entity.Vector{0.5, 0.5}},
+		{ID: 20, ChunkIndex: 1, Content: unchanged, ContentHash: hashContent(unchanged), EmbeddingModel: model, Embedding: entity.Vector{1, 0}},
+	}
+	if !reflect.DeepEqual(chunks, wantChunks) {
+		t.Errorf("indexChunks() chunks = %v, want %v", chunks, wantChunks)
+	}
+	if !reflect.DeepEqual(stale, []int{22, 21}) {
+		t.Errorf("indexChunks() stale = %v, want %v", stale, []int{22, 21})
+	}
+	embeddingProvider.AssertExpectations(t)
+}
@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+)
+
+const (
+	// MaxTitleLength is the number of characters kept of a conversation title
+	MaxTitleLength = 100
+	// MaxTags is the number of tags a conversation can have
+	MaxTags = 10
+	// MaxTagLength is the number of characters of a tag
+	MaxTagLength = 30
+)
+
+type ConversationUsecase interface {
+	GetConversations(ctx context.Context, userId int, filter dto.ConversationFilter) (resp []dto.ConversationResponse, err error)
+	UpdateConversationTitle(ctx context.Context, userId, id int, req dto.ConversationTitleRequest) (resp dto.ConversationResponse, err error)
+	SetConversationTags(ctx context.Context, userId, id int, req dto.ConversationTagsRequest) (resp dto.ConversationResponse, err error)
+	PinConversation(ctx context.Context, userId, id int, req dto.ConversationPinRequest) (resp dto.ConversationResponse, err error)
+	GetTags(ctx context.Context, userId int) (resp []string, err error)
+	DeleteChat(ctx context.Context, userId, id int) (err error)
+	ClearConversation(ctx context.Context, userId, id int) (err error)
+	ResetContext(ctx context.Context, userId, id int) (resp dto.ConversationResponse, err error)
+}
+
+type defaultConversationUsecase struct {
+	conversationRepo mysql.ConversationRepository
+	chatRepo         mysql.ChatRepository
+	auditRepo        mysql.AuditRepository
+	cacheWrapper     cached.CacheWrapper
+}
+
+// NewConversationUsecase creates a new instance of ConversationUsecase
+func NewConversationUsecase(conversationRepo mysql.ConversationRepository, chatRepo mysql.ChatRepository, auditRepo mysql.AuditRepository, cacheWrapper cached.CacheWrapper) ConversationUsecase {
+	return &defaultConversationUsecase{
+		conversationRepo: conversationRepo,
+		chatRepo:         chatRepo,
+		auditRepo:        auditRepo,
+		cacheWrapper:     cacheWrapper,
+	}
+}
+
+// deletedChat is what the audit log keeps of a deleted message
+type deletedChat struct {
+	Id             int       `json:"id"`
+	ConversationId int       `json:"conversationId"`
+	Name           string    `json:"name"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// GetConversations returns the conversations of the user with their tags,
+// pinned ones first and then the latest first
+func (s *defaultConversationUsecase) GetConversations(ctx context.Context, userId int, filter dto.ConversationFilter) (resp []dto.ConversationResponse, err error) {
+	conversations, err := s.conversationRepo.Find(ctx, mysql.ConversationFilter{
+		UserId: userId,
+		Tag:    cleanTag(filter.Tag),
+		Pinned: filter.Pinned,
+	})
+	if err != nil {
+		logger.Error(ctx, "error getting conversations", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	ids := make([]int, len(conversations))
+	for i := 0; i < len(conversations); i++ {
+		ids[i] = conversations[i].ID
+	}
+	tags, err := s.conversationRepo.GetTags(ctx, ids)
+	if err != nil {
+		logger.Error(ctx, "error getting conversation tags", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	tagsByConversation := map[int][]string{}
+	for i := 0; i < len(tags); i++ {
+		tagsByConversation[tags[i].ConversationID] = append(tagsByConversation[tags[i].ConversationID], tags[i].Name)
+	}
+
+	resp = []dto.ConversationResponse{}
+	for i := 0; i < len(conversations); i++ {
+		conversation := toConversationResponse(conversations[i])
+		conversation.Tags = tagsByConversation[conversations[i].ID]
+		resp = append(resp, conversation)
+	}
+	return
+}
+
+// UpdateConversationTitle replaces the title of a conversation of the user.
+// A title set here is never overwritten by a generated one.
+func (s *defaultConversationUsecase) UpdateConversationTitle(ctx context.Context, userId, id int, req dto.ConversationTitleRequest) (resp dto.ConversationResponse, err error) {
+	title := cleanTitle(req.Title)
+	if title == "" {
+		logger.Error(ctx, "title is required")
+		err = errors.SetError(http.StatusBadRequest, "title is required")
+		return
+	}
+
+	conversation, err := s.getConversation(ctx, userId, id)
+	if err != nil {
+		return
+	}
+
+	err = s.conversationRepo.UpdateTitle(ctx, id, title)
+	if err != nil {
+		logger.Error(ctx, "error updating conversation title", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	conversation.Title = title
+	resp = toConversationResponse(*conversation)
+	return
+}
+
+// SetConversationTags replaces the tags of a conversation of the user. Tags
+// are kept in lower case, so filtering on them ignores case.
+func (s *defaultConversationUsecase) SetConversationTags(ctx context.Context, userId, id int, req dto.ConversationTagsRequest) (resp dto.ConversationResponse, err error) {
+	tags := []string{}
+	for i := 0; i < len(req.Tags); i++ {
+		tag := cleanTag(req.Tags[i])
+		if tag == "" || containsString(tags, tag) {
+			continue
+		}
+		if len([]rune(tag)) > MaxTagLength {
+			logger.Error(ctx, "tag too long", tag)
+			err = errors.SetError(http.StatusBadRequest, fmt.Sprintf("tags must be at most %v characters", MaxTagLength))
+			return
+		}
+		tags = append(tags, tag)
+	}
+	if len(tags) > MaxTags {
+		logger.Error(ctx, "too many tags", len(tags))
+		err = errors.SetError(http.StatusBadRequest, fmt.Sprintf("a conversation can have at most %v tags", MaxTags))
+		return
+	}
+
+	conversation, err := s.getConversation(ctx, userId, id)
+	if err != nil {
+		return
+	}
+
+	err = s.conversationRepo.SetTags(ctx, userId, id, tags)
+	if err != nil {
+		logger.Error(ctx, "error setting conversation tags", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	sort.Strings(tags)
+	resp = toConversationResponse(*conversation)
+	resp.Tags = tags
+	return
+}
+
+// PinConversation pins a conversation of the user to the top of the listing,
+// or unpins it
+func (s *defaultConversationUsecase) PinConversation(ctx context.Context, userId, id int, req dto.ConversationPinRequest) (resp dto.ConversationResponse, err error) {
+	conversation, err := s.getConversation(ctx, userId, id)
+	if err != nil {
+		return
+	}
+
+	err = s.conversationRepo.SetPinned(ctx, id, req.Pinned)
+	if err != nil {
+		logger.Error(ctx, "error pinning conversation", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	conversation.Pinned = req.Pinned
+	resp = toConversationResponse(*conversation)
+	return
+}
+
+// GetTags returns every tag the user has put on a conversation
+func (s *defaultConversationUsecase) GetTags(ctx context.Context, userId int) (resp []string, err error) {
+	resp, err = s.conversationRepo.GetTagNames(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting tags", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	if resp == nil {
+		resp = []string{}
+	}
+	return
+}
+
+// DeleteChat deletes a message of the user. It is left out of the history
+// and of the context of its conversation from now on.
+func (s *defaultConversationUsecase) DeleteChat(ctx context.Context, userId, id int) (err error) {
+	chat, err := s.chatRepo.GetById(ctx, id)
+	if err != nil || chat.UserID != userId {
+		logger.Error(ctx, "chat not found")
+		err = errors.SetError(http.StatusNotFound, "chat not found")
+		return
+	}
+
+	err = s.chatRepo.Delete(ctx, chat.ID)
+	if err != nil {
+		logger.Error(ctx, "error deleting chat", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	s.invalidateCache(ctx, userId, chat.ConversationID)
+	recordAudit(ctx, s.auditRepo, userId, constrans.AuditChatDeleted, "chat", chat.ID, deletedChat{
+		Id:             chat.ID,
+		ConversationId: chat.ConversationID,
+		Name:           chat.Name,
+		CreatedAt:      chat.CreatedAt,
+	}, nil)
+	return
+}
+
+// ClearConversation deletes every message of a conversation of the user. The
+// conversation itself is kept and can be continued.
+func (s *defaultConversationUsecase) ClearConversation(ctx context.Context, userId, id int) (err error) {
+	conversation, err := s.getConversation(ctx, userId, id)
+	if err != nil {
+		return
+	}
+
+	chats, err := s.chatRepo.Find(ctx, mysql.ChatFilter{UserId: userId, ConversationId: conversation.ID})
+	if err != nil {
+		logger.Error(ctx, "error getting chats", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	err = s.chatRepo.DeleteByConversationId(ctx, conversation.ID)
+	if err != nil {
+		logger.Error(ctx, "error clearing conversation", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	deleted := []deletedChat{}
+	for i := 0; i < len(chats); i++ {
+		deleted = append(deleted, deletedChat{
+			Id:             chats[i].ID,
+			ConversationId: chats[i].ConversationID,
+			Name:           chats[i].Name,
+			CreatedAt:      chats[i].CreatedAt,
+		})
+	}
+
+	s.invalidateCache(ctx, userId, conversation.ID)
+	recordAudit(ctx, s.auditRepo, userId, constrans.AuditConversationCleared, "conversation", conversation.ID, deleted, nil)
+	return
+}
+
+// ResetContext starts a fresh context in a conversation of the user. The
+// messages written so far stay in the history, but the model no longer
+// answers from them.
+func (s *defaultConversationUsecase) ResetContext(ctx context.Context, userId, id int) (resp dto.ConversationResponse, err error) {
+	conversation, err := s.getConversation(ctx, userId, id)
+	if err != nil {
+		return
+	}
+
+	chats, err := s.chatRepo.GetByConversationId(ctx, conversation.ID)
+	if err != nil {
+		logger.Error(ctx, "error getting chats", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	if len(chats) > 0 {
+		before := map[string]int{"contextStartId": conversation.ContextStartID}
+		conversation.ContextStartID = chats[len(chats)-1].ID
+		err = s.conversationRepo.SetContextStart(ctx, conversation.ID, conversation.ContextStartID)
+		if err != nil {
+			logger.Error(ctx, "error resetting context", err.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+
+		after := map[string]int{"contextStartId": conversation.ContextStartID}
+		recordAudit(ctx, s.auditRepo, userId, constrans.AuditContextReset, "conversation", conversation.ID, before, after)
+	}
+
+	// the cached context and history are dropped so the next question
+	// starts fresh
+	s.invalidateCache(ctx, userId, conversation.ID)
+
+	resp = toConversationResponse(*conversation)
+	return
+}
+
+// invalidateCache drops the cached context of the conversation and the
+// cached history of the user
+func (s *defaultConversationUsecase) invalidateCache(ctx context.Context, userId, conversationId int) {
+	key := fmt.Sprintf("%v_%v_%v", KeyChatBot, userId, conversationId)
+	s.cacheWrapper.Delete(ctx, key)
+
+	keyHistory := fmt.Sprintf("%v_%v", KeyHistory, userId)
+	s.cacheWrapper.Delete(ctx, keyHistory)
+}
+
+// getConversation returns a conversation of the user
+func (s *defaultConversationUsecase) getConversation(ctx context.Context, userId, id int) (conversation *entity.Conversation, err error) {
+	conversation, err = s.conversationRepo.GetById(ctx, id)
+	if err != nil || conversation.UserID != userId {
+		logger.Error(ctx, "conversation not found")
+		err = errors.SetError(http.StatusNotFound, "conversation not found")
+		return
+	}
+	return
+}
+
+// cleanTag collapses the whitespace of a tag and puts it in lower case
+func cleanTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// cleanTitle trims the whitespace, quotes and final period a title tends to
+// come with and cuts it to MaxTitleLength characters
+func cleanTitle(title string) string {
+	title = strings.Join(strings.Fields(title), " ")
+	title = strings.Trim(title, "\"'`*")
+	title = strings.TrimSuffix(title, ".")
+	title = strings.TrimSpace(title)
+
+	runes := []rune(title)
+	if len(runes) > MaxTitleLength {
+		title = strings.TrimSpace(string(runes[:MaxTitleLength]))
+	}
+	return title
+}
+
+// toConversationResponse converts a conversation to its dto
+func toConversationResponse(conversation entity.Conversation) dto.ConversationResponse {
+	return dto.ConversationResponse{
+		Id:        conversation.ID,
+		PersonaId: conversation.PersonaID,
+		Title:     conversation.Title,
+		Pinned:    conversation.Pinned,
+		CreatedAt: conversation.CreatedAt,
+		UpdatedAt: conversation.UpdatedAt,
+	}
+}
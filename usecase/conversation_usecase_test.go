@@ -0,0 +1,351 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultConversationUsecase_UpdateConversationTitle(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	type args struct {
+		userId int
+		id     int
+		req    dto.ConversationTitleRequest
+	}
+	tests := []struct {
+		name      string
+		args      args
+		getResp   *entity.Conversation
+		getErr    error
+		updateErr error
+		wantResp  dto.ConversationResponse
+		wantErr   bool
+	}{
+		{
+			name: "title is empty",
+			args: args{
+				userId: 1,
+				id:     2,
+				req:    dto.ConversationTitleRequest{Title: "  "},
+			},
+			wantErr: true,
+		},
+		{
+			name: "conversation not found",
+			args: args{
+				userId: 1,
+				id:     2,
+				req:    dto.ConversationTitleRequest{Title: "Resep"},
+			},
+			getErr:  errors.New("record not found"),
+			wantErr: true,
+		},
+		{
+			name: "conversation of another user",
+			args: args{
+				userId: 1,
+				id:     2,
+				req:    dto.ConversationTitleRequest{Title: "Resep"},
+			},
+			getResp: &entity.Conversation{ID: 2, UserID: 3},
+			wantErr: true,
+		},
+		{
+			name: "update title error",
+			args: args{
+				userId: 1,
+				id:     2,
+				req:    dto.ConversationTitleRequest{Title: "Resep"},
+			},
+			getResp:   &entity.Conversation{ID: 2, UserID: 1},
+			updateErr: errors.New("update title error"),
+			wantErr:   true,
+		},
+		{
+			name: "success update title",
+			args: args{
+				userId: 1,
+				id:     2,
+				req:    dto.ConversationTitleRequest{Title: "  Resep   nasi goreng "},
+			},
+			getResp: &entity.Conversation{ID: 2, UserID: 1, Title: "Cara memasak"},
+			wantResp: dto.ConversationResponse{
+				Id:    2,
+				Title: "Resep nasi goreng",
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := new(mocks.ConversationRepository)
+			conversationRepo.On("GetById", mock.Anything, tt.args.id).Return(tt.getResp, tt.getErr).Once()
+			conversationRepo.On("UpdateTitle", mock.Anything, tt.args.id, mock.Anything).Return(tt.updateErr).Once()
+
+			s := NewConversationUsecase(conversationRepo, new(mocks.ChatRepository), new(mocks.AuditRepository), new(mocks.CacheWrapper))
+			gotResp, err := s.UpdateConversationTitle(ctx, tt.args.userId, tt.args.id, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultConversationUsecase.UpdateConversationTitle() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultConversationUsecase.UpdateConversationTitle() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
+
+func Test_defaultConversationUsecase_GetConversations(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name       string
+		filter     dto.ConversationFilter
+		wantFilter mysql.ConversationFilter
+		findResp   []entity.Conversation
+		findErr    error
+		tagsResp   []entity.ConversationTag
+		wantResp   []dto.ConversationResponse
+		wantErr    bool
+	}{
+		{
+			name:       "find error",
+			wantFilter: mysql.ConversationFilter{UserId: 1},
+			findErr:    errors.New("find error"),
+			wantErr:    true,
+		},
+		{
+			name:       "success with tags",
+			filter:     dto.ConversationFilter{Tag: " Riset ", Pinned: true},
+			wantFilter: mysql.ConversationFilter{UserId: 1, Tag: "riset", Pinned: true},
+			findResp:   []entity.Conversation{{ID: 3, Pinned: true}, {ID: 2, Pinned: true}},
+			tagsResp:   []entity.ConversationTag{{ConversationID: 3, Name: "pasar"}, {ConversationID: 3, Name: "riset"}},
+			wantResp: []dto.ConversationResponse{
+				{Id: 3, Pinned: true, Tags: []string{"pasar", "riset"}},
+				{Id: 2, Pinned: true},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := new(mocks.ConversationRepository)
+			conversationRepo.On("Find", mock.Anything, tt.wantFilter).Return(tt.findResp, tt.findErr).Once()
+			conversationRepo.On("GetTags", mock.Anything, []int{3, 2}).Return(tt.tagsResp, nil).Once()
+
+			s := NewConversationUsecase(conversationRepo, new(mocks.ChatRepository), new(mocks.AuditRepository), new(mocks.CacheWrapper))
+			gotResp, err := s.GetConversations(ctx, 1, tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultConversationUsecase.GetConversations() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultConversationUsecase.GetConversations() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
+
+func Test_defaultConversationUsecase_SetConversationTags(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name     string
+		req      dto.ConversationTagsRequest
+		getResp  *entity.Conversation
+		wantTags []string
+		wantErr  bool
+	}{
+		{
+			name:    "tag too long",
+			req:     dto.ConversationTagsRequest{Tags: []string{strings.Repeat("a", MaxTagLength+1)}},
+			getResp: &entity.Conversation{ID: 2, UserID: 1},
+			wantErr: true,
+		},
+		{
+			name:    "too many tags",
+			req:     dto.ConversationTagsRequest{Tags: strings.Split("a b c d e f g h i j k", " ")},
+			getResp: &entity.Conversation{ID: 2, UserID: 1},
+			wantErr: true,
+		},
+		{
+			name:    "conversation of another user",
+			req:     dto.ConversationTagsRequest{Tags: []string{"riset"}},
+			getResp: &entity.Conversation{ID: 2, UserID: 3},
+			wantErr: true,
+		},
+		{
+			name:     "success with tags cleaned and deduplicated",
+			req:      dto.ConversationTagsRequest{Tags: []string{" Riset ", "pasar", "riset", ""}},
+			getResp:  &entity.Conversation{ID: 2, UserID: 1},
+			wantTags: []string{"pasar", "riset"},
+			wantErr:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := new(mocks.ConversationRepository)
+			conversationRepo.On("GetById", mock.Anything, 2).Return(tt.getResp, nil).Once()
+			conversationRepo.On("SetTags", mock.Anything, 1, 2, mock.Anything).Return(nil).Once()
+
+			s := NewConversationUsecase(conversationRepo, new(mocks.ChatRepository), new(mocks.AuditRepository), new(mocks.CacheWrapper))
+			gotResp, err := s.SetConversationTags(ctx, 1, 2, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultConversationUsecase.SetConversationTags() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				conversationRepo.AssertNotCalled(t, "SetTags", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			if !reflect.DeepEqual(gotResp.Tags, tt.wantTags) {
+				t.Errorf("defaultConversationUsecase.SetConversationTags() = %v, want %v", gotResp.Tags, tt.wantTags)
+			}
+		})
+	}
+}
+
+func Test_defaultConversationUsecase_DeleteChat(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name      string
+		getResp   *entity.Chat
+		getErr    error
+		deleteErr error
+		wantErr   bool
+	}{
+		{
+			name:    "chat not found",
+			getErr:  errors.New("record not found"),
+			wantErr: true,
+		},
+		{
+			name:    "chat of another user",
+			getResp: &entity.Chat{ID: 5, UserID: 3, ConversationID: 2},
+			wantErr: true,
+		},
+		{
+			name:      "delete chat error",
+			getResp:   &entity.Chat{ID: 5, UserID: 1, ConversationID: 2},
+			deleteErr: errors.New("delete chat error"),
+			wantErr:   true,
+		},
+		{
+			name:    "success delete chat",
+			getResp: &entity.Chat{ID: 5, UserID: 1, ConversationID: 2, Name: "fadilah"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := new(mocks.ConversationRepository)
+			chatRepo := new(mocks.ChatRepository)
+			auditRepo := new(mocks.AuditRepository)
+			cacheWrapper := new(mocks.CacheWrapper)
+
+			var gotLog entity.AuditLog
+			chatRepo.On("GetById", mock.Anything, 5).Return(tt.getResp, tt.getErr).Once()
+			chatRepo.On("Delete", mock.Anything, 5).Return(tt.deleteErr).Once()
+			auditRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				gotLog = *args.Get(1).(*entity.AuditLog)
+			}).Return(nil).Once()
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+			s := NewConversationUsecase(conversationRepo, chatRepo, auditRepo, cacheWrapper)
+			err := s.DeleteChat(ctx, 1, 5)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultConversationUsecase.DeleteChat() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				auditRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+			if gotLog.ActorID != 1 || gotLog.TargetID != 5 || !strings.Contains(gotLog.Before, `"name":"fadilah"`) {
+				t.Errorf("defaultConversationUsecase.DeleteChat() audit = %+v", gotLog)
+			}
+			cacheWrapper.AssertCalled(t, "Delete", mock.Anything, "ChatBot_1_2")
+			cacheWrapper.AssertCalled(t, "Delete", mock.Anything, "getHistory_1")
+		})
+	}
+}
+
+func Test_defaultConversationUsecase_ResetContext(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name          string
+		getResp       *entity.Conversation
+		getChatResp   []entity.Chat
+		wantStart     int
+		wantSetCalled bool
+		wantErr       bool
+	}{
+		{
+			name:    "conversation of another user",
+			getResp: &entity.Conversation{ID: 2, UserID: 3},
+			wantErr: true,
+		},
+		{
+			name:    "conversation without messages",
+			getResp: &entity.Conversation{ID: 2, UserID: 1},
+			wantErr: false,
+		},
+		{
+			name:          "success reset context",
+			getResp:       &entity.Conversation{ID: 2, UserID: 1},
+			getChatResp:   []entity.Chat{{ID: 7}, {ID: 8}},
+			wantStart:     8,
+			wantSetCalled: true,
+			wantErr:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := new(mocks.ConversationRepository)
+			chatRepo := new(mocks.ChatRepository)
+			auditRepo := new(mocks.AuditRepository)
+			cacheWrapper := new(mocks.CacheWrapper)
+
+			conversationRepo.On("GetById", mock.Anything, 2).Return(tt.getResp, nil).Once()
+			chatRepo.On("GetByConversationId", mock.Anything, 2).Return(tt.getChatResp, nil).Once()
+			conversationRepo.On("SetContextStart", mock.Anything, 2, tt.wantStart).Return(nil).Once()
+			auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+			s := NewConversationUsecase(conversationRepo, chatRepo, auditRepo, cacheWrapper)
+			_, err := s.ResetContext(ctx, 1, 2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultConversationUsecase.ResetContext() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantSetCalled {
+				conversationRepo.AssertCalled(t, "SetContextStart", mock.Anything, 2, tt.wantStart)
+			} else {
+				conversationRepo.AssertNotCalled(t, "SetContextStart", mock.Anything, mock.Anything, mock.Anything)
+			}
+			cacheWrapper.AssertCalled(t, "Delete", mock.Anything, "ChatBot_1_2")
+			cacheWrapper.AssertCalled(t, "Delete", mock.Anything, "getHistory_1")
+		})
+	}
+}
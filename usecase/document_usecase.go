@@ -0,0 +1,287 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/http/embedding"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/repository/vectorstore"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/document"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/spf13/cast"
+)
+
+const (
+	// MaxDocumentSize is the largest file accepted for upload, in bytes
+	MaxDocumentSize = 10 << 20
+
+	// ChunkSize and ChunkOverlap are the number of characters in a chunk and
+	// shared between consecutive chunks
+	ChunkSize    = 1000
+	ChunkOverlap = 200
+
+	// DefaultRagTopK is the number of chunks given to the model when
+	// RAG_TOP_K is not set
+	DefaultRagTopK = 4
+)
+
+type DocumentUsecase interface {
+	UploadDocument(ctx context.Context, userId int, req dto.DocumentUploadRequest) (resp dto.DocumentResponse, err error)
+	GetDocuments(ctx context.Context, userId int) (resp []dto.DocumentResponse, err error)
+	DeleteDocument(ctx context.Context, userId, id int) (err error)
+}
+
+type defaultDocumentUsecase struct {
+	documentRepo      mysql.DocumentRepository
+	embeddingProvider embedding.EmbeddingProvider
+	vectorStore       vectorstore.VectorStore
+}
+
+// NewDocumentUsecase creates a new instance of DocumentUsecase
+func NewDocumentUsecase(documentRepo mysql.DocumentRepository, embeddingProvider embedding.EmbeddingProvider, vectorStore vectorstore.VectorStore) DocumentUsecase {
+	return &defaultDocumentUsecase{
+		documentRepo:      documentRepo,
+		embeddingProvider: embeddingProvider,
+		vectorStore:       vectorStore,
+	}
+}
+
+// UploadDocument extracts the text of the file, splits it into chunks and
+// stores their embeddings so the chat can answer from the document
+func (s *defaultDocumentUsecase) UploadDocument(ctx context.Context, userId int, req dto.DocumentUploadRequest) (resp dto.DocumentResponse, err error) {
+	contents, err := extractChunks(ctx, req)
+	if err != nil {
+		return
+	}
+
+	chunks, _, err := indexChunks(ctx, s.embeddingProvider, contents, nil)
+	if err != nil {
+		return
+	}
+
+	doc := entity.Document{
+		UserID:      userId,
+		Name:        req.Name,
+		ContentType: req.ContentType,
+		Size:        len(req.Data),
+		ChunkCount:  len(chunks),
+		Version:     1,
+		ContentHash: hashContent(string(req.Data)),
+	}
+	err = s.documentRepo.Create(ctx, &doc)
+	if err != nil {
+		logger.Error(ctx, "error creating document", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(chunks); i++ {
+		chunks[i].DocumentID = doc.ID
+		chunks[i].UserID = userId
+	}
+
+	err = s.vectorStore.Upsert(ctx, chunks)
+	if err != nil {
+		logger.Error(ctx, "error storing document chunks", err.Error())
+		s.documentRepo.Delete(ctx, doc.ID)
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toDocumentResponse(doc)
+	return
+}
+
+// GetDocuments returns the documents the user uploaded
+func (s *defaultDocumentUsecase) GetDocuments(ctx context.Context, userId int) (resp []dto.DocumentResponse, err error) {
+	documents, err := s.documentRepo.GetByUserId(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting documents", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(documents); i++ {
+		resp = append(resp, toDocumentResponse(documents[i]))
+	}
+	return
+}
+
+// DeleteDocument deletes a document of the user together with its chunks
+func (s *defaultDocumentUsecase) DeleteDocument(ctx context.Context, userId, id int) (err error) {
+	doc, err := s.documentRepo.GetById(ctx, id)
+	if err != nil || doc.UserID != userId {
+		logger.Error(ctx, "document not found")
+		err = errors.SetError(http.StatusNotFound, "document not found")
+		return
+	}
+
+	err = s.vectorStore.DeleteByDocumentId(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "error deleting document chunks", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	err = s.documentRepo.Delete(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "error deleting document", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	return
+}
+
+// extractChunks validates the uploaded file and splits its text into chunks
+func extractChunks(ctx context.Context, req dto.DocumentUploadRequest) (contents []string, err error) {
+	if len(req.Data) == 0 {
+		logger.Error(ctx, "document is empty")
+		err = errors.SetError(http.StatusBadRequest, "document is empty")
+		return
+	}
+
+	if len(req.Data) > MaxDocumentSize {
+		logger.Error(ctx, "document too large")
+		err = errors.SetError(http.StatusRequestEntityTooLarge, "document is too large")
+		return
+	}
+
+	text, err := document.ExtractText(req.Name, req.ContentType, req.Data)
+	if err != nil {
+		logger.Error(ctx, "error extracting document text", err.Error())
+		err = errors.SetError(http.StatusBadRequest, "document must be a txt, markdown, html or pdf file")
+		return
+	}
+
+	contents = document.Chunk(text, ChunkSize, ChunkOverlap)
+	if len(contents) == 0 {
+		logger.Error(ctx, "document has no text")
+		err = errors.SetError(http.StatusBadRequest, "document has no text")
+		return
+	}
+	return
+}
+
+// indexChunks builds the chunks of a document from the contents. Existing
+// chunks with the same content and embedding model are reused, so only new or
+// changed chunks are sent to the embedding provider. The ids of the existing
+// chunks that are no longer used are returned as stale.
+func indexChunks(ctx context.Context, embeddingProvider embedding.EmbeddingProvider, contents []string, existing []entity.DocumentChunk) (chunks []entity.DocumentChunk, stale []int, err error) {
+	model := embeddingProvider.Model()
+	reusable := map[string][]entity.DocumentChunk{}
+	for i := 0; i < len(existing); i++ {
+		if existing[i].EmbeddingModel != model {
+			stale = append(stale, existing[i].ID)
+			continue
+		}
+		reusable[existing[i].ContentHash] = append(reusable[existing[i].ContentHash], existing[i])
+	}
+
+	var missing []int
+	var inputs []string
+	for i := 0; i < len(contents); i++ {
+		hash := hashContent(contents[i])
+		chunk := entity.DocumentChunk{
+			Content:        contents[i],
+			ContentHash:    hash,
+			EmbeddingModel: model,
+		}
+		if matches := reusable[hash]; len(matches) > 0 {
+			chunk = matches[0]
+			reusable[hash] = matches[1:]
+		} else {
+			missing = append(missing, i)
+			inputs = append(inputs, contents[i])
+		}
+		chunk.ChunkIndex = i
+		chunks = append(chunks, chunk)
+	}
+
+	for _, matches := range reusable {
+		for i := 0; i < len(matches); i++ {
+			stale = append(stale, matches[i].ID)
+		}
+	}
+
+	if len(inputs) == 0 {
+		return
+	}
+
+	embeddings, err := embeddingProvider.Embed(ctx, inputs)
+	if err != nil || len(embeddings) != len(inputs) {
+		logger.Error(ctx, "error embedding document", fmt.Sprint(err))
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(missing); i++ {
+		chunks[missing[i]].Embedding = embeddings[i]
+	}
+	return
+}
+
+// hashContent returns the sha256 of the content in hex
+func hashContent(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// ragTopK returns the number of chunks given to the model
+func ragTopK() int {
+	topK := cast.ToInt(os.Getenv("RAG_TOP_K"))
+	if topK <= 0 {
+		topK = DefaultRagTopK
+	}
+	return topK
+}
+
+// buildSourcesPrompt numbers the retrieved chunks so the model can cite them
+func buildSourcesPrompt(results []vectorstore.SearchResult) string {
+	var builder strings.Builder
+	builder.WriteString("Answer using the sources below when they are relevant and cite them by their number, for example [1]. If the sources do not contain the answer, say so.\n")
+	for i := 0; i < len(results); i++ {
+		builder.WriteString(fmt.Sprintf("\n[%v] %v\n%v\n", i+1, results[i].Chunk.Document.Name, results[i].Chunk.Content))
+	}
+	return builder.String()
+}
+
+// toSourceResponses converts the retrieved chunks to their dto
+func toSourceResponses(results []vectorstore.SearchResult) (resp []dto.SourceResponse) {
+	for i := 0; i < len(results); i++ {
+		excerpt := []rune(results[i].Chunk.Content)
+		if len(excerpt) > 200 {
+			excerpt = append(excerpt[:200], []rune("...")...)
+		}
+		resp = append(resp, dto.SourceResponse{
+			Index:        i + 1,
+			DocumentId:   results[i].Chunk.DocumentID,
+			CollectionId: results[i].Chunk.CollectionID,
+			DocumentName: results[i].Chunk.Document.Name,
+			ChunkIndex:   results[i].Chunk.ChunkIndex,
+			Score:        results[i].Score,
+			Excerpt:      string(excerpt),
+		})
+	}
+	return
+}
+
+// toDocumentResponse converts a document to its dto
+func toDocumentResponse(doc entity.Document) dto.DocumentResponse {
+	return dto.DocumentResponse{
+		Id:          doc.ID,
+		Name:        doc.Name,
+		ContentType: doc.ContentType,
+		Size:        doc.Size,
+		ChunkCount:  doc.ChunkCount,
+		CreatedAt:   doc.CreatedAt,
+	}
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultDocumentUsecase_UploadDocument(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	type args struct {
+		ctx    context.Context
+		userId int
+		req    dto.DocumentUploadRequest
+	}
+	tests := []struct {
+		name       string
+		args       args
+		embedResp  [][]float32
+		embedErr   error
+		createErr  error
+		upsertErr  error
+		wantChunks int
+		wantErr    bool
+	}{
+		{
+			name: "document is empty",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.DocumentUploadRequest{Name: "kosong.txt"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported document type",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.DocumentUploadRequest{Name: "gambar.png", ContentType: "image/png", Data: []byte{0x89, 0x50}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "embedding error",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.DocumentUploadRequest{Name: "handbook.md", Data: []byte("# Cuti\nCuti tahunan adalah 12 hari.")},
+			},
+			embedErr: errors.New("embedding error"),
+			wantErr:  true,
+		},
+		{
+			name: "store chunks error",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.DocumentUploadRequest{Name: "handbook.md", Data: []byte("# Cuti\nCuti tahunan adalah 12 hari.")},
+			},
+			embedResp: [][]float32{{1, 0}},
+			upsertErr: errors.New("store error"),
+			wantErr:   true,
+		},
+		{
+			name: "success upload html",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req:    dto.DocumentUploadRequest{Name: "handbook.html", Data: []byte("<html><body><p>Cuti tahunan adalah 12 hari.</p></body></html>")},
+			},
+			embedResp:  [][]float32{{1, 0}},
+			wantChunks: 1,
+			wantErr:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documentRepo := new(mocks.DocumentRepository)
+			embeddingProvider := new(mocks.EmbeddingProvider)
+			vectorStore := new(mocks.VectorStore)
+
+			embeddingProvider.On("Embed", mock.Anything, mock.Anything).Return(tt.embedResp, tt.embedErr).Once()
+			embeddingProvider.On("Model").Return("text-embedding-ada-002")
+			documentRepo.On("Create", mock.Anything, mock.Anything).Return(tt.createErr).Once()
+			documentRepo.On("Delete", mock.Anything, mock.Anything).Return(nil).Once()
+			vectorStore.On("Upsert", mock.Anything, mock.MatchedBy(func(chunks []entity.DocumentChunk) bool {
+				return len(chunks) == len(tt.embedResp) && chunks[0].UserID == tt.args.userId
+			})).Return(tt.upsertErr).Once()
+
+			s := NewDocumentUsecase(documentRepo, embeddingProvider, vectorStore)
+			gotResp, err := s.UploadDocument(tt.args.ctx, tt.args.userId, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultDocumentUsecase.UploadDocument() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResp.ChunkCount != tt.wantChunks {
+				t.Errorf("defaultDocumentUsecase.UploadDocument() chunks = %v, want %v", gotResp.ChunkCount, tt.wantChunks)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditFilter narrows the audit log. From and To are dates in the YYYY-MM-DD
+// form, To is inclusive. Zero fields are not filtered on.
+type AuditFilter struct {
+	ActorId    int
+	Action     string
+	TargetType string
+	TargetId   int
+	RequestId  string
+	From       string
+	To         string
+	Page       int
+	Limit      int
+}
+
+type AuditLogResponse struct {
+	Id         int             `json:"id"`
+	ActorId    int             `json:"actorId"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"targetType"`
+	TargetId   int             `json:"targetId"`
+	Ip         string          `json:"ip"`
+	UserAgent  string          `json:"userAgent"`
+	RequestId  string          `json:"requestId"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Hash       string          `json:"hash,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type AuditLogListResponse struct {
+	Logs  []AuditLogResponse `json:"logs"`
+	Total int64              `json:"total"`
+}
+
+// AuditVerifyResponse tells whether the hash chain of the audit log is whole.
+// BrokenId is the first record that does not match the chain.
+type AuditVerifyResponse struct {
+	Valid    bool `json:"valid"`
+	Checked  int  `json:"checked"`
+	Unhashed int  `json:"unhashed"`
+	BrokenId int  `json:"brokenId,omitempty"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+import "time"
+
+// ChannelLinkResponse is a one-time code that links the user of a messaging
+// channel to the user when they send it to the bot. Links open the bots
+// with the code filled in, on the channels that support it.
+type ChannelLinkResponse struct {
+	Code      string            `json:"code"`
+	Links     map[string]string `json:"links,omitempty"`
+	ExpiresAt time.Time         `json:"expiresAt"`
+}
+
+type ChannelIdentityResponse struct {
+	Id             int       `json:"id"`
+	Channel        string    `json:"channel"`
+	ExternalId     string    `json:"externalId"`
+	Username       string    `json:"username"`
+	ConversationId int       `json:"conversationId"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
@@ -1,7 +1,42 @@
 package dto
 
+// ChatHistoryFilter narrows the history to a conversation, to the
+// conversations with a tag, or to the starred messages
+type ChatHistoryFilter struct {
+	ConversationId int
+	Tag            string
+	Starred        bool
+}
+
+type ChatStarRequest struct {
+	Starred bool `json:"starred"`
+}
+
 type ChatHistoryResponse struct {
-	Id      int    `json:"id"`
-	Name    string `json:"name"`
-	Message string `json:"message"`
+	Id             int                `json:"id"`
+	ConversationId int                `json:"conversationId"`
+	Name           string             `json:"name"`
+	Message        string             `json:"message"`
+	Starred        bool               `json:"starred"`
+	ToolCalls      []ToolCallResponse `json:"toolCalls,omitempty"`
+	Images         []ImageResponse    `json:"images,omitempty"`
+	Audio          []AudioResponse    `json:"audio,omitempty"`
+}
+
+type ImageResponse struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"contentType"`
+	Url         string `json:"url"`
+}
+
+type AudioResponse struct {
+	Id          int     `json:"id"`
+	Kind        string  `json:"kind"`
+	Name        string  `json:"name"`
+	ContentType string  `json:"contentType"`
+	Language    string  `json:"language,omitempty"`
+	Duration    float64 `json:"duration,omitempty"`
+	Transcript  string  `json:"transcript"`
+	Url         string  `json:"url"`
 }
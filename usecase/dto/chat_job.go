@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// ChatJobResponse is a question answered in the background. Result is set
+// once the job completed, Error and ErrorCode once it failed.
+type ChatJobResponse struct {
+	Id             string                `json:"id"`
+	Status         string                `json:"status"`
+	Result         *ChatQuestionResponse `json:"result,omitempty"`
+	Error          string                `json:"error,omitempty"`
+	ErrorCode      int                   `json:"errorCode,omitempty"`
+	CallbackUrl    string                `json:"callbackUrl,omitempty"`
+	CallbackStatus int                   `json:"callbackStatus,omitempty"`
+	CallbackError  string                `json:"callbackError,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	UpdatedAt      time.Time             `json:"updatedAt"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+type ChatModelRequest struct {
+	Name               string  `json:"name"`
+	MaxTokens          int     `json:"maxTokens"`
+	MaxTemperature     float32 `json:"maxTemperature"`
+	SupportsJsonFormat bool    `json:"supportsJsonFormat"`
+	SupportsVision     bool    `json:"supportsVision"`
+	Enabled            bool    `json:"enabled"`
+}
+
+type ChatModelResponse struct {
+	Id                 int     `json:"id"`
+	Name               string  `json:"name"`
+	MaxTokens          int     `json:"maxTokens"`
+	MaxTemperature     float32 `json:"maxTemperature"`
+	SupportsJsonFormat bool    `json:"supportsJsonFormat"`
+	SupportsVision     bool    `json:"supportsVision"`
+	Enabled            bool    `json:"enabled"`
+}
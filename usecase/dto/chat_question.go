@@ -1,9 +1,82 @@
 package dto
 
+const (
+	ResponseFormatText = "text"
+	ResponseFormatJson = "json"
+)
+
 type ChatQuestionRequest struct {
-	Question string `json:"question"`
+	ConversationId int               `json:"conversationId"`
+	PersonaId      int               `json:"personaId"`
+	Question       string            `json:"question"`
+	TemplateId     int               `json:"templateId"`
+	Variables      map[string]string `json:"variables"`
+	Model          string            `json:"model"`
+	Temperature    *float32          `json:"temperature"`
+	TopP           *float32          `json:"topP"`
+	MaxTokens      int               `json:"maxTokens"`
+	Stop           []string          `json:"stop"`
+	ResponseFormat string            `json:"responseFormat"`
+	UseDocuments   bool              `json:"useDocuments"`
+	CollectionIds  []int             `json:"collectionIds"`
+	Images         []ImageInput      `json:"images"`
+	CallbackUrl    string            `json:"callbackUrl"`
+}
+
+// ImageInput is an image attached to a question, either base64 encoded in
+// Data (optionally as a data URL) or uploaded in a multipart request.
+type ImageInput struct {
+	Name    string `json:"name"`
+	Data    string `json:"data"`
+	Content []byte `json:"-"`
+}
+
+// VoiceQuestionRequest is a question asked with an audio recording. The
+// transcript of the recording is the question, and Speak asks for the answer
+// to be spoken back.
+type VoiceQuestionRequest struct {
+	ChatQuestionRequest
+	Speak bool       `json:"speak"`
+	Audio AudioInput `json:"-"`
+}
+
+// AudioInput is an audio recording uploaded in a multipart request
+type AudioInput struct {
+	Name    string
+	Content []byte
 }
 
 type ChatQuestionResponse struct {
-	Answer string `json:"answer"`
+	ConversationId int                 `json:"conversationId"`
+	Answer         string              `json:"answer"`
+	Model          string              `json:"model"`
+	FinishReason   string              `json:"finishReason"`
+	ToolCalls      []ToolCallResponse  `json:"toolCalls,omitempty"`
+	Sources        []SourceResponse    `json:"sources,omitempty"`
+	CollectionIds  []int               `json:"collectionIds,omitempty"`
+	Warnings       []ModerationWarning `json:"warnings,omitempty"`
+	Cached         bool                `json:"cached,omitempty"`
+}
+
+type VoiceQuestionResponse struct {
+	ChatQuestionResponse
+	Transcript string         `json:"transcript"`
+	Audio      AudioResponse  `json:"audio"`
+	Speech     *AudioResponse `json:"speech,omitempty"`
+}
+
+type ToolCallResponse struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result"`
+}
+
+type SourceResponse struct {
+	Index        int     `json:"index"`
+	DocumentId   int     `json:"documentId"`
+	CollectionId int     `json:"collectionId,omitempty"`
+	DocumentName string  `json:"documentName"`
+	ChunkIndex   int     `json:"chunkIndex"`
+	Score        float32 `json:"score"`
+	Excerpt      string  `json:"excerpt"`
 }
@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+type CollectionRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	TeamId      int    `json:"teamId"`
+}
+
+type CollectionResponse struct {
+	Id             int    `json:"id"`
+	UserId         int    `json:"userId"`
+	TeamId         int    `json:"teamId,omitempty"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	EmbeddingModel string `json:"embeddingModel"`
+}
+
+type CollectionDocumentResponse struct {
+	Id          int       `json:"id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"contentType"`
+	Size        int       `json:"size"`
+	ChunkCount  int       `json:"chunkCount"`
+	Version     int       `json:"version"`
+	Embedded    int       `json:"embedded"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type EmbeddingJobResponse struct {
+	Id             int    `json:"id"`
+	CollectionId   int    `json:"collectionId"`
+	EmbeddingModel string `json:"embeddingModel"`
+	Status         string `json:"status"`
+	Total          int    `json:"total"`
+	Processed      int    `json:"processed"`
+	Error          string `json:"error,omitempty"`
+}
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+type ConversationTitleRequest struct {
+	Title string `json:"title"`
+}
+
+type ConversationTagsRequest struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type ConversationPinRequest struct {
+	Pinned bool `json:"pinned"`
+}
+
+// ConversationFilter narrows the listing of conversations to the ones with
+// the tag, or to the pinned ones
+type ConversationFilter struct {
+	Tag    string
+	Pinned bool
+}
+
+type ConversationResponse struct {
+	Id        int       `json:"id"`
+	PersonaId int       `json:"personaId"`
+	Title     string    `json:"title"`
+	Pinned    bool      `json:"pinned"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+type DocumentUploadRequest struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+type DocumentResponse struct {
+	Id          int       `json:"id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"contentType"`
+	Size        int       `json:"size"`
+	ChunkCount  int       `json:"chunkCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+type EncryptionJobResponse struct {
+	Id        int    `json:"id"`
+	KeyId     string `json:"keyId"`
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Error     string `json:"error,omitempty"`
+}
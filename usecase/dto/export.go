@@ -0,0 +1,16 @@
+package dto
+
+// ExportRequest selects the messages to export: a conversation, a period of
+// dates in the YYYY-MM-DD form, or both. To is inclusive.
+type ExportRequest struct {
+	ConversationId int    `json:"conversationId"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Format         string `json:"format"`
+}
+
+type ExportResponse struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
@@ -0,0 +1,21 @@
+package dto
+
+// ImportRequest is an export file of another chat service. Without a format
+// the format is detected from the content.
+type ImportRequest struct {
+	Format   string
+	FileName string
+	Data     []byte
+}
+
+type ImportJobResponse struct {
+	Id        int    `json:"id"`
+	Format    string `json:"format"`
+	FileName  string `json:"fileName"`
+	Status    string `json:"status"`
+	Total     int    `json:"total"`
+	Processed int    `json:"processed"`
+	Imported  int    `json:"imported"`
+	Skipped   int    `json:"skipped"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import "time"
+
+// ModerationWarning tells the user that a question or answer was let through
+// with a warning
+type ModerationWarning struct {
+	Direction  string   `json:"direction"`
+	Categories []string `json:"categories"`
+}
+
+// ModerationErrorResponse is the data of the error returned for blocked content
+type ModerationErrorResponse struct {
+	Code       string   `json:"code"`
+	Direction  string   `json:"direction"`
+	Categories []string `json:"categories"`
+}
+
+// ModerationFilter narrows the review queue. Status defaults to pending.
+type ModerationFilter struct {
+	Status    string
+	Direction string
+	UserId    int
+	Page      int
+	Limit     int
+}
+
+// ModerationReviewRequest records the decision of an admin on a flag, either
+// confirmed or dismissed as a false positive
+type ModerationReviewRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+type ModerationFlagResponse struct {
+	Id             int        `json:"id"`
+	UserId         int        `json:"userId"`
+	ConversationId int        `json:"conversationId"`
+	ChatId         int        `json:"chatId"`
+	Direction      string     `json:"direction"`
+	Content        string     `json:"content"`
+	Providers      []string   `json:"providers"`
+	Categories     []string   `json:"categories"`
+	Action         string     `json:"action"`
+	Status         string     `json:"status"`
+	ReviewerId     int        `json:"reviewerId,omitempty"`
+	ReviewNote     string     `json:"reviewNote,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+type ModerationFlagListResponse struct {
+	Flags []ModerationFlagResponse `json:"flags"`
+	Total int64                    `json:"total"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+type PersonaRequest struct {
+	Name           string  `json:"name"`
+	Description    string  `json:"description"`
+	SystemPrompt   string  `json:"systemPrompt"`
+	Model          string  `json:"model"`
+	Temperature    float32 `json:"temperature"`
+	MaxTokens      int     `json:"maxTokens"`
+	AllowedUserIds []int   `json:"allowedUserIds"`
+}
+
+type PersonaResponse struct {
+	Id             int     `json:"id"`
+	Name           string  `json:"name"`
+	Description    string  `json:"description"`
+	SystemPrompt   string  `json:"systemPrompt,omitempty"`
+	PromptVersion  int     `json:"promptVersion"`
+	Model          string  `json:"model"`
+	Temperature    float32 `json:"temperature"`
+	MaxTokens      int     `json:"maxTokens"`
+	AllowedUserIds []int   `json:"allowedUserIds,omitempty"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+type PromptTemplateVariable struct {
+	Name         string `json:"name"`
+	Required     bool   `json:"required"`
+	DefaultValue string `json:"defaultValue"`
+}
+
+type PromptTemplateRequest struct {
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Content     string                   `json:"content"`
+	Visibility  string                   `json:"visibility"`
+	Variables   []PromptTemplateVariable `json:"variables"`
+}
+
+type PromptTemplateResponse struct {
+	Id          int                      `json:"id"`
+	UserId      int                      `json:"userId"`
+	Name        string                   `json:"name"`
+	Description string                   `json:"description"`
+	Content     string                   `json:"content"`
+	Visibility  string                   `json:"visibility"`
+	Variables   []PromptTemplateVariable `json:"variables"`
+}
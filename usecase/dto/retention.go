@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// RetentionPolicyRequest sets a policy for the role or the user given, or the
+// global policy when neither is given
+type RetentionPolicyRequest struct {
+	Role   string `json:"role"`
+	UserId int    `json:"userId"`
+	Days   int    `json:"days"`
+	Action string `json:"action"`
+}
+
+type RetentionPolicyResponse struct {
+	Id     int    `json:"id"`
+	Scope  string `json:"scope"`
+	Role   string `json:"role,omitempty"`
+	UserId int    `json:"userId,omitempty"`
+	Days   int    `json:"days"`
+	Action string `json:"action"`
+}
+
+type PurgeReportResponse struct {
+	Id              int       `json:"id"`
+	DryRun          bool      `json:"dryRun"`
+	Status          string    `json:"status"`
+	Users           int       `json:"users"`
+	ChatsDeleted    int       `json:"chatsDeleted"`
+	ChatsAnonymized int       `json:"chatsAnonymized"`
+	UsersDeleted    int       `json:"usersDeleted"`
+	UsersAnonymized int       `json:"usersAnonymized"`
+	Error           string    `json:"error,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+// ShareRequest publishes a conversation. The link stops working at ExpiresAt
+// when it is set, and asks for the password when one is set.
+type ShareRequest struct {
+	ConversationId int        `json:"conversationId"`
+	Password       string     `json:"password"`
+	ExpiresAt      *time.Time `json:"expiresAt"`
+}
+
+type ShareResponse struct {
+	Id             int        `json:"id"`
+	ConversationId int        `json:"conversationId"`
+	Token          string     `json:"token"`
+	Url            string     `json:"url"`
+	Protected      bool       `json:"protected"`
+	ExpiresAt      *time.Time `json:"expiresAt,omitempty"`
+	Revoked        bool       `json:"revoked"`
+	Views          int        `json:"views"`
+	LastViewedAt   *time.Time `json:"lastViewedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// SharedConversationResponse is the public view of a shared conversation
+type SharedConversationResponse struct {
+	Title    string                  `json:"title"`
+	Owner    string                  `json:"owner"`
+	SharedAt time.Time               `json:"sharedAt"`
+	Messages []SharedMessageResponse `json:"messages"`
+}
+
+type SharedMessageResponse struct {
+	Role      string    `json:"role"`
+	Name      string    `json:"name"`
+	Model     string    `json:"model,omitempty"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+type TeamRequest struct {
+	Name      string `json:"name"`
+	MemberIds []int  `json:"memberIds"`
+}
+
+type TeamResponse struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	MemberIds []int  `json:"memberIds"`
+}
@@ -0,0 +1,108 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscriptionRequest subscribes a url to events. Only admins can
+// subscribe to the events of all users. Active is only read on updates.
+type WebhookSubscriptionRequest struct {
+	Url      string   `json:"url"`
+	Events   []string `json:"events"`
+	AllUsers bool     `json:"allUsers"`
+	Active   *bool    `json:"active"`
+}
+
+// WebhookSubscriptionResponse is a subscription. The secret is only returned
+// when the subscription is created.
+type WebhookSubscriptionResponse struct {
+	Id        int       `json:"id"`
+	Url       string    `json:"url"`
+	Events    []string  `json:"events"`
+	AllUsers  bool      `json:"allUsers"`
+	Active    bool      `json:"active"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WebhookDeliveryFilter struct {
+	SubscriptionId int
+	Page           int
+	Limit          int
+}
+
+type WebhookDeliveryResponse struct {
+	Id             int                      `json:"id"`
+	SubscriptionId int                      `json:"subscriptionId"`
+	EventId        string                   `json:"eventId"`
+	Event          string                   `json:"event"`
+	Status         string                   `json:"status"`
+	Attempts       int                      `json:"attempts"`
+	NextAttemptAt  *time.Time               `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    *time.Time               `json:"deliveredAt,omitempty"`
+	Payload        json.RawMessage          `json:"payload,omitempty"`
+	AttemptLog     []WebhookAttemptResponse `json:"attemptLog,omitempty"`
+	CreatedAt      time.Time                `json:"createdAt"`
+}
+
+type WebhookAttemptResponse struct {
+	StatusCode int       `json:"statusCode"`
+	Error      string    `json:"error,omitempty"`
+	Duration   int64     `json:"duration"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Total      int64                     `json:"total"`
+}
+
+// WebhookEvent is the body posted to a webhook. Id is the same on every
+// attempt, so receivers can drop the events they already got.
+type WebhookEvent struct {
+	Id        string      `json:"id"`
+	Event     string      `json:"event"`
+	UserId    int         `json:"userId"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// MessageEvent is the data of a message.created event
+type MessageEvent struct {
+	ChatId         int       `json:"chatId"`
+	ConversationId int       `json:"conversationId"`
+	Role           string    `json:"role"`
+	Name           string    `json:"name"`
+	Message        string    `json:"message"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// AnswerEvent is the data of an answer.completed event
+type AnswerEvent struct {
+	ConversationId int    `json:"conversationId"`
+	QuestionId     int    `json:"questionId"`
+	AnswerId       int    `json:"answerId"`
+	Question       string `json:"question"`
+	Answer         string `json:"answer"`
+	Model          string `json:"model"`
+	FinishReason   string `json:"finishReason"`
+	Cached         bool   `json:"cached,omitempty"`
+}
+
+// UserEvent is the data of a user.registered event
+type UserEvent struct {
+	Id        int       `json:"id"`
+	Email     string    `json:"email"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// QuotaEvent is the data of a quota.exceeded event
+type QuotaEvent struct {
+	ConversationId int    `json:"conversationId"`
+	Model          string `json:"model"`
+	Reason         string `json:"reason"`
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/envelope"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+)
+
+// ReencryptBatchSize is the number of messages encrypted again at a time
+const ReencryptBatchSize = 100
+
+type EncryptionUsecase interface {
+	StartReencryption(ctx context.Context, adminId int, rotate bool) (resp dto.EncryptionJobResponse, err error)
+	ReencryptOutdatedChats(ctx context.Context) (err error)
+	GetEncryptionJob(ctx context.Context, id int) (resp dto.EncryptionJobResponse, err error)
+}
+
+type defaultEncryptionUsecase struct {
+	chatRepo       mysql.ChatRepository
+	encryptionRepo mysql.EncryptionRepository
+	auditRepo      mysql.AuditRepository
+	encryptor      *envelope.Encryptor
+}
+
+// NewEncryptionUsecase creates a new instance of EncryptionUsecase
+func NewEncryptionUsecase(chatRepo mysql.ChatRepository, encryptionRepo mysql.EncryptionRepository, auditRepo mysql.AuditRepository, encryptor *envelope.Encryptor) EncryptionUsecase {
+	return &defaultEncryptionUsecase{
+		chatRepo:       chatRepo,
+		encryptionRepo: encryptionRepo,
+		auditRepo:      auditRepo,
+		encryptor:      encryptor,
+	}
+}
+
+// StartReencryption encrypts the messages that are not encrypted under the
+// current master key again in a background job. With rotate a new master key
+// is made current first.
+func (s *defaultEncryptionUsecase) StartReencryption(ctx context.Context, adminId int, rotate bool) (resp dto.EncryptionJobResponse, err error) {
+	if !s.encryptor.Enabled() {
+		logger.Error(ctx, "encryption is turned off")
+		err = errors.SetError(http.StatusBadRequest, "encryption is turned off")
+		return
+	}
+
+	if rotate {
+		before := map[string]string{"keyId": s.encryptor.CurrentKeyID()}
+		keyId, errRes := s.encryptor.Rotate(ctx)
+		if stdErrors.Is(errRes, envelope.ErrRotationNotSupported) {
+			logger.Error(ctx, "master key cannot be rotated", errRes.Error())
+			err = errors.SetError(http.StatusBadRequest, errRes.Error())
+			return
+		}
+		if errRes != nil {
+			logger.Error(ctx, "error rotating master key", errRes.Error())
+			err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+		logger.Info(ctx, "master key rotated", keyId)
+		recordAudit(ctx, s.auditRepo, adminId, constrans.AuditEncryptionKeyRotated, "master_key", 0, before, map[string]string{"keyId": keyId})
+	}
+
+	job, err := s.startEncryptionJob(ctx)
+	if err != nil {
+		logger.Error(ctx, "error creating encryption job", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	recordAudit(ctx, s.auditRepo, adminId, constrans.AuditEncryptionStarted, "encryption_job", job.ID, nil, toEncryptionJobResponse(*job))
+
+	resp = toEncryptionJobResponse(*job)
+	return
+}
+
+// ReencryptOutdatedChats starts an encryption job when there are messages not
+// encrypted under the current master key, such as after the key was rotated
+// in config
+func (s *defaultEncryptionUsecase) ReencryptOutdatedChats(ctx context.Context) (err error) {
+	if !s.encryptor.Enabled() {
+		return
+	}
+
+	total, err := s.chatRepo.CountOutdatedKey(ctx)
+	if err != nil {
+		logger.Error(ctx, "error counting outdated messages", err.Error())
+		return
+	}
+	if total == 0 {
+		return
+	}
+
+	_, err = s.startEncryptionJob(ctx)
+	if err != nil {
+		logger.Error(ctx, "error creating encryption job", err.Error())
+	}
+	return
+}
+
+// GetEncryptionJob returns the progress of an encryption job
+func (s *defaultEncryptionUsecase) GetEncryptionJob(ctx context.Context, id int) (resp dto.EncryptionJobResponse, err error) {
+	job, err := s.encryptionRepo.GetJobById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "encryption job not found")
+		err = errors.SetError(http.StatusNotFound, "encryption job not found")
+		return
+	}
+
+	resp = toEncryptionJobResponse(*job)
+	return
+}
+
+// startEncryptionJob records a pending job and runs it in the background
+func (s *defaultEncryptionUsecase) startEncryptionJob(ctx context.Context) (job *entity.EncryptionJob, err error) {
+	total, err := s.chatRepo.CountOutdatedKey(ctx)
+	if err != nil {
+		return
+	}
+
+	job = &entity.EncryptionJob{
+		KeyID:  s.encryptor.CurrentKeyID(),
+		Status: constrans.JobStatusPending,
+		Total:  int(total),
+	}
+	err = s.encryptionRepo.CreateJob(ctx, job)
+	if err != nil {
+		return
+	}
+
+	go s.runEncryptionJob(context.WithoutCancel(ctx), *job)
+	return
+}
+
+// runEncryptionJob encrypts the outdated messages again in batches, recording
+// the progress on the job. Messages are readable during the job, as the old
+// master keys are kept.
+func (s *defaultEncryptionUsecase) runEncryptionJob(ctx context.Context, job entity.EncryptionJob) {
+	fail := func(err error) {
+		logger.Error(ctx, "encryption job failed", job.ID, err.Error())
+		job.Status = constrans.JobStatusFailed
+		job.Error = err.Error()
+		s.encryptionRepo.UpdateJob(ctx, &job)
+	}
+
+	job.Status = constrans.JobStatusRunning
+	s.encryptionRepo.UpdateJob(ctx, &job)
+
+	afterId := 0
+	for {
+		chats, err := s.chatRepo.FindOutdatedKey(ctx, afterId, ReencryptBatchSize)
+		if err != nil {
+			fail(err)
+			return
+		}
+		if len(chats) == 0 {
+			break
+		}
+
+		for i := 0; i < len(chats); i++ {
+			err = s.chatRepo.Reencrypt(ctx, &chats[i])
+			if err != nil {
+				fail(err)
+				return
+			}
+			afterId = chats[i].ID
+		}
+
+		job.Processed += len(chats)
+		s.encryptionRepo.UpdateJob(ctx, &job)
+	}
+
+	job.Status = constrans.JobStatusCompleted
+	s.encryptionRepo.UpdateJob(ctx, &job)
+}
+
+// toEncryptionJobResponse converts an encryption job to its dto
+func toEncryptionJobResponse(job entity.EncryptionJob) dto.EncryptionJobResponse {
+	return dto.EncryptionJobResponse{
+		Id:        job.ID,
+		KeyId:     job.KeyID,
+		Status:    job.Status,
+		Total:     job.Total,
+		Processed: job.Processed,
+		Error:     job.Error,
+	}
+}
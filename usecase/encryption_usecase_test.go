@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/envelope"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultEncryptionUsecase_StartReencryption(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	keyring, _ := envelope.NewKeyring(map[string][]byte{"k1": bytes.Repeat([]byte{1}, envelope.MasterKeySize)}, "k1")
+
+	tests := []struct {
+		name      string
+		encryptor *envelope.Encryptor
+		rotate    bool
+		wantErr   bool
+	}{
+		{
+			name:      "encryption turned off",
+			encryptor: envelope.NewEncryptor(nil),
+			wantErr:   true,
+		},
+		{
+			name:      "keys from config cannot be rotated",
+			encryptor: envelope.NewEncryptor(keyring),
+			rotate:    true,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatRepo := new(mocks.ChatRepository)
+			encryptionRepo := new(mocks.EncryptionRepository)
+
+			s := NewEncryptionUsecase(chatRepo, encryptionRepo, new(mocks.AuditRepository), tt.encryptor)
+			_, err := s.StartReencryption(ctx, 9, tt.rotate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultEncryptionUsecase.StartReencryption() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			encryptionRepo.AssertNotCalled(t, "CreateJob", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func Test_defaultEncryptionUsecase_runEncryptionJob(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name          string
+		reencryptErr  error
+		wantStatus    string
+		wantProcessed int
+	}{
+		{
+			name:          "all batches encrypted again",
+			wantStatus:    constrans.JobStatusCompleted,
+			wantProcessed: 2,
+		},
+		{
+			name:         "re-encrypt error",
+			reencryptErr: errors.New("connection refused"),
+			wantStatus:   constrans.JobStatusFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatRepo := new(mocks.ChatRepository)
+			chatRepo.On("FindOutdatedKey", mock.Anything, 0, ReencryptBatchSize).Return([]entity.Chat{{ID: 4}, {ID: 9}}, nil).Once()
+			chatRepo.On("FindOutdatedKey", mock.Anything, 9, ReencryptBatchSize).Return([]entity.Chat{}, nil).Once()
+			chatRepo.On("Reencrypt", mock.Anything, mock.Anything).Return(tt.reencryptErr)
+
+			var last entity.EncryptionJob
+			encryptionRepo := new(mocks.EncryptionRepository)
+			encryptionRepo.On("UpdateJob", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				last = *args.Get(1).(*entity.EncryptionJob)
+			}).Return(nil)
+
+			s := &defaultEncryptionUsecase{chatRepo: chatRepo, encryptionRepo: encryptionRepo}
+			s.runEncryptionJob(ctx, entity.EncryptionJob{ID: 1, KeyID: "k2", Total: 2})
+
+			if last.Status != tt.wantStatus || last.Processed != tt.wantProcessed {
+				t.Errorf("defaultEncryptionUsecase.runEncryptionJob() status = %v, processed = %v, want %v, %v", last.Status, last.Processed, tt.wantStatus, tt.wantProcessed)
+			}
+		})
+	}
+}
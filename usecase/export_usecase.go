@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/export"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// MaxExportMessages is the number of messages written in a single export
+	MaxExportMessages = 5000
+
+	// DateLayout is the layout of the dates in requests
+	DateLayout = "2006-01-02"
+)
+
+type ExportUsecase interface {
+	ExportChats(ctx context.Context, userId int, req dto.ExportRequest) (resp dto.ExportResponse, err error)
+}
+
+type defaultExportUsecase struct {
+	userRepo         mysql.UserRepository
+	chatRepo         mysql.ChatRepository
+	conversationRepo mysql.ConversationRepository
+}
+
+// NewExportUsecase creates a new instance of ExportUsecase
+func NewExportUsecase(userRepo mysql.UserRepository, chatRepo mysql.ChatRepository, conversationRepo mysql.ConversationRepository) ExportUsecase {
+	return &defaultExportUsecase{
+		userRepo:         userRepo,
+		chatRepo:         chatRepo,
+		conversationRepo: conversationRepo,
+	}
+}
+
+// ExportChats renders the messages of a conversation or of a period of dates
+// as markdown, json, html or pdf
+func (s *defaultExportUsecase) ExportChats(ctx context.Context, userId int, req dto.ExportRequest) (resp dto.ExportResponse, err error) {
+	if req.Format == "" {
+		req.Format = export.FormatMarkdown
+	}
+	switch req.Format {
+	case export.FormatMarkdown, export.FormatJSON, export.FormatHTML, export.FormatPDF:
+	default:
+		logger.Error(ctx, "export format not valid", req.Format)
+		err = errors.SetError(http.StatusBadRequest, "format must be markdown, json, html or pdf")
+		return
+	}
+
+	if req.ConversationId == 0 && req.From == "" && req.To == "" {
+		logger.Error(ctx, "nothing to export")
+		err = errors.SetError(http.StatusBadRequest, "conversation id or a period is required")
+		return
+	}
+
+	filter := mysql.ChatFilter{
+		UserId:         userId,
+		ConversationId: req.ConversationId,
+		Limit:          MaxExportMessages,
+	}
+	filter.From, filter.To, err = parsePeriod(ctx, req.From, req.To)
+	if err != nil {
+		return
+	}
+
+	userData, err := s.userRepo.GetUserById(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "user not found")
+		err = errors.SetError(http.StatusBadRequest, "user not found")
+		return
+	}
+
+	transcript := export.Transcript{
+		Title:      "Chat export",
+		User:       userData.Name,
+		ExportedAt: time.Now(),
+	}
+	fileName := fmt.Sprintf("chat-export-%v", transcript.ExportedAt.Format("20060102-150405"))
+	if req.ConversationId != 0 {
+		conversation, errRes := s.conversationRepo.GetById(ctx, req.ConversationId)
+		if errRes != nil || conversation.UserID != userId {
+			logger.Error(ctx, "conversation not found")
+			err = errors.SetError(http.StatusNotFound, "conversation not found")
+			return
+		}
+
+		transcript.ConversationId = conversation.ID
+		transcript.Title = conversation.Title
+		if transcript.Title == "" {
+			transcript.Title = fmt.Sprintf("Conversation %v", conversation.ID)
+		}
+		fileName = fmt.Sprintf("conversation-%v", conversation.ID)
+	}
+
+	chats, err := s.chatRepo.Find(ctx, filter)
+	if err != nil {
+		logger.Error(ctx, "error getting chats", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(chats); i++ {
+		role := openai.ChatMessageRoleUser
+		if chats[i].Name == BotName {
+			role = openai.ChatMessageRoleAssistant
+		}
+		if chats[i].Model != "" && !containsString(transcript.Models, chats[i].Model) {
+			transcript.Models = append(transcript.Models, chats[i].Model)
+		}
+		transcript.Messages = append(transcript.Messages, export.Message{
+			Id:             chats[i].ID,
+			ConversationId: chats[i].ConversationID,
+			Role:           role,
+			Name:           chats[i].Name,
+			Model:          chats[i].Model,
+			CreatedAt:      chats[i].CreatedAt,
+			Content:        chats[i].Message,
+		})
+	}
+	if len(chats) > 0 {
+		transcript.From = chats[0].CreatedAt
+		transcript.To = chats[len(chats)-1].CreatedAt
+	}
+
+	file, err := export.Render(req.Format, transcript)
+	if err != nil {
+		logger.Error(ctx, "error rendering export", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = dto.ExportResponse{
+		FileName:    fileName + file.Extension,
+		ContentType: file.ContentType,
+		Data:        file.Data,
+	}
+	return
+}
+
+// parsePeriod parses the dates of a period. The end date is included, so the
+// returned end is the start of the following day.
+func parsePeriod(ctx context.Context, from, to string) (start, end time.Time, err error) {
+	if from != "" {
+		start, err = time.ParseInLocation(DateLayout, from, time.Local)
+		if err != nil {
+			logger.Error(ctx, "from date not valid", from)
+			err = errors.SetError(http.StatusBadRequest, "from must be a date in the YYYY-MM-DD format")
+			return
+		}
+	}
+
+	if to != "" {
+		end, err = time.ParseInLocation(DateLayout, to, time.Local)
+		if err != nil {
+			logger.Error(ctx, "to date not valid", to)
+			err = errors.SetError(http.StatusBadRequest, "to must be a date in the YYYY-MM-DD format")
+			return
+		}
+		end = end.AddDate(0, 0, 1)
+	}
+
+	if !start.IsZero() && !end.IsZero() && !start.Before(end) {
+		logger.Error(ctx, "period not valid")
+		err = errors.SetError(http.StatusBadRequest, "from must not be after to")
+		return
+	}
+	return
+}
@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultExportUsecase_ExportChats(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	at := time.Date(2024, 1, 18, 10, 11, 41, 0, time.Local)
+	chats := []entity.Chat{
+		{ID: 1, ConversationID: 2, Name: "fadilah", Message: "contoh kode?", CreatedAt: at},
+		{ID: 2, ConversationID: 2, Name: BotName, Model: "gpt-3.5-turbo", Message: "```go\nfmt.Println(1)\n```", CreatedAt: at},
+	}
+
+	tests := []struct {
+		name         string
+		req          dto.ExportRequest
+		getConvResp  *entity.Conversation
+		wantFilter   mysql.ChatFilter
+		wantFileName string
+		wantContains string
+		wantErr      bool
+	}{
+		{
+			name:    "format not valid",
+			req:     dto.ExportRequest{ConversationId: 2, Format: "docx"},
+			wantErr: true,
+		},
+		{
+			name:    "nothing selected",
+			req:     dto.ExportRequest{Format: "json"},
+			wantErr: true,
+		},
+		{
+			name:    "date not valid",
+			req:     dto.ExportRequest{From: "18-01-2024"},
+			wantErr: true,
+		},
+		{
+			name:    "from after to",
+			req:     dto.ExportRequest{From: "2024-01-19", To: "2024-01-18"},
+			wantErr: true,
+		},
+		{
+			name:        "conversation of another user",
+			req:         dto.ExportRequest{ConversationId: 2},
+			getConvResp: &entity.Conversation{ID: 2, UserID: 3},
+			wantErr:     true,
+		},
+		{
+			name:         "success export conversation as markdown",
+			req:          dto.ExportRequest{ConversationId: 2},
+			getConvResp:  &entity.Conversation{ID: 2, UserID: 1, Title: "Belajar Go"},
+			wantFilter:   mysql.ChatFilter{UserId: 1, ConversationId: 2, Limit: MaxExportMessages},
+			wantFileName: "conversation-2.md",
+			wantContains: "# Belajar Go\n\n- **User:** fadilah\n- **Conversation:** 2\n- **Models:** gpt-3.5-turbo",
+			wantErr:      false,
+		},
+		{
+			name: "success export period as json",
+			req:  dto.ExportRequest{From: "2024-01-18", To: "2024-01-18", Format: "json"},
+			wantFilter: mysql.ChatFilter{
+				UserId: 1,
+				From:   time.Date(2024, 1, 18, 0, 0, 0, 0, time.Local),
+				To:     time.Date(2024, 1, 19, 0, 0, 0, 0, time.Local),
+				Limit:  MaxExportMessages,
+			},
+			wantFileName: "chat-export-",
+			wantContains: "\"role\": \"assistant\"",
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+
+			userRepo.On("GetUserById", mock.Anything, 1).Return(&entity.User{ID: 1, Name: "fadilah"}, nil).Once()
+			conversationRepo.On("GetById", mock.Anything, tt.req.ConversationId).Return(tt.getConvResp, nil).Once()
+			chatRepo.On("Find", mock.Anything, tt.wantFilter).Return(chats, nil).Once()
+
+			s := NewExportUsecase(userRepo, chatRepo, conversationRepo)
+			gotResp, err := s.ExportChats(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultExportUsecase.ExportChats() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !strings.HasPrefix(gotResp.FileName, tt.wantFileName) {
+				t.Errorf("defaultExportUsecase.ExportChats() file name = %v, want %v", gotResp.FileName, tt.wantFileName)
+			}
+			if !strings.Contains(string(gotResp.Data), tt.wantContains) {
+				t.Errorf("defaultExportUsecase.ExportChats() = %s, want it to contain %q", gotResp.Data, tt.wantContains)
+			}
+		})
+	}
+}
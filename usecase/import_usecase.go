@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/chatimport"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/sashabaranov/go-openai"
+	"gorm.io/gorm"
+)
+
+// MaxImportSize is the largest export file that can be imported
+const MaxImportSize = 100 << 20
+
+type ImportUsecase interface {
+	ImportChats(ctx context.Context, userId int, req dto.ImportRequest) (resp dto.ImportJobResponse, err error)
+	GetImportJob(ctx context.Context, userId, id int) (resp dto.ImportJobResponse, err error)
+}
+
+type defaultImportUsecase struct {
+	userRepo         mysql.UserRepository
+	chatRepo         mysql.ChatRepository
+	conversationRepo mysql.ConversationRepository
+	importRepo       mysql.ImportRepository
+	cacheWrapper     cached.CacheWrapper
+}
+
+// NewImportUsecase creates a new instance of ImportUsecase
+func NewImportUsecase(userRepo mysql.UserRepository, chatRepo mysql.ChatRepository, conversationRepo mysql.ConversationRepository, importRepo mysql.ImportRepository, cacheWrapper cached.CacheWrapper) ImportUsecase {
+	return &defaultImportUsecase{
+		userRepo:         userRepo,
+		chatRepo:         chatRepo,
+		conversationRepo: conversationRepo,
+		importRepo:       importRepo,
+		cacheWrapper:     cacheWrapper,
+	}
+}
+
+// ImportChats reads the conversations of an export file and imports them in a
+// background job. The file is parsed before the job starts, so a file in the
+// wrong format is rejected right away. Without a format the format is
+// detected from the content.
+func (s *defaultImportUsecase) ImportChats(ctx context.Context, userId int, req dto.ImportRequest) (resp dto.ImportJobResponse, err error) {
+	userData, err := s.userRepo.GetUserById(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "user not found")
+		err = errors.SetError(http.StatusBadRequest, "user not found")
+		return
+	}
+
+	if req.Format == "" {
+		req.Format = chatimport.DetectFormat(req.Data)
+	}
+	switch req.Format {
+	case chatimport.FormatChatGPT, chatimport.FormatGeneric:
+	default:
+		logger.Error(ctx, "import format not valid", req.Format)
+		err = errors.SetError(http.StatusBadRequest, "format must be chatgpt or generic")
+		return
+	}
+
+	conversations, err := chatimport.Parse(req.Format, req.Data)
+	if err != nil {
+		logger.Error(ctx, "error parsing import file", err.Error())
+		err = errors.SetError(http.StatusBadRequest, "file is not a valid "+req.Format+" export")
+		return
+	}
+
+	job := &entity.ImportJob{
+		UserID:   userId,
+		Format:   req.Format,
+		FileName: req.FileName,
+		Status:   constrans.JobStatusPending,
+		Total:    len(conversations),
+	}
+	err = s.importRepo.CreateJob(ctx, job)
+	if err != nil {
+		logger.Error(ctx, "error creating import job", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	go s.runImportJob(context.WithoutCancel(ctx), *job, *userData, conversations)
+
+	resp = toImportJobResponse(*job)
+	return
+}
+
+// GetImportJob returns the progress of an import job of the user
+func (s *defaultImportUsecase) GetImportJob(ctx context.Context, userId, id int) (resp dto.ImportJobResponse, err error) {
+	job, err := s.importRepo.GetJobById(ctx, id)
+	if err != nil || job.UserID != userId {
+		logger.Error(ctx, "import job not found")
+		err = errors.SetError(http.StatusNotFound, "import job not found")
+		return
+	}
+
+	resp = toImportJobResponse(*job)
+	return
+}
+
+// runImportJob imports the conversations one at a time, recording the
+// progress on the job. A conversation imported before is continued, and its
+// messages that are already stored are skipped.
+func (s *defaultImportUsecase) runImportJob(ctx context.Context, job entity.ImportJob, user entity.User, conversations []chatimport.Conversation) {
+	fail := func(err error) {
+		logger.Error(ctx, "import job failed", job.ID, err.Error())
+		job.Status = constrans.JobStatusFailed
+		job.Error = err.Error()
+		s.importRepo.UpdateJob(ctx, &job)
+	}
+
+	job.Status = constrans.JobStatusRunning
+	s.importRepo.UpdateJob(ctx, &job)
+
+	for i := 0; i < len(conversations); i++ {
+		imported, skipped, err := s.importConversation(ctx, job, user, conversations[i])
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		job.Imported += imported
+		job.Skipped += skipped
+		job.Processed = i + 1
+		s.importRepo.UpdateJob(ctx, &job)
+	}
+
+	// the imported messages show up in the history from now on
+	keyHistory := fmt.Sprintf("%v_%v", KeyHistory, user.ID)
+	s.cacheWrapper.Delete(ctx, keyHistory)
+
+	job.Status = constrans.JobStatusCompleted
+	s.importRepo.UpdateJob(ctx, &job)
+}
+
+// importConversation stores the messages of a conversation that are not
+// stored yet, creating the conversation on its first import
+func (s *defaultImportUsecase) importConversation(ctx context.Context, job entity.ImportJob, user entity.User, item chatimport.Conversation) (imported, skipped int, err error) {
+	stored := map[string]bool{}
+	conversation, err := s.conversationRepo.GetByExternalId(ctx, user.ID, job.Format, item.ExternalID)
+	switch {
+	case err == nil:
+		externalIds, errRes := s.chatRepo.GetExternalIds(ctx, conversation.ID)
+		if errRes != nil {
+			return 0, 0, errRes
+		}
+		for i := 0; i < len(externalIds); i++ {
+			stored[externalIds[i]] = true
+		}
+	case stdErrors.Is(err, gorm.ErrRecordNotFound):
+		conversation = &entity.Conversation{
+			UserID:     user.ID,
+			Title:      truncateRunes(item.Title, MaxTitleLength),
+			Source:     job.Format,
+			ExternalID: item.ExternalID,
+			CreatedAt:  item.CreatedAt,
+		}
+		err = s.conversationRepo.Create(ctx, conversation)
+		if err != nil {
+			return
+		}
+	default:
+		return
+	}
+
+	var chats []entity.Chat
+	for _, message := range item.Messages {
+		if stored[message.ExternalID] {
+			skipped++
+			continue
+		}
+		stored[message.ExternalID] = true
+
+		chat := entity.Chat{
+			UserID:         user.ID,
+			ConversationID: conversation.ID,
+			Name:           user.Name,
+			Message:        message.Content,
+			ExternalID:     message.ExternalID,
+			CreatedAt:      message.CreatedAt,
+		}
+		if message.Role == openai.ChatMessageRoleAssistant {
+			chat.Name = BotName
+			chat.Model = message.Model
+		}
+		chats = append(chats, chat)
+	}
+	if len(chats) == 0 {
+		return
+	}
+
+	tx := s.chatRepo.BeginsTrans()
+	for i := 0; i < len(chats); i++ {
+		err = s.chatRepo.Create(ctx, tx, &chats[i])
+		if err != nil {
+			s.chatRepo.Rollback(tx)
+			return 0, 0, err
+		}
+	}
+	err = s.chatRepo.Commit(tx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// the cached context of a continued conversation misses the new messages
+	key := fmt.Sprintf("%v_%v_%v", KeyChatBot, user.ID, conversation.ID)
+	s.cacheWrapper.Delete(ctx, key)
+
+	imported = len(chats)
+	return
+}
+
+// toImportJobResponse converts an import job to its dto
+func toImportJobResponse(job entity.ImportJob) dto.ImportJobResponse {
+	return dto.ImportJobResponse{
+		Id:        job.ID,
+		Format:    job.Format,
+		FileName:  job.FileName,
+		Status:    job.Status,
+		Total:     job.Total,
+		Processed: job.Processed,
+		Imported:  job.Imported,
+		Skipped:   job.Skipped,
+		Error:     job.Error,
+	}
+}
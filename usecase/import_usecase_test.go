@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils"
+	"github.com/fadilahonespot/chatbot/utils/chatimport"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func Test_defaultImportUsecase_ImportChats(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name       string
+		req        dto.ImportRequest
+		createErr  error
+		wantFormat string
+		wantErr    bool
+	}{
+		{
+			name:    "format not valid",
+			req:     dto.ImportRequest{Format: "claude", Data: []byte(`[]`)},
+			wantErr: true,
+		},
+		{
+			name:    "file not valid",
+			req:     dto.ImportRequest{Format: chatimport.FormatChatGPT, Data: []byte(`halo`)},
+			wantErr: true,
+		},
+		{
+			name:      "error creating job",
+			req:       dto.ImportRequest{Data: []byte(`[]`)},
+			createErr: errors.New("error"),
+			wantErr:   true,
+		},
+		{
+			name:       "success with detected format",
+			req:        dto.ImportRequest{FileName: "conversations.json", Data: []byte(`{"conversations": []}`)},
+			wantFormat: chatimport.FormatGeneric,
+			wantErr:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			importRepo := new(mocks.ImportRepository)
+			cacheWrapper := new(mocks.CacheWrapper)
+
+			userRepo.On("GetUserById", mock.Anything, 1).Return(&entity.User{ID: 1, Name: "fadilah"}, nil).Once()
+			importRepo.On("CreateJob", mock.Anything, mock.Anything).Return(tt.createErr).Once()
+			// the job runs in the background with nothing to import
+			importRepo.On("UpdateJob", mock.Anything, mock.Anything).Return(nil).Maybe()
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+			s := NewImportUsecase(userRepo, chatRepo, conversationRepo, importRepo, cacheWrapper)
+			gotResp, err := s.ImportChats(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultImportUsecase.ImportChats() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotResp.Format != tt.wantFormat || gotResp.Status != constrans.JobStatusPending || gotResp.FileName != tt.req.FileName {
+				t.Errorf("defaultImportUsecase.ImportChats() = %+v, want a pending %v job", gotResp, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func Test_defaultImportUsecase_runImportJob(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	at := time.Date(2024, 1, 18, 10, 11, 41, 0, time.Local)
+	conversations := []chatimport.Conversation{
+		{
+			ExternalID: "c1",
+			Title:      "Belajar Go",
+			CreatedAt:  at,
+			Messages: []chatimport.Message{
+				{ExternalID: "m1", Role: "user", Content: "apa itu go?", CreatedAt: at},
+				{ExternalID: "m2", Role: "assistant", Content: "bahasa", Model: "gpt-4", CreatedAt: at},
+			},
+		},
+		{
+			ExternalID: "c2",
+			Messages: []chatimport.Message{
+				{ExternalID: "m3", Role: "user", Content: "halo", CreatedAt: at},
+				{ExternalID: "m4", Role: "assistant", Content: "hai", CreatedAt: at},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		createErr    error
+		wantStatus   string
+		wantImported int
+		wantSkipped  int
+		wantChats    []entity.Chat
+	}{
+		{
+			name:         "new conversation created and imported one skipped",
+			wantStatus:   constrans.JobStatusCompleted,
+			wantImported: 3,
+			wantSkipped:  1,
+			wantChats: []entity.Chat{
+				{UserID: 1, ConversationID: 10, Name: "fadilah", Message: "apa itu go?", ExternalID: "m1", CreatedAt: at},
+				{UserID: 1, ConversationID: 10, Name: BotName, Model: "gpt-4", Message: "bahasa", ExternalID: "m2", CreatedAt: at},
+				{UserID: 1, ConversationID: 20, Name: BotName, Message: "hai", ExternalID: "m4", CreatedAt: at},
+			},
+		},
+		{
+			name:       "error creating chat",
+			createErr:  errors.New("error"),
+			wantStatus: constrans.JobStatusFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			importRepo := new(mocks.ImportRepository)
+			cacheWrapper := new(mocks.CacheWrapper)
+
+			var gotChats []entity.Chat
+			var gotJob entity.ImportJob
+			conversationRepo.On("GetByExternalId", mock.Anything, 1, chatimport.FormatGeneric, "c1").Return(nil, gorm.ErrRecordNotFound).Once()
+			conversationRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				conversation := args.Get(1).(*entity.Conversation)
+				if conversation.Title != "Belajar Go" || conversation.Source != chatimport.FormatGeneric || !conversation.CreatedAt.Equal(at) {
+					t.Errorf("conversation created = %+v", conversation)
+				}
+				conversation.ID = 10
+			}).Return(nil).Once()
+			conversationRepo.On("GetByExternalId", mock.Anything, 1, chatimport.FormatGeneric, "c2").Return(&entity.Conversation{ID: 20, UserID: 1}, nil).Maybe()
+			chatRepo.On("GetExternalIds", mock.Anything, 20).Return([]string{"m3"}, nil).Maybe()
+			chatRepo.On("BeginsTrans").Return(utils.MockGorm())
+			chatRepo.On("Create", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				gotChats = append(gotChats, *args.Get(2).(*entity.Chat))
+			}).Return(tt.createErr)
+			chatRepo.On("Rollback", mock.Anything).Return(nil).Maybe()
+			chatRepo.On("Commit", mock.Anything).Return(nil)
+			importRepo.On("UpdateJob", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				gotJob = *args.Get(1).(*entity.ImportJob)
+			}).Return(nil)
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+			s := &defaultImportUsecase{
+				chatRepo:         chatRepo,
+				conversationRepo: conversationRepo,
+				importRepo:       importRepo,
+				cacheWrapper:     cacheWrapper,
+			}
+			job := entity.ImportJob{ID: 1, UserID: 1, Format: chatimport.FormatGeneric, Total: len(conversations)}
+			s.runImportJob(ctx, job, entity.User{ID: 1, Name: "fadilah"}, conversations)
+
+			if gotJob.Status != tt.wantStatus || gotJob.Imported != tt.wantImported || gotJob.Skipped != tt.wantSkipped {
+				t.Errorf("defaultImportUsecase.runImportJob() job = %+v, want status %v imported %v skipped %v", gotJob, tt.wantStatus, tt.wantImported, tt.wantSkipped)
+			}
+			if tt.wantChats != nil && len(gotChats) != len(tt.wantChats) {
+				t.Fatalf("defaultImportUsecase.runImportJob() chats = %+v, want %+v", gotChats, tt.wantChats)
+			}
+			for i := 0; i < len(tt.wantChats); i++ {
+				if !reflect.DeepEqual(gotChats[i], tt.wantChats[i]) {
+					t.Errorf("defaultImportUsecase.runImportJob() chat = %+v, want %+v", gotChats[i], tt.wantChats[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+)
+
+// MaxReviewNoteLength is the longest note an admin can leave on a flag
+const MaxReviewNoteLength = 500
+
+type ModerationUsecase interface {
+	GetFlags(ctx context.Context, adminId int, filter dto.ModerationFilter) (resp dto.ModerationFlagListResponse, err error)
+	ReviewFlag(ctx context.Context, reviewerId, id int, req dto.ModerationReviewRequest) (resp dto.ModerationFlagResponse, err error)
+}
+
+type defaultModerationUsecase struct {
+	moderationRepo mysql.ModerationRepository
+	auditRepo      mysql.AuditRepository
+}
+
+// NewModerationUsecase creates a new instance of ModerationUsecase
+func NewModerationUsecase(moderationRepo mysql.ModerationRepository, auditRepo mysql.AuditRepository) ModerationUsecase {
+	return &defaultModerationUsecase{
+		moderationRepo: moderationRepo,
+		auditRepo:      auditRepo,
+	}
+}
+
+// GetFlags returns a page of the review queue, the pending flags by default
+func (s *defaultModerationUsecase) GetFlags(ctx context.Context, adminId int, filter dto.ModerationFilter) (resp dto.ModerationFlagListResponse, err error) {
+	if filter.Status == "" {
+		filter.Status = constrans.ModerationStatusPending
+	}
+
+	flags, total, err := s.moderationRepo.FindFlags(ctx, mysql.ModerationFilter{
+		Status:    filter.Status,
+		Direction: filter.Direction,
+		UserId:    filter.UserId,
+		Page:      filter.Page,
+		Limit:     filter.Limit,
+	})
+	if err != nil {
+		logger.Error(ctx, "error getting moderation flags", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp.Total = total
+	resp.Flags = []dto.ModerationFlagResponse{}
+	flagIds := []int{}
+	for i := 0; i < len(flags); i++ {
+		resp.Flags = append(resp.Flags, toModerationFlagResponse(flags[i]))
+		flagIds = append(flagIds, flags[i].ID)
+	}
+
+	// The flags hold the messages of other users
+	if len(flagIds) > 0 {
+		recordAudit(ctx, s.auditRepo, adminId, constrans.AuditModerationViewed, "user", filter.UserId, nil, map[string]interface{}{
+			"status":  filter.Status,
+			"flagIds": flagIds,
+		})
+	}
+	return
+}
+
+// ReviewFlag records the decision of an admin on a flag, taking it out of the
+// pending queue
+func (s *defaultModerationUsecase) ReviewFlag(ctx context.Context, reviewerId, id int, req dto.ModerationReviewRequest) (resp dto.ModerationFlagResponse, err error) {
+	if req.Status != constrans.ModerationStatusConfirmed && req.Status != constrans.ModerationStatusDismissed {
+		logger.Error(ctx, "review status not valid", req.Status)
+		err = errors.SetError(http.StatusBadRequest, "status must be confirmed or dismissed")
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if len([]rune(req.Note)) > MaxReviewNoteLength {
+		logger.Error(ctx, "review note too long")
+		err = errors.SetError(http.StatusBadRequest, "note is too long")
+		return
+	}
+
+	flag, err := s.moderationRepo.GetFlagById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "moderation flag not found")
+		err = errors.SetError(http.StatusNotFound, "moderation flag not found")
+		return
+	}
+
+	before := map[string]string{"status": flag.Status}
+	now := time.Now()
+	flag.Status = req.Status
+	flag.ReviewNote = req.Note
+	flag.ReviewerID = reviewerId
+	flag.ReviewedAt = &now
+	err = s.moderationRepo.UpdateFlag(ctx, flag)
+	if err != nil {
+		logger.Error(ctx, "error reviewing moderation flag", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	recordAudit(ctx, s.auditRepo, reviewerId, constrans.AuditModerationReviewed, "moderation_flag", flag.ID, before, map[string]string{
+		"status": flag.Status,
+		"note":   flag.ReviewNote,
+	})
+
+	resp = toModerationFlagResponse(*flag)
+	return
+}
+
+// toModerationFlagResponse converts a moderation flag to its dto
+func toModerationFlagResponse(flag entity.ModerationFlag) dto.ModerationFlagResponse {
+	return dto.ModerationFlagResponse{
+		Id:             flag.ID,
+		UserId:         flag.UserID,
+		ConversationId: flag.ConversationID,
+		ChatId:         flag.ChatID,
+		Direction:      flag.Direction,
+		Content:        flag.Content,
+		Providers:      strings.Split(flag.Providers, ","),
+		Categories:     strings.Split(flag.Categories, ","),
+		Action:         flag.Action,
+		Status:         flag.Status,
+		ReviewerId:     flag.ReviewerID,
+		ReviewNote:     flag.ReviewNote,
+		ReviewedAt:     flag.ReviewedAt,
+		CreatedAt:      flag.CreatedAt,
+	}
+}
@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultModerationUsecase_GetFlags(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	moderationRepo := new(mocks.ModerationRepository)
+	moderationRepo.On("FindFlags", mock.Anything, mysql.ModerationFilter{Status: constrans.ModerationStatusPending, Page: 1, Limit: 10}).Return([]entity.ModerationFlag{
+		{ID: 3, Direction: constrans.ModerationInput, Providers: "policy,openai", Categories: "hate"},
+	}, int64(1), nil).Once()
+
+	auditRepo := new(mocks.AuditRepository)
+	auditRepo.On("Create", mock.Anything, mock.MatchedBy(func(log *entity.AuditLog) bool {
+		return log.ActorID == 9 && log.Action == constrans.AuditModerationViewed
+	})).Return(nil).Once()
+
+	s := NewModerationUsecase(moderationRepo, auditRepo)
+	gotResp, err := s.GetFlags(ctx, 9, dto.ModerationFilter{Page: 1, Limit: 10})
+	if err != nil {
+		t.Fatalf("defaultModerationUsecase.GetFlags() error = %v", err)
+	}
+	if gotResp.Total != 1 || len(gotResp.Flags) != 1 || len(gotResp.Flags[0].Providers) != 2 {
+		t.Errorf("defaultModerationUsecase.GetFlags() = %+v", gotResp)
+	}
+}
+
+func Test_defaultModerationUsecase_ReviewFlag(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name    string
+		req     dto.ModerationReviewRequest
+		getErr  error
+		wantErr bool
+	}{
+		{
+			name:    "status not valid",
+			req:     dto.ModerationReviewRequest{Status: constrans.ModerationStatusPending},
+			wantErr: true,
+		},
+		{
+			name:    "note too long",
+			req:     dto.ModerationReviewRequest{Status: constrans.ModerationStatusDismissed, Note: strings.Repeat("a", MaxReviewNoteLength+1)},
+			wantErr: true,
+		},
+		{
+			name:    "flag not found",
+			req:     dto.ModerationReviewRequest{Status: constrans.ModerationStatusConfirmed},
+			getErr:  errors.New("record not found"),
+			wantErr: true,
+		},
+		{
+			name:    "success dismiss flag",
+			req:     dto.ModerationReviewRequest{Status: constrans.ModerationStatusDismissed, Note: " salah tangkap "},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moderationRepo := new(mocks.ModerationRepository)
+			moderationRepo.On("GetFlagById", mock.Anything, 3).Return(&entity.ModerationFlag{ID: 3, Status: constrans.ModerationStatusPending}, tt.getErr).Once()
+			moderationRepo.On("UpdateFlag", mock.Anything, mock.Anything).Return(nil).Once()
+
+			auditRepo := new(mocks.AuditRepository)
+			auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			s := NewModerationUsecase(moderationRepo, auditRepo)
+			gotResp, err := s.ReviewFlag(ctx, 9, 3, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultModerationUsecase.ReviewFlag() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotResp.Status != tt.req.Status || gotResp.ReviewerId != 9 || gotResp.ReviewNote != "salah tangkap" || gotResp.ReviewedAt == nil {
+				t.Errorf("defaultModerationUsecase.ReviewFlag() = %+v", gotResp)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/sashabaranov/go-openai"
+)
+
+type PersonaUsecase interface {
+	CreatePersona(ctx context.Context, req dto.PersonaRequest) (resp dto.PersonaResponse, err error)
+	UpdatePersona(ctx context.Context, id int, req dto.PersonaRequest) (resp dto.PersonaResponse, err error)
+	DeletePersona(ctx context.Context, id int) (err error)
+	GetPersonas(ctx context.Context) (resp []dto.PersonaResponse, err error)
+	GetAvailablePersonas(ctx context.Context, userId int) (resp []dto.PersonaResponse, err error)
+}
+
+type defaultPersonaUsecase struct {
+	personaRepo mysql.PersonaRepository
+}
+
+// DefaultSystemPrompt is used for conversations that have no persona.
+const DefaultSystemPrompt = "Halo! Perkenalkan aku adalah ChatBot Assistant. Bagimana aku bisa membantumu hari ini?"
+
+// NewPersonaUsecase creates a new instance of PersonaUsecase
+func NewPersonaUsecase(personaRepo mysql.PersonaRepository) PersonaUsecase {
+	return &defaultPersonaUsecase{
+		personaRepo: personaRepo,
+	}
+}
+
+// CreatePersona creates a new persona with the first version of its system prompt
+func (s *defaultPersonaUsecase) CreatePersona(ctx context.Context, req dto.PersonaRequest) (resp dto.PersonaResponse, err error) {
+	err = validatePersona(ctx, req)
+	if err != nil {
+		return
+	}
+
+	persona := entity.Persona{PromptVersion: 1}
+	applyPersonaRequest(&persona, req)
+
+	err = s.personaRepo.Create(ctx, &persona)
+	if err != nil {
+		logger.Error(ctx, "error creating persona", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toPersonaResponse(persona, true)
+	return
+}
+
+// UpdatePersona updates a persona, bumping its prompt version when the system prompt changes
+func (s *defaultPersonaUsecase) UpdatePersona(ctx context.Context, id int, req dto.PersonaRequest) (resp dto.PersonaResponse, err error) {
+	err = validatePersona(ctx, req)
+	if err != nil {
+		return
+	}
+
+	persona, err := s.personaRepo.GetById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "persona not found", err.Error())
+		err = errors.SetError(http.StatusNotFound, "persona not found")
+		return
+	}
+
+	if persona.SystemPrompt != req.SystemPrompt {
+		persona.PromptVersion++
+	}
+	applyPersonaRequest(persona, req)
+
+	err = s.personaRepo.Update(ctx, persona)
+	if err != nil {
+		logger.Error(ctx, "error updating persona", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toPersonaResponse(*persona, true)
+	return
+}
+
+// DeletePersona deletes a persona
+func (s *defaultPersonaUsecase) DeletePersona(ctx context.Context, id int) (err error) {
+	_, err = s.personaRepo.GetById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "persona not found", err.Error())
+		err = errors.SetError(http.StatusNotFound, "persona not found")
+		return
+	}
+
+	err = s.personaRepo.Delete(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "error deleting persona", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	return
+}
+
+// GetPersonas returns every persona with its full configuration
+func (s *defaultPersonaUsecase) GetPersonas(ctx context.Context) (resp []dto.PersonaResponse, err error) {
+	personas, err := s.personaRepo.GetAll(ctx)
+	if err != nil {
+		logger.Error(ctx, "error getting personas", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(personas); i++ {
+		resp = append(resp, toPersonaResponse(personas[i], true))
+	}
+	return
+}
+
+// GetAvailablePersonas returns the personas the user is allowed to pick
+func (s *defaultPersonaUsecase) GetAvailablePersonas(ctx context.Context, userId int) (resp []dto.PersonaResponse, err error) {
+	personas, err := s.personaRepo.GetAll(ctx)
+	if err != nil {
+		logger.Error(ctx, "error getting personas", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(personas); i++ {
+		if !isPersonaAllowed(&personas[i], userId) {
+			continue
+		}
+		resp = append(resp, toPersonaResponse(personas[i], false))
+	}
+	return
+}
+
+// validatePersona checks the fields an admin has to provide for a persona
+func validatePersona(ctx context.Context, req dto.PersonaRequest) (err error) {
+	if strings.TrimSpace(req.Name) == "" {
+		logger.Error(ctx, "persona name is required")
+		err = errors.SetError(http.StatusBadRequest, "persona name is required")
+		return
+	}
+
+	if strings.TrimSpace(req.SystemPrompt) == "" {
+		logger.Error(ctx, "system prompt is required")
+		err = errors.SetError(http.StatusBadRequest, "system prompt is required")
+		return
+	}
+
+	if req.Temperature < 0 || req.Temperature > 2 {
+		logger.Error(ctx, "temperature not valid")
+		err = errors.SetError(http.StatusBadRequest, "temperature must be between 0 and 2")
+		return
+	}
+
+	if req.MaxTokens < 0 {
+		logger.Error(ctx, "max tokens not valid")
+		err = errors.SetError(http.StatusBadRequest, "max tokens must not be negative")
+		return
+	}
+	return
+}
+
+// applyPersonaRequest copies the request fields into the persona
+func applyPersonaRequest(persona *entity.Persona, req dto.PersonaRequest) {
+	persona.Name = req.Name
+	persona.Description = req.Description
+	persona.SystemPrompt = req.SystemPrompt
+	persona.Model = req.Model
+	persona.Temperature = req.Temperature
+	persona.MaxTokens = req.MaxTokens
+	if persona.Model == "" {
+		persona.Model = openai.GPT3Dot5Turbo
+	}
+
+	persona.AllowedUsers = nil
+	for i := 0; i < len(req.AllowedUserIds); i++ {
+		persona.AllowedUsers = append(persona.AllowedUsers, entity.PersonaUser{
+			PersonaID: persona.ID,
+			UserID:    req.AllowedUserIds[i],
+		})
+	}
+}
+
+// toPersonaResponse converts a persona to its dto, hiding the system prompt
+// and allowed users unless detail is requested
+func toPersonaResponse(persona entity.Persona, detail bool) dto.PersonaResponse {
+	resp := dto.PersonaResponse{
+		Id:            persona.ID,
+		Name:          persona.Name,
+		Description:   persona.Description,
+		PromptVersion: persona.PromptVersion,
+		Model:         persona.Model,
+		Temperature:   persona.Temperature,
+		MaxTokens:     persona.MaxTokens,
+	}
+
+	if detail {
+		resp.SystemPrompt = persona.SystemPrompt
+		for i := 0; i < len(persona.AllowedUsers); i++ {
+			resp.AllowedUserIds = append(resp.AllowedUserIds, persona.AllowedUsers[i].UserID)
+		}
+	}
+	return resp
+}
+
+// isPersonaAllowed reports whether the user may talk to the persona.
+// A persona without allowed users is open to everyone.
+func isPersonaAllowed(persona *entity.Persona, userId int) bool {
+	if len(persona.AllowedUsers) == 0 {
+		return true
+	}
+
+	for i := 0; i < len(persona.AllowedUsers); i++ {
+		if persona.AllowedUsers[i].UserID == userId {
+			return true
+		}
+	}
+	return false
+}
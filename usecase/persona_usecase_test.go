@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultPersonaUsecase_CreatePersona(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	type args struct {
+		ctx context.Context
+		req dto.PersonaRequest
+	}
+	tests := []struct {
+		name      string
+		args      args
+		createErr error
+		wantResp  dto.PersonaResponse
+		wantErr   bool
+	}{
+		{
+			name: "name is empty",
+			args: args{
+				ctx: ctx,
+				req: dto.PersonaRequest{
+					SystemPrompt: "kamu adalah koki",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "system prompt is empty",
+			args: args{
+				ctx: ctx,
+				req: dto.PersonaRequest{
+					Name: "koki",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "temperature not valid",
+			args: args{
+				ctx: ctx,
+				req: dto.PersonaRequest{
+					Name:         "koki",
+					SystemPrompt: "kamu adalah koki",
+					Temperature:  3,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "create persona error",
+			args: args{
+				ctx: ctx,
+				req: dto.PersonaRequest{
+					Name:         "koki",
+					SystemPrompt: "kamu adalah koki",
+				},
+			},
+			createErr: errors.New("create persona error"),
+			wantErr:   true,
+		},
+		{
+			name: "success create persona",
+			args: args{
+				ctx: ctx,
+				req: dto.PersonaRequest{
+					Name:           "koki",
+					SystemPrompt:   "kamu adalah koki",
+					Temperature:    0.5,
+					AllowedUserIds: []int{1, 2},
+				},
+			},
+			wantResp: dto.PersonaResponse{
+				Name:           "koki",
+				SystemPrompt:   "kamu adalah koki",
+				PromptVersion:  1,
+				Model:          openai.GPT3Dot5Turbo,
+				Temperature:    0.5,
+				AllowedUserIds: []int{1, 2},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			personaRepo := new(mocks.PersonaRepository)
+			personaRepo.On("Create", mock.Anything, mock.Anything).Return(tt.createErr).Once()
+
+			s := NewPersonaUsecase(personaRepo)
+			gotResp, err := s.CreatePersona(tt.args.ctx, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultPersonaUsecase.CreatePersona() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultPersonaUsecase.CreatePersona() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
+
+func Test_defaultPersonaUsecase_UpdatePersona(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	type args struct {
+		ctx context.Context
+		id  int
+		req dto.PersonaRequest
+	}
+	tests := []struct {
+		name      string
+		args      args
+		getResp   *entity.Persona
+		getErr    error
+		updateErr error
+		wantResp  dto.PersonaResponse
+		wantErr   bool
+	}{
+		{
+			name: "persona not found",
+			args: args{
+				ctx: ctx,
+				id:  1,
+				req: dto.PersonaRequest{
+					Name:         "koki",
+					SystemPrompt: "kamu adalah koki",
+				},
+			},
+			getErr:  errors.New("record not found"),
+			wantErr: true,
+		},
+		{
+			name: "update persona error",
+			args: args{
+				ctx: ctx,
+				id:  1,
+				req: dto.PersonaRequest{
+					Name:         "koki",
+					SystemPrompt: "kamu adalah koki",
+				},
+			},
+			getResp: &entity.Persona{
+				ID:            1,
+				SystemPrompt:  "kamu adalah koki",
+				PromptVersion: 1,
+			},
+			updateErr: errors.New("update persona error"),
+			wantErr:   true,
+		},
+		{
+			name: "prompt unchanged keeps version",
+			args: args{
+				ctx: ctx,
+				id:  1,
+				req: dto.PersonaRequest{
+					Name:         "koki handal",
+					SystemPrompt: "kamu adalah koki",
+					Model:        openai.GPT4,
+				},
+			},
+			getResp: &entity.Persona{
+				ID:            1,
+				Name:          "koki",
+				SystemPrompt:  "kamu adalah koki",
+				PromptVersion: 1,
+			},
+			wantResp: dto.PersonaResponse{
+				Id:            1,
+				Name:          "koki handal",
+				SystemPrompt:  "kamu adalah koki",
+				PromptVersion: 1,
+				Model:         openai.GPT4,
+			},
+			wantErr: false,
+		},
+		{
+			name: "prompt changed bumps version",
+			args: args{
+				ctx: ctx,
+				id:  1,
+				req: dto.PersonaRequest{
+					Name:         "koki",
+					SystemPrompt: "kamu adalah koki profesional",
+				},
+			},
+			getResp: &entity.Persona{
+				ID:            1,
+				Name:          "koki",
+				SystemPrompt:  "kamu adalah koki",
+				PromptVersion: 1,
+			},
+			wantResp: dto.PersonaResponse{
+				Id:            1,
+				Name:          "koki",
+				SystemPrompt:  "kamu adalah koki profesional",
+				PromptVersion: 2,
+				Model:         openai.GPT3Dot5Turbo,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			personaRepo := new(mocks.PersonaRepository)
+			personaRepo.On("GetById", mock.Anything, mock.Anything).Return(tt.getResp, tt.getErr).Once()
+			personaRepo.On("Update", mock.Anything, mock.Anything).Return(tt.updateErr).Once()
+
+			s := NewPersonaUsecase(personaRepo)
+			gotResp, err := s.UpdatePersona(tt.args.ctx, tt.args.id, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultPersonaUsecase.UpdatePersona() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultPersonaUsecase.UpdatePersona() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
+
+func Test_defaultPersonaUsecase_GetAvailablePersonas(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	personas := []entity.Persona{
+		{
+			ID:           1,
+			Name:         "umum",
+			SystemPrompt: "kamu adalah asisten",
+		},
+		{
+			ID:           2,
+			Name:         "internal",
+			SystemPrompt: "kamu adalah asisten internal",
+			AllowedUsers: []entity.PersonaUser{{PersonaID: 2, UserID: 7}},
+		},
+	}
+
+	type args struct {
+		ctx    context.Context
+		userId int
+	}
+	tests := []struct {
+		name       string
+		args       args
+		getAllResp []entity.Persona
+		getAllErr  error
+		wantResp   []dto.PersonaResponse
+		wantErr    bool
+	}{
+		{
+			name: "get personas error",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+			},
+			getAllErr: errors.New("error getting personas"),
+			wantErr:   true,
+		},
+		{
+			name: "restricted persona hidden from other users",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+			},
+			getAllResp: personas,
+			wantResp: []dto.PersonaResponse{
+				{Id: 1, Name: "umum"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "restricted persona shown to allowed user",
+			args: args{
+				ctx:    ctx,
+				userId: 7,
+			},
+			getAllResp: personas,
+			wantResp: []dto.PersonaResponse{
+				{Id: 1, Name: "umum"},
+				{Id: 2, Name: "internal"},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			personaRepo := new(mocks.PersonaRepository)
+			personaRepo.On("GetAll", mock.Anything).Return(tt.getAllResp, tt.getAllErr).Once()
+
+			s := NewPersonaUsecase(personaRepo)
+			gotResp, err := s.GetAvailablePersonas(tt.args.ctx, tt.args.userId)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultPersonaUsecase.GetAvailablePersonas() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultPersonaUsecase.GetAvailablePersonas() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
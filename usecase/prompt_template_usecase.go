@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/chatbot/utils/prompt"
+	"github.com/fadilahonespot/library/errors"
+)
+
+type PromptTemplateUsecase interface {
+	CreatePromptTemplate(ctx context.Context, userId int, req dto.PromptTemplateRequest) (resp dto.PromptTemplateResponse, err error)
+	UpdatePromptTemplate(ctx context.Context, userId, id int, req dto.PromptTemplateRequest) (resp dto.PromptTemplateResponse, err error)
+	DeletePromptTemplate(ctx context.Context, userId, id int) (err error)
+	GetPromptTemplate(ctx context.Context, userId, id int) (resp dto.PromptTemplateResponse, err error)
+	GetPromptTemplates(ctx context.Context, userId int) (resp []dto.PromptTemplateResponse, err error)
+}
+
+type defaultPromptTemplateUsecase struct {
+	promptTemplateRepo mysql.PromptTemplateRepository
+}
+
+// NewPromptTemplateUsecase creates a new instance of PromptTemplateUsecase
+func NewPromptTemplateUsecase(promptTemplateRepo mysql.PromptTemplateRepository) PromptTemplateUsecase {
+	return &defaultPromptTemplateUsecase{
+		promptTemplateRepo: promptTemplateRepo,
+	}
+}
+
+// CreatePromptTemplate creates a template owned by the user
+func (s *defaultPromptTemplateUsecase) CreatePromptTemplate(ctx context.Context, userId int, req dto.PromptTemplateRequest) (resp dto.PromptTemplateResponse, err error) {
+	template := entity.PromptTemplate{UserID: userId}
+	err = applyPromptTemplateRequest(ctx, &template, req)
+	if err != nil {
+		return
+	}
+
+	err = s.promptTemplateRepo.Create(ctx, &template)
+	if err != nil {
+		logger.Error(ctx, "error creating template", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toPromptTemplateResponse(template)
+	return
+}
+
+// UpdatePromptTemplate updates a template owned by the user
+func (s *defaultPromptTemplateUsecase) UpdatePromptTemplate(ctx context.Context, userId, id int, req dto.PromptTemplateRequest) (resp dto.PromptTemplateResponse, err error) {
+	template, err := s.promptTemplateRepo.GetById(ctx, id)
+	if err != nil || template.UserID != userId {
+		logger.Error(ctx, "template not found")
+		err = errors.SetError(http.StatusNotFound, "template not found")
+		return
+	}
+
+	err = applyPromptTemplateRequest(ctx, template, req)
+	if err != nil {
+		return
+	}
+
+	err = s.promptTemplateRepo.Update(ctx, template)
+	if err != nil {
+		logger.Error(ctx, "error updating template", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toPromptTemplateResponse(*template)
+	return
+}
+
+// DeletePromptTemplate deletes a template owned by the user
+func (s *defaultPromptTemplateUsecase) DeletePromptTemplate(ctx context.Context, userId, id int) (err error) {
+	template, err := s.promptTemplateRepo.GetById(ctx, id)
+	if err != nil || template.UserID != userId {
+		logger.Error(ctx, "template not found")
+		err = errors.SetError(http.StatusNotFound, "template not found")
+		return
+	}
+
+	err = s.promptTemplateRepo.Delete(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "error deleting template", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	return
+}
+
+// GetPromptTemplate returns a template the user owns or that is shared
+func (s *defaultPromptTemplateUsecase) GetPromptTemplate(ctx context.Context, userId, id int) (resp dto.PromptTemplateResponse, err error) {
+	template, err := s.promptTemplateRepo.GetById(ctx, id)
+	if err != nil || !isPromptTemplateAccessible(template, userId) {
+		logger.Error(ctx, "template not found")
+		err = errors.SetError(http.StatusNotFound, "template not found")
+		return
+	}
+
+	resp = toPromptTemplateResponse(*template)
+	return
+}
+
+// GetPromptTemplates returns the templates of the user and the shared ones
+func (s *defaultPromptTemplateUsecase) GetPromptTemplates(ctx context.Context, userId int) (resp []dto.PromptTemplateResponse, err error) {
+	templates, err := s.promptTemplateRepo.GetAccessibleByUserId(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting templates", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(templates); i++ {
+		resp = append(resp, toPromptTemplateResponse(templates[i]))
+	}
+	return
+}
+
+// applyPromptTemplateRequest validates the request and copies it into the template.
+// Every placeholder in the content becomes a variable; placeholders that are
+// not described in the request are required.
+func applyPromptTemplateRequest(ctx context.Context, template *entity.PromptTemplate, req dto.PromptTemplateRequest) (err error) {
+	if strings.TrimSpace(req.Name) == "" {
+		logger.Error(ctx, "template name is required")
+		err = errors.SetError(http.StatusBadRequest, "template name is required")
+		return
+	}
+
+	if strings.TrimSpace(req.Content) == "" {
+		logger.Error(ctx, "template content is required")
+		err = errors.SetError(http.StatusBadRequest, "template content is required")
+		return
+	}
+
+	if req.Visibility == "" {
+		req.Visibility = constrans.VisibilityPrivate
+	}
+	if req.Visibility != constrans.VisibilityPrivate && req.Visibility != constrans.VisibilityShared {
+		logger.Error(ctx, "visibility not valid")
+		err = errors.SetError(http.StatusBadRequest, "visibility must be private or shared")
+		return
+	}
+
+	placeholders := prompt.Placeholders(req.Content)
+	definitions := map[string]dto.PromptTemplateVariable{}
+	for i := 0; i < len(req.Variables); i++ {
+		definitions[req.Variables[i].Name] = req.Variables[i]
+	}
+	for name := range definitions {
+		if !containsString(placeholders, name) {
+			logger.Error(ctx, "variable not used in template", name)
+			err = errors.SetError(http.StatusBadRequest, fmt.Sprintf("variable %v is not used in the template", name))
+			return
+		}
+	}
+
+	template.Name = req.Name
+	template.Description = req.Description
+	template.Content = req.Content
+	template.Visibility = req.Visibility
+	template.Variables = nil
+	for i := 0; i < len(placeholders); i++ {
+		variable := entity.PromptTemplateVariable{
+			PromptTemplateID: template.ID,
+			Name:             placeholders[i],
+			Required:         true,
+		}
+		if definition, ok := definitions[placeholders[i]]; ok {
+			variable.Required = definition.Required
+			variable.DefaultValue = definition.DefaultValue
+		}
+		template.Variables = append(template.Variables, variable)
+	}
+	return
+}
+
+// renderPromptTemplate fills the template with the given values, using the
+// defaults of optional variables and failing when a required one is missing
+func renderPromptTemplate(ctx context.Context, template *entity.PromptTemplate, values map[string]string) (result string, err error) {
+	merged := map[string]string{}
+	var missing []string
+	for i := 0; i < len(template.Variables); i++ {
+		variable := template.Variables[i]
+		value, ok := values[variable.Name]
+		switch {
+		case ok:
+			merged[variable.Name] = value
+		case !variable.Required:
+			merged[variable.Name] = variable.DefaultValue
+		default:
+			missing = append(missing, variable.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		logger.Error(ctx, "missing template variables", missing)
+		err = errors.SetError(http.StatusBadRequest, fmt.Sprintf("missing required variables: %v", strings.Join(missing, ", ")))
+		return
+	}
+
+	result, _ = prompt.Render(template.Content, merged)
+	return
+}
+
+// isPromptTemplateAccessible reports whether the user owns the template or it is shared
+func isPromptTemplateAccessible(template *entity.PromptTemplate, userId int) bool {
+	return template.UserID == userId || template.Visibility == constrans.VisibilityShared
+}
+
+// toPromptTemplateResponse converts a template to its dto
+func toPromptTemplateResponse(template entity.PromptTemplate) dto.PromptTemplateResponse {
+	resp := dto.PromptTemplateResponse{
+		Id:          template.ID,
+		UserId:      template.UserID,
+		Name:        template.Name,
+		Description: template.Description,
+		Content:     template.Content,
+		Visibility:  template.Visibility,
+	}
+
+	for i := 0; i < len(template.Variables); i++ {
+		resp.Variables = append(resp.Variables, dto.PromptTemplateVariable{
+			Name:         template.Variables[i].Name,
+			Required:     template.Variables[i].Required,
+			DefaultValue: template.Variables[i].DefaultValue,
+		})
+	}
+	return resp
+}
+
+// containsString reports whether the slice contains the value
+func containsString(values []string, value string) bool {
+	for i := 0; i < len(values); i++ {
+		if values[i] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// containsInt reports whether the slice contains the value
+func containsInt(values []int, value int) bool {
+	for i := 0; i < len(values); i++ {
+		if values[i] == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,243 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultPromptTemplateUsecase_CreatePromptTemplate(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	type args struct {
+		ctx    context.Context
+		userId int
+		req    dto.PromptTemplateRequest
+	}
+	tests := []struct {
+		name      string
+		args      args
+		createErr error
+		wantResp  dto.PromptTemplateResponse
+		wantErr   bool
+	}{
+		{
+			name: "content is empty",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.PromptTemplateRequest{
+					Name: "ringkas tiket",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "visibility not valid",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.PromptTemplateRequest{
+					Name:       "ringkas tiket",
+					Content:    "ringkas tiket ini: {{tiket}}",
+					Visibility: "public",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "variable not used in content",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.PromptTemplateRequest{
+					Name:    "ringkas tiket",
+					Content: "ringkas tiket ini: {{tiket}}",
+					Variables: []dto.PromptTemplateVariable{
+						{Name: "bahasa"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "create template error",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.PromptTemplateRequest{
+					Name:    "ringkas tiket",
+					Content: "ringkas tiket ini: {{tiket}}",
+				},
+			},
+			createErr: errors.New("create template error"),
+			wantErr:   true,
+		},
+		{
+			name: "success create template",
+			args: args{
+				ctx:    ctx,
+				userId: 1,
+				req: dto.PromptTemplateRequest{
+					Name:       "ringkas tiket",
+					Content:    "ringkas tiket ini dalam {{bahasa}}: {{tiket}} {{ tiket }}",
+					Visibility: "shared",
+					Variables: []dto.PromptTemplateVariable{
+						{Name: "bahasa", DefaultValue: "Bahasa Indonesia"},
+					},
+				},
+			},
+			wantResp: dto.PromptTemplateResponse{
+				UserId:     1,
+				Name:       "ringkas tiket",
+				Content:    "ringkas tiket ini dalam {{bahasa}}: {{tiket}} {{ tiket }}",
+				Visibility: "shared",
+				Variables: []dto.PromptTemplateVariable{
+					{Name: "bahasa", DefaultValue: "Bahasa Indonesia"},
+					{Name: "tiket", Required: true},
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promptTemplateRepo := new(mocks.PromptTemplateRepository)
+			promptTemplateRepo.On("Create", mock.Anything, mock.Anything).Return(tt.createErr).Once()
+
+			s := NewPromptTemplateUsecase(promptTemplateRepo)
+			gotResp, err := s.CreatePromptTemplate(tt.args.ctx, tt.args.userId, tt.args.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultPromptTemplateUsecase.CreatePromptTemplate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultPromptTemplateUsecase.CreatePromptTemplate() = %v, want %v", gotResp, tt.wantResp)
+			}
+		})
+	}
+}
+
+func Test_defaultPromptTemplateUsecase_UpdatePromptTemplate(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	req := dto.PromptTemplateRequest{
+		Name:    "ringkas tiket",
+		Content: "ringkas tiket ini: {{tiket}}",
+	}
+
+	tests := []struct {
+		name      string
+		userId    int
+		getResp   *entity.PromptTemplate
+		getErr    error
+		updateErr error
+		wantErr   bool
+	}{
+		{
+			name:    "template not found",
+			userId:  1,
+			getErr:  errors.New("record not found"),
+			wantErr: true,
+		},
+		{
+			name:   "shared template of another user",
+			userId: 1,
+			getResp: &entity.PromptTemplate{
+				ID:         3,
+				UserID:     2,
+				Visibility: "shared",
+			},
+			wantErr: true,
+		},
+		{
+			name:   "update template error",
+			userId: 1,
+			getResp: &entity.PromptTemplate{
+				ID:     3,
+				UserID: 1,
+			},
+			updateErr: errors.New("update template error"),
+			wantErr:   true,
+		},
+		{
+			name:   "success update template",
+			userId: 1,
+			getResp: &entity.PromptTemplate{
+				ID:     3,
+				UserID: 1,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promptTemplateRepo := new(mocks.PromptTemplateRepository)
+			promptTemplateRepo.On("GetById", mock.Anything, mock.Anything).Return(tt.getResp, tt.getErr).Once()
+			promptTemplateRepo.On("Update", mock.Anything, mock.Anything).Return(tt.updateErr).Once()
+
+			s := NewPromptTemplateUsecase(promptTemplateRepo)
+			_, err := s.UpdatePromptTemplate(ctx, tt.userId, 3, req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultPromptTemplateUsecase.UpdatePromptTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_renderPromptTemplate(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	template := &entity.PromptTemplate{
+		Content: "terjemahkan ke {{bahasa}}: {{teks}} ({{ teks }})",
+		Variables: []entity.PromptTemplateVariable{
+			{Name: "bahasa", DefaultValue: "bahasa formal"},
+			{Name: "teks", Required: true},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		values     map[string]string
+		wantResult string
+		wantErr    bool
+	}{
+		{
+			name:    "required variable missing",
+			values:  map[string]string{"bahasa": "inggris"},
+			wantErr: true,
+		},
+		{
+			name:       "optional variable uses default",
+			values:     map[string]string{"teks": "halo"},
+			wantResult: "terjemahkan ke bahasa formal: halo (halo)",
+		},
+		{
+			name:       "all variables given",
+			values:     map[string]string{"bahasa": "inggris", "teks": "halo"},
+			wantResult: "terjemahkan ke inggris: halo (halo)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotResult, err := renderPromptTemplate(ctx, template, tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("renderPromptTemplate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotResult != tt.wantResult {
+				t.Errorf("renderPromptTemplate() = %v, want %v", gotResult, tt.wantResult)
+			}
+		})
+	}
+}
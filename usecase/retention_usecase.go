@@ -0,0 +1,502 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/cached"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/repository/storage"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+)
+
+// PurgeBatchSize is the number of users, and of messages of a user, purged at
+// a time
+const PurgeBatchSize = 100
+
+type RetentionUsecase interface {
+	CreatePolicy(ctx context.Context, adminId int, req dto.RetentionPolicyRequest) (resp dto.RetentionPolicyResponse, err error)
+	UpdatePolicy(ctx context.Context, adminId, id int, req dto.RetentionPolicyRequest) (resp dto.RetentionPolicyResponse, err error)
+	DeletePolicy(ctx context.Context, adminId, id int) (err error)
+	GetPolicies(ctx context.Context) (resp []dto.RetentionPolicyResponse, err error)
+	StartPurge(ctx context.Context, adminId int, dryRun bool) (resp dto.PurgeReportResponse, err error)
+	GetPurgeReport(ctx context.Context, id int) (resp dto.PurgeReportResponse, err error)
+	SchedulePurge(ctx context.Context)
+}
+
+type defaultRetentionUsecase struct {
+	userRepo       mysql.UserRepository
+	chatRepo       mysql.ChatRepository
+	retentionRepo  mysql.RetentionRepository
+	webhookRepo    mysql.WebhookRepository
+	moderationRepo mysql.ModerationRepository
+	auditRepo      mysql.AuditRepository
+	blobStorage    storage.BlobStorage
+	cacheWrapper   cached.CacheWrapper
+	purging        atomic.Bool
+}
+
+// NewRetentionUsecase creates a new instance of RetentionUsecase
+func NewRetentionUsecase(userRepo mysql.UserRepository, chatRepo mysql.ChatRepository, retentionRepo mysql.RetentionRepository, webhookRepo mysql.WebhookRepository, moderationRepo mysql.ModerationRepository, auditRepo mysql.AuditRepository, blobStorage storage.BlobStorage, cacheWrapper cached.CacheWrapper) RetentionUsecase {
+	return &defaultRetentionUsecase{
+		userRepo:       userRepo,
+		chatRepo:       chatRepo,
+		retentionRepo:  retentionRepo,
+		webhookRepo:    webhookRepo,
+		moderationRepo: moderationRepo,
+		auditRepo:      auditRepo,
+		blobStorage:    blobStorage,
+		cacheWrapper:   cacheWrapper,
+	}
+}
+
+// CreatePolicy adds a policy for a scope that has none yet
+func (s *defaultRetentionUsecase) CreatePolicy(ctx context.Context, adminId int, req dto.RetentionPolicyRequest) (resp dto.RetentionPolicyResponse, err error) {
+	err = s.validatePolicy(ctx, 0, req)
+	if err != nil {
+		return
+	}
+
+	policy := entity.RetentionPolicy{
+		Role:   req.Role,
+		UserID: req.UserId,
+		Days:   req.Days,
+		Action: req.Action,
+	}
+	err = s.retentionRepo.CreatePolicy(ctx, &policy)
+	if err != nil {
+		logger.Error(ctx, "error creating retention policy", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toRetentionPolicyResponse(policy)
+	recordAudit(ctx, s.auditRepo, adminId, constrans.AuditRetentionPolicyCreated, "retention_policy", policy.ID, nil, resp)
+	return
+}
+
+// UpdatePolicy changes the policy given in the id
+func (s *defaultRetentionUsecase) UpdatePolicy(ctx context.Context, adminId, id int, req dto.RetentionPolicyRequest) (resp dto.RetentionPolicyResponse, err error) {
+	policy, err := s.retentionRepo.GetPolicyById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "retention policy not found")
+		err = errors.SetError(http.StatusNotFound, "retention policy not found")
+		return
+	}
+
+	err = s.validatePolicy(ctx, id, req)
+	if err != nil {
+		return
+	}
+
+	before := toRetentionPolicyResponse(*policy)
+	policy.Role = req.Role
+	policy.UserID = req.UserId
+	policy.Days = req.Days
+	policy.Action = req.Action
+	err = s.retentionRepo.UpdatePolicy(ctx, policy)
+	if err != nil {
+		logger.Error(ctx, "error updating retention policy", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toRetentionPolicyResponse(*policy)
+	recordAudit(ctx, s.auditRepo, adminId, constrans.AuditRetentionPolicyUpdated, "retention_policy", policy.ID, before, resp)
+	return
+}
+
+// DeletePolicy removes the policy given in the id
+func (s *defaultRetentionUsecase) DeletePolicy(ctx context.Context, adminId, id int) (err error) {
+	policy, err := s.retentionRepo.GetPolicyById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "retention policy not found")
+		err = errors.SetError(http.StatusNotFound, "retention policy not found")
+		return
+	}
+
+	err = s.retentionRepo.DeletePolicy(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "error deleting retention policy", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	recordAudit(ctx, s.auditRepo, adminId, constrans.AuditRetentionPolicyDeleted, "retention_policy", policy.ID, toRetentionPolicyResponse(*policy), nil)
+	return
+}
+
+// GetPolicies returns all policies
+func (s *defaultRetentionUsecase) GetPolicies(ctx context.Context) (resp []dto.RetentionPolicyResponse, err error) {
+	policies, err := s.retentionRepo.GetPolicies(ctx)
+	if err != nil {
+		logger.Error(ctx, "error getting retention policies", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = []dto.RetentionPolicyResponse{}
+	for i := 0; i < len(policies); i++ {
+		resp = append(resp, toRetentionPolicyResponse(policies[i]))
+	}
+	return
+}
+
+// StartPurge runs the retention policies in the background. A dry run only
+// counts what would be purged.
+func (s *defaultRetentionUsecase) StartPurge(ctx context.Context, adminId int, dryRun bool) (resp dto.PurgeReportResponse, err error) {
+	report, err := s.startPurge(ctx, adminId, dryRun)
+	if err != nil {
+		return
+	}
+
+	resp = toPurgeReportResponse(*report)
+	return
+}
+
+// GetPurgeReport returns the report of a purge
+func (s *defaultRetentionUsecase) GetPurgeReport(ctx context.Context, id int) (resp dto.PurgeReportResponse, err error) {
+	report, err := s.retentionRepo.GetReportById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "purge report not found")
+		err = errors.SetError(http.StatusNotFound, "purge report not found")
+		return
+	}
+
+	resp = toPurgeReportResponse(*report)
+	return
+}
+
+// SchedulePurge runs the retention policies every RETENTION_PURGE_INTERVAL,
+// only counting what would be purged when RETENTION_DRY_RUN is true. Nothing
+// is scheduled when the interval is empty.
+func (s *defaultRetentionUsecase) SchedulePurge(ctx context.Context) {
+	value := os.Getenv("RETENTION_PURGE_INTERVAL")
+	if value == "" {
+		return
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		logger.Error(ctx, "retention purge interval not valid", value)
+		return
+	}
+	dryRun := os.Getenv("RETENTION_DRY_RUN") == "true"
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.startPurge(ctx, 0, dryRun)
+		}
+	}()
+}
+
+// startPurge records a pending report and runs the purge in the background,
+// started by the admin given or by the schedule when adminId is 0. Only one
+// purge runs at a time.
+func (s *defaultRetentionUsecase) startPurge(ctx context.Context, adminId int, dryRun bool) (report *entity.PurgeReport, err error) {
+	if !s.purging.CompareAndSwap(false, true) {
+		logger.Error(ctx, "a purge is already running")
+		err = errors.SetError(http.StatusConflict, "a purge is already running")
+		return
+	}
+
+	report = &entity.PurgeReport{
+		DryRun: dryRun,
+		Status: constrans.JobStatusPending,
+	}
+	err = s.retentionRepo.CreateReport(ctx, report)
+	if err != nil {
+		s.purging.Store(false)
+		logger.Error(ctx, "error creating purge report", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	recordAudit(ctx, s.auditRepo, adminId, constrans.AuditRetentionPurgeStarted, "purge_report", report.ID, nil, map[string]bool{"dryRun": dryRun})
+
+	go func(report entity.PurgeReport) {
+		defer s.purging.Store(false)
+		s.runPurge(context.WithoutCancel(ctx), report)
+	}(*report)
+	return
+}
+
+// runPurge applies the policy of every user, deleted ones included, counting
+// what was purged on the report
+func (s *defaultRetentionUsecase) runPurge(ctx context.Context, report entity.PurgeReport) {
+	fail := func(err error) {
+		logger.Error(ctx, "purge failed", report.ID, err.Error())
+		report.Status = constrans.JobStatusFailed
+		report.Error = err.Error()
+		s.retentionRepo.UpdateReport(ctx, &report)
+	}
+
+	report.Status = constrans.JobStatusRunning
+	s.retentionRepo.UpdateReport(ctx, &report)
+
+	policies, err := s.retentionRepo.GetPolicies(ctx)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	now := time.Now()
+	afterId := 0
+	for {
+		users, err := s.userRepo.FindUnscoped(ctx, afterId, PurgeBatchSize)
+		if err != nil {
+			fail(err)
+			return
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		for i := 0; i < len(users); i++ {
+			afterId = users[i].ID
+			policy, ok := policyForUser(policies, users[i])
+			if !ok {
+				continue
+			}
+
+			report.Users++
+			err = s.purgeUser(ctx, &report, users[i], policy, now)
+			if err != nil {
+				fail(err)
+				return
+			}
+		}
+		s.retentionRepo.UpdateReport(ctx, &report)
+	}
+
+	report.Status = constrans.JobStatusCompleted
+	s.retentionRepo.UpdateReport(ctx, &report)
+}
+
+// purgeUser applies the policy to the messages of the user older than the
+// retention, and to the user when they were deleted before it. All messages of
+// such a user are purged. The webhook deliveries of the events about the user
+// are deleted with the messages, whether they are deleted or anonymized, and
+// the content of the user's moderation flags is emptied.
+func (s *defaultRetentionUsecase) purgeUser(ctx context.Context, report *entity.PurgeReport, user entity.User, policy entity.RetentionPolicy, now time.Time) (err error) {
+	cutoff := now.AddDate(0, 0, -policy.Days)
+	before := cutoff
+	userExpired := user.DeletedAt.Valid && user.DeletedAt.Time.Before(cutoff)
+	if userExpired {
+		before = now
+	}
+	anonymize := policy.Action == constrans.RetentionAnonymize
+
+	var total int
+	if report.DryRun {
+		count, errRes := s.chatRepo.CountExpired(ctx, user.ID, before)
+		if errRes != nil {
+			err = errRes
+			return
+		}
+		total = int(count)
+	} else {
+		total, err = s.purgeChats(ctx, user.ID, before, anonymize)
+		if err != nil {
+			return
+		}
+		// the payloads of the events about the user hold their messages
+		err = s.webhookRepo.DeleteUserDeliveries(ctx, user.ID, before)
+		if err != nil {
+			return
+		}
+		// and the flags of blocked questions hold questions never stored
+		err = s.moderationRepo.RemoveContent(ctx, user.ID, before)
+		if err != nil {
+			return
+		}
+	}
+	if anonymize {
+		report.ChatsAnonymized += total
+	} else {
+		report.ChatsDeleted += total
+	}
+
+	if !userExpired || (anonymize && strings.HasSuffix(user.Email, "@"+constrans.AnonymizedEmailDomain)) {
+		return
+	}
+
+	if anonymize {
+		report.UsersAnonymized++
+		if !report.DryRun {
+			err = s.userRepo.Anonymize(ctx, user.ID)
+		}
+		return
+	}
+
+	report.UsersDeleted++
+	if !report.DryRun {
+		err = s.userRepo.Purge(ctx, user.ID)
+	}
+	return
+}
+
+// purgeChats deletes or anonymizes the messages of the user written before the
+// given time in batches, with their stored files and cached context
+func (s *defaultRetentionUsecase) purgeChats(ctx context.Context, userId int, before time.Time, anonymize bool) (total int, err error) {
+	conversationIds := map[int]bool{}
+	defer func() {
+		for conversationId := range conversationIds {
+			s.cacheWrapper.Delete(ctx, fmt.Sprintf("%v_%v_%v", KeyChatBot, userId, conversationId))
+		}
+		if len(conversationIds) > 0 {
+			s.cacheWrapper.Delete(ctx, fmt.Sprintf("%v_%v", KeyHistory, userId))
+		}
+	}()
+
+	for {
+		chats, errRes := s.chatRepo.FindExpired(ctx, userId, before, PurgeBatchSize)
+		if errRes != nil {
+			err = errRes
+			return
+		}
+		if len(chats) == 0 {
+			return
+		}
+
+		ids := []int{}
+		for i := 0; i < len(chats); i++ {
+			ids = append(ids, chats[i].ID)
+			conversationIds[chats[i].ConversationID] = true
+			for _, attachment := range chats[i].Attachments {
+				errRes := s.blobStorage.Delete(ctx, attachment.StorageKey)
+				if errRes != nil {
+					logger.Error(ctx, "error deleting attachment file", attachment.StorageKey, errRes.Error())
+				}
+			}
+		}
+
+		if anonymize {
+			err = s.chatRepo.AnonymizeChats(ctx, ids)
+		} else {
+			err = s.chatRepo.PurgeChats(ctx, ids)
+		}
+		if err != nil {
+			return
+		}
+		total += len(ids)
+	}
+}
+
+// validatePolicy checks the policy and that no other policy, except the one
+// given in the id, has the same scope
+func (s *defaultRetentionUsecase) validatePolicy(ctx context.Context, id int, req dto.RetentionPolicyRequest) (err error) {
+	if req.Role != "" && req.UserId != 0 {
+		logger.Error(ctx, "retention policy scope not valid")
+		err = errors.SetError(http.StatusBadRequest, "a retention policy is for a role or a user, not both")
+		return
+	}
+
+	if req.Role != "" && req.Role != constrans.RoleAdmin && req.Role != constrans.RoleUser {
+		logger.Error(ctx, "role not valid", req.Role)
+		err = errors.SetError(http.StatusBadRequest, "role not valid")
+		return
+	}
+
+	if req.Days <= 0 {
+		logger.Error(ctx, "retention days not valid")
+		err = errors.SetError(http.StatusBadRequest, "days must be more than 0")
+		return
+	}
+
+	if req.Action != constrans.RetentionDelete && req.Action != constrans.RetentionAnonymize {
+		logger.Error(ctx, "retention action not valid", req.Action)
+		err = errors.SetError(http.StatusBadRequest, "action must be delete or anonymize")
+		return
+	}
+
+	policies, err := s.retentionRepo.GetPolicies(ctx)
+	if err != nil {
+		logger.Error(ctx, "error getting retention policies", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	for _, policy := range policies {
+		if policy.ID != id && policy.Role == req.Role && policy.UserID == req.UserId {
+			logger.Error(ctx, "retention policy already exists", policy.ID)
+			err = errors.SetError(http.StatusConflict, "a retention policy for this scope already exists")
+			return
+		}
+	}
+	return
+}
+
+// policyForUser returns the policy of the user, else the one of their role,
+// else the global one
+func policyForUser(policies []entity.RetentionPolicy, user entity.User) (resp entity.RetentionPolicy, ok bool) {
+	var byRole, global *entity.RetentionPolicy
+	for i := 0; i < len(policies); i++ {
+		switch {
+		case policies[i].UserID != 0:
+			if policies[i].UserID == user.ID {
+				return policies[i], true
+			}
+		case policies[i].Role != "":
+			if policies[i].Role == user.Role {
+				byRole = &policies[i]
+			}
+		default:
+			global = &policies[i]
+		}
+	}
+
+	if byRole != nil {
+		return *byRole, true
+	}
+	if global != nil {
+		return *global, true
+	}
+	return
+}
+
+// toRetentionPolicyResponse converts a retention policy to its dto
+func toRetentionPolicyResponse(policy entity.RetentionPolicy) dto.RetentionPolicyResponse {
+	scope := constrans.RetentionScopeGlobal
+	if policy.UserID != 0 {
+		scope = constrans.RetentionScopeUser
+	} else if policy.Role != "" {
+		scope = constrans.RetentionScopeRole
+	}
+
+	return dto.RetentionPolicyResponse{
+		Id:     policy.ID,
+		Scope:  scope,
+		Role:   policy.Role,
+		UserId: policy.UserID,
+		Days:   policy.Days,
+		Action: policy.Action,
+	}
+}
+
+// toPurgeReportResponse converts a purge report to its dto
+func toPurgeReportResponse(report entity.PurgeReport) dto.PurgeReportResponse {
+	return dto.PurgeReportResponse{
+		Id:              report.ID,
+		DryRun:          report.DryRun,
+		Status:          report.Status,
+		Users:           report.Users,
+		ChatsDeleted:    report.ChatsDeleted,
+		ChatsAnonymized: report.ChatsAnonymized,
+		UsersDeleted:    report.UsersDeleted,
+		UsersAnonymized: report.UsersAnonymized,
+		Error:           report.Error,
+		CreatedAt:       report.CreatedAt,
+		UpdatedAt:       report.UpdatedAt,
+	}
+}
@@ -0,0 +1,224 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func Test_policyForUser(t *testing.T) {
+	global := entity.RetentionPolicy{ID: 1, Days: 365, Action: constrans.RetentionAnonymize}
+	byRole := entity.RetentionPolicy{ID: 2, Role: constrans.RoleUser, Days: 90, Action: constrans.RetentionDelete}
+	byUser := entity.RetentionPolicy{ID: 3, UserID: 7, Days: 30, Action: constrans.RetentionDelete}
+
+	tests := []struct {
+		name     string
+		policies []entity.RetentionPolicy
+		user     entity.User
+		wantId   int
+		wantOk   bool
+	}{
+		{
+			name:     "user override",
+			policies: []entity.RetentionPolicy{global, byRole, byUser},
+			user:     entity.User{ID: 7, Role: constrans.RoleUser},
+			wantId:   3,
+			wantOk:   true,
+		},
+		{
+			name:     "role over global",
+			policies: []entity.RetentionPolicy{global, byRole, byUser},
+			user:     entity.User{ID: 8, Role: constrans.RoleUser},
+			wantId:   2,
+			wantOk:   true,
+		},
+		{
+			name:     "global for other roles",
+			policies: []entity.RetentionPolicy{global, byRole, byUser},
+			user:     entity.User{ID: 9, Role: constrans.RoleAdmin},
+			wantId:   1,
+			wantOk:   true,
+		},
+		{
+			name:     "no policy",
+			policies: []entity.RetentionPolicy{byRole},
+			user:     entity.User{ID: 9, Role: constrans.RoleAdmin},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := policyForUser(tt.policies, tt.user)
+			if ok != tt.wantOk || got.ID != tt.wantId {
+				t.Errorf("policyForUser() = %v, %v, want %v, %v", got.ID, ok, tt.wantId, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_defaultRetentionUsecase_CreatePolicy(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name    string
+		req     dto.RetentionPolicyRequest
+		wantErr bool
+	}{
+		{
+			name: "global policy",
+			req:  dto.RetentionPolicyRequest{Days: 365, Action: constrans.RetentionAnonymize},
+		},
+		{
+			name:    "role and user",
+			req:     dto.RetentionPolicyRequest{Role: constrans.RoleUser, UserId: 7, Days: 30, Action: constrans.RetentionDelete},
+			wantErr: true,
+		},
+		{
+			name:    "unknown role",
+			req:     dto.RetentionPolicyRequest{Role: "guest", Days: 30, Action: constrans.RetentionDelete},
+			wantErr: true,
+		},
+		{
+			name:    "days not valid",
+			req:     dto.RetentionPolicyRequest{Days: 0, Action: constrans.RetentionDelete},
+			wantErr: true,
+		},
+		{
+			name:    "action not valid",
+			req:     dto.RetentionPolicyRequest{Days: 30, Action: "archive"},
+			wantErr: true,
+		},
+		{
+			name:    "scope taken",
+			req:     dto.RetentionPolicyRequest{Role: constrans.RoleUser, Days: 30, Action: constrans.RetentionDelete},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retentionRepo := new(mocks.RetentionRepository)
+			retentionRepo.On("GetPolicies", mock.Anything).Return([]entity.RetentionPolicy{
+				{ID: 2, Role: constrans.RoleUser, Days: 90, Action: constrans.RetentionDelete},
+			}, nil)
+			retentionRepo.On("CreatePolicy", mock.Anything, mock.Anything).Return(nil)
+			auditRepo := new(mocks.AuditRepository)
+			auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			s := NewRetentionUsecase(nil, nil, retentionRepo, nil, nil, auditRepo, nil, nil)
+			gotResp, err := s.CreatePolicy(ctx, 9, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultRetentionUsecase.CreatePolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && gotResp.Scope != constrans.RetentionScopeGlobal {
+				t.Errorf("defaultRetentionUsecase.CreatePolicy() scope = %v, want %v", gotResp.Scope, constrans.RetentionScopeGlobal)
+			}
+		})
+	}
+}
+
+func Test_defaultRetentionUsecase_runPurge(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	deletedAt := gorm.DeletedAt{Time: time.Now().AddDate(0, 0, -60), Valid: true}
+	users := []entity.User{
+		{ID: 1, Role: constrans.RoleUser},
+		{ID: 2, Role: constrans.RoleUser, DeletedAt: deletedAt},
+		{ID: 3, Role: constrans.RoleAdmin},
+	}
+	policies := []entity.RetentionPolicy{
+		{ID: 1, Role: constrans.RoleUser, Days: 30, Action: constrans.RetentionDelete},
+	}
+
+	tests := []struct {
+		name   string
+		dryRun bool
+		want   entity.PurgeReport
+	}{
+		{
+			name:   "dry run only counts",
+			dryRun: true,
+			want:   entity.PurgeReport{DryRun: true, Status: constrans.JobStatusCompleted, Users: 2, ChatsDeleted: 5, UsersDeleted: 1},
+		},
+		{
+			name: "purge",
+			want: entity.PurgeReport{Status: constrans.JobStatusCompleted, Users: 2, ChatsDeleted: 3, UsersDeleted: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			userRepo.On("FindUnscoped", mock.Anything, 0, PurgeBatchSize).Return(users, nil).Once()
+			userRepo.On("FindUnscoped", mock.Anything, 3, PurgeBatchSize).Return([]entity.User{}, nil).Once()
+			userRepo.On("Purge", mock.Anything, 2).Return(nil).Once()
+
+			chatRepo := new(mocks.ChatRepository)
+			chatRepo.On("CountExpired", mock.Anything, 1, mock.Anything).Return(int64(2), nil)
+			chatRepo.On("CountExpired", mock.Anything, 2, mock.Anything).Return(int64(3), nil)
+			chatRepo.On("FindExpired", mock.Anything, 1, mock.Anything, PurgeBatchSize).Return([]entity.Chat{
+				{ID: 10, ConversationID: 4, Attachments: []entity.ChatAttachment{{StorageKey: "1/a.png"}}},
+			}, nil).Once()
+			chatRepo.On("FindExpired", mock.Anything, 2, mock.Anything, PurgeBatchSize).Return([]entity.Chat{
+				{ID: 11, ConversationID: 5}, {ID: 12, ConversationID: 5},
+			}, nil).Once()
+			chatRepo.On("FindExpired", mock.Anything, mock.Anything, mock.Anything, PurgeBatchSize).Return([]entity.Chat{}, nil)
+			chatRepo.On("PurgeChats", mock.Anything, mock.Anything).Return(nil)
+
+			webhookRepo := new(mocks.WebhookRepository)
+			webhookRepo.On("DeleteUserDeliveries", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			moderationRepo := new(mocks.ModerationRepository)
+			moderationRepo.On("RemoveContent", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+			blobStorage := new(mocks.BlobStorage)
+			blobStorage.On("Delete", mock.Anything, "1/a.png").Return(nil)
+
+			cacheWrapper := new(mocks.CacheWrapper)
+			cacheWrapper.On("Delete", mock.Anything, mock.Anything).Return(nil)
+
+			var last entity.PurgeReport
+			retentionRepo := new(mocks.RetentionRepository)
+			retentionRepo.On("GetPolicies", mock.Anything).Return(policies, nil)
+			retentionRepo.On("UpdateReport", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				last = *args.Get(1).(*entity.PurgeReport)
+			}).Return(nil)
+
+			s := &defaultRetentionUsecase{
+				userRepo:       userRepo,
+				chatRepo:       chatRepo,
+				retentionRepo:  retentionRepo,
+				webhookRepo:    webhookRepo,
+				moderationRepo: moderationRepo,
+				blobStorage:    blobStorage,
+				cacheWrapper:   cacheWrapper,
+			}
+			s.runPurge(ctx, entity.PurgeReport{DryRun: tt.dryRun})
+
+			if last != tt.want {
+				t.Errorf("defaultRetentionUsecase.runPurge() report = %+v, want %+v", last, tt.want)
+			}
+			if tt.dryRun {
+				userRepo.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+				chatRepo.AssertNotCalled(t, "PurgeChats", mock.Anything, mock.Anything)
+				webhookRepo.AssertNotCalled(t, "DeleteUserDeliveries", mock.Anything, mock.Anything, mock.Anything)
+				moderationRepo.AssertNotCalled(t, "RemoveContent", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				blobStorage.AssertCalled(t, "Delete", mock.Anything, "1/a.png")
+				webhookRepo.AssertCalled(t, "DeleteUserDeliveries", mock.Anything, 1, mock.Anything)
+				webhookRepo.AssertCalled(t, "DeleteUserDeliveries", mock.Anything, 2, mock.Anything)
+				moderationRepo.AssertCalled(t, "RemoveContent", mock.Anything, 1, mock.Anything)
+				moderationRepo.AssertCalled(t, "RemoveContent", mock.Anything, 2, mock.Anything)
+				cacheWrapper.AssertCalled(t, "Delete", mock.Anything, "ChatBot_2_5")
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// ShareTokenSize is the number of random bytes of a share token
+	ShareTokenSize = 32
+	// MaxSharedMessages is the number of messages shown on a shared conversation
+	MaxSharedMessages = 1000
+
+	// redactedEmail replaces the email of the owner in shared messages
+	redactedEmail = "[email redacted]"
+)
+
+type ShareUsecase interface {
+	CreateShare(ctx context.Context, userId int, req dto.ShareRequest) (resp dto.ShareResponse, err error)
+	GetShares(ctx context.Context, userId int) (resp []dto.ShareResponse, err error)
+	RevokeShare(ctx context.Context, userId, id int) (err error)
+	ViewShare(ctx context.Context, token, password string) (resp dto.SharedConversationResponse, err error)
+}
+
+type defaultShareUsecase struct {
+	userRepo         mysql.UserRepository
+	chatRepo         mysql.ChatRepository
+	conversationRepo mysql.ConversationRepository
+	shareRepo        mysql.ShareRepository
+	auditRepo        mysql.AuditRepository
+}
+
+// NewShareUsecase creates a new instance of ShareUsecase
+func NewShareUsecase(userRepo mysql.UserRepository, chatRepo mysql.ChatRepository, conversationRepo mysql.ConversationRepository, shareRepo mysql.ShareRepository, auditRepo mysql.AuditRepository) ShareUsecase {
+	return &defaultShareUsecase{
+		userRepo:         userRepo,
+		chatRepo:         chatRepo,
+		conversationRepo: conversationRepo,
+		shareRepo:        shareRepo,
+		auditRepo:        auditRepo,
+	}
+}
+
+// CreateShare publishes a snapshot of a conversation of the user. Messages
+// written after the link is created are not shared.
+func (s *defaultShareUsecase) CreateShare(ctx context.Context, userId int, req dto.ShareRequest) (resp dto.ShareResponse, err error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		logger.Error(ctx, "share expiry not valid")
+		err = errors.SetError(http.StatusBadRequest, "expiresAt must be in the future")
+		return
+	}
+
+	conversation, err := s.conversationRepo.GetById(ctx, req.ConversationId)
+	if err != nil || conversation.UserID != userId {
+		logger.Error(ctx, "conversation not found")
+		err = errors.SetError(http.StatusNotFound, "conversation not found")
+		return
+	}
+
+	chats, err := s.chatRepo.GetByConversationId(ctx, conversation.ID)
+	if err != nil {
+		logger.Error(ctx, "error getting chats", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	if len(chats) == 0 {
+		logger.Error(ctx, "conversation has no messages")
+		err = errors.SetError(http.StatusBadRequest, "conversation has no messages to share")
+		return
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		logger.Error(ctx, "error generating share token", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	link := entity.ShareLink{
+		UserID:         userId,
+		ConversationID: conversation.ID,
+		Token:          token,
+		LastChatID:     chats[len(chats)-1].ID,
+		ExpiresAt:      req.ExpiresAt,
+	}
+	if req.Password != "" {
+		link.PasswordHash = hashPassword(req.Password)
+	}
+	err = s.shareRepo.Create(ctx, &link)
+	if err != nil {
+		logger.Error(ctx, "error creating share link", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toShareResponse(link)
+	return
+}
+
+// GetShares returns the share links of the user with their view counters
+func (s *defaultShareUsecase) GetShares(ctx context.Context, userId int) (resp []dto.ShareResponse, err error) {
+	links, err := s.shareRepo.GetByUserId(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting share links", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = []dto.ShareResponse{}
+	for i := 0; i < len(links); i++ {
+		resp = append(resp, toShareResponse(links[i]))
+	}
+	return
+}
+
+// RevokeShare stops a share link of the user from working
+func (s *defaultShareUsecase) RevokeShare(ctx context.Context, userId, id int) (err error) {
+	link, err := s.shareRepo.GetById(ctx, id)
+	if err != nil || link.UserID != userId {
+		logger.Error(ctx, "share link not found")
+		err = errors.SetError(http.StatusNotFound, "share link not found")
+		return
+	}
+
+	err = s.shareRepo.Revoke(ctx, link.ID)
+	if err != nil {
+		logger.Error(ctx, "error revoking share link", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	recordAudit(ctx, s.auditRepo, userId, constrans.AuditShareRevoked, "share", link.ID, map[string]int{"conversationId": link.ConversationID}, nil)
+	return
+}
+
+// ViewShare returns the snapshot of a shared conversation to anyone with the
+// token, and the password when the link has one. Only the name of the owner
+// is shown, and the owner's email is redacted from the messages.
+func (s *defaultShareUsecase) ViewShare(ctx context.Context, token, password string) (resp dto.SharedConversationResponse, err error) {
+	link, err := s.shareRepo.GetByToken(ctx, token)
+	if token == "" || err != nil {
+		logger.Error(ctx, "share link not found")
+		err = errors.SetError(http.StatusNotFound, "shared conversation not found")
+		return
+	}
+
+	if link.RevokedAt != nil {
+		logger.Error(ctx, "share link revoked", link.ID)
+		err = errors.SetError(http.StatusGone, "link has been revoked")
+		return
+	}
+	if link.ExpiresAt != nil && !link.ExpiresAt.After(time.Now()) {
+		logger.Error(ctx, "share link expired", link.ID)
+		err = errors.SetError(http.StatusGone, "link has expired")
+		return
+	}
+
+	if link.PasswordHash != "" {
+		if password == "" {
+			logger.Error(ctx, "share password missing", link.ID)
+			err = errors.SetError(http.StatusUnauthorized, "password is required")
+			return
+		}
+		if comparePassword(link.PasswordHash, password) != nil {
+			logger.Error(ctx, "share password not valid", link.ID)
+			err = errors.SetError(http.StatusUnauthorized, "password not valid")
+			return
+		}
+	}
+
+	conversation, err := s.conversationRepo.GetById(ctx, link.ConversationID)
+	if err != nil {
+		logger.Error(ctx, "shared conversation not found", link.ID)
+		err = errors.SetError(http.StatusNotFound, "shared conversation not found")
+		return
+	}
+
+	owner, err := s.userRepo.GetUserById(ctx, link.UserID)
+	if err != nil {
+		logger.Error(ctx, "owner of shared conversation not found", link.ID)
+		err = errors.SetError(http.StatusNotFound, "shared conversation not found")
+		return
+	}
+
+	chats, err := s.chatRepo.Find(ctx, mysql.ChatFilter{
+		UserId:         link.UserID,
+		ConversationId: link.ConversationID,
+		MaxId:          link.LastChatID,
+		Limit:          MaxSharedMessages,
+	})
+	if err != nil {
+		logger.Error(ctx, "error getting chats", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	err = s.shareRepo.AddView(ctx, link.ID)
+	if err != nil {
+		logger.Error(ctx, "error counting share view", err.Error())
+		err = nil
+	}
+
+	resp = dto.SharedConversationResponse{
+		Title:    conversation.Title,
+		Owner:    owner.Name,
+		SharedAt: link.CreatedAt,
+		Messages: []dto.SharedMessageResponse{},
+	}
+	if resp.Title == "" {
+		resp.Title = fmt.Sprintf("Conversation %v", conversation.ID)
+	}
+
+	email := regexp.MustCompile("(?i)" + regexp.QuoteMeta(owner.Email))
+	for i := 0; i < len(chats); i++ {
+		message := dto.SharedMessageResponse{
+			Role:      openai.ChatMessageRoleUser,
+			Name:      chats[i].Name,
+			Message:   chats[i].Message,
+			CreatedAt: chats[i].CreatedAt,
+		}
+		if chats[i].Name == BotName {
+			message.Role = openai.ChatMessageRoleAssistant
+			message.Model = chats[i].Model
+		}
+		if owner.Email != "" {
+			message.Name = email.ReplaceAllLiteralString(message.Name, redactedEmail)
+			message.Message = email.ReplaceAllLiteralString(message.Message, redactedEmail)
+		}
+		resp.Messages = append(resp.Messages, message)
+	}
+	return
+}
+
+// newShareToken returns a random url safe token
+func newShareToken() (token string, err error) {
+	data := make([]byte, ShareTokenSize)
+	_, err = rand.Read(data)
+	if err != nil {
+		return
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(data)
+	return
+}
+
+// toShareResponse converts a share link to its dto
+func toShareResponse(link entity.ShareLink) dto.ShareResponse {
+	return dto.ShareResponse{
+		Id:             link.ID,
+		ConversationId: link.ConversationID,
+		Token:          link.Token,
+		Url:            "/shared?token=" + link.Token,
+		Protected:      link.PasswordHash != "",
+		ExpiresAt:      link.ExpiresAt,
+		Revoked:        link.RevokedAt != nil,
+		Views:          link.Views,
+		LastViewedAt:   link.LastViewedAt,
+		CreatedAt:      link.CreatedAt,
+	}
+}
@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_defaultShareUsecase_CreateShare(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		req         dto.ShareRequest
+		getConvResp *entity.Conversation
+		getChatResp []entity.Chat
+		wantErr     bool
+	}{
+		{
+			name:    "expiry in the past",
+			req:     dto.ShareRequest{ConversationId: 2, ExpiresAt: &past},
+			wantErr: true,
+		},
+		{
+			name:        "conversation of another user",
+			req:         dto.ShareRequest{ConversationId: 2},
+			getConvResp: &entity.Conversation{ID: 2, UserID: 3},
+			wantErr:     true,
+		},
+		{
+			name:        "conversation without messages",
+			req:         dto.ShareRequest{ConversationId: 2},
+			getConvResp: &entity.Conversation{ID: 2, UserID: 1},
+			wantErr:     true,
+		},
+		{
+			name:        "success with password and expiry",
+			req:         dto.ShareRequest{ConversationId: 2, Password: "rahasia", ExpiresAt: &future},
+			getConvResp: &entity.Conversation{ID: 2, UserID: 1},
+			getChatResp: []entity.Chat{{ID: 7}, {ID: 8}},
+			wantErr:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			shareRepo := new(mocks.ShareRepository)
+
+			var gotLink entity.ShareLink
+			conversationRepo.On("GetById", mock.Anything, tt.req.ConversationId).Return(tt.getConvResp, nil).Once()
+			chatRepo.On("GetByConversationId", mock.Anything, tt.req.ConversationId).Return(tt.getChatResp, nil).Once()
+			shareRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				gotLink = *args.Get(1).(*entity.ShareLink)
+			}).Return(nil).Once()
+
+			s := NewShareUsecase(userRepo, chatRepo, conversationRepo, shareRepo, new(mocks.AuditRepository))
+			gotResp, err := s.CreateShare(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultShareUsecase.CreateShare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotLink.LastChatID != 8 || len(gotLink.Token) < ShareTokenSize || comparePassword(gotLink.PasswordHash, tt.req.Password) != nil {
+				t.Errorf("defaultShareUsecase.CreateShare() link = %+v", gotLink)
+			}
+			if !gotResp.Protected || gotResp.Url != "/shared?token="+gotLink.Token {
+				t.Errorf("defaultShareUsecase.CreateShare() = %+v", gotResp)
+			}
+		})
+	}
+}
+
+func Test_defaultShareUsecase_ViewShare(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	at := time.Date(2024, 1, 18, 10, 11, 41, 0, time.Local)
+	past := time.Now().Add(-time.Hour)
+	owner := &entity.User{ID: 1, Name: "fadilah", Email: "fadilah@example.com"}
+	chats := []entity.Chat{
+		{ID: 7, Name: "fadilah", Message: "kirim ke Fadilah@Example.com ya", CreatedAt: at},
+		{ID: 8, Name: BotName, Model: "gpt-4", Message: "baik", CreatedAt: at},
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		password    string
+		getLinkResp *entity.ShareLink
+		getLinkErr  error
+		wantResp    dto.SharedConversationResponse
+		wantErr     bool
+	}{
+		{
+			name:       "token not found",
+			token:      "abc",
+			getLinkErr: errors.New("record not found"),
+			wantErr:    true,
+		},
+		{
+			name:        "link revoked",
+			token:       "abc",
+			getLinkResp: &entity.ShareLink{ID: 1, UserID: 1, RevokedAt: &past},
+			wantErr:     true,
+		},
+		{
+			name:        "link expired",
+			token:       "abc",
+			getLinkResp: &entity.ShareLink{ID: 1, UserID: 1, ExpiresAt: &past},
+			wantErr:     true,
+		},
+		{
+			name:        "password missing",
+			token:       "abc",
+			getLinkResp: &entity.ShareLink{ID: 1, UserID: 1, PasswordHash: hashPassword("rahasia")},
+			wantErr:     true,
+		},
+		{
+			name:        "password not valid",
+			token:       "abc",
+			password:    "salah",
+			getLinkResp: &entity.ShareLink{ID: 1, UserID: 1, PasswordHash: hashPassword("rahasia")},
+			wantErr:     true,
+		},
+		{
+			name:        "success with the email redacted",
+			token:       "abc",
+			password:    "rahasia",
+			getLinkResp: &entity.ShareLink{ID: 1, UserID: 1, ConversationID: 2, LastChatID: 8, PasswordHash: hashPassword("rahasia"), CreatedAt: at},
+			wantResp: dto.SharedConversationResponse{
+				Title:    "Belajar Go",
+				Owner:    "fadilah",
+				SharedAt: at,
+				Messages: []dto.SharedMessageResponse{
+					{Role: "user", Name: "fadilah", Message: "kirim ke [email redacted] ya", CreatedAt: at},
+					{Role: "assistant", Name: BotName, Model: "gpt-4", Message: "baik", CreatedAt: at},
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			chatRepo := new(mocks.ChatRepository)
+			conversationRepo := new(mocks.ConversationRepository)
+			shareRepo := new(mocks.ShareRepository)
+
+			shareRepo.On("GetByToken", mock.Anything, tt.token).Return(tt.getLinkResp, tt.getLinkErr).Once()
+			conversationRepo.On("GetById", mock.Anything, 2).Return(&entity.Conversation{ID: 2, UserID: 1, Title: "Belajar Go"}, nil).Once()
+			userRepo.On("GetUserById", mock.Anything, 1).Return(owner, nil).Once()
+			chatRepo.On("Find", mock.Anything, mysql.ChatFilter{UserId: 1, ConversationId: 2, MaxId: 8, Limit: MaxSharedMessages}).Return(chats, nil).Once()
+			shareRepo.On("AddView", mock.Anything, 1).Return(nil).Once()
+
+			s := NewShareUsecase(userRepo, chatRepo, conversationRepo, shareRepo, new(mocks.AuditRepository))
+			gotResp, err := s.ViewShare(ctx, tt.token, tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("defaultShareUsecase.ViewShare() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				shareRepo.AssertNotCalled(t, "AddView", mock.Anything, mock.Anything)
+				return
+			}
+			if !reflect.DeepEqual(gotResp, tt.wantResp) {
+				t.Errorf("defaultShareUsecase.ViewShare() = %+v, want %+v", gotResp, tt.wantResp)
+			}
+			shareRepo.AssertCalled(t, "AddView", mock.Anything, 1)
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+)
+
+type TeamUsecase interface {
+	CreateTeam(ctx context.Context, req dto.TeamRequest) (resp dto.TeamResponse, err error)
+	UpdateTeam(ctx context.Context, id int, req dto.TeamRequest) (resp dto.TeamResponse, err error)
+	DeleteTeam(ctx context.Context, id int) (err error)
+	GetTeams(ctx context.Context) (resp []dto.TeamResponse, err error)
+	GetUserTeams(ctx context.Context, userId int) (resp []dto.TeamResponse, err error)
+}
+
+type defaultTeamUsecase struct {
+	teamRepo mysql.TeamRepository
+}
+
+// NewTeamUsecase creates a new instance of TeamUsecase
+func NewTeamUsecase(teamRepo mysql.TeamRepository) TeamUsecase {
+	return &defaultTeamUsecase{
+		teamRepo: teamRepo,
+	}
+}
+
+// CreateTeam creates a team with its members
+func (s *defaultTeamUsecase) CreateTeam(ctx context.Context, req dto.TeamRequest) (resp dto.TeamResponse, err error) {
+	err = validateTeam(ctx, req)
+	if err != nil {
+		return
+	}
+
+	team := entity.Team{}
+	applyTeamRequest(&team, req)
+	err = s.teamRepo.Create(ctx, &team)
+	if err != nil {
+		logger.Error(ctx, "error creating team", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toTeamResponse(team)
+	return
+}
+
+// UpdateTeam renames a team and replaces its members
+func (s *defaultTeamUsecase) UpdateTeam(ctx context.Context, id int, req dto.TeamRequest) (resp dto.TeamResponse, err error) {
+	err = validateTeam(ctx, req)
+	if err != nil {
+		return
+	}
+
+	team, err := s.teamRepo.GetById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "team not found", err.Error())
+		err = errors.SetError(http.StatusNotFound, "team not found")
+		return
+	}
+
+	applyTeamRequest(team, req)
+	err = s.teamRepo.Update(ctx, team)
+	if err != nil {
+		logger.Error(ctx, "error updating team", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toTeamResponse(*team)
+	return
+}
+
+// DeleteTeam deletes a team
+func (s *defaultTeamUsecase) DeleteTeam(ctx context.Context, id int) (err error) {
+	_, err = s.teamRepo.GetById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "team not found", err.Error())
+		err = errors.SetError(http.StatusNotFound, "team not found")
+		return
+	}
+
+	err = s.teamRepo.Delete(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "error deleting team", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	return
+}
+
+// GetTeams returns all teams
+func (s *defaultTeamUsecase) GetTeams(ctx context.Context) (resp []dto.TeamResponse, err error) {
+	teams, err := s.teamRepo.GetAll(ctx)
+	if err != nil {
+		logger.Error(ctx, "error getting teams", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(teams); i++ {
+		resp = append(resp, toTeamResponse(teams[i]))
+	}
+	return
+}
+
+// GetUserTeams returns the teams the user is a member of
+func (s *defaultTeamUsecase) GetUserTeams(ctx context.Context, userId int) (resp []dto.TeamResponse, err error) {
+	teams, err := s.teamRepo.GetByUserId(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting teams", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	for i := 0; i < len(teams); i++ {
+		resp = append(resp, toTeamResponse(teams[i]))
+	}
+	return
+}
+
+// validateTeam checks the team request
+func validateTeam(ctx context.Context, req dto.TeamRequest) (err error) {
+	if strings.TrimSpace(req.Name) == "" {
+		logger.Error(ctx, "team name is required")
+		err = errors.SetError(http.StatusBadRequest, "team name is required")
+		return
+	}
+	return
+}
+
+// applyTeamRequest copies the request fields into the team
+func applyTeamRequest(team *entity.Team, req dto.TeamRequest) {
+	team.Name = req.Name
+	team.Members = nil
+	for i := 0; i < len(req.MemberIds); i++ {
+		team.Members = append(team.Members, entity.TeamMember{
+			TeamID: team.ID,
+			UserID: req.MemberIds[i],
+		})
+	}
+}
+
+// toTeamResponse converts a team to its dto
+func toTeamResponse(team entity.Team) dto.TeamResponse {
+	resp := dto.TeamResponse{
+		Id:   team.ID,
+		Name: team.Name,
+	}
+	for i := 0; i < len(team.Members); i++ {
+		resp.MemberIds = append(resp.MemberIds, team.Members[i].UserID)
+	}
+	return resp
+}
+
+// isTeamMember reports whether the user is a member of the team
+func isTeamMember(team *entity.Team, userId int) bool {
+	for i := 0; i < len(team.Members); i++ {
+		if team.Members[i].UserID == userId {
+			return true
+		}
+	}
+	return false
+}
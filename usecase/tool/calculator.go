@@ -0,0 +1,222 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+type calculatorTool struct{}
+
+type calculatorArguments struct {
+	Expression string `json:"expression"`
+}
+
+// NewCalculatorTool creates a tool that evaluates arithmetic expressions
+func NewCalculatorTool() Tool {
+	return &calculatorTool{}
+}
+
+func (t *calculatorTool) Name() string {
+	return "calculate"
+}
+
+func (t *calculatorTool) Description() string {
+	return "Evaluate an arithmetic expression with + - * / % ^ and parentheses."
+}
+
+func (t *calculatorTool) Parameters() any {
+	return jsonschema.Definition{
+		Type: jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{
+			"expression": {
+				Type:        jsonschema.String,
+				Description: "The expression to evaluate, e.g. (12.5 + 3) * 4",
+			},
+		},
+		Required: []string{"expression"},
+	}
+}
+
+func (t *calculatorTool) Execute(ctx context.Context, userId int, arguments string) (result string, err error) {
+	var args calculatorArguments
+	err = json.Unmarshal([]byte(arguments), &args)
+	if err != nil {
+		return
+	}
+
+	value, err := Evaluate(args.Expression)
+	if err != nil {
+		return
+	}
+
+	result = strconv.FormatFloat(value, 'f', -1, 64)
+	return
+}
+
+// Evaluate calculates the value of an arithmetic expression
+func Evaluate(expression string) (value float64, err error) {
+	p := &expressionParser{input: strings.TrimSpace(expression)}
+	if p.input == "" {
+		err = fmt.Errorf("expression is empty")
+		return
+	}
+
+	result, err := p.parseExpression()
+	if err != nil {
+		return
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		err = fmt.Errorf("unexpected %q at position %v", p.input[p.pos], p.pos)
+		return
+	}
+
+	if math.IsInf(result, 0) || math.IsNaN(result) {
+		err = fmt.Errorf("result is not a number")
+		return
+	}
+
+	value = result
+	return
+}
+
+// expressionParser is a recursive descent parser for
+//
+//	expression = term { ("+" | "-") term }
+//	term       = power { ("*" | "/" | "%") power }
+//	power      = unary [ "^" power ]
+//	unary      = ("+" | "-") unary | primary
+//	primary    = number | "(" expression ")"
+type expressionParser struct {
+	input string
+	pos   int
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *expressionParser) peek() byte {
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *expressionParser) parseExpression() (value float64, err error) {
+	value, err = p.parseTerm()
+	for err == nil {
+		operator := p.peek()
+		if operator != '+' && operator != '-' {
+			return
+		}
+		p.pos++
+
+		var right float64
+		right, err = p.parseTerm()
+		if operator == '+' {
+			value += right
+		} else {
+			value -= right
+		}
+	}
+	return
+}
+
+func (p *expressionParser) parseTerm() (value float64, err error) {
+	value, err = p.parsePower()
+	for err == nil {
+		operator := p.peek()
+		if operator != '*' && operator != '/' && operator != '%' {
+			return
+		}
+		p.pos++
+
+		var right float64
+		right, err = p.parsePower()
+		if err != nil {
+			return
+		}
+
+		switch operator {
+		case '*':
+			value *= right
+		case '/', '%':
+			if right == 0 {
+				err = fmt.Errorf("division by zero")
+				return
+			}
+			if operator == '/' {
+				value /= right
+			} else {
+				value = math.Mod(value, right)
+			}
+		}
+	}
+	return
+}
+
+func (p *expressionParser) parsePower() (value float64, err error) {
+	value, err = p.parseUnary()
+	if err != nil || p.peek() != '^' {
+		return
+	}
+	p.pos++
+
+	exponent, err := p.parsePower()
+	value = math.Pow(value, exponent)
+	return
+}
+
+func (p *expressionParser) parseUnary() (value float64, err error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		value, err = p.parseUnary()
+		value = -value
+		return
+	case '+':
+		p.pos++
+		return p.parseUnary()
+	}
+	return p.parsePrimary()
+}
+
+func (p *expressionParser) parsePrimary() (value float64, err error) {
+	if p.peek() == '(' {
+		p.pos++
+		value, err = p.parseExpression()
+		if err != nil {
+			return
+		}
+		if p.peek() != ')' {
+			err = fmt.Errorf("missing closing parenthesis")
+			return
+		}
+		p.pos++
+		return
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && (unicode.IsDigit(rune(p.input[p.pos])) || p.input[p.pos] == '.') {
+		p.pos++
+	}
+	if start == p.pos {
+		err = fmt.Errorf("expected a number at position %v", start)
+		return
+	}
+
+	value, err = strconv.ParseFloat(p.input[start:p.pos], 64)
+	return
+}
@@ -0,0 +1,36 @@
+package tool
+
+import (
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		wantValue  float64
+		wantErr    bool
+	}{
+		{name: "precedence", expression: "2 + 3 * 4", wantValue: 14},
+		{name: "parentheses", expression: "(2 + 3) * 4", wantValue: 20},
+		{name: "unary minus", expression: "-3 + -(2 * 2)", wantValue: -7},
+		{name: "power is right associative", expression: "2 ^ 3 ^ 2", wantValue: 512},
+		{name: "decimals and modulo", expression: "7.5 % 2", wantValue: 1.5},
+		{name: "division by zero", expression: "1 / 0", wantErr: true},
+		{name: "missing parenthesis", expression: "(1 + 2", wantErr: true},
+		{name: "trailing garbage", expression: "1 + 2 abc", wantErr: true},
+		{name: "empty", expression: " ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotValue, err := Evaluate(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Evaluate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if gotValue != tt.wantValue {
+				t.Errorf("Evaluate() = %v, want %v", gotValue, tt.wantValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+type chatHistoryTool struct {
+	chatRepo mysql.ChatRepository
+}
+
+type chatHistoryArguments struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+// NewChatHistoryTool creates a tool that searches the past chats of the user
+func NewChatHistoryTool(chatRepo mysql.ChatRepository) Tool {
+	return &chatHistoryTool{
+		chatRepo: chatRepo,
+	}
+}
+
+func (t *chatHistoryTool) Name() string {
+	return "search_past_chats"
+}
+
+func (t *chatHistoryTool) Description() string {
+	return "Search the user's earlier chat messages, across all conversations, for a keyword."
+}
+
+func (t *chatHistoryTool) Parameters() any {
+	return jsonschema.Definition{
+		Type: jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{
+			"query": {
+				Type:        jsonschema.String,
+				Description: "Keyword or phrase to look for",
+			},
+			"limit": {
+				Type:        jsonschema.Integer,
+				Description: "Maximum number of messages to return, at most 20. Defaults to 5.",
+			},
+		},
+		Required: []string{"query"},
+	}
+}
+
+func (t *chatHistoryTool) Execute(ctx context.Context, userId int, arguments string) (result string, err error) {
+	var args chatHistoryArguments
+	err = json.Unmarshal([]byte(arguments), &args)
+	if err != nil {
+		return
+	}
+
+	if strings.TrimSpace(args.Query) == "" {
+		err = fmt.Errorf("query is empty")
+		return
+	}
+	if args.Limit <= 0 {
+		args.Limit = 5
+	}
+	if args.Limit > 20 {
+		args.Limit = 20
+	}
+
+	chats, err := t.chatRepo.SearchByUserId(ctx, userId, args.Query, args.Limit)
+	if err != nil {
+		return
+	}
+
+	if len(chats) == 0 {
+		result = "no past messages found"
+		return
+	}
+
+	var builder strings.Builder
+	for i := 0; i < len(chats); i++ {
+		fmt.Fprintf(&builder, "[%v] %v: %v\n", chats[i].CreatedAt.Format("2006-01-02 15:04"), chats[i].Name, chats[i].Message)
+	}
+	result = builder.String()
+	return
+}
@@ -0,0 +1,65 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sashabaranov/go-openai/jsonschema"
+)
+
+type dateTimeTool struct{}
+
+type dateTimeArguments struct {
+	Timezone string `json:"timezone"`
+}
+
+// NewDateTimeTool creates a tool that tells the current date and time
+func NewDateTimeTool() Tool {
+	return &dateTimeTool{}
+}
+
+func (t *dateTimeTool) Name() string {
+	return "get_current_datetime"
+}
+
+func (t *dateTimeTool) Description() string {
+	return "Get the current date, time and day of the week."
+}
+
+func (t *dateTimeTool) Parameters() any {
+	return jsonschema.Definition{
+		Type: jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{
+			"timezone": {
+				Type:        jsonschema.String,
+				Description: "IANA timezone, e.g. Asia/Jakarta. Defaults to Asia/Jakarta.",
+			},
+		},
+	}
+}
+
+func (t *dateTimeTool) Execute(ctx context.Context, userId int, arguments string) (result string, err error) {
+	var args dateTimeArguments
+	if arguments != "" {
+		err = json.Unmarshal([]byte(arguments), &args)
+		if err != nil {
+			return
+		}
+	}
+
+	if args.Timezone == "" {
+		args.Timezone = "Asia/Jakarta"
+	}
+
+	location, err := time.LoadLocation(args.Timezone)
+	if err != nil {
+		err = fmt.Errorf("unknown timezone %v", args.Timezone)
+		return
+	}
+
+	now := time.Now().In(location)
+	result = fmt.Sprintf("%v (%v, %v)", now.Format(time.RFC3339), now.Weekday(), args.Timezone)
+	return
+}
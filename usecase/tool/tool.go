@@ -0,0 +1,70 @@
+package tool
+
+import (
+	"context"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Tool is a server side function the model can ask to call while answering.
+type Tool interface {
+	// Name is the function name the model uses to call the tool.
+	Name() string
+	// Description tells the model when to use the tool.
+	Description() string
+	// Parameters is the JSON schema of the arguments the tool expects.
+	Parameters() any
+	// Execute runs the tool for the user with the JSON encoded arguments
+	// from the model and returns the result handed back to the model.
+	Execute(ctx context.Context, userId int, arguments string) (result string, err error)
+}
+
+// Registry holds the tools offered to the model.
+type Registry struct {
+	tools []Tool
+}
+
+// NewRegistry creates a registry with the given tools
+func NewRegistry(tools ...Tool) *Registry {
+	registry := &Registry{}
+	for i := 0; i < len(tools); i++ {
+		registry.Register(tools[i])
+	}
+	return registry
+}
+
+// Register adds a tool, replacing a registered tool with the same name
+func (r *Registry) Register(tool Tool) {
+	for i := 0; i < len(r.tools); i++ {
+		if r.tools[i].Name() == tool.Name() {
+			r.tools[i] = tool
+			return
+		}
+	}
+	r.tools = append(r.tools, tool)
+}
+
+// Get returns the tool with the given name
+func (r *Registry) Get(name string) (tool Tool, ok bool) {
+	for i := 0; i < len(r.tools); i++ {
+		if r.tools[i].Name() == name {
+			return r.tools[i], true
+		}
+	}
+	return nil, false
+}
+
+// Definitions returns the tools in the format of the chat completion request
+func (r *Registry) Definitions() (resp []openai.Tool) {
+	for i := 0; i < len(r.tools); i++ {
+		resp = append(resp, openai.Tool{
+			Type: openai.ToolTypeFunction,
+			Function: openai.FunctionDefinition{
+				Name:        r.tools[i].Name(),
+				Description: r.tools[i].Description(),
+				Parameters:  r.tools[i].Parameters(),
+			},
+		})
+	}
+	return
+}
@@ -19,18 +19,29 @@ import (
 type UserUsecase interface {
 	Register(ctx context.Context, req dto.RegisterRequest) (err error)
 	Login(ctx context.Context, req dto.LoginRequest) (resp dto.LoginResponse, err error)
+	PromoteAdmin(ctx context.Context, email string) (err error)
 }
 
 type defaultUserUsecase struct {
-	userRepo mysql.UserRepository
+	userRepo       mysql.UserRepository
+	auditRepo      mysql.AuditRepository
+	eventPublisher EventPublisher
 }
 
-func NewUserUsecase(userRepo mysql.UserRepository) UserUsecase {
+func NewUserUsecase(userRepo mysql.UserRepository, auditRepo mysql.AuditRepository, eventPublisher EventPublisher) UserUsecase {
 	return &defaultUserUsecase{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		auditRepo:      auditRepo,
+		eventPublisher: eventPublisher,
 	}
 }
 
+// loginAttempt is the audit summary of a login
+type loginAttempt struct {
+	Email  string `json:"email"`
+	Reason string `json:"reason,omitempty"`
+}
+
 // Register registers a new user.
 //
 // If the email is already registered, returns an error.
@@ -59,6 +70,7 @@ func (s *defaultUserUsecase) Register(ctx context.Context, req dto.RegisterReque
 		Email:    req.Email,
 		Password: hashPassword(req.Password),
 		Name:     req.Name,
+		Role:     constrans.RoleUser,
 	}
 	err = s.userRepo.Create(ctx, &createUser)
 	if err != nil {
@@ -66,6 +78,18 @@ func (s *defaultUserUsecase) Register(ctx context.Context, req dto.RegisterReque
 		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 		return
 	}
+
+	recordAudit(ctx, s.auditRepo, createUser.ID, constrans.AuditUserRegistered, "user", createUser.ID, nil, map[string]string{
+		"email": createUser.Email,
+		"role":  createUser.Role,
+	})
+	s.eventPublisher.Publish(ctx, createUser.ID, constrans.EventUserRegistered, dto.UserEvent{
+		Id:        createUser.ID,
+		Email:     createUser.Email,
+		Name:      createUser.Name,
+		Role:      createUser.Role,
+		CreatedAt: createUser.CreatedAt,
+	})
 	return
 }
 
@@ -89,6 +113,7 @@ func (s *defaultUserUsecase) Login(ctx context.Context, req dto.LoginRequest) (r
 	userData, err := s.userRepo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		logger.Error(ctx, "error getting user", err.Error())
+		recordAudit(ctx, s.auditRepo, 0, constrans.AuditUserLoginFailed, "user", 0, nil, loginAttempt{Email: req.Email, Reason: "user not found"})
 		err = errors.SetError(http.StatusBadRequest, "user not found")
 		return
 	}
@@ -97,27 +122,52 @@ func (s *defaultUserUsecase) Login(ctx context.Context, req dto.LoginRequest) (r
 	err = comparePassword(userData.Password, req.Password)
 	if err != nil {
 		logger.Error(ctx, "password not valid")
+		recordAudit(ctx, s.auditRepo, 0, constrans.AuditUserLoginFailed, "user", userData.ID, nil, loginAttempt{Email: req.Email, Reason: "password not valid"})
 		err = errors.SetError(http.StatusBadRequest, "password not valid")
 		return
 	}
 
 	// Create a JSON Web Token and return it to the user.
-	token := createToken(userData.ID)
+	token := createToken(userData.ID, userData.Role)
 	resp = dto.LoginResponse{
 		Id:          userData.ID,
 		Name:        userData.Name,
 		Email:       userData.Email,
 		AccessToken: token,
 	}
+	recordAudit(ctx, s.auditRepo, userData.ID, constrans.AuditUserLogin, "user", userData.ID, nil, loginAttempt{Email: userData.Email})
+
+	return
+}
+
+// PromoteAdmin gives the admin role to the registered user with the email.
+// It is run by an operator from the command line, so registering never
+// grants the role. The user gets it on their next login.
+func (s *defaultUserUsecase) PromoteAdmin(ctx context.Context, email string) (err error) {
+	userData, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		logger.Error(ctx, "error getting user", err.Error())
+		err = errors.SetError(http.StatusNotFound, "user not found")
+		return
+	}
+
+	err = s.userRepo.UpdateRole(ctx, userData.ID, constrans.RoleAdmin)
+	if err != nil {
+		logger.Error(ctx, "error updating user role", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
 
+	recordAudit(ctx, s.auditRepo, 0, constrans.AuditUserPromoted, "user", userData.ID, map[string]string{"role": userData.Role}, map[string]string{"role": constrans.RoleAdmin})
 	return
 }
 
-// createToken creates a JSON Web Token (JWT) with the given user ID.
-func createToken(userId int) string {
+// createToken creates a JSON Web Token (JWT) with the given user ID and role.
+func createToken(userId int, role string) string {
 	// Create the claims for the token.
 	claims := jwt.MapClaims{
 		"userId": userId,
+		"role":   role,
 		"exp":    time.Now().Add(time.Hour * 24).Unix(),
 	}
 
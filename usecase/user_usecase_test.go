@@ -9,8 +9,10 @@ import (
 	"github.com/fadilahonespot/chatbot/entity"
 	"github.com/fadilahonespot/chatbot/mocks"
 	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
 	"github.com/fadilahonespot/chatbot/utils/logger"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 func Test_defaultUserUsecase_Register(t *testing.T) {
@@ -81,8 +83,10 @@ func Test_defaultUserUsecase_Register(t *testing.T) {
 			userRepo := new(mocks.UserRepository)
 			userRepo.On("GetUserByEmail", mock.Anything, mock.Anything).Return(tt.getUserResp, tt.getUserErr).Once()
 			userRepo.On("Create", mock.Anything, mock.Anything).Return(tt.createUserErr).Once()
+			auditRepo := new(mocks.AuditRepository)
+			auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
 
-			s := NewUserUsecase(userRepo)
+			s := NewUserUsecase(userRepo, auditRepo, newEventPublisher())
 			if err := s.Register(tt.args.ctx, tt.args.req); (err != nil) != tt.wantErr {
 				t.Errorf("defaultUserUsecase.Register() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -174,13 +178,21 @@ func Test_defaultUserUsecase_Login(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			userRepo := new(mocks.UserRepository)
 			userRepo.On("GetUserByEmail", mock.Anything, mock.Anything).Return(tt.getUserResp, tt.getUserErr).Once()
+			var gotAudit entity.AuditLog
+			auditRepo := new(mocks.AuditRepository)
+			auditRepo.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				gotAudit = *args.Get(1).(*entity.AuditLog)
+			}).Return(nil)
 
-			s := NewUserUsecase(userRepo)
+			s := NewUserUsecase(userRepo, auditRepo, newEventPublisher())
 			gotResp, err := s.Login(tt.args.ctx, tt.args.req)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("defaultUserUsecase.Login() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
+			if !tt.wantErr && (gotAudit.Action != constrans.AuditUserLogin || gotAudit.ActorID != tt.wantResp.Id) {
+				t.Errorf("defaultUserUsecase.Login() audit = %+v", gotAudit)
+			}
 			if !reflect.DeepEqual(gotResp.Email, tt.wantResp.Email) {
 				t.Errorf("defaultUserUsecase.Login() = %v, want %v", gotResp.Email, tt.wantResp.Email)
 			}
@@ -193,3 +205,66 @@ func Test_defaultUserUsecase_Login(t *testing.T) {
 		})
 	}
 }
+
+func Test_defaultUserUsecase_Register_role(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	userRepo := new(mocks.UserRepository)
+	userRepo.On("GetUserByEmail", mock.Anything, "admin@example.com").Return(&entity.User{}, nil)
+	userRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+	auditRepo := new(mocks.AuditRepository)
+	auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	s := NewUserUsecase(userRepo, auditRepo, newEventPublisher())
+	err := s.Register(ctx, dto.RegisterRequest{Email: "admin@example.com", Password: "rahasia", Name: "Budi"})
+	if err != nil {
+		t.Fatalf("defaultUserUsecase.Register() error = %v", err)
+	}
+	// registering never grants the admin role, whatever the email
+	userRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return user.Role == constrans.RoleUser
+	}))
+}
+
+func Test_defaultUserUsecase_PromoteAdmin(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name       string
+		getUserErr error
+		wantErr    bool
+	}{
+		{
+			name: "promoted",
+		},
+		{
+			name:       "user not found",
+			getUserErr: gorm.ErrRecordNotFound,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			userRepo.On("GetUserByEmail", mock.Anything, "admin@example.com").Return(&entity.User{ID: 3, Role: constrans.RoleUser}, tt.getUserErr)
+			userRepo.On("UpdateRole", mock.Anything, 3, constrans.RoleAdmin).Return(nil)
+			auditRepo := new(mocks.AuditRepository)
+			auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			s := NewUserUsecase(userRepo, auditRepo, newEventPublisher())
+			err := s.PromoteAdmin(ctx, "admin@example.com")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("defaultUserUsecase.PromoteAdmin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				userRepo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			auditRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(log *entity.AuditLog) bool {
+				return log.Action == constrans.AuditUserPromoted && log.TargetID == 3
+			}))
+		})
+	}
+}
@@ -0,0 +1,507 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/repository/http/webhook"
+	"github.com/fadilahonespot/chatbot/repository/mysql"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/fadilahonespot/library/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	// WebhookPollInterval is how often the due deliveries are looked up, and
+	// WebhookBatchSize how many of them are sent at a time
+	WebhookPollInterval = 5 * time.Second
+	WebhookBatchSize    = 20
+
+	// WebhookLease is how long a claimed delivery is kept from the other
+	// instances while it is sent
+	WebhookLease = time.Minute
+
+	// WebhookMaxAttempts is the number of attempts before a delivery is given
+	// up. The wait after a failed attempt starts at WebhookBackoff and doubles
+	// after every attempt, up to WebhookMaxBackoff.
+	WebhookMaxAttempts = 8
+	WebhookBackoff     = 30 * time.Second
+	WebhookMaxBackoff  = time.Hour
+
+	// WebhookSecretSize is the number of random bytes of a signing secret
+	WebhookSecretSize = 32
+
+	// WebhookDeliveryRetention is how long the deliveries that were delivered
+	// or given up on are kept, pruned every WebhookPruneInterval
+	WebhookDeliveryRetention = 7 * 24 * time.Hour
+	WebhookPruneInterval     = time.Hour
+)
+
+// EventPublisher publishes the events of a user to their webhooks
+type EventPublisher interface {
+	Publish(ctx context.Context, userId int, event string, data interface{})
+}
+
+type WebhookUsecase interface {
+	EventPublisher
+	CreateSubscription(ctx context.Context, userId int, req dto.WebhookSubscriptionRequest) (resp dto.WebhookSubscriptionResponse, err error)
+	UpdateSubscription(ctx context.Context, userId, id int, req dto.WebhookSubscriptionRequest) (resp dto.WebhookSubscriptionResponse, err error)
+	DeleteSubscription(ctx context.Context, userId, id int) (err error)
+	GetSubscriptions(ctx context.Context, userId int) (resp []dto.WebhookSubscriptionResponse, err error)
+	GetDeliveries(ctx context.Context, userId int, filter dto.WebhookDeliveryFilter) (resp dto.WebhookDeliveryListResponse, err error)
+	GetDelivery(ctx context.Context, userId, id int) (resp dto.WebhookDeliveryResponse, err error)
+	StartDeliveries(ctx context.Context)
+}
+
+type defaultWebhookUsecase struct {
+	userRepo      mysql.UserRepository
+	webhookRepo   mysql.WebhookRepository
+	auditRepo     mysql.AuditRepository
+	webhookSender webhook.Sender
+}
+
+// NewWebhookUsecase creates a new instance of WebhookUsecase
+func NewWebhookUsecase(userRepo mysql.UserRepository, webhookRepo mysql.WebhookRepository, auditRepo mysql.AuditRepository, webhookSender webhook.Sender) WebhookUsecase {
+	return &defaultWebhookUsecase{
+		userRepo:      userRepo,
+		webhookRepo:   webhookRepo,
+		auditRepo:     auditRepo,
+		webhookSender: webhookSender,
+	}
+}
+
+// CreateSubscription subscribes a url to events and returns the subscription
+// with the secret its payloads are signed with
+func (s *defaultWebhookUsecase) CreateSubscription(ctx context.Context, userId int, req dto.WebhookSubscriptionRequest) (resp dto.WebhookSubscriptionResponse, err error) {
+	err = s.validateSubscription(ctx, userId, req)
+	if err != nil {
+		return
+	}
+
+	secret, err := newWebhookSecret()
+	if err != nil {
+		logger.Error(ctx, "error creating webhook secret", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	subscription := entity.WebhookSubscription{
+		UserID:   userId,
+		URL:      req.Url,
+		Events:   strings.Join(req.Events, ","),
+		Secret:   secret,
+		AllUsers: req.AllUsers,
+		Active:   req.Active == nil || *req.Active,
+	}
+	err = s.webhookRepo.CreateSubscription(ctx, &subscription)
+	if err != nil {
+		logger.Error(ctx, "error creating webhook subscription", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toWebhookSubscriptionResponse(subscription)
+	resp.Secret = subscription.Secret
+	return
+}
+
+// UpdateSubscription changes the url, events and activity of a subscription
+// of the user. The secret is kept.
+func (s *defaultWebhookUsecase) UpdateSubscription(ctx context.Context, userId, id int, req dto.WebhookSubscriptionRequest) (resp dto.WebhookSubscriptionResponse, err error) {
+	subscription, err := s.getSubscription(ctx, userId, id)
+	if err != nil {
+		return
+	}
+
+	err = s.validateSubscription(ctx, userId, req)
+	if err != nil {
+		return
+	}
+
+	subscription.URL = req.Url
+	subscription.Events = strings.Join(req.Events, ",")
+	subscription.AllUsers = req.AllUsers
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+	err = s.webhookRepo.UpdateSubscription(ctx, subscription)
+	if err != nil {
+		logger.Error(ctx, "error updating webhook subscription", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = toWebhookSubscriptionResponse(*subscription)
+	return
+}
+
+// DeleteSubscription deletes a subscription of the user with its deliveries
+func (s *defaultWebhookUsecase) DeleteSubscription(ctx context.Context, userId, id int) (err error) {
+	_, err = s.getSubscription(ctx, userId, id)
+	if err != nil {
+		return
+	}
+
+	err = s.webhookRepo.DeleteSubscription(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "error deleting webhook subscription", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+	return
+}
+
+// GetSubscriptions returns the subscriptions of the user
+func (s *defaultWebhookUsecase) GetSubscriptions(ctx context.Context, userId int) (resp []dto.WebhookSubscriptionResponse, err error) {
+	subscriptions, err := s.webhookRepo.GetSubscriptionsByUserId(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting webhook subscriptions", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp = []dto.WebhookSubscriptionResponse{}
+	for _, subscription := range subscriptions {
+		resp = append(resp, toWebhookSubscriptionResponse(subscription))
+	}
+	return
+}
+
+// GetDeliveries returns a page of the deliveries of a subscription of the
+// user, the latest first
+func (s *defaultWebhookUsecase) GetDeliveries(ctx context.Context, userId int, filter dto.WebhookDeliveryFilter) (resp dto.WebhookDeliveryListResponse, err error) {
+	_, err = s.getSubscription(ctx, userId, filter.SubscriptionId)
+	if err != nil {
+		return
+	}
+
+	deliveries, total, err := s.webhookRepo.FindDeliveries(ctx, filter.SubscriptionId, filter.Page, filter.Limit)
+	if err != nil {
+		logger.Error(ctx, "error getting webhook deliveries", err.Error())
+		err = errors.SetError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	resp.Deliveries = []dto.WebhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		resp.Deliveries = append(resp.Deliveries, toWebhookDeliveryResponse(delivery))
+	}
+	resp.Total = total
+	return
+}
+
+// GetDelivery returns a delivery of a subscription of the user with its
+// payload and attempts
+func (s *defaultWebhookUsecase) GetDelivery(ctx context.Context, userId, id int) (resp dto.WebhookDeliveryResponse, err error) {
+	delivery, err := s.webhookRepo.GetDeliveryById(ctx, id)
+	if err != nil {
+		logger.Error(ctx, "webhook delivery not found")
+		err = errors.SetError(http.StatusNotFound, "webhook delivery not found")
+		return
+	}
+
+	_, err = s.getSubscription(ctx, userId, delivery.SubscriptionID)
+	if err != nil {
+		err = errors.SetError(http.StatusNotFound, "webhook delivery not found")
+		return
+	}
+
+	// The payloads of the subscriptions of admins to all users hold the
+	// messages of other users
+	if delivery.UserID != userId {
+		recordAudit(ctx, s.auditRepo, userId, constrans.AuditWebhookDeliveryViewed, "user", delivery.UserID, nil, map[string]interface{}{
+			"deliveryId": delivery.ID,
+			"event":      delivery.Event,
+		})
+	}
+
+	resp = toWebhookDeliveryResponse(*delivery)
+	resp.Payload = json.RawMessage(delivery.Payload)
+	for _, attempt := range delivery.AttemptLog {
+		resp.AttemptLog = append(resp.AttemptLog, dto.WebhookAttemptResponse{
+			StatusCode: attempt.StatusCode,
+			Error:      attempt.Error,
+			Duration:   attempt.Duration,
+			CreatedAt:  attempt.CreatedAt,
+		})
+	}
+	return
+}
+
+// Publish queues an event of the user for the active subscriptions to it.
+// Failures are only logged, so publishing never fails the action the event
+// is about.
+func (s *defaultWebhookUsecase) Publish(ctx context.Context, userId int, event string, data interface{}) {
+	subscriptions, err := s.webhookRepo.GetActiveSubscriptions(ctx, userId)
+	if err != nil {
+		logger.Error(ctx, "error getting webhook subscriptions", event, err.Error())
+		return
+	}
+
+	var deliveries []entity.WebhookDelivery
+	now := time.Now()
+	payload := dto.WebhookEvent{
+		Event:     event,
+		UserId:    userId,
+		CreatedAt: now,
+		Data:      data,
+	}
+	for _, subscription := range subscriptions {
+		if !containsEvent(subscription.Events, event) {
+			continue
+		}
+
+		payload.Id = uuid.New().String()
+		body, _ := json.Marshal(payload)
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			UserID:         userId,
+			EventID:        payload.Id,
+			Event:          event,
+			Payload:        string(body),
+			Status:         constrans.DeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	err = s.webhookRepo.CreateDeliveries(ctx, deliveries)
+	if err != nil {
+		logger.Error(ctx, "error queueing webhook deliveries", event, err.Error())
+	}
+}
+
+// StartDeliveries sends the due deliveries in the background, and prunes the
+// old ones, until the context is done
+func (s *defaultWebhookUsecase) StartDeliveries(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(WebhookPollInterval)
+		defer ticker.Stop()
+		pruneTicker := time.NewTicker(WebhookPruneInterval)
+		defer pruneTicker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.sendDueDeliveries(ctx)
+			case <-pruneTicker.C:
+				s.pruneDeliveries(ctx)
+			}
+		}
+	}()
+}
+
+// pruneDeliveries deletes the deliveries that were delivered or given up on
+// more than WebhookDeliveryRetention ago, so the payloads are not kept longer
+// than they are needed
+func (s *defaultWebhookUsecase) pruneDeliveries(ctx context.Context) {
+	total, err := s.webhookRepo.PruneDeliveries(ctx, time.Now().Add(-WebhookDeliveryRetention))
+	if err != nil {
+		logger.Error(ctx, "error pruning webhook deliveries", err.Error())
+		return
+	}
+	if total > 0 {
+		logger.Info(ctx, "webhook deliveries pruned", total)
+	}
+}
+
+// sendDueDeliveries claims the due deliveries a batch at a time and sends the
+// deliveries of a batch at the same time
+func (s *defaultWebhookUsecase) sendDueDeliveries(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := s.webhookRepo.ClaimDeliveries(ctx, WebhookLease, WebhookBatchSize)
+		if err != nil {
+			logger.Error(ctx, "error claiming webhook deliveries", err.Error())
+			return
+		}
+		if len(deliveries) == 0 {
+			return
+		}
+
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func(delivery entity.WebhookDelivery) {
+				defer wg.Done()
+				s.sendDelivery(ctx, delivery)
+			}(deliveries[i])
+		}
+		wg.Wait()
+
+		if len(deliveries) < WebhookBatchSize {
+			return
+		}
+	}
+}
+
+// sendDelivery makes an attempt at a delivery and records it. A failed
+// delivery is tried again after the backoff until it runs out of attempts.
+func (s *defaultWebhookUsecase) sendDelivery(ctx context.Context, delivery entity.WebhookDelivery) {
+	subscription, err := s.webhookRepo.GetSubscriptionById(ctx, delivery.SubscriptionID)
+	if err != nil || !subscription.Active {
+		logger.Error(ctx, "webhook subscription not active", delivery.SubscriptionID)
+		delivery.Status = constrans.DeliveryFailed
+		s.webhookRepo.UpdateDelivery(ctx, &delivery)
+		return
+	}
+
+	start := time.Now()
+	statusCode, err := s.webhookSender.Send(ctx, subscription.URL, subscription.Secret, []byte(delivery.Payload))
+	attempt := entity.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		StatusCode: statusCode,
+		Duration:   time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	errRes := s.webhookRepo.CreateAttempt(ctx, &attempt)
+	if errRes != nil {
+		logger.Error(ctx, "error recording webhook attempt", delivery.ID, errRes.Error())
+	}
+
+	delivery.Attempts++
+	switch {
+	case err == nil:
+		now := time.Now()
+		delivery.Status = constrans.DeliveryDelivered
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= WebhookMaxAttempts:
+		logger.Error(ctx, "webhook delivery given up", delivery.ID, err.Error())
+		delivery.Status = constrans.DeliveryFailed
+	default:
+		delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+	}
+
+	errRes = s.webhookRepo.UpdateDelivery(ctx, &delivery)
+	if errRes != nil {
+		logger.Error(ctx, "error updating webhook delivery", delivery.ID, errRes.Error())
+	}
+}
+
+// getSubscription returns a subscription of the user
+func (s *defaultWebhookUsecase) getSubscription(ctx context.Context, userId, id int) (resp *entity.WebhookSubscription, err error) {
+	resp, err = s.webhookRepo.GetSubscriptionById(ctx, id)
+	if err != nil || resp.UserID != userId {
+		logger.Error(ctx, "webhook subscription not found")
+		err = errors.SetError(http.StatusNotFound, "webhook subscription not found")
+		return
+	}
+	return
+}
+
+// validateSubscription checks the url and events of a subscription, and that
+// only admins subscribe to the events of all users
+func (s *defaultWebhookUsecase) validateSubscription(ctx context.Context, userId int, req dto.WebhookSubscriptionRequest) (err error) {
+	webhookUrl, errRes := url.Parse(req.Url)
+	if errRes != nil || (webhookUrl.Scheme != "http" && webhookUrl.Scheme != "https") || webhookUrl.Host == "" {
+		logger.Error(ctx, "webhook url not valid", req.Url)
+		err = errors.SetError(http.StatusBadRequest, "url must be an http or https url")
+		return
+	}
+	errRes = s.webhookSender.CheckUrl(ctx, req.Url)
+	if errRes != nil {
+		logger.Error(ctx, "webhook url not allowed", req.Url, errRes.Error())
+		err = errors.SetError(http.StatusBadRequest, "url must resolve to a public address")
+		return
+	}
+
+	if len(req.Events) == 0 {
+		logger.Error(ctx, "webhook events empty")
+		err = errors.SetError(http.StatusBadRequest, "events must not be empty")
+		return
+	}
+	for _, event := range req.Events {
+		if !containsEvent(strings.Join(constrans.WebhookEvents, ","), event) {
+			logger.Error(ctx, "webhook event not valid", event)
+			err = errors.SetError(http.StatusBadRequest, "events must be any of "+strings.Join(constrans.WebhookEvents, ", "))
+			return
+		}
+	}
+
+	if req.AllUsers {
+		userData, errRes := s.userRepo.GetUserById(ctx, userId)
+		if errRes != nil || userData.Role != constrans.RoleAdmin {
+			logger.Error(ctx, "only admins subscribe to all users")
+			err = errors.SetError(http.StatusForbidden, "only admins can subscribe to the events of all users")
+			return
+		}
+	}
+	return
+}
+
+// containsEvent reports whether the comma separated events hold the event
+func containsEvent(events, event string) bool {
+	for _, value := range strings.Split(events, ",") {
+		if value == event {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookBackoff returns the wait after a delivery failed the number of
+// attempts
+func webhookBackoff(attempts int) time.Duration {
+	backoff := WebhookBackoff
+	for i := 1; i < attempts && backoff < WebhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > WebhookMaxBackoff {
+		backoff = WebhookMaxBackoff
+	}
+	return backoff
+}
+
+// newWebhookSecret returns a random signing secret
+func newWebhookSecret() (secret string, err error) {
+	data := make([]byte, WebhookSecretSize)
+	_, err = rand.Read(data)
+	if err != nil {
+		return
+	}
+
+	secret = hex.EncodeToString(data)
+	return
+}
+
+func toWebhookSubscriptionResponse(subscription entity.WebhookSubscription) dto.WebhookSubscriptionResponse {
+	return dto.WebhookSubscriptionResponse{
+		Id:        subscription.ID,
+		Url:       subscription.URL,
+		Events:    strings.Split(subscription.Events, ","),
+		AllUsers:  subscription.AllUsers,
+		Active:    subscription.Active,
+		CreatedAt: subscription.CreatedAt,
+		UpdatedAt: subscription.UpdatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery entity.WebhookDelivery) (resp dto.WebhookDeliveryResponse) {
+	resp = dto.WebhookDeliveryResponse{
+		Id:             delivery.ID,
+		SubscriptionId: delivery.SubscriptionID,
+		EventId:        delivery.EventID,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.Status == constrans.DeliveryPending {
+		resp.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return
+}
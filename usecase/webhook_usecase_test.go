@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/mocks"
+	"github.com/fadilahonespot/chatbot/repository/http/webhook"
+	"github.com/fadilahonespot/chatbot/usecase/dto"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
+	"github.com/fadilahonespot/chatbot/utils/logger"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_webhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: 30 * time.Second},
+		{attempts: 2, want: time.Minute},
+		{attempts: 4, want: 4 * time.Minute},
+		{attempts: 7, want: 32 * time.Minute},
+		{attempts: 8, want: time.Hour},
+		{attempts: 20, want: time.Hour},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%v) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func Test_defaultWebhookUsecase_CreateSubscription(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name    string
+		role    string
+		req     dto.WebhookSubscriptionRequest
+		wantErr bool
+	}{
+		{
+			name: "own events",
+			role: constrans.RoleUser,
+			req:  dto.WebhookSubscriptionRequest{Url: "https://crm.example.com/hooks", Events: []string{constrans.EventMessageCreated, constrans.EventAnswerCompleted}},
+		},
+		{
+			name: "admin for all users",
+			role: constrans.RoleAdmin,
+			req:  dto.WebhookSubscriptionRequest{Url: "https://crm.example.com/hooks", Events: []string{constrans.EventUserRegistered}, AllUsers: true},
+		},
+		{
+			name:    "user for all users",
+			role:    constrans.RoleUser,
+			req:     dto.WebhookSubscriptionRequest{Url: "https://crm.example.com/hooks", Events: []string{constrans.EventUserRegistered}, AllUsers: true},
+			wantErr: true,
+		},
+		{
+			name:    "unknown event",
+			role:    constrans.RoleUser,
+			req:     dto.WebhookSubscriptionRequest{Url: "https://crm.example.com/hooks", Events: []string{"chat.deleted"}},
+			wantErr: true,
+		},
+		{
+			name:    "url not http",
+			role:    constrans.RoleUser,
+			req:     dto.WebhookSubscriptionRequest{Url: "ftp://crm.example.com/hooks", Events: []string{constrans.EventMessageCreated}},
+			wantErr: true,
+		},
+		{
+			name:    "url to an internal address",
+			role:    constrans.RoleUser,
+			req:     dto.WebhookSubscriptionRequest{Url: "http://169.254.169.254/latest/meta-data", Events: []string{constrans.EventMessageCreated}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := new(mocks.UserRepository)
+			userRepo.On("GetUserById", mock.Anything, 1).Return(&entity.User{ID: 1, Role: tt.role}, nil)
+			webhookRepo := new(mocks.WebhookRepository)
+			webhookRepo.On("CreateSubscription", mock.Anything, mock.Anything).Return(nil)
+			webhookSender := new(mocks.Sender)
+			webhookSender.On("CheckUrl", mock.Anything, "https://crm.example.com/hooks").Return(nil)
+			webhookSender.On("CheckUrl", mock.Anything, mock.Anything).Return(webhook.ErrAddressNotAllowed)
+
+			s := NewWebhookUsecase(userRepo, webhookRepo, nil, webhookSender)
+			gotResp, err := s.CreateSubscription(ctx, 1, tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("defaultWebhookUsecase.CreateSubscription() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (len(gotResp.Secret) != 2*WebhookSecretSize || !gotResp.Active) {
+				t.Errorf("defaultWebhookUsecase.CreateSubscription() = %+v, want an active subscription with its secret", gotResp)
+			}
+		})
+	}
+}
+
+func Test_defaultWebhookUsecase_Publish(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	webhookRepo := new(mocks.WebhookRepository)
+	webhookRepo.On("GetActiveSubscriptions", mock.Anything, 1).Return([]entity.WebhookSubscription{
+		{ID: 1, UserID: 1, Events: "message.created,answer.completed", Active: true},
+		{ID: 2, UserID: 9, Events: "user.registered,answer.completed", AllUsers: true, Active: true},
+		{ID: 3, UserID: 1, Events: "quota.exceeded", Active: true},
+	}, nil)
+	var deliveries []entity.WebhookDelivery
+	webhookRepo.On("CreateDeliveries", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		deliveries = args.Get(1).([]entity.WebhookDelivery)
+	}).Return(nil)
+
+	s := NewWebhookUsecase(nil, webhookRepo, nil, nil)
+	s.Publish(ctx, 1, constrans.EventAnswerCompleted, dto.AnswerEvent{ConversationId: 3, Answer: "Text editor dan git."})
+
+	if len(deliveries) != 2 || deliveries[0].SubscriptionID != 1 || deliveries[1].SubscriptionID != 2 {
+		t.Fatalf("defaultWebhookUsecase.Publish() deliveries = %+v, want subscriptions 1 and 2", deliveries)
+	}
+	var payload dto.WebhookEvent
+	json.Unmarshal([]byte(deliveries[0].Payload), &payload)
+	if payload.Event != constrans.EventAnswerCompleted || payload.UserId != 1 || payload.Id != deliveries[0].EventID {
+		t.Errorf("defaultWebhookUsecase.Publish() payload = %+v", payload)
+	}
+	if deliveries[0].Status != constrans.DeliveryPending {
+		t.Errorf("defaultWebhookUsecase.Publish() status = %v, want %v", deliveries[0].Status, constrans.DeliveryPending)
+	}
+	// the deliveries to an admin's subscription are still about the user
+	if deliveries[0].UserID != 1 || deliveries[1].UserID != 1 {
+		t.Errorf("defaultWebhookUsecase.Publish() users = %v and %v, want 1", deliveries[0].UserID, deliveries[1].UserID)
+	}
+}
+
+func Test_defaultWebhookUsecase_pruneDeliveries(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	webhookRepo := new(mocks.WebhookRepository)
+	webhookRepo.On("PruneDeliveries", mock.Anything, mock.Anything).Return(int64(4), nil)
+
+	s := &defaultWebhookUsecase{webhookRepo: webhookRepo}
+	s.pruneDeliveries(ctx)
+
+	webhookRepo.AssertCalled(t, "PruneDeliveries", mock.Anything, mock.MatchedBy(func(before time.Time) bool {
+		age := time.Since(before)
+		return age > WebhookDeliveryRetention-time.Minute && age < WebhookDeliveryRetention+time.Minute
+	}))
+}
+
+func Test_defaultWebhookUsecase_sendDelivery(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name         string
+		attempts     int
+		sendErr      error
+		wantStatus   string
+		wantAttempts int
+		wantRetry    bool
+	}{
+		{
+			name:         "delivered",
+			wantStatus:   constrans.DeliveryDelivered,
+			wantAttempts: 1,
+		},
+		{
+			name:         "retried later",
+			attempts:     2,
+			sendErr:      errors.New("webhook responded with status 500"),
+			wantStatus:   constrans.DeliveryPending,
+			wantAttempts: 3,
+			wantRetry:    true,
+		},
+		{
+			name:         "out of attempts",
+			attempts:     WebhookMaxAttempts - 1,
+			sendErr:      errors.New("webhook responded with status 500"),
+			wantStatus:   constrans.DeliveryFailed,
+			wantAttempts: WebhookMaxAttempts,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepo := new(mocks.WebhookRepository)
+			webhookRepo.On("GetSubscriptionById", mock.Anything, 4).Return(&entity.WebhookSubscription{ID: 4, URL: "https://crm.example.com/hooks", Secret: "rahasia", Active: true}, nil)
+			webhookRepo.On("CreateAttempt", mock.Anything, mock.Anything).Return(nil)
+			var last entity.WebhookDelivery
+			webhookRepo.On("UpdateDelivery", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				last = *args.Get(1).(*entity.WebhookDelivery)
+			}).Return(nil)
+			webhookSender := new(mocks.Sender)
+			webhookSender.On("Send", mock.Anything, "https://crm.example.com/hooks", "rahasia", []byte(`{"id":"1"}`)).Return(500, tt.sendErr)
+
+			s := &defaultWebhookUsecase{
+				webhookRepo:   webhookRepo,
+				webhookSender: webhookSender,
+			}
+			s.sendDelivery(ctx, entity.WebhookDelivery{ID: 7, SubscriptionID: 4, Payload: `{"id":"1"}`, Status: constrans.DeliveryPending, Attempts: tt.attempts})
+
+			if last.Status != tt.wantStatus || last.Attempts != tt.wantAttempts {
+				t.Errorf("defaultWebhookUsecase.sendDelivery() = %v after %v attempts, want %v after %v", last.Status, last.Attempts, tt.wantStatus, tt.wantAttempts)
+			}
+			if tt.wantRetry && last.NextAttemptAt.Before(time.Now().Add(webhookBackoff(tt.wantAttempts)-time.Second)) {
+				t.Errorf("defaultWebhookUsecase.sendDelivery() next attempt = %v, want after the backoff", last.NextAttemptAt)
+			}
+			webhookRepo.AssertCalled(t, "CreateAttempt", mock.Anything, mock.MatchedBy(func(attempt *entity.WebhookAttempt) bool {
+				return attempt.DeliveryID == 7 && (attempt.Error != "") == (tt.sendErr != nil)
+			}))
+		})
+	}
+}
+
+func Test_defaultWebhookUsecase_GetDelivery(t *testing.T) {
+	ctx := context.TODO()
+	logger.NewLogger()
+
+	tests := []struct {
+		name      string
+		userId    int
+		wantAudit bool
+	}{
+		{
+			name:   "event about the subscriber",
+			userId: 9,
+		},
+		{
+			name:      "event about another user",
+			userId:    2,
+			wantAudit: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookRepo := new(mocks.WebhookRepository)
+			webhookRepo.On("GetDeliveryById", mock.Anything, 7).Return(&entity.WebhookDelivery{ID: 7, SubscriptionID: 2, UserID: tt.userId, Event: constrans.EventAnswerCompleted, Payload: `{"id":"1"}`}, nil)
+			webhookRepo.On("GetSubscriptionById", mock.Anything, 2).Return(&entity.WebhookSubscription{ID: 2, UserID: 9, AllUsers: true}, nil)
+			auditRepo := new(mocks.AuditRepository)
+			auditRepo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			s := NewWebhookUsecase(nil, webhookRepo, auditRepo, nil)
+			gotResp, err := s.GetDelivery(ctx, 9, 7)
+			if err != nil || string(gotResp.Payload) != `{"id":"1"}` {
+				t.Fatalf("defaultWebhookUsecase.GetDelivery() = %+v, %v", gotResp, err)
+			}
+			if tt.wantAudit {
+				auditRepo.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(log *entity.AuditLog) bool {
+					return log.Action == constrans.AuditWebhookDeliveryViewed && log.ActorID == 9 && log.TargetID == 2
+				}))
+			} else {
+				auditRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
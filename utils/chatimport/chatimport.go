@@ -0,0 +1,322 @@
+package chatimport
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const (
+	// FormatChatGPT is the conversations.json file of a ChatGPT data export
+	FormatChatGPT = "chatgpt"
+	// FormatGeneric is a list of conversations with their messages, either as
+	// a json array or under a "conversations" key
+	FormatGeneric = "generic"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for formats that cannot be imported
+	ErrUnsupportedFormat = errors.New("unsupported import format")
+	// ErrInvalidFile is returned for files that do not hold conversations in
+	// the expected format
+	ErrInvalidFile = errors.New("invalid import file")
+)
+
+// Conversation is an imported conversation. ExternalID identifies it in the
+// source so importing the same file again does not duplicate it.
+type Conversation struct {
+	ExternalID string
+	Title      string
+	CreatedAt  time.Time
+	Messages   []Message
+}
+
+// Message is an imported message, with the role of openai
+type Message struct {
+	ExternalID string
+	Role       string
+	Content    string
+	Model      string
+	CreatedAt  time.Time
+}
+
+// Parse reads the conversations of an import file. Without a format the
+// format is detected from the content. Messages that are not written by the
+// user or the assistant, or that hold no text, are left out.
+func Parse(format string, data []byte) (conversations []Conversation, err error) {
+	if format == "" {
+		format = DetectFormat(data)
+	}
+
+	switch format {
+	case FormatChatGPT:
+		conversations, err = parseChatGPT(data)
+	case FormatGeneric:
+		conversations, err = parseGeneric(data)
+	default:
+		err = ErrUnsupportedFormat
+	}
+	return
+}
+
+// DetectFormat tells a ChatGPT export from the generic format by the message
+// tree every ChatGPT conversation has
+func DetectFormat(data []byte) string {
+	var items []map[string]json.RawMessage
+	if json.Unmarshal(data, &items) == nil && len(items) > 0 {
+		if _, ok := items[0]["mapping"]; ok {
+			return FormatChatGPT
+		}
+	}
+	return FormatGeneric
+}
+
+type chatGPTConversation struct {
+	Id             string                 `json:"id"`
+	ConversationId string                 `json:"conversation_id"`
+	Title          string                 `json:"title"`
+	CreateTime     float64                `json:"create_time"`
+	CurrentNode    string                 `json:"current_node"`
+	Mapping        map[string]chatGPTNode `json:"mapping"`
+}
+
+type chatGPTNode struct {
+	Id       string          `json:"id"`
+	Parent   string          `json:"parent"`
+	Children []string        `json:"children"`
+	Message  *chatGPTMessage `json:"message"`
+}
+
+type chatGPTMessage struct {
+	Id     string `json:"id"`
+	Author struct {
+		Role string `json:"role"`
+	} `json:"author"`
+	CreateTime float64 `json:"create_time"`
+	Content    struct {
+		ContentType string            `json:"content_type"`
+		Parts       []json.RawMessage `json:"parts"`
+	} `json:"content"`
+	Metadata struct {
+		ModelSlug string `json:"model_slug"`
+	} `json:"metadata"`
+}
+
+// parseChatGPT reads a ChatGPT export. Each conversation is a tree of
+// messages where edited questions branch off; the branch that was last shown,
+// ending at the current node, is imported.
+func parseChatGPT(data []byte) (conversations []Conversation, err error) {
+	var items []chatGPTConversation
+	err = json.Unmarshal(data, &items)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidFile, err.Error())
+		return
+	}
+
+	for _, item := range items {
+		conversation := Conversation{
+			ExternalID: item.ConversationId,
+			Title:      item.Title,
+			CreatedAt:  fromUnix(item.CreateTime),
+		}
+		if conversation.ExternalID == "" {
+			conversation.ExternalID = item.Id
+		}
+		if conversation.ExternalID == "" || item.Mapping == nil {
+			err = fmt.Errorf("%w: conversation without id or messages", ErrInvalidFile)
+			return nil, err
+		}
+
+		for _, node := range chatGPTBranch(item) {
+			message := node.Message
+			if message == nil {
+				continue
+			}
+
+			role := message.Author.Role
+			if role != openai.ChatMessageRoleUser && role != openai.ChatMessageRoleAssistant {
+				continue
+			}
+			if message.Content.ContentType != "text" && message.Content.ContentType != "multimodal_text" {
+				continue
+			}
+
+			// only the text parts are kept, images and other files are skipped
+			var parts []string
+			for _, raw := range message.Content.Parts {
+				var part string
+				if json.Unmarshal(raw, &part) == nil && strings.TrimSpace(part) != "" {
+					parts = append(parts, part)
+				}
+			}
+			if len(parts) == 0 {
+				continue
+			}
+
+			createdAt := fromUnix(message.CreateTime)
+			if createdAt.IsZero() {
+				createdAt = conversation.CreatedAt
+			}
+
+			externalId := message.Id
+			if externalId == "" {
+				externalId = node.Id
+			}
+			conversation.Messages = append(conversation.Messages, Message{
+				ExternalID: externalId,
+				Role:       role,
+				Content:    strings.Join(parts, "\n"),
+				Model:      message.Metadata.ModelSlug,
+				CreatedAt:  createdAt,
+			})
+		}
+		conversations = append(conversations, conversation)
+	}
+	return
+}
+
+// chatGPTBranch returns the nodes from the root of the conversation to its
+// current node. Without a current node the latest child is followed from the
+// root.
+func chatGPTBranch(item chatGPTConversation) (branch []chatGPTNode) {
+	visited := map[string]bool{}
+	if node, ok := item.Mapping[item.CurrentNode]; ok {
+		for ok && !visited[node.Id] {
+			visited[node.Id] = true
+			branch = append(branch, node)
+			node, ok = item.Mapping[node.Parent]
+		}
+		for i, j := 0, len(branch)-1; i < j; i, j = i+1, j-1 {
+			branch[i], branch[j] = branch[j], branch[i]
+		}
+		return
+	}
+
+	// the map has no order, so the roots are sorted to pick the same one
+	// on every import
+	var roots []string
+	for id, node := range item.Mapping {
+		if _, ok := item.Mapping[node.Parent]; !ok {
+			roots = append(roots, id)
+		}
+	}
+	if len(roots) == 0 {
+		return
+	}
+	sort.Strings(roots)
+
+	node, ok := item.Mapping[roots[0]]
+	for ok && !visited[node.Id] {
+		visited[node.Id] = true
+		branch = append(branch, node)
+		if len(node.Children) == 0 {
+			break
+		}
+		node, ok = item.Mapping[node.Children[len(node.Children)-1]]
+	}
+	return
+}
+
+type genericFile struct {
+	Conversations []genericConversation `json:"conversations"`
+}
+
+type genericConversation struct {
+	Id        string           `json:"id"`
+	Title     string           `json:"title"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Messages  []genericMessage `json:"messages"`
+}
+
+type genericMessage struct {
+	Id        string    `json:"id"`
+	Role      string    `json:"role"`
+	Content   string    `json:"content"`
+	Model     string    `json:"model"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// parseGeneric reads the generic format. Conversations and messages without
+// an id are identified by a hash of their content.
+func parseGeneric(data []byte) (conversations []Conversation, err error) {
+	var file genericFile
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(data, &file.Conversations)
+	} else {
+		err = json.Unmarshal(data, &file)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidFile, err.Error())
+		return
+	}
+
+	for _, item := range file.Conversations {
+		conversation := Conversation{
+			ExternalID: item.Id,
+			Title:      item.Title,
+			CreatedAt:  item.CreatedAt,
+		}
+
+		for i, message := range item.Messages {
+			role := strings.ToLower(message.Role)
+			if role == "bot" {
+				role = openai.ChatMessageRoleAssistant
+			}
+			if role != openai.ChatMessageRoleUser && role != openai.ChatMessageRoleAssistant {
+				continue
+			}
+			if strings.TrimSpace(message.Content) == "" {
+				continue
+			}
+
+			externalId := message.Id
+			if externalId == "" {
+				externalId = hash(fmt.Sprint(i), role, message.Content, message.CreatedAt.String())
+			}
+			conversation.Messages = append(conversation.Messages, Message{
+				ExternalID: externalId,
+				Role:       role,
+				Content:    message.Content,
+				Model:      message.Model,
+				CreatedAt:  message.CreatedAt,
+			})
+		}
+
+		if conversation.ExternalID == "" {
+			first := ""
+			if len(conversation.Messages) > 0 {
+				first = conversation.Messages[0].Content
+			}
+			conversation.ExternalID = hash(item.Title, item.CreatedAt.String(), first)
+		}
+		if conversation.CreatedAt.IsZero() && len(conversation.Messages) > 0 {
+			conversation.CreatedAt = conversation.Messages[0].CreatedAt
+		}
+		conversations = append(conversations, conversation)
+	}
+	return
+}
+
+// fromUnix converts a unix time in seconds with a fraction to a time
+func fromUnix(seconds float64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*1e9))
+}
+
+// hash returns the hex sha256 of the values
+func hash(values ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
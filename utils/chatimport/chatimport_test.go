@@ -0,0 +1,131 @@
+package chatimport
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// chatGPTExport is a conversation where the first question was edited, so the
+// tree has two branches and the current node ends the second one
+const chatGPTExport = `[{
+	"id": "conv-1",
+	"conversation_id": "conv-1",
+	"title": "Belajar Go",
+	"create_time": 1705572701.5,
+	"current_node": "a2",
+	"mapping": {
+		"root": {"id": "root", "parent": null, "children": ["sys"], "message": null},
+		"sys": {"id": "sys", "parent": "root", "children": ["q1", "q2"], "message": {"id": "sys", "author": {"role": "system"}, "content": {"content_type": "text", "parts": [""]}}},
+		"q1": {"id": "q1", "parent": "sys", "children": ["a1"], "message": {"id": "q1", "author": {"role": "user"}, "create_time": 1705572702, "content": {"content_type": "text", "parts": ["apa itu go?"]}}},
+		"a1": {"id": "a1", "parent": "q1", "children": [], "message": {"id": "a1", "author": {"role": "assistant"}, "create_time": 1705572703, "content": {"content_type": "text", "parts": ["bahasa"]}}},
+		"q2": {"id": "q2", "parent": "sys", "children": ["a2"], "message": {"id": "q2", "author": {"role": "user"}, "create_time": 1705572704, "content": {"content_type": "multimodal_text", "parts": [{"asset_pointer": "file-service://1"}, "apa itu golang?"]}}},
+		"a2": {"id": "a2", "parent": "q2", "children": [], "message": {"id": "a2", "author": {"role": "assistant"}, "create_time": 1705572705, "content": {"content_type": "text", "parts": ["Go adalah bahasa pemrograman."]}, "metadata": {"model_slug": "gpt-4"}}}
+	}
+}]`
+
+func TestParse(t *testing.T) {
+	at := time.Date(2024, 1, 18, 10, 11, 41, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		format  string
+		data    string
+		want    []Conversation
+		wantErr error
+	}{
+		{
+			name: "chatgpt export follows the current branch",
+			data: chatGPTExport,
+			want: []Conversation{{
+				ExternalID: "conv-1",
+				Title:      "Belajar Go",
+				CreatedAt:  time.Unix(1705572701, 5e8),
+				Messages: []Message{
+					{ExternalID: "q2", Role: "user", Content: "apa itu golang?", CreatedAt: time.Unix(1705572704, 0)},
+					{ExternalID: "a2", Role: "assistant", Content: "Go adalah bahasa pemrograman.", Model: "gpt-4", CreatedAt: time.Unix(1705572705, 0)},
+				},
+			}},
+		},
+		{
+			name:   "generic export with ids",
+			format: FormatGeneric,
+			data:   `{"conversations": [{"id": "c1", "title": "Halo", "messages": [{"id": "m1", "role": "user", "content": "halo", "createdAt": "2024-01-18T10:11:41Z"}, {"id": "m2", "role": "system", "content": "abaikan"}, {"id": "m3", "role": "Bot", "content": "hai", "model": "gpt-3.5-turbo", "createdAt": "2024-01-18T10:11:41Z"}]}]}`,
+			want: []Conversation{{
+				ExternalID: "c1",
+				Title:      "Halo",
+				CreatedAt:  at,
+				Messages: []Message{
+					{ExternalID: "m1", Role: "user", Content: "halo", CreatedAt: at},
+					{ExternalID: "m3", Role: "assistant", Content: "hai", Model: "gpt-3.5-turbo", CreatedAt: at},
+				},
+			}},
+		},
+		{
+			name:    "chatgpt export that is not a list",
+			format:  FormatChatGPT,
+			data:    `{"conversations": []}`,
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "generic export that is not json",
+			data:    `halo`,
+			wantErr: ErrInvalidFile,
+		},
+		{
+			name:    "unsupported format",
+			format:  "claude",
+			data:    `[]`,
+			wantErr: ErrUnsupportedFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.format, []byte(tt.data))
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_genericWithoutIds(t *testing.T) {
+	data := []byte(`[{"title": "Halo", "messages": [{"role": "user", "content": "halo"}, {"role": "assistant", "content": "hai"}]}]`)
+
+	first, err := Parse("", data)
+	if err != nil || len(first) != 1 || len(first[0].Messages) != 2 {
+		t.Fatalf("Parse() = %#v, error = %v", first, err)
+	}
+	if first[0].ExternalID == "" || first[0].Messages[0].ExternalID == first[0].Messages[1].ExternalID {
+		t.Errorf("Parse() ids = %#v, want stable and distinct ids", first[0])
+	}
+
+	second, _ := Parse("", data)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Parse() = %#v, want the same ids on every import %#v", second, first)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{name: "chatgpt", data: chatGPTExport, want: FormatChatGPT},
+		{name: "generic object", data: `{"conversations": []}`, want: FormatGeneric},
+		{name: "generic array", data: `[{"messages": []}]`, want: FormatGeneric},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat([]byte(tt.data)); got != tt.want {
+				t.Errorf("DetectFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package constrans
+
+const (
+	AttachmentImage  = "image"
+	AttachmentAudio  = "audio"
+	AttachmentSpeech = "speech"
+)
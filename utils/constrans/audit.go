@@ -0,0 +1,27 @@
+package constrans
+
+const (
+	AuditChatDeleted         = "chat.deleted"
+	AuditConversationCleared = "conversation.cleared"
+	AuditContextReset        = "conversation.context_reset"
+)
+
+const (
+	AuditUserRegistered  = "user.registered"
+	AuditUserLogin       = "user.login"
+	AuditUserLoginFailed = "user.login_failed"
+	AuditShareRevoked    = "share.revoked"
+	AuditUserPromoted    = "user.promoted"
+)
+
+const (
+	AuditModerationViewed       = "moderation.viewed"
+	AuditWebhookDeliveryViewed  = "webhook.delivery_viewed"
+	AuditModerationReviewed     = "moderation.reviewed"
+	AuditRetentionPolicyCreated = "retention.policy_created"
+	AuditRetentionPolicyUpdated = "retention.policy_updated"
+	AuditRetentionPolicyDeleted = "retention.policy_deleted"
+	AuditRetentionPurgeStarted  = "retention.purge_started"
+	AuditEncryptionKeyRotated   = "encryption.key_rotated"
+	AuditEncryptionStarted      = "encryption.reencryption_started"
+)
@@ -0,0 +1,8 @@
+package constrans
+
+// the messaging channels the bot answers on, as they are routed and stored
+const (
+	ChannelTelegram = "telegram"
+	ChannelWhatsApp = "whatsapp"
+	ChannelSlack    = "slack"
+)
@@ -0,0 +1,19 @@
+package constrans
+
+const (
+	// ModerationBlock rejects the content, ModerationWarn lets it through with
+	// a warning and ModerationLog only records it for review
+	ModerationBlock = "block"
+	ModerationWarn  = "warn"
+	ModerationLog   = "log"
+
+	ModerationInput  = "input"
+	ModerationOutput = "output"
+
+	ModerationStatusPending   = "pending"
+	ModerationStatusConfirmed = "confirmed"
+	ModerationStatusDismissed = "dismissed"
+
+	// ModerationBlockedCode is the error code of content blocked by moderation
+	ModerationBlockedCode = "content_blocked"
+)
@@ -0,0 +1,15 @@
+package constrans
+
+const (
+	RetentionScopeGlobal = "global"
+	RetentionScopeRole   = "role"
+	RetentionScopeUser   = "user"
+)
+
+const (
+	RetentionDelete    = "delete"
+	RetentionAnonymize = "anonymize"
+)
+
+// AnonymizedEmailDomain is the domain of the email an anonymized user is given
+const AnonymizedEmailDomain = "anonymized.invalid"
@@ -0,0 +1,6 @@
+package constrans
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
@@ -0,0 +1,6 @@
+package constrans
+
+const (
+	VisibilityPrivate = "private"
+	VisibilityShared  = "shared"
+)
@@ -0,0 +1,17 @@
+package constrans
+
+const (
+	EventMessageCreated  = "message.created"
+	EventAnswerCompleted = "answer.completed"
+	EventUserRegistered  = "user.registered"
+	EventQuotaExceeded   = "quota.exceeded"
+)
+
+// WebhookEvents are the events webhooks can subscribe to
+var WebhookEvents = []string{EventMessageCreated, EventAnswerCompleted, EventUserRegistered, EventQuotaExceeded}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
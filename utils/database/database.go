@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/fadilahonespot/chatbot/entity"
+	"github.com/fadilahonespot/chatbot/utils/constrans"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 )
@@ -39,7 +40,79 @@ func InitDB() *gorm.DB {
 	}
 
 	DB.AutoMigrate(&entity.User{})
+	backfillChats(DB)
 	DB.AutoMigrate(&entity.Chat{})
+	DB.AutoMigrate(&entity.Conversation{})
+	DB.AutoMigrate(&entity.ConversationTag{})
+	DB.AutoMigrate(&entity.Persona{})
+	DB.AutoMigrate(&entity.PersonaUser{})
+	DB.AutoMigrate(&entity.PersonaPrompt{})
+	DB.AutoMigrate(&entity.ChatModel{})
+	DB.AutoMigrate(&entity.PromptTemplate{})
+	DB.AutoMigrate(&entity.PromptTemplateVariable{})
+	DB.AutoMigrate(&entity.ToolCall{})
+	DB.AutoMigrate(&entity.ChatAttachment{})
+	DB.AutoMigrate(&entity.Document{})
+	DB.AutoMigrate(&entity.DocumentVersion{})
+	DB.AutoMigrate(&entity.DocumentChunk{})
+	DB.AutoMigrate(&entity.Team{})
+	DB.AutoMigrate(&entity.TeamMember{})
+	DB.AutoMigrate(&entity.Collection{})
+	DB.AutoMigrate(&entity.ConversationCollection{})
+	DB.AutoMigrate(&entity.EmbeddingJob{})
+	DB.AutoMigrate(&entity.ImportJob{})
+	DB.AutoMigrate(&entity.ShareLink{})
+	DB.AutoMigrate(&entity.AuditLog{})
+	DB.AutoMigrate(&entity.ModerationFlag{})
+	DB.AutoMigrate(&entity.EncryptionJob{})
+	DB.AutoMigrate(&entity.RetentionPolicy{})
+	DB.AutoMigrate(&entity.PurgeReport{})
+	DB.AutoMigrate(&entity.WebhookSubscription{})
+	DB.AutoMigrate(&entity.WebhookDelivery{})
+	DB.AutoMigrate(&entity.WebhookAttempt{})
+	DB.AutoMigrate(&entity.ChannelIdentity{})
+	migrateTelegramAccounts(DB)
 
 	return DB
 }
+
+// backfillChats fills the key id and anonymized columns of the messages
+// written before the columns were added, which hold NULL for them, so they
+// can be made not null and the messages are found by the key rotation and
+// the retention policies
+func backfillChats(db *gorm.DB) {
+	columns := map[string]string{
+		"KeyID":      "UPDATE chats SET key_id = '' WHERE key_id IS NULL",
+		"Anonymized": "UPDATE chats SET anonymized = false WHERE anonymized IS NULL",
+	}
+	for field, query := range columns {
+		if !db.Migrator().HasColumn(&entity.Chat{}, field) {
+			continue
+		}
+
+		err := db.Exec(query).Error
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// migrateTelegramAccounts copies the Telegram chats linked before the
+// messaging channels were generalized into the channel identities, and drops
+// their old table so chats unlinked since are not copied again
+func migrateTelegramAccounts(db *gorm.DB) {
+	if !db.Migrator().HasTable("telegram_accounts") {
+		return
+	}
+
+	err := db.Exec(`INSERT IGNORE INTO channel_identities (channel, external_id, user_id, username, conversation_id, created_at, updated_at)
+		SELECT ?, CAST(chat_id AS CHAR), user_id, username, conversation_id, created_at, updated_at FROM telegram_accounts`, constrans.ChannelTelegram).Error
+	if err != nil {
+		panic(err)
+	}
+
+	err = db.Migrator().DropTable("telegram_accounts")
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,136 @@
+package document
+
+import (
+	"bytes"
+	"errors"
+	"html"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+var (
+	// ErrUnsupportedType is returned for files that text cannot be extracted from
+	ErrUnsupportedType = errors.New("unsupported document type")
+
+	htmlIgnoredPattern = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlBlockPattern   = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6]|/tr)[^>]*>`)
+	htmlTagPattern     = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesPattern  = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// ExtractText returns the plain text of a txt, markdown, HTML or PDF file.
+// The type is taken from the file extension, falling back to the content type.
+func ExtractText(fileName, contentType string, data []byte) (text string, err error) {
+	switch documentType(fileName, contentType) {
+	case "text":
+		text = string(data)
+	case "html":
+		text = extractHTML(string(data))
+	case "pdf":
+		text, err = extractPDF(data)
+	default:
+		err = ErrUnsupportedType
+	}
+	if err != nil {
+		return
+	}
+
+	text = strings.TrimSpace(blankLinesPattern.ReplaceAllString(text, "\n\n"))
+	return
+}
+
+// Chunk splits the text into pieces of at most size characters on word
+// boundaries. Consecutive chunks share up to overlap characters so a sentence
+// cut at a boundary still appears whole in one of them.
+func Chunk(text string, size, overlap int) (chunks []string) {
+	words := strings.Fields(text)
+	start := 0
+	for start < len(words) {
+		end := start
+		length := 0
+		for end < len(words) && (end == start || length+1+len(words[end]) <= size) {
+			if end > start {
+				length++
+			}
+			length += len(words[end])
+			end++
+		}
+		chunks = append(chunks, strings.Join(words[start:end], " "))
+		if end == len(words) {
+			break
+		}
+
+		// step back over the words that fit in the overlap
+		next := end
+		shared := 0
+		for next > start+1 {
+			length := len(words[next-1])
+			if shared > 0 {
+				length++
+			}
+			if shared+length > overlap {
+				break
+			}
+			shared += length
+			next--
+		}
+		start = next
+	}
+	return
+}
+
+// documentType maps a file to the extractor used for it
+func documentType(fileName, contentType string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".txt", ".md", ".markdown":
+		return "text"
+	case ".html", ".htm":
+		return "html"
+	case ".pdf":
+		return "pdf"
+	}
+
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.HasPrefix(contentType, "text/html"):
+		return "html"
+	case strings.HasPrefix(contentType, "text/"):
+		return "text"
+	case strings.HasPrefix(contentType, "application/pdf"):
+		return "pdf"
+	}
+	return ""
+}
+
+// extractHTML drops the markup of an HTML page, keeping block breaks as new lines
+func extractHTML(content string) string {
+	content = htmlIgnoredPattern.ReplaceAllString(content, "")
+	content = htmlBlockPattern.ReplaceAllString(content, "\n")
+	content = htmlTagPattern.ReplaceAllString(content, "")
+	return html.UnescapeString(content)
+}
+
+// extractPDF reads the text layer of a PDF
+func extractPDF(data []byte) (text string, err error) {
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return
+	}
+
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return
+	}
+
+	content, err := io.ReadAll(plain)
+	if err != nil {
+		return
+	}
+
+	text = string(content)
+	return
+}
@@ -0,0 +1,98 @@
+package document
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		size    int
+		overlap int
+		want    []string
+	}{
+		{
+			name: "empty text",
+			text: "  ",
+			size: 10,
+		},
+		{
+			name: "text fits in one chunk",
+			text: "satu dua tiga",
+			size: 20,
+			want: []string{"satu dua tiga"},
+		},
+		{
+			name:    "chunks share the overlap",
+			text:    "satu dua tiga empat lima",
+			size:    14,
+			overlap: 5,
+			want:    []string{"satu dua tiga", "tiga empat", "empat lima"},
+		},
+		{
+			name: "long word gets its own chunk",
+			text: "a " + strings.Repeat("x", 12) + " b",
+			size: 5,
+			want: []string{"a", strings.Repeat("x", 12), "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Chunk(tt.text, tt.size, tt.overlap); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Chunk() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	tests := []struct {
+		name        string
+		fileName    string
+		contentType string
+		data        string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:     "markdown is kept as is",
+			fileName: "notes.md",
+			data:     "# Judul\n\nIsi catatan",
+			want:     "# Judul\n\nIsi catatan",
+		},
+		{
+			name:     "html markup is removed",
+			fileName: "page.html",
+			data:     "<html><head><title>x</title></head><body><script>alert(1)</script><p>Halo &amp; selamat</p><p>datang</p></body></html>",
+			want:     "Halo & selamat\ndatang",
+		},
+		{
+			name:        "type from content type",
+			fileName:    "upload",
+			contentType: "text/plain; charset=utf-8",
+			data:        "teks biasa",
+			want:        "teks biasa",
+		},
+		{
+			name:     "unsupported type",
+			fileName: "image.png",
+			data:     "png",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractText(tt.fileName, tt.contentType, []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExtractText() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ExtractText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
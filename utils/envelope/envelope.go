@@ -0,0 +1,179 @@
+// Package envelope encrypts data at rest with envelope encryption: every value
+// is encrypted with its own AES-GCM data key, and the data key is stored with
+// it wrapped by a master key of a key provider.
+package envelope
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DataKeySize is the size of a data key, for AES-256
+const DataKeySize = 32
+
+// prefix marks an encrypted value, followed by the id of the master key, the
+// wrapped data key and the ciphertext
+const prefix = "enc:v1:"
+
+// rawPrefix marks a value kept as it is whose text starts like an encrypted
+// value, so what a user writes never decides how a value is read
+const rawPrefix = "enc:raw:"
+
+var ErrInvalidCiphertext = errors.New("encrypted value is not valid")
+
+// Encryptor encrypts and decrypts values. Without a key provider encryption
+// is turned off and values are kept as they are, escaped when they start
+// like an encrypted value. Values stored before encryption was turned on are
+// read as they are.
+type Encryptor struct {
+	provider KeyProvider
+}
+
+// NewEncryptor creates an encryptor wrapping its data keys with the provider
+func NewEncryptor(provider KeyProvider) *Encryptor {
+	return &Encryptor{
+		provider: provider,
+	}
+}
+
+// NewEncryptorFromEnv creates the encryptor of ENCRYPTION_PROVIDER. With
+// config the master keys are read from ENCRYPTION_MASTER_KEYS, the current one
+// named by ENCRYPTION_MASTER_KEY_ID. With local they are kept by a local KMS
+// stand-in in ENCRYPTION_KMS_PATH. Without a provider nothing is encrypted.
+func NewEncryptorFromEnv() (resp *Encryptor, err error) {
+	var provider KeyProvider
+	switch os.Getenv("ENCRYPTION_PROVIDER") {
+	case "":
+	case "config":
+		keys, errRes := ParseKeys(os.Getenv("ENCRYPTION_MASTER_KEYS"))
+		if errRes != nil {
+			return nil, errRes
+		}
+		provider, err = NewKeyring(keys, os.Getenv("ENCRYPTION_MASTER_KEY_ID"))
+	case "local":
+		path := os.Getenv("ENCRYPTION_KMS_PATH")
+		if path == "" {
+			path = "./storage/kms.json"
+		}
+		provider, err = NewLocalKMS(path)
+	default:
+		err = fmt.Errorf("unknown encryption provider %q", os.Getenv("ENCRYPTION_PROVIDER"))
+	}
+	if err != nil {
+		return
+	}
+
+	resp = NewEncryptor(provider)
+	return
+}
+
+// Enabled reports whether values are encrypted
+func (e *Encryptor) Enabled() bool {
+	return e != nil && e.provider != nil
+}
+
+// CurrentKeyID returns the id of the master key new values are encrypted
+// under, empty when encryption is turned off
+func (e *Encryptor) CurrentKeyID() string {
+	if !e.Enabled() {
+		return ""
+	}
+	return e.provider.CurrentKeyID()
+}
+
+// Rotate makes a new master key current. Values encrypted before stay
+// readable until they are encrypted again.
+func (e *Encryptor) Rotate(ctx context.Context) (keyId string, err error) {
+	if !e.Enabled() {
+		err = errors.New("encryption is turned off")
+		return
+	}
+	return e.provider.Rotate(ctx)
+}
+
+// Encrypt encrypts the value with a new data key. It returns the encrypted
+// value and the id of the master key it is encrypted under.
+func (e *Encryptor) Encrypt(ctx context.Context, value string) (resp, keyId string, err error) {
+	if !e.Enabled() {
+		resp = escape(value)
+		return
+	}
+
+	dataKey := make([]byte, DataKeySize)
+	_, err = rand.Read(dataKey)
+	if err != nil {
+		return
+	}
+
+	ciphertext, err := seal(dataKey, []byte(value))
+	if err != nil {
+		return
+	}
+	wrapped, keyId, err := e.provider.WrapKey(ctx, dataKey)
+	if err != nil {
+		return
+	}
+
+	resp = prefix + keyId + ":" + base64.RawStdEncoding.EncodeToString(wrapped) + ":" + base64.RawStdEncoding.EncodeToString(ciphertext)
+	return
+}
+
+// Decrypt returns the plain value of an encrypted value, and a value that is
+// not encrypted as it is
+func (e *Encryptor) Decrypt(ctx context.Context, value string) (resp string, err error) {
+	if strings.HasPrefix(value, rawPrefix) {
+		resp = strings.TrimPrefix(value, rawPrefix)
+		return
+	}
+	if !IsEncrypted(value) {
+		resp = value
+		return
+	}
+	if !e.Enabled() {
+		err = errors.New("encrypted value found while encryption is turned off")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+	if len(parts) != 3 {
+		err = ErrInvalidCiphertext
+		return
+	}
+	wrapped, errWrapped := base64.RawStdEncoding.DecodeString(parts[1])
+	ciphertext, errCiphertext := base64.RawStdEncoding.DecodeString(parts[2])
+	if errWrapped != nil || errCiphertext != nil {
+		err = ErrInvalidCiphertext
+		return
+	}
+
+	dataKey, err := e.provider.UnwrapKey(ctx, parts[0], wrapped)
+	if err != nil {
+		return
+	}
+	plain, err := open(dataKey, ciphertext)
+	if err != nil {
+		return
+	}
+
+	resp = string(plain)
+	return
+}
+
+// IsEncrypted reports whether the value was encrypted by an encryptor
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// escape marks a value kept as it is when it starts like an encrypted or an
+// escaped value
+func escape(value string) string {
+	if strings.HasPrefix(value, prefix) || strings.HasPrefix(value, rawPrefix) {
+		return rawPrefix + value
+	}
+	return value
+}
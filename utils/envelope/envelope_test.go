@@ -0,0 +1,174 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptor_Encrypt(t *testing.T) {
+	ctx := context.Background()
+	provider, err := NewKeyring(map[string][]byte{"k1": bytes.Repeat([]byte{1}, MasterKeySize)}, "k1")
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	encryptor := NewEncryptor(provider)
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{name: "empty", value: ""},
+		{name: "message", value: "resep nasi goreng untuk 4 orang"},
+		{name: "looks like a separator", value: "a:b:c,enc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, keyId, err := encryptor.Encrypt(ctx, tt.value)
+			if err != nil {
+				t.Fatalf("Encryptor.Encrypt() error = %v", err)
+			}
+			if keyId != "k1" || !IsEncrypted(got) {
+				t.Errorf("Encryptor.Encrypt() = %v, %v, want encrypted under k1", got, keyId)
+			}
+			if tt.value != "" && strings.Contains(got, tt.value) {
+				t.Errorf("Encryptor.Encrypt() = %v, holds the plain text", got)
+			}
+
+			plain, err := encryptor.Decrypt(ctx, got)
+			if err != nil || plain != tt.value {
+				t.Errorf("Encryptor.Decrypt() = %v, %v, want %v", plain, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestEncryptor_Decrypt(t *testing.T) {
+	ctx := context.Background()
+	key := bytes.Repeat([]byte{1}, MasterKeySize)
+	provider, _ := NewKeyring(map[string][]byte{"k1": key}, "k1")
+	encrypted, _, _ := NewEncryptor(provider).Encrypt(ctx, "rahasia")
+	other, _ := NewKeyring(map[string][]byte{"k2": key}, "k2")
+
+	tests := []struct {
+		name      string
+		encryptor *Encryptor
+		value     string
+		want      string
+		wantErr   bool
+	}{
+		{name: "plain text is read as it is", encryptor: NewEncryptor(provider), value: "halo", want: "halo"},
+		{name: "turned off", encryptor: NewEncryptor(nil), value: "halo", want: "halo"},
+		{name: "unknown master key", encryptor: NewEncryptor(other), value: encrypted, wantErr: true},
+		{name: "tampered", encryptor: NewEncryptor(provider), value: encrypted[:len(encrypted)-2] + "AA", wantErr: true},
+		{name: "malformed", encryptor: NewEncryptor(provider), value: "enc:v1:k1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.encryptor.Decrypt(ctx, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Encryptor.Decrypt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Encryptor.Decrypt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncryptor_turnedOff(t *testing.T) {
+	ctx := context.Background()
+	encryptor := NewEncryptor(nil)
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "message", value: "resep nasi goreng", want: "resep nasi goreng"},
+		{name: "looks encrypted", value: "enc:v1:k1:a:b", want: "enc:raw:enc:v1:k1:a:b"},
+		{name: "looks escaped", value: "enc:raw:halo", want: "enc:raw:enc:raw:halo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, keyId, err := encryptor.Encrypt(ctx, tt.value)
+			if err != nil || got != tt.want || keyId != "" {
+				t.Fatalf("Encryptor.Encrypt() = %v, %v, %v, want %v", got, keyId, err, tt.want)
+			}
+			if IsEncrypted(got) {
+				t.Errorf("IsEncrypted(%v) = true, want false", got)
+			}
+
+			plain, err := encryptor.Decrypt(ctx, got)
+			if err != nil || plain != tt.value {
+				t.Errorf("Encryptor.Decrypt() = %v, %v, want %v", plain, err, tt.value)
+			}
+		})
+	}
+}
+
+func TestLocalKMS_Rotate(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "kms.json")
+
+	provider, err := NewLocalKMS(path)
+	if err != nil {
+		t.Fatalf("NewLocalKMS() error = %v", err)
+	}
+	encryptor := NewEncryptor(provider)
+	before, oldKeyId, _ := encryptor.Encrypt(ctx, "pesan lama")
+
+	newKeyId, err := encryptor.Rotate(ctx)
+	if err != nil || newKeyId == oldKeyId {
+		t.Fatalf("Encryptor.Rotate() = %v, %v, want a new key", newKeyId, err)
+	}
+
+	// The keys are read back from the file
+	reloaded, err := NewLocalKMS(path)
+	if err != nil {
+		t.Fatalf("NewLocalKMS() error = %v", err)
+	}
+	if reloaded.CurrentKeyID() != newKeyId {
+		t.Errorf("CurrentKeyID() = %v, want %v", reloaded.CurrentKeyID(), newKeyId)
+	}
+	if got, err := NewEncryptor(reloaded).Decrypt(ctx, before); err != nil || got != "pesan lama" {
+		t.Errorf("Encryptor.Decrypt() = %v, %v, want the message under the old key", got, err)
+	}
+}
+
+func TestKeyring_Rotate(t *testing.T) {
+	provider, _ := NewKeyring(map[string][]byte{"k1": bytes.Repeat([]byte{1}, MasterKeySize)}, "k1")
+	if _, err := provider.Rotate(context.Background()); !errors.Is(err, ErrRotationNotSupported) {
+		t.Errorf("keyring.Rotate() error = %v, want %v", err, ErrRotationNotSupported)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "two keys", value: "k1:AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=, k2:AgICAgICAgICAgICAgICAgICAgICAgICAgICAgICAgI=", want: 2},
+		{name: "short key", value: "k1:AQEB", wantErr: true},
+		{name: "no id", value: "AQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQE=", wantErr: true},
+		{name: "not base64", value: "k1:***", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKeys(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseKeys() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != tt.want {
+				t.Errorf("ParseKeys() = %v keys, want %v", len(got), tt.want)
+			}
+		})
+	}
+}
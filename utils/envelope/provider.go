@@ -0,0 +1,271 @@
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MasterKeySize is the size of a master key, for AES-256
+const MasterKeySize = 32
+
+var (
+	ErrUnknownKey           = errors.New("unknown master key")
+	ErrRotationNotSupported = errors.New("master keys from config are rotated by adding a key to ENCRYPTION_MASTER_KEYS and making it current with ENCRYPTION_MASTER_KEY_ID")
+)
+
+// KeyProvider holds the master keys that wrap the data keys. Old keys are kept
+// so data wrapped before a rotation can still be read.
+type KeyProvider interface {
+	CurrentKeyID() string
+	WrapKey(ctx context.Context, dataKey []byte) (wrapped []byte, keyId string, err error)
+	UnwrapKey(ctx context.Context, keyId string, wrapped []byte) (dataKey []byte, err error)
+	Rotate(ctx context.Context) (keyId string, err error)
+}
+
+type keyring struct {
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	current string
+}
+
+// NewKeyring creates a key provider of master keys given in config, by key id
+func NewKeyring(keys map[string][]byte, current string) (resp KeyProvider, err error) {
+	if _, ok := keys[current]; !ok {
+		err = fmt.Errorf("current master key %q is not configured", current)
+		return
+	}
+	for keyId, key := range keys {
+		err = validateKey(keyId, key)
+		if err != nil {
+			return
+		}
+	}
+
+	resp = &keyring{
+		keys:    keys,
+		current: current,
+	}
+	return
+}
+
+// ParseKeys reads master keys written as a comma separated list of
+// id:base64-key pairs
+func ParseKeys(value string) (resp map[string][]byte, err error) {
+	resp = map[string][]byte{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		keyId, encoded, ok := strings.Cut(pair, ":")
+		if !ok {
+			err = fmt.Errorf("master key %q is not written as id:base64-key", keyId)
+			return
+		}
+		key, errRes := base64.StdEncoding.DecodeString(encoded)
+		if errRes != nil {
+			err = fmt.Errorf("master key %q is not valid base64", keyId)
+			return
+		}
+		err = validateKey(keyId, key)
+		if err != nil {
+			return
+		}
+		resp[keyId] = key
+	}
+	return
+}
+
+func (k *keyring) CurrentKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.current
+}
+
+func (k *keyring) WrapKey(ctx context.Context, dataKey []byte) (wrapped []byte, keyId string, err error) {
+	k.mu.RLock()
+	keyId = k.current
+	key := k.keys[keyId]
+	k.mu.RUnlock()
+
+	wrapped, err = seal(key, dataKey)
+	return
+}
+
+func (k *keyring) UnwrapKey(ctx context.Context, keyId string, wrapped []byte) (dataKey []byte, err error) {
+	k.mu.RLock()
+	key, ok := k.keys[keyId]
+	k.mu.RUnlock()
+	if !ok {
+		err = fmt.Errorf("%w %q", ErrUnknownKey, keyId)
+		return
+	}
+
+	dataKey, err = open(key, wrapped)
+	return
+}
+
+func (k *keyring) Rotate(ctx context.Context) (keyId string, err error) {
+	err = ErrRotationNotSupported
+	return
+}
+
+type localKMS struct {
+	keyring
+	path string
+}
+
+// localKMSFile is the file a local KMS keeps its master keys in
+type localKMSFile struct {
+	Current string            `json:"current"`
+	Keys    map[string][]byte `json:"keys"`
+}
+
+// NewLocalKMS creates a stand-in for a key management service that keeps its
+// master keys in a local file, creating the file with a first key when it
+// does not exist. Unlike keys from config, its keys can be rotated at runtime.
+func NewLocalKMS(path string) (resp KeyProvider, err error) {
+	kms := &localKMS{
+		keyring: keyring{keys: map[string][]byte{}},
+		path:    path,
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var file localKMSFile
+		err = json.Unmarshal(data, &file)
+		if err != nil {
+			err = fmt.Errorf("local kms file %v is not valid: %s", path, err.Error())
+			return
+		}
+		if _, ok := file.Keys[file.Current]; !ok {
+			err = fmt.Errorf("local kms file %v has no current key", path)
+			return
+		}
+		kms.keys = file.Keys
+		kms.current = file.Current
+	case errors.Is(err, os.ErrNotExist):
+		_, err = kms.Rotate(context.Background())
+		if err != nil {
+			return
+		}
+	default:
+		return
+	}
+
+	resp = kms
+	return
+}
+
+// Rotate creates a new master key and makes it current
+func (k *localKMS) Rotate(ctx context.Context) (keyId string, err error) {
+	key := make([]byte, MasterKeySize)
+	_, err = rand.Read(key)
+	if err != nil {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	keyId = newKeyID(k.keys)
+	keys := map[string][]byte{keyId: key}
+	for id, value := range k.keys {
+		keys[id] = value
+	}
+
+	data, err := json.Marshal(localKMSFile{Current: keyId, Keys: keys})
+	if err != nil {
+		return
+	}
+	err = os.MkdirAll(filepath.Dir(k.path), 0o700)
+	if err != nil {
+		return
+	}
+	err = os.WriteFile(k.path, data, 0o600)
+	if err != nil {
+		return
+	}
+
+	k.keys = keys
+	k.current = keyId
+	return
+}
+
+// newKeyID returns an id for a new key that sorts after the existing ones
+func newKeyID(keys map[string][]byte) string {
+	keyId := time.Now().UTC().Format("20060102T150405")
+	ids := make([]string, 0, len(keys))
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for i := 1; len(ids) > 0 && keyId <= ids[len(ids)-1]; i++ {
+		keyId = fmt.Sprintf("%v-%v", time.Now().UTC().Format("20060102T150405"), i)
+	}
+	return keyId
+}
+
+// validateKey checks that a master key is usable
+func validateKey(keyId string, key []byte) error {
+	if keyId == "" || strings.ContainsAny(keyId, ":,") {
+		return fmt.Errorf("master key id %q must not be empty or contain : or ,", keyId)
+	}
+	if len(key) != MasterKeySize {
+		return fmt.Errorf("master key %q must be %v bytes", keyId, MasterKeySize)
+	}
+	return nil
+}
+
+// seal encrypts the data with AES-GCM, prefixing it with the nonce
+func seal(key, data []byte) (resp []byte, err error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return
+	}
+
+	resp = aead.Seal(nonce, nonce, data, nil)
+	return
+}
+
+// open decrypts data sealed with seal
+func open(key, data []byte) (resp []byte, err error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return
+	}
+	if len(data) < aead.NonceSize() {
+		err = errors.New("ciphertext is too short")
+		return
+	}
+
+	resp, err = aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+	return
+}
+
+func newGCM(key []byte) (resp cipher.AEAD, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return
+	}
+	return cipher.NewGCM(block)
+}
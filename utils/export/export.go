@@ -0,0 +1,251 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	FormatMarkdown = "markdown"
+	FormatJSON     = "json"
+	FormatHTML     = "html"
+	FormatPDF      = "pdf"
+
+	// timeLayout is how timestamps are written in the exports
+	timeLayout = "2006-01-02 15:04:05 MST"
+)
+
+// ErrUnsupportedFormat is returned for export formats that cannot be rendered
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+// Transcript is a set of chat messages with the metadata written in the
+// header of an export
+type Transcript struct {
+	Title          string    `json:"title"`
+	User           string    `json:"user"`
+	ConversationId int       `json:"conversationId,omitempty"`
+	Models         []string  `json:"models"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	ExportedAt     time.Time `json:"exportedAt"`
+	Messages       []Message `json:"messages"`
+}
+
+// Message is a single chat message of a transcript
+type Message struct {
+	Id             int       `json:"id"`
+	ConversationId int       `json:"conversationId"`
+	Role           string    `json:"role"`
+	Name           string    `json:"name"`
+	Model          string    `json:"model,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	Content        string    `json:"content"`
+}
+
+// File is a rendered export
+type File struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// Render renders the transcript in the given format
+func Render(format string, transcript Transcript) (file File, err error) {
+	switch format {
+	case FormatMarkdown:
+		file = File{Data: renderMarkdown(transcript), ContentType: "text/markdown; charset=utf-8", Extension: ".md"}
+	case FormatJSON:
+		file = File{ContentType: "application/json", Extension: ".json"}
+		file.Data, err = json.MarshalIndent(transcript, "", "  ")
+	case FormatHTML:
+		file = File{Data: renderHTML(transcript), ContentType: "text/html; charset=utf-8", Extension: ".html"}
+	case FormatPDF:
+		file = File{ContentType: "application/pdf", Extension: ".pdf"}
+		file.Data, err = renderPDF(transcript)
+	default:
+		err = ErrUnsupportedFormat
+	}
+	return
+}
+
+// segment is a part of a message, either prose or a fenced code block
+type segment struct {
+	code     bool
+	language string
+	text     string
+}
+
+// splitCodeBlocks splits a message into prose and fenced code blocks. A code
+// block left open runs to the end of the message.
+func splitCodeBlocks(content string) (segments []segment) {
+	var current segment
+	var lines []string
+	flush := func() {
+		current.text = strings.Join(lines, "\n")
+		if current.code || strings.TrimSpace(current.text) != "" {
+			segments = append(segments, current)
+		}
+		lines = nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			flush()
+			if current.code {
+				current = segment{}
+			} else {
+				current = segment{code: true, language: strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "```"))}
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if current.code || len(lines) > 0 {
+		flush()
+	}
+	return
+}
+
+// header returns the metadata lines of the transcript
+func header(transcript Transcript) (lines [][2]string) {
+	lines = append(lines, [2]string{"User", transcript.User})
+	if transcript.ConversationId != 0 {
+		lines = append(lines, [2]string{"Conversation", fmt.Sprint(transcript.ConversationId)})
+	}
+	if len(transcript.Models) > 0 {
+		lines = append(lines, [2]string{"Models", strings.Join(transcript.Models, ", ")})
+	}
+	if !transcript.From.IsZero() {
+		lines = append(lines, [2]string{"Period", fmt.Sprintf("%v - %v", transcript.From.Format(timeLayout), transcript.To.Format(timeLayout))})
+	}
+	lines = append(lines, [2]string{"Messages", fmt.Sprint(len(transcript.Messages))})
+	lines = append(lines, [2]string{"Exported at", transcript.ExportedAt.Format(timeLayout)})
+	return
+}
+
+// messageTitle returns the heading of a message with its author, model and time
+func messageTitle(message Message) string {
+	title := message.Name
+	if message.Model != "" {
+		title += fmt.Sprintf(" (%v)", message.Model)
+	}
+	return title + " - " + message.CreatedAt.Format(timeLayout)
+}
+
+// renderMarkdown writes the transcript as markdown. Code blocks are written
+// back fenced, closing the ones a message left open.
+func renderMarkdown(transcript Transcript) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %v\n\n", transcript.Title)
+	for _, line := range header(transcript) {
+		fmt.Fprintf(&buf, "- **%v:** %v\n", line[0], line[1])
+	}
+
+	for _, message := range transcript.Messages {
+		fmt.Fprintf(&buf, "\n---\n\n### %v\n\n", messageTitle(message))
+		for _, part := range splitCodeBlocks(message.Content) {
+			if part.code {
+				fmt.Fprintf(&buf, "```%v\n%v\n```\n\n", part.language, part.text)
+				continue
+			}
+			fmt.Fprintf(&buf, "%v\n\n", strings.Trim(part.text, "\n"))
+		}
+	}
+	return buf.Bytes()
+}
+
+// htmlStyle is the stylesheet of the standalone HTML export
+const htmlStyle = `body{font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;max-width:860px;margin:2em auto;padding:0 1em;color:#222}
+dl{display:grid;grid-template-columns:max-content auto;gap:.2em 1em;color:#555}
+dt{font-weight:bold}dd{margin:0}
+section{border-top:1px solid #ddd;padding:.5em 0}
+h3{font-size:1em;color:#555}
+pre{background:#f5f5f5;padding:.8em;overflow-x:auto;border-radius:4px}
+p{white-space:pre-wrap}`
+
+// renderHTML writes the transcript as a standalone HTML page. Everything is
+// escaped, and code blocks are kept as preformatted text.
+func renderHTML(transcript Transcript) []byte {
+	var buf bytes.Buffer
+	title := html.EscapeString(transcript.Title)
+	fmt.Fprintf(&buf, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%v</title>\n<style>\n%v\n</style>\n</head>\n<body>\n", title, htmlStyle)
+	fmt.Fprintf(&buf, "<h1>%v</h1>\n<dl>\n", title)
+	for _, line := range header(transcript) {
+		fmt.Fprintf(&buf, "<dt>%v</dt><dd>%v</dd>\n", html.EscapeString(line[0]), html.EscapeString(line[1]))
+	}
+	buf.WriteString("</dl>\n")
+
+	for _, message := range transcript.Messages {
+		fmt.Fprintf(&buf, "<section class=\"%v\">\n<h3>%v</h3>\n", html.EscapeString(message.Role), html.EscapeString(messageTitle(message)))
+		for _, part := range splitCodeBlocks(message.Content) {
+			if part.code {
+				class := ""
+				if part.language != "" {
+					class = fmt.Sprintf(" class=\"language-%v\"", html.EscapeString(part.language))
+				}
+				fmt.Fprintf(&buf, "<pre><code%v>%v</code></pre>\n", class, html.EscapeString(part.text))
+				continue
+			}
+			for _, paragraph := range strings.Split(strings.Trim(part.text, "\n"), "\n\n") {
+				if strings.TrimSpace(paragraph) != "" {
+					fmt.Fprintf(&buf, "<p>%v</p>\n", html.EscapeString(paragraph))
+				}
+			}
+		}
+		buf.WriteString("</section>\n")
+	}
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// renderPDF writes the transcript as an A4 PDF. The built in fonts only cover
+// Latin-1, so other characters are replaced. Code blocks are set in a
+// monospace font on a shaded background with their indentation kept.
+func renderPDF(transcript Transcript) (data []byte, err error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.MultiCell(0, 8, translate(transcript.Title), "", "L", false)
+	pdf.Ln(2)
+	for _, line := range header(transcript) {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(28, 5, translate(line[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(0, 5, translate(line[1]), "", "L", false)
+	}
+
+	for _, message := range transcript.Messages {
+		pdf.Ln(4)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.MultiCell(0, 6, translate(messageTitle(message)), "B", "L", false)
+		pdf.Ln(1)
+		for _, part := range splitCodeBlocks(message.Content) {
+			if part.code {
+				pdf.SetFont("Courier", "", 9)
+				pdf.SetFillColor(240, 240, 240)
+				pdf.MultiCell(0, 4.5, translate(strings.ReplaceAll(part.text, "\t", "    ")), "", "L", true)
+				pdf.Ln(1)
+				continue
+			}
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.MultiCell(0, 5, translate(strings.Trim(part.text, "\n")), "", "L", false)
+			pdf.Ln(1)
+		}
+	}
+
+	var buf bytes.Buffer
+	err = pdf.Output(&buf)
+	data = buf.Bytes()
+	return
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func Test_splitCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []segment
+	}{
+		{
+			name:    "prose only",
+			content: "halo\n\napa kabar?",
+			want:    []segment{{text: "halo\n\napa kabar?"}},
+		},
+		{
+			name:    "code block between prose",
+			content: "Contoh:\n```go\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n```\nSelesai.",
+			want: []segment{
+				{text: "Contoh:"},
+				{code: true, language: "go", text: "func main() {\n\tfmt.Println(\"hi\")\n}"},
+				{text: "Selesai."},
+			},
+		},
+		{
+			name:    "open code block runs to the end",
+			content: "```\nSELECT 1;",
+			want:    []segment{{code: true, text: "SELECT 1;"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitCodeBlocks(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitCodeBlocks() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	at := time.Date(2024, 1, 18, 10, 11, 41, 0, time.UTC)
+	transcript := Transcript{
+		Title:          "Belajar koding",
+		User:           "fadilah",
+		ConversationId: 1,
+		Models:         []string{"gpt-3.5-turbo"},
+		From:           at,
+		To:             at,
+		ExportedAt:     at,
+		Messages: []Message{
+			{Id: 1, ConversationId: 1, Role: "user", Name: "fadilah", CreatedAt: at, Content: "contoh <script>?"},
+			{Id: 2, ConversationId: 1, Role: "assistant", Name: "Bot", Model: "gpt-3.5-turbo", CreatedAt: at, Content: "Ini:\n```go\nif a < b {\n}\n"},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		format      string
+		contentType string
+		contains    []string
+		wantErr     bool
+	}{
+		{
+			name:        "markdown",
+			format:      FormatMarkdown,
+			contentType: "text/markdown; charset=utf-8",
+			contains:    []string{"# Belajar koding", "- **Models:** gpt-3.5-turbo", "### Bot (gpt-3.5-turbo) - 2024-01-18 10:11:41 UTC", "```go\nif a < b {\n}\n\n```"},
+		},
+		{
+			name:        "html",
+			format:      FormatHTML,
+			contentType: "text/html; charset=utf-8",
+			contains:    []string{"<!DOCTYPE html>", "<p>contoh &lt;script&gt;?</p>", "<pre><code class=\"language-go\">if a &lt; b {\n}\n</code></pre>"},
+		},
+		{
+			name:        "json",
+			format:      FormatJSON,
+			contentType: "application/json",
+			contains:    []string{"\"exportedAt\": \"2024-01-18T10:11:41Z\""},
+		},
+		{
+			name:        "pdf",
+			format:      FormatPDF,
+			contentType: "application/pdf",
+			contains:    []string{"%PDF-"},
+		},
+		{
+			name:    "unsupported format",
+			format:  "docx",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.format, transcript)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Render() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.ContentType != tt.contentType {
+				t.Errorf("Render() content type = %v, want %v", got.ContentType, tt.contentType)
+			}
+			for _, want := range tt.contains {
+				if !strings.Contains(string(got.Data), want) {
+					t.Errorf("Render() = %s, want it to contain %q", got.Data, want)
+				}
+			}
+		})
+	}
+
+	t.Run("json round trip", func(t *testing.T) {
+		got, _ := Render(FormatJSON, transcript)
+		var decoded Transcript
+		if err := json.Unmarshal(got.Data, &decoded); err != nil || !reflect.DeepEqual(decoded, transcript) {
+			t.Errorf("Render() json = %s, error = %v", got.Data, err)
+		}
+	})
+}
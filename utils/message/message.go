@@ -0,0 +1,42 @@
+package message
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Split cuts the text into parts of at most limit characters so it can be
+// sent on channels that cap the length of a message. A part ends at the last
+// blank line, line break or space that fits, and only a run of text without
+// any of them is cut mid-word. A text within the limit is returned whole.
+func Split(text string, limit int) (parts []string) {
+	text = strings.TrimSpace(text)
+	for utf8.RuneCountInString(text) > limit {
+		// the byte offset of the first character past the limit
+		cut := 0
+		for i := 0; i < limit; i++ {
+			_, size := utf8.DecodeRuneInString(text[cut:])
+			cut += size
+		}
+
+		// a separator right after the limit still ends a full part
+		end := cut
+		for _, separator := range []string{"\n\n", "\n", " "} {
+			window := cut + len(separator)
+			if window > len(text) {
+				window = len(text)
+			}
+			if i := strings.LastIndex(text[:window], separator); i > 0 {
+				end = i
+				break
+			}
+		}
+
+		parts = append(parts, strings.TrimSpace(text[:end]))
+		text = strings.TrimSpace(text[end:])
+	}
+	if text != "" {
+		parts = append(parts, text)
+	}
+	return
+}
@@ -0,0 +1,65 @@
+package message
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "empty text",
+			text:  " \n ",
+			limit: 10,
+		},
+		{
+			name:  "text within the limit",
+			text:  "Text editor dan git.",
+			limit: 20,
+			want:  []string{"Text editor dan git."},
+		},
+		{
+			name:  "split on paragraphs first",
+			text:  "satu dua\n\ntiga empat lima",
+			limit: 20,
+			want:  []string{"satu dua", "tiga empat lima"},
+		},
+		{
+			name:  "split on lines",
+			text:  "satu\ndua tiga\nempat",
+			limit: 14,
+			want:  []string{"satu\ndua tiga", "empat"},
+		},
+		{
+			name:  "split on words",
+			text:  "satu dua tiga empat lima",
+			limit: 10,
+			want:  []string{"satu dua", "tiga empat", "lima"},
+		},
+		{
+			name:  "long word is cut",
+			text:  strings.Repeat("x", 12),
+			limit: 5,
+			want:  []string{"xxxxx", "xxxxx", "xx"},
+		},
+		{
+			name:  "limit counts characters",
+			text:  "héllo wörld",
+			limit: 5,
+			want:  []string{"héllo", "wörld"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Split(tt.text, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Split() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package prompt
+
+import (
+	"regexp"
+)
+
+var placeholderRegex = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_]+)\s*\}\}`)
+
+// Placeholders returns the distinct variable names used as {{variable}} in the content,
+// in the order they first appear.
+func Placeholders(content string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, match := range placeholderRegex.FindAllStringSubmatch(content, -1) {
+		if seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// Render replaces every {{variable}} in the content with its value.
+// Placeholders without a value are left untouched and returned as missing.
+func Render(content string, values map[string]string) (result string, missing []string) {
+	seen := map[string]bool{}
+	result = placeholderRegex.ReplaceAllStringFunc(content, func(placeholder string) string {
+		name := placeholderRegex.FindStringSubmatch(placeholder)[1]
+		value, ok := values[name]
+		if !ok {
+			if !seen[name] {
+				seen[name] = true
+				missing = append(missing, name)
+			}
+			return placeholder
+		}
+		return value
+	})
+	return
+}
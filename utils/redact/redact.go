@@ -0,0 +1,137 @@
+// Package redact replaces personal data in text with placeholders before it
+// leaves the service, and puts the originals back in the text that comes back.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Pattern finds one kind of personal data. Name is used in its placeholders,
+// for example [EMAIL_1].
+type Pattern struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// DefaultPatterns find emails, Indonesian phone numbers and national ID
+// numbers (NIK). NIK comes before phone so its digits are not taken for a
+// phone number.
+var DefaultPatterns = []Pattern{
+	{Name: "EMAIL", Pattern: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`},
+	{Name: "NIK", Pattern: `\b\d{16}\b`},
+	{Name: "PHONE", Pattern: `(?:\+62|\b62|\b0)[ -]?8\d{1,3}[ -]?\d{3,4}[ -]?\d{3,5}\b`},
+}
+
+var patternNameRegex = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+type compiledPattern struct {
+	name  string
+	regex *regexp.Regexp
+}
+
+// Redactor holds the patterns of the personal data to redact. A redactor
+// without patterns leaves text as it is.
+type Redactor struct {
+	patterns []compiledPattern
+}
+
+// NewRedactor compiles the patterns. Names are upper case letters, digits and
+// underscores so placeholders cannot be mistaken for other text.
+func NewRedactor(patterns []Pattern) (resp *Redactor, err error) {
+	resp = &Redactor{}
+	for i, pattern := range patterns {
+		if !patternNameRegex.MatchString(pattern.Name) {
+			err = fmt.Errorf("redaction pattern %v has an invalid name %q", i, pattern.Name)
+			return nil, err
+		}
+		regex, errRes := regexp.Compile(pattern.Pattern)
+		if errRes != nil {
+			err = fmt.Errorf("redaction pattern %v is not valid: %s", pattern.Name, errRes.Error())
+			return nil, err
+		}
+		resp.patterns = append(resp.patterns, compiledPattern{name: pattern.Name, regex: regex})
+	}
+	return
+}
+
+// NewRedactorFromEnv creates the redactor of the patterns in the json file
+// PII_PATTERNS_FILE, or of the default patterns without one. PII_REDACTION set
+// to false turns redaction off.
+func NewRedactorFromEnv() (resp *Redactor, err error) {
+	if os.Getenv("PII_REDACTION") == "false" {
+		return NewRedactor(nil)
+	}
+
+	patterns := DefaultPatterns
+	if path := os.Getenv("PII_PATTERNS_FILE"); path != "" {
+		data, errRes := os.ReadFile(path)
+		if errRes != nil {
+			return nil, errRes
+		}
+		patterns = nil
+		err = json.Unmarshal(data, &patterns)
+		if err != nil {
+			err = fmt.Errorf("redaction patterns %v are not valid: %s", path, err.Error())
+			return
+		}
+	}
+	return NewRedactor(patterns)
+}
+
+// Session redacts the texts of a single request, so a value gets the same
+// placeholder in every text, and restores them in the texts coming back.
+type Session struct {
+	redactor     *Redactor
+	placeholders map[string]string
+	originals    map[string]string
+	counts       map[string]int
+}
+
+// NewSession starts redacting a request
+func (r *Redactor) NewSession() *Session {
+	return &Session{
+		redactor:     r,
+		placeholders: map[string]string{},
+		originals:    map[string]string{},
+		counts:       map[string]int{},
+	}
+}
+
+// Redact replaces the personal data in the text with placeholders
+func (s *Session) Redact(text string) string {
+	for _, pattern := range s.redactor.patterns {
+		text = pattern.regex.ReplaceAllStringFunc(text, func(value string) string {
+			placeholder, ok := s.placeholders[value]
+			if !ok {
+				s.counts[pattern.name]++
+				placeholder = fmt.Sprintf("[%v_%v]", pattern.name, s.counts[pattern.name])
+				s.placeholders[value] = placeholder
+				s.originals[placeholder] = value
+			}
+			return placeholder
+		})
+	}
+	return text
+}
+
+// Restore puts the originals back in place of the placeholders of the session
+func (s *Session) Restore(text string) string {
+	if len(s.originals) == 0 {
+		return text
+	}
+
+	pairs := make([]string, 0, len(s.originals)*2)
+	for placeholder, value := range s.originals {
+		pairs = append(pairs, placeholder, value)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Redacted reports whether the session replaced any personal data
+func (s *Session) Redacted() bool {
+	return len(s.originals) > 0
+}
@@ -0,0 +1,80 @@
+package redact
+
+import "testing"
+
+func TestSession_Redact(t *testing.T) {
+	redactor, err := NewRedactor(DefaultPatterns)
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "nothing to redact",
+			text: "resep nasi goreng untuk 4 orang",
+			want: "resep nasi goreng untuk 4 orang",
+		},
+		{
+			name: "email, phone and nik",
+			text: "Pelanggan budi.s@example.co.id, hp 0812-3456-7890, NIK 3171234567890001",
+			want: "Pelanggan [EMAIL_1], hp [PHONE_1], NIK [NIK_1]",
+		},
+		{
+			name: "international phone and repeated values",
+			text: "hubungi +6281234567890 atau +6281234567890, cc a@b.com dan c@d.com",
+			want: "hubungi [PHONE_1] atau [PHONE_1], cc [EMAIL_1] dan [EMAIL_2]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := redactor.NewSession()
+			got := session.Redact(tt.text)
+			if got != tt.want {
+				t.Errorf("Session.Redact() = %v, want %v", got, tt.want)
+			}
+			if restored := session.Restore(got); restored != tt.text {
+				t.Errorf("Session.Restore() = %v, want %v", restored, tt.text)
+			}
+		})
+	}
+}
+
+func TestSession_sharedAcrossTexts(t *testing.T) {
+	redactor, _ := NewRedactor(DefaultPatterns)
+	session := redactor.NewSession()
+
+	first := session.Redact("email saya a@b.com")
+	second := session.Redact("kirim ke a@b.com dan x@y.com")
+	if first != "email saya [EMAIL_1]" || second != "kirim ke [EMAIL_1] dan [EMAIL_2]" {
+		t.Errorf("Session.Redact() = %v | %v", first, second)
+	}
+
+	answer := session.Restore("Sudah dikirim ke [EMAIL_2], bukan [EMAIL_1]. [EMAIL_3] tidak dikenal.")
+	if answer != "Sudah dikirim ke x@y.com, bukan a@b.com. [EMAIL_3] tidak dikenal." {
+		t.Errorf("Session.Restore() = %v", answer)
+	}
+}
+
+func TestNewRedactor(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []Pattern
+		wantErr  bool
+	}{
+		{name: "custom pattern", patterns: []Pattern{{Name: "NPWP", Pattern: `\d{2}\.\d{3}\.\d{3}\.\d-\d{3}\.\d{3}`}}},
+		{name: "name not valid", patterns: []Pattern{{Name: "kartu kredit", Pattern: `\d+`}}, wantErr: true},
+		{name: "pattern not valid", patterns: []Pattern{{Name: "CARD", Pattern: `(`}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRedactor(tt.patterns)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRedactor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
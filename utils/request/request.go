@@ -1,15 +1,23 @@
 package request
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"strings"
 
 	"github.com/fadilahonespot/chatbot/utils/logger"
 )
 
 const (
 	RequestBodyKey = "requestBody"
+	ClientIPKey    = "clientIp"
 )
 
 // SetRequestInContext sets the request data in the context.
@@ -37,3 +45,170 @@ func GetRequestFromContext(ctx context.Context, req any) error {
 	logger.Info(ctx, "[REQUEST]", req)
 	return nil
 }
+
+// GetBodyFromContext returns the request body as it was read, for requests
+// whose signature is computed over the raw bytes.
+func GetBodyFromContext(ctx context.Context) ([]byte, error) {
+	requestData, ok := ctx.Value(RequestBodyKey).([]byte)
+	if !ok {
+		return nil, errors.New("failed to get request body")
+	}
+	return requestData, nil
+}
+
+// SetClientIPInContext sets the address of the client in the context.
+func SetClientIPInContext(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, ClientIPKey, clientIP(r))
+}
+
+// GetClientIPFromContext returns the address of the client set in the context.
+func GetClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(ClientIPKey).(string)
+	return ip
+}
+
+// clientIP returns the address the request came from. Behind proxies listed
+// in TRUSTED_PROXIES it is the right-most address of X-Forwarded-For that is
+// not one of them, or X-Real-IP, since only the entries added by the trusted
+// proxies can be believed. The headers of anyone else are ignored.
+func clientIP(r *http.Request) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+
+	proxies := trustedProxies()
+	if !isTrusted(proxies, remote) {
+		return remote
+	}
+
+	var forwarded []string
+	for _, value := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(value, ",")...)
+	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if net.ParseIP(ip) == nil {
+			break
+		}
+		if !isTrusted(proxies, ip) || i == 0 {
+			return ip
+		}
+	}
+
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+	return remote
+}
+
+// trustedProxies returns the addresses and networks in TRUSTED_PROXIES, a
+// comma separated list such as "10.0.0.1,172.16.0.0/12"
+func trustedProxies() (resp []*net.IPNet) {
+	for _, value := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			resp = append(resp, network)
+		}
+	}
+	return
+}
+
+// isTrusted reports whether the address is one of the trusted proxies
+func isTrusted(proxies []*net.IPNet, address string) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// File is a file uploaded in a multipart request.
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// GetMultipartFromContext parses a multipart request. The body was already
+// consumed into the context by the logger middleware, so the request is
+// parsed from the stored body.
+func GetMultipartFromContext(r *http.Request, maxSize int64) (form *multipart.Form, err error) {
+	requestData, ok := r.Context().Value(RequestBodyKey).([]byte)
+	if !ok {
+		return nil, errors.New("failed to get request body")
+	}
+
+	req := r.Clone(r.Context())
+	req.Body = io.NopCloser(bytes.NewReader(requestData))
+	err = req.ParseMultipartForm(maxSize)
+	if err != nil {
+		return
+	}
+
+	form = req.MultipartForm
+	return
+}
+
+// GetFileFromContext reads the file in the given field of a multipart request.
+func GetFileFromContext(r *http.Request, field string, maxSize int64) (file File, err error) {
+	form, err := GetMultipartFromContext(r, maxSize)
+	if err != nil {
+		return
+	}
+
+	files, err := ReadFiles(form, field)
+	if err != nil {
+		return
+	}
+	if len(files) == 0 {
+		return file, http.ErrMissingFile
+	}
+
+	file = files[0]
+	return
+}
+
+// ReadFiles reads every file in the given field of a parsed multipart form.
+func ReadFiles(form *multipart.Form, field string) (files []File, err error) {
+	headers := form.File[field]
+	for i := 0; i < len(headers); i++ {
+		part, errRes := headers[i].Open()
+		if errRes != nil {
+			return nil, errRes
+		}
+
+		data, errRes := io.ReadAll(part)
+		part.Close()
+		if errRes != nil {
+			return nil, errRes
+		}
+
+		files = append(files, File{
+			Name:        headers[i].Filename,
+			ContentType: headers[i].Header.Get("Content-Type"),
+			Data:        data,
+		})
+	}
+	return
+}
+
+// IsMultipart reports whether the request body is a multipart form.
+func IsMultipart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
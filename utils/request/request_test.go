@@ -0,0 +1,90 @@
+package request
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		proxies    string
+		remoteAddr string
+		forwarded  []string
+		realIp     string
+		want       string
+	}{
+		{
+			name:       "direct",
+			remoteAddr: "203.0.113.7:52100",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forged header without proxies",
+			remoteAddr: "203.0.113.7:52100",
+			forwarded:  []string{"198.51.100.1"},
+			realIp:     "198.51.100.2",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forged header from an untrusted address",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "203.0.113.7:52100",
+			forwarded:  []string{"198.51.100.1"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "behind a trusted proxy",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.2:52100",
+			forwarded:  []string{"203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "forged entry before the proxy",
+			proxies:    "10.0.0.0/8",
+			remoteAddr: "10.0.0.2:52100",
+			forwarded:  []string{"198.51.100.1, 203.0.113.7"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "chain of trusted proxies",
+			proxies:    "10.0.0.2, 10.0.0.3",
+			remoteAddr: "10.0.0.2:52100",
+			forwarded:  []string{"198.51.100.1, 203.0.113.7", "10.0.0.3"},
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "real ip from a trusted proxy",
+			proxies:    "10.0.0.2",
+			remoteAddr: "10.0.0.2:52100",
+			realIp:     "203.0.113.7",
+			want:       "203.0.113.7",
+		},
+		{
+			name:       "malformed entry",
+			proxies:    "10.0.0.2",
+			remoteAddr: "10.0.0.2:52100",
+			forwarded:  []string{"unknown"},
+			want:       "10.0.0.2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TRUSTED_PROXIES", tt.proxies)
+			r := httptest.NewRequest(http.MethodGet, "/chat", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			if tt.realIp != "" {
+				r.Header.Set("X-Real-IP", tt.realIp)
+			}
+
+			if got := clientIP(r); got != tt.want {
+				t.Errorf("clientIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
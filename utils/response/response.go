@@ -35,7 +35,7 @@ func ResponseSuccess(w http.ResponseWriter, data interface{}) {
 //
 // The response will have a status code of http.StatusInternalServerError and a content type of "application/json".
 //
-// The error will be encoded as JSON and included in the response body. If the error is an ApplicationError, its ErrorCode and ErrorMessage fields will be used for the response code and message, respectively. Otherwise, a generic error message will be used.
+// The error will be encoded as JSON and included in the response body. If the error is an ApplicationError, its ErrorCode and ErrorMessage fields will be used for the response code and message, respectively, and its Data, when set, for the data. Otherwise, a generic error message will be used.
 func ResponseError(w http.ResponseWriter, err error) {
 	resp := response.Response{
 		Code:    http.StatusInternalServerError,
@@ -46,6 +46,9 @@ func ResponseError(w http.ResponseWriter, err error) {
 	if he, ok := err.(*errors.ApplicationError); ok {
 		resp.Code = he.ErrorCode
 		resp.Message = he.Error()
+		if he.Data != nil {
+			resp.Data = he.Data
+		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")